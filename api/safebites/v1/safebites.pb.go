@@ -0,0 +1,3123 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: safebites/v1/safebites.proto
+
+package safebitesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AnalysisStage int32
+
+const (
+	AnalysisStage_ANALYSIS_STAGE_UNSPECIFIED   AnalysisStage = 0
+	AnalysisStage_ANALYSIS_STAGE_READING_LABEL AnalysisStage = 1
+	AnalysisStage_ANALYSIS_STAGE_LABEL_READ    AnalysisStage = 2
+	AnalysisStage_ANALYSIS_STAGE_SEARCHING     AnalysisStage = 3
+	AnalysisStage_ANALYSIS_STAGE_SCORING       AnalysisStage = 4
+	AnalysisStage_ANALYSIS_STAGE_LOCALIZING    AnalysisStage = 5
+)
+
+// Enum value maps for AnalysisStage.
+var (
+	AnalysisStage_name = map[int32]string{
+		0: "ANALYSIS_STAGE_UNSPECIFIED",
+		1: "ANALYSIS_STAGE_READING_LABEL",
+		2: "ANALYSIS_STAGE_LABEL_READ",
+		3: "ANALYSIS_STAGE_SEARCHING",
+		4: "ANALYSIS_STAGE_SCORING",
+		5: "ANALYSIS_STAGE_LOCALIZING",
+	}
+	AnalysisStage_value = map[string]int32{
+		"ANALYSIS_STAGE_UNSPECIFIED":   0,
+		"ANALYSIS_STAGE_READING_LABEL": 1,
+		"ANALYSIS_STAGE_LABEL_READ":    2,
+		"ANALYSIS_STAGE_SEARCHING":     3,
+		"ANALYSIS_STAGE_SCORING":       4,
+		"ANALYSIS_STAGE_LOCALIZING":    5,
+	}
+)
+
+func (x AnalysisStage) Enum() *AnalysisStage {
+	p := new(AnalysisStage)
+	*p = x
+	return p
+}
+
+func (x AnalysisStage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AnalysisStage) Descriptor() protoreflect.EnumDescriptor {
+	return file_safebites_v1_safebites_proto_enumTypes[0].Descriptor()
+}
+
+func (AnalysisStage) Type() protoreflect.EnumType {
+	return &file_safebites_v1_safebites_proto_enumTypes[0]
+}
+
+func (x AnalysisStage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AnalysisStage.Descriptor instead.
+func (AnalysisStage) EnumDescriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{0}
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Picture       string                 `protobuf:"bytes,4,opt,name=picture,proto3" json:"picture,omitempty"`
+	Preferences   *Preferences           `protobuf:"bytes,5,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetPicture() string {
+	if x != nil {
+		return x.Picture
+	}
+	return ""
+}
+
+func (x *User) GetPreferences() *Preferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *User) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Preferences struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Allergies        []string               `protobuf:"bytes,1,rep,name=allergies,proto3" json:"allergies,omitempty"`
+	DietGoals        []string               `protobuf:"bytes,2,rep,name=diet_goals,json=dietGoals,proto3" json:"diet_goals,omitempty"`
+	AvoidIngredients []string               `protobuf:"bytes,3,rep,name=avoid_ingredients,json=avoidIngredients,proto3" json:"avoid_ingredients,omitempty"`
+	// home_region is one of EU, UK, US, CA, AU, or empty.
+	HomeRegion    string `protobuf:"bytes,4,opt,name=home_region,json=homeRegion,proto3" json:"home_region,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Preferences) Reset() {
+	*x = Preferences{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Preferences) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Preferences) ProtoMessage() {}
+
+func (x *Preferences) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Preferences.ProtoReflect.Descriptor instead.
+func (*Preferences) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Preferences) GetAllergies() []string {
+	if x != nil {
+		return x.Allergies
+	}
+	return nil
+}
+
+func (x *Preferences) GetDietGoals() []string {
+	if x != nil {
+		return x.DietGoals
+	}
+	return nil
+}
+
+func (x *Preferences) GetAvoidIngredients() []string {
+	if x != nil {
+		return x.AvoidIngredients
+	}
+	return nil
+}
+
+func (x *Preferences) GetHomeRegion() string {
+	if x != nil {
+		return x.HomeRegion
+	}
+	return ""
+}
+
+type GetMeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeRequest) Reset() {
+	*x = GetMeRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeRequest) ProtoMessage() {}
+
+func (x *GetMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeRequest.ProtoReflect.Descriptor instead.
+func (*GetMeRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{2}
+}
+
+type GetMeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeResponse) Reset() {
+	*x = GetMeResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeResponse) ProtoMessage() {}
+
+func (x *GetMeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeResponse.ProtoReflect.Descriptor instead.
+func (*GetMeResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetMeResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type UpsertMeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Picture       string                 `protobuf:"bytes,3,opt,name=picture,proto3" json:"picture,omitempty"`
+	Preferences   *Preferences           `protobuf:"bytes,4,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertMeRequest) Reset() {
+	*x = UpsertMeRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertMeRequest) ProtoMessage() {}
+
+func (x *UpsertMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertMeRequest.ProtoReflect.Descriptor instead.
+func (*UpsertMeRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpsertMeRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UpsertMeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpsertMeRequest) GetPicture() string {
+	if x != nil {
+		return x.Picture
+	}
+	return ""
+}
+
+func (x *UpsertMeRequest) GetPreferences() *Preferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+type UpsertMeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertMeResponse) Reset() {
+	*x = UpsertMeResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertMeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertMeResponse) ProtoMessage() {}
+
+func (x *UpsertMeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertMeResponse.ProtoReflect.Descriptor instead.
+func (*UpsertMeResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpsertMeResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type UpdatePreferencesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Preferences   *Preferences           `protobuf:"bytes,1,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePreferencesRequest) Reset() {
+	*x = UpdatePreferencesRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePreferencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePreferencesRequest) ProtoMessage() {}
+
+func (x *UpdatePreferencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePreferencesRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePreferencesRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdatePreferencesRequest) GetPreferences() *Preferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+type UpdatePreferencesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePreferencesResponse) Reset() {
+	*x = UpdatePreferencesResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePreferencesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePreferencesResponse) ProtoMessage() {}
+
+func (x *UpdatePreferencesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePreferencesResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePreferencesResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdatePreferencesResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type Scan struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId      string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductName string                 `protobuf:"bytes,3,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	Brand       string                 `protobuf:"bytes,4,opt,name=brand,proto3" json:"brand,omitempty"`
+	// image is a signed, expiring download URL when image_id is set.
+	Image   string `protobuf:"bytes,5,opt,name=image,proto3" json:"image,omitempty"`
+	ImageId string `protobuf:"bytes,6,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	// safety_score is between 0 and 100.
+	SafetyScore   int32                  `protobuf:"varint,7,opt,name=safety_score,json=safetyScore,proto3" json:"safety_score,omitempty"`
+	IsSafe        bool                   `protobuf:"varint,8,opt,name=is_safe,json=isSafe,proto3" json:"is_safe,omitempty"`
+	Ingredients   []*structpb.Struct     `protobuf:"bytes,9,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	Sources       []*Source              `protobuf:"bytes,10,rep,name=sources,proto3" json:"sources,omitempty"`
+	Confidence    *Confidence            `protobuf:"bytes,11,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Scan) Reset() {
+	*x = Scan{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Scan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Scan) ProtoMessage() {}
+
+func (x *Scan) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Scan.ProtoReflect.Descriptor instead.
+func (*Scan) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Scan) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Scan) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Scan) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Scan) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *Scan) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Scan) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *Scan) GetSafetyScore() int32 {
+	if x != nil {
+		return x.SafetyScore
+	}
+	return 0
+}
+
+func (x *Scan) GetIsSafe() bool {
+	if x != nil {
+		return x.IsSafe
+	}
+	return false
+}
+
+func (x *Scan) GetIngredients() []*structpb.Struct {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *Scan) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *Scan) GetConfidence() *Confidence {
+	if x != nil {
+		return x.Confidence
+	}
+	return nil
+}
+
+func (x *Scan) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type ListScansRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// limit defaults to 20 and is capped at 100.
+	Limit         int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScansRequest) Reset() {
+	*x = ListScansRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScansRequest) ProtoMessage() {}
+
+func (x *ListScansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScansRequest.ProtoReflect.Descriptor instead.
+func (*ListScansRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListScansRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListScansResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scans         []*Scan                `protobuf:"bytes,1,rep,name=scans,proto3" json:"scans,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScansResponse) Reset() {
+	*x = ListScansResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScansResponse) ProtoMessage() {}
+
+func (x *ListScansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScansResponse.ProtoReflect.Descriptor instead.
+func (*ListScansResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListScansResponse) GetScans() []*Scan {
+	if x != nil {
+		return x.Scans
+	}
+	return nil
+}
+
+type CreateScanRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is generated when empty. Pass the scan_id of an analysis to keep its
+	// agent sessions linked to the scan.
+	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductName string `protobuf:"bytes,2,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	Brand       string `protobuf:"bytes,3,opt,name=brand,proto3" json:"brand,omitempty"`
+	Image       string `protobuf:"bytes,4,opt,name=image,proto3" json:"image,omitempty"`
+	// image_id must reference an image stored by AnalyzeImage.
+	ImageId       string             `protobuf:"bytes,5,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	SafetyScore   int32              `protobuf:"varint,6,opt,name=safety_score,json=safetyScore,proto3" json:"safety_score,omitempty"`
+	IsSafe        bool               `protobuf:"varint,7,opt,name=is_safe,json=isSafe,proto3" json:"is_safe,omitempty"`
+	Ingredients   []*structpb.Struct `protobuf:"bytes,8,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	Sources       []*Source          `protobuf:"bytes,9,rep,name=sources,proto3" json:"sources,omitempty"`
+	Confidence    *Confidence        `protobuf:"bytes,10,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateScanRequest) Reset() {
+	*x = CreateScanRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateScanRequest) ProtoMessage() {}
+
+func (x *CreateScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateScanRequest.ProtoReflect.Descriptor instead.
+func (*CreateScanRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateScanRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateScanRequest) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *CreateScanRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *CreateScanRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *CreateScanRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *CreateScanRequest) GetSafetyScore() int32 {
+	if x != nil {
+		return x.SafetyScore
+	}
+	return 0
+}
+
+func (x *CreateScanRequest) GetIsSafe() bool {
+	if x != nil {
+		return x.IsSafe
+	}
+	return false
+}
+
+func (x *CreateScanRequest) GetIngredients() []*structpb.Struct {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *CreateScanRequest) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *CreateScanRequest) GetConfidence() *Confidence {
+	if x != nil {
+		return x.Confidence
+	}
+	return nil
+}
+
+type CreateScanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scan          *Scan                  `protobuf:"bytes,1,opt,name=scan,proto3" json:"scan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateScanResponse) Reset() {
+	*x = CreateScanResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateScanResponse) ProtoMessage() {}
+
+func (x *CreateScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateScanResponse.ProtoReflect.Descriptor instead.
+func (*CreateScanResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateScanResponse) GetScan() *Scan {
+	if x != nil {
+		return x.Scan
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{13}
+}
+
+type GetStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalScans    int32                  `protobuf:"varint,1,opt,name=total_scans,json=totalScans,proto3" json:"total_scans,omitempty"`
+	TodayScans    int32                  `protobuf:"varint,2,opt,name=today_scans,json=todayScans,proto3" json:"today_scans,omitempty"`
+	SafeToday     int32                  `protobuf:"varint,3,opt,name=safe_today,json=safeToday,proto3" json:"safe_today,omitempty"`
+	RiskyToday    int32                  `protobuf:"varint,4,opt,name=risky_today,json=riskyToday,proto3" json:"risky_today,omitempty"`
+	AverageScore  float64                `protobuf:"fixed64,5,opt,name=average_score,json=averageScore,proto3" json:"average_score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetStatsResponse) GetTotalScans() int32 {
+	if x != nil {
+		return x.TotalScans
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetTodayScans() int32 {
+	if x != nil {
+		return x.TodayScans
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetSafeToday() int32 {
+	if x != nil {
+		return x.SafeToday
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetRiskyToday() int32 {
+	if x != nil {
+		return x.RiskyToday
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetAverageScore() float64 {
+	if x != nil {
+		return x.AverageScore
+	}
+	return 0
+}
+
+type Favorite struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductName   string                 `protobuf:"bytes,3,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	Brand         string                 `protobuf:"bytes,4,opt,name=brand,proto3" json:"brand,omitempty"`
+	SafetyScore   *int32                 `protobuf:"varint,5,opt,name=safety_score,json=safetyScore,proto3,oneof" json:"safety_score,omitempty"`
+	Image         string                 `protobuf:"bytes,6,opt,name=image,proto3" json:"image,omitempty"`
+	AddedAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=added_at,json=addedAt,proto3" json:"added_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Favorite) Reset() {
+	*x = Favorite{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Favorite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Favorite) ProtoMessage() {}
+
+func (x *Favorite) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Favorite.ProtoReflect.Descriptor instead.
+func (*Favorite) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Favorite) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Favorite) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Favorite) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Favorite) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *Favorite) GetSafetyScore() int32 {
+	if x != nil && x.SafetyScore != nil {
+		return *x.SafetyScore
+	}
+	return 0
+}
+
+func (x *Favorite) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Favorite) GetAddedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AddedAt
+	}
+	return nil
+}
+
+type ListFavoritesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFavoritesRequest) Reset() {
+	*x = ListFavoritesRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFavoritesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFavoritesRequest) ProtoMessage() {}
+
+func (x *ListFavoritesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFavoritesRequest.ProtoReflect.Descriptor instead.
+func (*ListFavoritesRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{16}
+}
+
+type ListFavoritesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Favorites     []*Favorite            `protobuf:"bytes,1,rep,name=favorites,proto3" json:"favorites,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFavoritesResponse) Reset() {
+	*x = ListFavoritesResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFavoritesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFavoritesResponse) ProtoMessage() {}
+
+func (x *ListFavoritesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFavoritesResponse.ProtoReflect.Descriptor instead.
+func (*ListFavoritesResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListFavoritesResponse) GetFavorites() []*Favorite {
+	if x != nil {
+		return x.Favorites
+	}
+	return nil
+}
+
+type AddFavoriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductName   string                 `protobuf:"bytes,1,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	Brand         string                 `protobuf:"bytes,2,opt,name=brand,proto3" json:"brand,omitempty"`
+	SafetyScore   *int32                 `protobuf:"varint,3,opt,name=safety_score,json=safetyScore,proto3,oneof" json:"safety_score,omitempty"`
+	Image         string                 `protobuf:"bytes,4,opt,name=image,proto3" json:"image,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFavoriteRequest) Reset() {
+	*x = AddFavoriteRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFavoriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFavoriteRequest) ProtoMessage() {}
+
+func (x *AddFavoriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFavoriteRequest.ProtoReflect.Descriptor instead.
+func (*AddFavoriteRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AddFavoriteRequest) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *AddFavoriteRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *AddFavoriteRequest) GetSafetyScore() int32 {
+	if x != nil && x.SafetyScore != nil {
+		return *x.SafetyScore
+	}
+	return 0
+}
+
+func (x *AddFavoriteRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+type AddFavoriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Favorite      *Favorite              `protobuf:"bytes,1,opt,name=favorite,proto3" json:"favorite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFavoriteResponse) Reset() {
+	*x = AddFavoriteResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFavoriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFavoriteResponse) ProtoMessage() {}
+
+func (x *AddFavoriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFavoriteResponse.ProtoReflect.Descriptor instead.
+func (*AddFavoriteResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AddFavoriteResponse) GetFavorite() *Favorite {
+	if x != nil {
+		return x.Favorite
+	}
+	return nil
+}
+
+type DeleteFavoriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FavoriteId    int64                  `protobuf:"varint,1,opt,name=favorite_id,json=favoriteId,proto3" json:"favorite_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFavoriteRequest) Reset() {
+	*x = DeleteFavoriteRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFavoriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFavoriteRequest) ProtoMessage() {}
+
+func (x *DeleteFavoriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFavoriteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFavoriteRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeleteFavoriteRequest) GetFavoriteId() int64 {
+	if x != nil {
+		return x.FavoriteId
+	}
+	return 0
+}
+
+type DeleteFavoriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFavoriteResponse) Reset() {
+	*x = DeleteFavoriteResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFavoriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFavoriteResponse) ProtoMessage() {}
+
+func (x *DeleteFavoriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFavoriteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFavoriteResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{21}
+}
+
+type CheckFavoriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductName   string                 `protobuf:"bytes,1,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckFavoriteRequest) Reset() {
+	*x = CheckFavoriteRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckFavoriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckFavoriteRequest) ProtoMessage() {}
+
+func (x *CheckFavoriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckFavoriteRequest.ProtoReflect.Descriptor instead.
+func (*CheckFavoriteRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CheckFavoriteRequest) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+type CheckFavoriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IsFavorite    bool                   `protobuf:"varint,1,opt,name=is_favorite,json=isFavorite,proto3" json:"is_favorite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckFavoriteResponse) Reset() {
+	*x = CheckFavoriteResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckFavoriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckFavoriteResponse) ProtoMessage() {}
+
+func (x *CheckFavoriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckFavoriteResponse.ProtoReflect.Descriptor instead.
+func (*CheckFavoriteResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CheckFavoriteResponse) GetIsFavorite() bool {
+	if x != nil {
+		return x.IsFavorite
+	}
+	return false
+}
+
+type DietaryTemplate struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Key              string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description      string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Allergies        []string               `protobuf:"bytes,4,rep,name=allergies,proto3" json:"allergies,omitempty"`
+	DietGoals        []string               `protobuf:"bytes,5,rep,name=diet_goals,json=dietGoals,proto3" json:"diet_goals,omitempty"`
+	AvoidIngredients []string               `protobuf:"bytes,6,rep,name=avoid_ingredients,json=avoidIngredients,proto3" json:"avoid_ingredients,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DietaryTemplate) Reset() {
+	*x = DietaryTemplate{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DietaryTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DietaryTemplate) ProtoMessage() {}
+
+func (x *DietaryTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DietaryTemplate.ProtoReflect.Descriptor instead.
+func (*DietaryTemplate) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DietaryTemplate) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *DietaryTemplate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DietaryTemplate) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DietaryTemplate) GetAllergies() []string {
+	if x != nil {
+		return x.Allergies
+	}
+	return nil
+}
+
+func (x *DietaryTemplate) GetDietGoals() []string {
+	if x != nil {
+		return x.DietGoals
+	}
+	return nil
+}
+
+func (x *DietaryTemplate) GetAvoidIngredients() []string {
+	if x != nil {
+		return x.AvoidIngredients
+	}
+	return nil
+}
+
+type ListTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTemplatesRequest) Reset() {
+	*x = ListTemplatesRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTemplatesRequest) ProtoMessage() {}
+
+func (x *ListTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{25}
+}
+
+type ListTemplatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Templates     []*DietaryTemplate     `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTemplatesResponse) Reset() {
+	*x = ListTemplatesResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTemplatesResponse) ProtoMessage() {}
+
+func (x *ListTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListTemplatesResponse) GetTemplates() []*DietaryTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type ApplyTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TemplateKey   string                 `protobuf:"bytes,1,opt,name=template_key,json=templateKey,proto3" json:"template_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyTemplateRequest) Reset() {
+	*x = ApplyTemplateRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyTemplateRequest) ProtoMessage() {}
+
+func (x *ApplyTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyTemplateRequest.ProtoReflect.Descriptor instead.
+func (*ApplyTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ApplyTemplateRequest) GetTemplateKey() string {
+	if x != nil {
+		return x.TemplateKey
+	}
+	return ""
+}
+
+type ApplyTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Template      *DietaryTemplate       `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyTemplateResponse) Reset() {
+	*x = ApplyTemplateResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyTemplateResponse) ProtoMessage() {}
+
+func (x *ApplyTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyTemplateResponse.ProtoReflect.Descriptor instead.
+func (*ApplyTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ApplyTemplateResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *ApplyTemplateResponse) GetTemplate() *DietaryTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+type Source struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Uri           string                 `protobuf:"bytes,2,opt,name=uri,proto3" json:"uri,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Source) Reset() {
+	*x = Source{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Source) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Source) ProtoMessage() {}
+
+func (x *Source) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Source.ProtoReflect.Descriptor instead.
+func (*Source) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Source) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Source) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+type RegionStatus struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Region string                 `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	// status is permitted, warning_label, restricted, or banned.
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Note          string `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegionStatus) Reset() {
+	*x = RegionStatus{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegionStatus) ProtoMessage() {}
+
+func (x *RegionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegionStatus.ProtoReflect.Descriptor instead.
+func (*RegionStatus) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RegionStatus) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *RegionStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RegionStatus) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type IngredientScore struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IngredientName string                 `protobuf:"bytes,1,opt,name=ingredient_name,json=ingredientName,proto3" json:"ingredient_name,omitempty"`
+	OriginalName   string                 `protobuf:"bytes,2,opt,name=original_name,json=originalName,proto3" json:"original_name,omitempty"`
+	AdditiveCode   string                 `protobuf:"bytes,3,opt,name=additive_code,json=additiveCode,proto3" json:"additive_code,omitempty"`
+	// safety_score is the scorer's 0-10 score, as text.
+	SafetyScore   string          `protobuf:"bytes,4,opt,name=safety_score,json=safetyScore,proto3" json:"safety_score,omitempty"`
+	Reasoning     string          `protobuf:"bytes,5,opt,name=reasoning,proto3" json:"reasoning,omitempty"`
+	Regulatory    []*RegionStatus `protobuf:"bytes,6,rep,name=regulatory,proto3" json:"regulatory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngredientScore) Reset() {
+	*x = IngredientScore{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngredientScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngredientScore) ProtoMessage() {}
+
+func (x *IngredientScore) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngredientScore.ProtoReflect.Descriptor instead.
+func (*IngredientScore) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *IngredientScore) GetIngredientName() string {
+	if x != nil {
+		return x.IngredientName
+	}
+	return ""
+}
+
+func (x *IngredientScore) GetOriginalName() string {
+	if x != nil {
+		return x.OriginalName
+	}
+	return ""
+}
+
+func (x *IngredientScore) GetAdditiveCode() string {
+	if x != nil {
+		return x.AdditiveCode
+	}
+	return ""
+}
+
+func (x *IngredientScore) GetSafetyScore() string {
+	if x != nil {
+		return x.SafetyScore
+	}
+	return ""
+}
+
+func (x *IngredientScore) GetReasoning() string {
+	if x != nil {
+		return x.Reasoning
+	}
+	return ""
+}
+
+func (x *IngredientScore) GetRegulatory() []*RegionStatus {
+	if x != nil {
+		return x.Regulatory
+	}
+	return nil
+}
+
+type ScorerResult struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	IngredientScores []*IngredientScore     `protobuf:"bytes,1,rep,name=ingredient_scores,json=ingredientScores,proto3" json:"ingredient_scores,omitempty"`
+	OverallScore     float64                `protobuf:"fixed64,2,opt,name=overall_score,json=overallScore,proto3" json:"overall_score,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ScorerResult) Reset() {
+	*x = ScorerResult{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScorerResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScorerResult) ProtoMessage() {}
+
+func (x *ScorerResult) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScorerResult.ProtoReflect.Descriptor instead.
+func (*ScorerResult) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ScorerResult) GetIngredientScores() []*IngredientScore {
+	if x != nil {
+		return x.IngredientScores
+	}
+	return nil
+}
+
+func (x *ScorerResult) GetOverallScore() float64 {
+	if x != nil {
+		return x.OverallScore
+	}
+	return 0
+}
+
+type Confidence struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Ocr               float64                `protobuf:"fixed64,1,opt,name=ocr,proto3" json:"ocr,omitempty"`
+	Sources           float64                `protobuf:"fixed64,2,opt,name=sources,proto3" json:"sources,omitempty"`
+	Scorer            float64                `protobuf:"fixed64,3,opt,name=scorer,proto3" json:"scorer,omitempty"`
+	Overall           float64                `protobuf:"fixed64,4,opt,name=overall,proto3" json:"overall,omitempty"`
+	NeedsVerification bool                   `protobuf:"varint,5,opt,name=needs_verification,json=needsVerification,proto3" json:"needs_verification,omitempty"`
+	Notes             []string               `protobuf:"bytes,6,rep,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Confidence) Reset() {
+	*x = Confidence{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Confidence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Confidence) ProtoMessage() {}
+
+func (x *Confidence) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Confidence.ProtoReflect.Descriptor instead.
+func (*Confidence) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Confidence) GetOcr() float64 {
+	if x != nil {
+		return x.Ocr
+	}
+	return 0
+}
+
+func (x *Confidence) GetSources() float64 {
+	if x != nil {
+		return x.Sources
+	}
+	return 0
+}
+
+func (x *Confidence) GetScorer() float64 {
+	if x != nil {
+		return x.Scorer
+	}
+	return 0
+}
+
+func (x *Confidence) GetOverall() float64 {
+	if x != nil {
+		return x.Overall
+	}
+	return 0
+}
+
+func (x *Confidence) GetNeedsVerification() bool {
+	if x != nil {
+		return x.NeedsVerification
+	}
+	return false
+}
+
+func (x *Confidence) GetNotes() []string {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+type AnalyzeImageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Image []byte                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	// mime_type is detected from the bytes when empty.
+	MimeType string `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	// language is the ISO 639-1 code to answer in; English when empty or
+	// unsupported.
+	Language      string `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeImageRequest) Reset() {
+	*x = AnalyzeImageRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeImageRequest) ProtoMessage() {}
+
+func (x *AnalyzeImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeImageRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeImageRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *AnalyzeImageRequest) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *AnalyzeImageRequest) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *AnalyzeImageRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type AnalyzeProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductName   string                 `protobuf:"bytes,1,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeProductRequest) Reset() {
+	*x = AnalyzeProductRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeProductRequest) ProtoMessage() {}
+
+func (x *AnalyzeProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeProductRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeProductRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *AnalyzeProductRequest) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *AnalyzeProductRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type AnalyzeProgress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Stage AnalysisStage          `protobuf:"varint,1,opt,name=stage,proto3,enum=safebites.v1.AnalysisStage" json:"stage,omitempty"`
+	// product_name is set once the product is known.
+	ProductName string `protobuf:"bytes,2,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	// ingredient_count is set from ANALYSIS_STAGE_SCORING on.
+	IngredientCount int32 `protobuf:"varint,3,opt,name=ingredient_count,json=ingredientCount,proto3" json:"ingredient_count,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AnalyzeProgress) Reset() {
+	*x = AnalyzeProgress{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeProgress) ProtoMessage() {}
+
+func (x *AnalyzeProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeProgress.ProtoReflect.Descriptor instead.
+func (*AnalyzeProgress) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *AnalyzeProgress) GetStage() AnalysisStage {
+	if x != nil {
+		return x.Stage
+	}
+	return AnalysisStage_ANALYSIS_STAGE_UNSPECIFIED
+}
+
+func (x *AnalyzeProgress) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *AnalyzeProgress) GetIngredientCount() int32 {
+	if x != nil {
+		return x.IngredientCount
+	}
+	return 0
+}
+
+type AnalysisResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// scan_id identifies the analysis; pass it to CreateScan to save it.
+	ScanId              string        `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	ProductName         string        `protobuf:"bytes,2,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	DetectedLanguage    string        `protobuf:"bytes,3,opt,name=detected_language,json=detectedLanguage,proto3" json:"detected_language,omitempty"`
+	Language            string        `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
+	IngredientBreakdown *ScorerResult `protobuf:"bytes,5,opt,name=ingredient_breakdown,json=ingredientBreakdown,proto3" json:"ingredient_breakdown,omitempty"`
+	Sources             []*Source     `protobuf:"bytes,6,rep,name=sources,proto3" json:"sources,omitempty"`
+	Confidence          *Confidence   `protobuf:"bytes,7,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// image_id and image_url are set when AnalyzeImage stored the upload.
+	ImageId       string `protobuf:"bytes,8,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	ImageUrl      string `protobuf:"bytes,9,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalysisResult) Reset() {
+	*x = AnalysisResult{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalysisResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalysisResult) ProtoMessage() {}
+
+func (x *AnalysisResult) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalysisResult.ProtoReflect.Descriptor instead.
+func (*AnalysisResult) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *AnalysisResult) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetDetectedLanguage() string {
+	if x != nil {
+		return x.DetectedLanguage
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetIngredientBreakdown() *ScorerResult {
+	if x != nil {
+		return x.IngredientBreakdown
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetConfidence() *Confidence {
+	if x != nil {
+		return x.Confidence
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+type AnalyzeEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*AnalyzeEvent_Progress
+	//	*AnalyzeEvent_Result
+	Event         isAnalyzeEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeEvent) Reset() {
+	*x = AnalyzeEvent{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeEvent) ProtoMessage() {}
+
+func (x *AnalyzeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeEvent.ProtoReflect.Descriptor instead.
+func (*AnalyzeEvent) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *AnalyzeEvent) GetEvent() isAnalyzeEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *AnalyzeEvent) GetProgress() *AnalyzeProgress {
+	if x != nil {
+		if x, ok := x.Event.(*AnalyzeEvent_Progress); ok {
+			return x.Progress
+		}
+	}
+	return nil
+}
+
+func (x *AnalyzeEvent) GetResult() *AnalysisResult {
+	if x != nil {
+		if x, ok := x.Event.(*AnalyzeEvent_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isAnalyzeEvent_Event interface {
+	isAnalyzeEvent_Event()
+}
+
+type AnalyzeEvent_Progress struct {
+	Progress *AnalyzeProgress `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type AnalyzeEvent_Result struct {
+	Result *AnalysisResult `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*AnalyzeEvent_Progress) isAnalyzeEvent_Event() {}
+
+func (*AnalyzeEvent_Result) isAnalyzeEvent_Event() {}
+
+type ScoreIngredientsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ingredients are names as printed on the label, at most 100.
+	Ingredients   []string `protobuf:"bytes,1,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoreIngredientsRequest) Reset() {
+	*x = ScoreIngredientsRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoreIngredientsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreIngredientsRequest) ProtoMessage() {}
+
+func (x *ScoreIngredientsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreIngredientsRequest.ProtoReflect.Descriptor instead.
+func (*ScoreIngredientsRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ScoreIngredientsRequest) GetIngredients() []string {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+type ScoreIngredientsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *ScorerResult          `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoreIngredientsResponse) Reset() {
+	*x = ScoreIngredientsResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoreIngredientsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreIngredientsResponse) ProtoMessage() {}
+
+func (x *ScoreIngredientsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreIngredientsResponse.ProtoReflect.Descriptor instead.
+func (*ScoreIngredientsResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ScoreIngredientsResponse) GetResult() *ScorerResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type RecommendRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ProductName string                 `protobuf:"bytes,1,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	// overall_score is the product's 0-10 score from an analysis.
+	OverallScore  float64 `protobuf:"fixed64,2,opt,name=overall_score,json=overallScore,proto3" json:"overall_score,omitempty"`
+	Language      string  `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecommendRequest) Reset() {
+	*x = RecommendRequest{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendRequest) ProtoMessage() {}
+
+func (x *RecommendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendRequest.ProtoReflect.Descriptor instead.
+func (*RecommendRequest) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *RecommendRequest) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *RecommendRequest) GetOverallScore() float64 {
+	if x != nil {
+		return x.OverallScore
+	}
+	return 0
+}
+
+func (x *RecommendRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type Recommendation struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ProductName         string                 `protobuf:"bytes,1,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	HealthScore         string                 `protobuf:"bytes,2,opt,name=health_score,json=healthScore,proto3" json:"health_score,omitempty"`
+	Reason              string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	IngredientBreakdown *ScorerResult          `protobuf:"bytes,4,opt,name=ingredient_breakdown,json=ingredientBreakdown,proto3" json:"ingredient_breakdown,omitempty"`
+	Sources             []*Source              `protobuf:"bytes,5,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Recommendation) Reset() {
+	*x = Recommendation{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recommendation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recommendation) ProtoMessage() {}
+
+func (x *Recommendation) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recommendation.ProtoReflect.Descriptor instead.
+func (*Recommendation) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *Recommendation) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Recommendation) GetHealthScore() string {
+	if x != nil {
+		return x.HealthScore
+	}
+	return ""
+}
+
+func (x *Recommendation) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Recommendation) GetIngredientBreakdown() *ScorerResult {
+	if x != nil {
+		return x.IngredientBreakdown
+	}
+	return nil
+}
+
+func (x *Recommendation) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type RecommendResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Language        string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Recommendations []*Recommendation      `protobuf:"bytes,2,rep,name=recommendations,proto3" json:"recommendations,omitempty"`
+	Sources         []*Source              `protobuf:"bytes,3,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RecommendResponse) Reset() {
+	*x = RecommendResponse{}
+	mi := &file_safebites_v1_safebites_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendResponse) ProtoMessage() {}
+
+func (x *RecommendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_safebites_v1_safebites_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendResponse.ProtoReflect.Descriptor instead.
+func (*RecommendResponse) Descriptor() ([]byte, []int) {
+	return file_safebites_v1_safebites_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *RecommendResponse) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *RecommendResponse) GetRecommendations() []*Recommendation {
+	if x != nil {
+		return x.Recommendations
+	}
+	return nil
+}
+
+func (x *RecommendResponse) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+var File_safebites_v1_safebites_proto protoreflect.FileDescriptor
+
+const file_safebites_v1_safebites_proto_rawDesc = "" +
+	"\n" +
+	"\x1csafebites/v1/safebites.proto\x12\fsafebites.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8d\x02\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\apicture\x18\x04 \x01(\tR\apicture\x12;\n" +
+	"\vpreferences\x18\x05 \x01(\v2\x19.safebites.v1.PreferencesR\vpreferences\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x98\x01\n" +
+	"\vPreferences\x12\x1c\n" +
+	"\tallergies\x18\x01 \x03(\tR\tallergies\x12\x1d\n" +
+	"\n" +
+	"diet_goals\x18\x02 \x03(\tR\tdietGoals\x12+\n" +
+	"\x11avoid_ingredients\x18\x03 \x03(\tR\x10avoidIngredients\x12\x1f\n" +
+	"\vhome_region\x18\x04 \x01(\tR\n" +
+	"homeRegion\"\x0e\n" +
+	"\fGetMeRequest\"7\n" +
+	"\rGetMeResponse\x12&\n" +
+	"\x04user\x18\x01 \x01(\v2\x12.safebites.v1.UserR\x04user\"\x92\x01\n" +
+	"\x0fUpsertMeRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\apicture\x18\x03 \x01(\tR\apicture\x12;\n" +
+	"\vpreferences\x18\x04 \x01(\v2\x19.safebites.v1.PreferencesR\vpreferences\":\n" +
+	"\x10UpsertMeResponse\x12&\n" +
+	"\x04user\x18\x01 \x01(\v2\x12.safebites.v1.UserR\x04user\"W\n" +
+	"\x18UpdatePreferencesRequest\x12;\n" +
+	"\vpreferences\x18\x01 \x01(\v2\x19.safebites.v1.PreferencesR\vpreferences\"C\n" +
+	"\x19UpdatePreferencesResponse\x12&\n" +
+	"\x04user\x18\x01 \x01(\v2\x12.safebites.v1.UserR\x04user\"\xb4\x03\n" +
+	"\x04Scan\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\fproduct_name\x18\x03 \x01(\tR\vproductName\x12\x14\n" +
+	"\x05brand\x18\x04 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05image\x18\x05 \x01(\tR\x05image\x12\x19\n" +
+	"\bimage_id\x18\x06 \x01(\tR\aimageId\x12!\n" +
+	"\fsafety_score\x18\a \x01(\x05R\vsafetyScore\x12\x17\n" +
+	"\ais_safe\x18\b \x01(\bR\x06isSafe\x129\n" +
+	"\vingredients\x18\t \x03(\v2\x17.google.protobuf.StructR\vingredients\x12.\n" +
+	"\asources\x18\n" +
+	" \x03(\v2\x14.safebites.v1.SourceR\asources\x128\n" +
+	"\n" +
+	"confidence\x18\v \x01(\v2\x18.safebites.v1.ConfidenceR\n" +
+	"confidence\x128\n" +
+	"\ttimestamp\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"(\n" +
+	"\x10ListScansRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"=\n" +
+	"\x11ListScansResponse\x12(\n" +
+	"\x05scans\x18\x01 \x03(\v2\x12.safebites.v1.ScanR\x05scans\"\xee\x02\n" +
+	"\x11CreateScanRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fproduct_name\x18\x02 \x01(\tR\vproductName\x12\x14\n" +
+	"\x05brand\x18\x03 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05image\x18\x04 \x01(\tR\x05image\x12\x19\n" +
+	"\bimage_id\x18\x05 \x01(\tR\aimageId\x12!\n" +
+	"\fsafety_score\x18\x06 \x01(\x05R\vsafetyScore\x12\x17\n" +
+	"\ais_safe\x18\a \x01(\bR\x06isSafe\x129\n" +
+	"\vingredients\x18\b \x03(\v2\x17.google.protobuf.StructR\vingredients\x12.\n" +
+	"\asources\x18\t \x03(\v2\x14.safebites.v1.SourceR\asources\x128\n" +
+	"\n" +
+	"confidence\x18\n" +
+	" \x01(\v2\x18.safebites.v1.ConfidenceR\n" +
+	"confidence\"<\n" +
+	"\x12CreateScanResponse\x12&\n" +
+	"\x04scan\x18\x01 \x01(\v2\x12.safebites.v1.ScanR\x04scan\"\x11\n" +
+	"\x0fGetStatsRequest\"\xb9\x01\n" +
+	"\x10GetStatsResponse\x12\x1f\n" +
+	"\vtotal_scans\x18\x01 \x01(\x05R\n" +
+	"totalScans\x12\x1f\n" +
+	"\vtoday_scans\x18\x02 \x01(\x05R\n" +
+	"todayScans\x12\x1d\n" +
+	"\n" +
+	"safe_today\x18\x03 \x01(\x05R\tsafeToday\x12\x1f\n" +
+	"\vrisky_today\x18\x04 \x01(\x05R\n" +
+	"riskyToday\x12#\n" +
+	"\raverage_score\x18\x05 \x01(\x01R\faverageScore\"\xf2\x01\n" +
+	"\bFavorite\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\fproduct_name\x18\x03 \x01(\tR\vproductName\x12\x14\n" +
+	"\x05brand\x18\x04 \x01(\tR\x05brand\x12&\n" +
+	"\fsafety_score\x18\x05 \x01(\x05H\x00R\vsafetyScore\x88\x01\x01\x12\x14\n" +
+	"\x05image\x18\x06 \x01(\tR\x05image\x125\n" +
+	"\badded_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aaddedAtB\x0f\n" +
+	"\r_safety_score\"\x16\n" +
+	"\x14ListFavoritesRequest\"M\n" +
+	"\x15ListFavoritesResponse\x124\n" +
+	"\tfavorites\x18\x01 \x03(\v2\x16.safebites.v1.FavoriteR\tfavorites\"\x9c\x01\n" +
+	"\x12AddFavoriteRequest\x12!\n" +
+	"\fproduct_name\x18\x01 \x01(\tR\vproductName\x12\x14\n" +
+	"\x05brand\x18\x02 \x01(\tR\x05brand\x12&\n" +
+	"\fsafety_score\x18\x03 \x01(\x05H\x00R\vsafetyScore\x88\x01\x01\x12\x14\n" +
+	"\x05image\x18\x04 \x01(\tR\x05imageB\x0f\n" +
+	"\r_safety_score\"I\n" +
+	"\x13AddFavoriteResponse\x122\n" +
+	"\bfavorite\x18\x01 \x01(\v2\x16.safebites.v1.FavoriteR\bfavorite\"8\n" +
+	"\x15DeleteFavoriteRequest\x12\x1f\n" +
+	"\vfavorite_id\x18\x01 \x01(\x03R\n" +
+	"favoriteId\"\x18\n" +
+	"\x16DeleteFavoriteResponse\"9\n" +
+	"\x14CheckFavoriteRequest\x12!\n" +
+	"\fproduct_name\x18\x01 \x01(\tR\vproductName\"8\n" +
+	"\x15CheckFavoriteResponse\x12\x1f\n" +
+	"\vis_favorite\x18\x01 \x01(\bR\n" +
+	"isFavorite\"\xc3\x01\n" +
+	"\x0fDietaryTemplate\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1c\n" +
+	"\tallergies\x18\x04 \x03(\tR\tallergies\x12\x1d\n" +
+	"\n" +
+	"diet_goals\x18\x05 \x03(\tR\tdietGoals\x12+\n" +
+	"\x11avoid_ingredients\x18\x06 \x03(\tR\x10avoidIngredients\"\x16\n" +
+	"\x14ListTemplatesRequest\"T\n" +
+	"\x15ListTemplatesResponse\x12;\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x1d.safebites.v1.DietaryTemplateR\ttemplates\"9\n" +
+	"\x14ApplyTemplateRequest\x12!\n" +
+	"\ftemplate_key\x18\x01 \x01(\tR\vtemplateKey\"z\n" +
+	"\x15ApplyTemplateResponse\x12&\n" +
+	"\x04user\x18\x01 \x01(\v2\x12.safebites.v1.UserR\x04user\x129\n" +
+	"\btemplate\x18\x02 \x01(\v2\x1d.safebites.v1.DietaryTemplateR\btemplate\"0\n" +
+	"\x06Source\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x10\n" +
+	"\x03uri\x18\x02 \x01(\tR\x03uri\"R\n" +
+	"\fRegionStatus\x12\x16\n" +
+	"\x06region\x18\x01 \x01(\tR\x06region\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04note\x18\x03 \x01(\tR\x04note\"\x81\x02\n" +
+	"\x0fIngredientScore\x12'\n" +
+	"\x0fingredient_name\x18\x01 \x01(\tR\x0eingredientName\x12#\n" +
+	"\roriginal_name\x18\x02 \x01(\tR\foriginalName\x12#\n" +
+	"\radditive_code\x18\x03 \x01(\tR\fadditiveCode\x12!\n" +
+	"\fsafety_score\x18\x04 \x01(\tR\vsafetyScore\x12\x1c\n" +
+	"\treasoning\x18\x05 \x01(\tR\treasoning\x12:\n" +
+	"\n" +
+	"regulatory\x18\x06 \x03(\v2\x1a.safebites.v1.RegionStatusR\n" +
+	"regulatory\"\x7f\n" +
+	"\fScorerResult\x12J\n" +
+	"\x11ingredient_scores\x18\x01 \x03(\v2\x1d.safebites.v1.IngredientScoreR\x10ingredientScores\x12#\n" +
+	"\roverall_score\x18\x02 \x01(\x01R\foverallScore\"\xaf\x01\n" +
+	"\n" +
+	"Confidence\x12\x10\n" +
+	"\x03ocr\x18\x01 \x01(\x01R\x03ocr\x12\x18\n" +
+	"\asources\x18\x02 \x01(\x01R\asources\x12\x16\n" +
+	"\x06scorer\x18\x03 \x01(\x01R\x06scorer\x12\x18\n" +
+	"\aoverall\x18\x04 \x01(\x01R\aoverall\x12-\n" +
+	"\x12needs_verification\x18\x05 \x01(\bR\x11needsVerification\x12\x14\n" +
+	"\x05notes\x18\x06 \x03(\tR\x05notes\"d\n" +
+	"\x13AnalyzeImageRequest\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\"V\n" +
+	"\x15AnalyzeProductRequest\x12!\n" +
+	"\fproduct_name\x18\x01 \x01(\tR\vproductName\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\"\x92\x01\n" +
+	"\x0fAnalyzeProgress\x121\n" +
+	"\x05stage\x18\x01 \x01(\x0e2\x1b.safebites.v1.AnalysisStageR\x05stage\x12!\n" +
+	"\fproduct_name\x18\x02 \x01(\tR\vproductName\x12)\n" +
+	"\x10ingredient_count\x18\x03 \x01(\x05R\x0fingredientCount\"\x86\x03\n" +
+	"\x0eAnalysisResult\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\x12!\n" +
+	"\fproduct_name\x18\x02 \x01(\tR\vproductName\x12+\n" +
+	"\x11detected_language\x18\x03 \x01(\tR\x10detectedLanguage\x12\x1a\n" +
+	"\blanguage\x18\x04 \x01(\tR\blanguage\x12M\n" +
+	"\x14ingredient_breakdown\x18\x05 \x01(\v2\x1a.safebites.v1.ScorerResultR\x13ingredientBreakdown\x12.\n" +
+	"\asources\x18\x06 \x03(\v2\x14.safebites.v1.SourceR\asources\x128\n" +
+	"\n" +
+	"confidence\x18\a \x01(\v2\x18.safebites.v1.ConfidenceR\n" +
+	"confidence\x12\x19\n" +
+	"\bimage_id\x18\b \x01(\tR\aimageId\x12\x1b\n" +
+	"\timage_url\x18\t \x01(\tR\bimageUrl\"\x8c\x01\n" +
+	"\fAnalyzeEvent\x12;\n" +
+	"\bprogress\x18\x01 \x01(\v2\x1d.safebites.v1.AnalyzeProgressH\x00R\bprogress\x126\n" +
+	"\x06result\x18\x02 \x01(\v2\x1c.safebites.v1.AnalysisResultH\x00R\x06resultB\a\n" +
+	"\x05event\";\n" +
+	"\x17ScoreIngredientsRequest\x12 \n" +
+	"\vingredients\x18\x01 \x03(\tR\vingredients\"N\n" +
+	"\x18ScoreIngredientsResponse\x122\n" +
+	"\x06result\x18\x01 \x01(\v2\x1a.safebites.v1.ScorerResultR\x06result\"v\n" +
+	"\x10RecommendRequest\x12!\n" +
+	"\fproduct_name\x18\x01 \x01(\tR\vproductName\x12#\n" +
+	"\roverall_score\x18\x02 \x01(\x01R\foverallScore\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\"\xed\x01\n" +
+	"\x0eRecommendation\x12!\n" +
+	"\fproduct_name\x18\x01 \x01(\tR\vproductName\x12!\n" +
+	"\fhealth_score\x18\x02 \x01(\tR\vhealthScore\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12M\n" +
+	"\x14ingredient_breakdown\x18\x04 \x01(\v2\x1a.safebites.v1.ScorerResultR\x13ingredientBreakdown\x12.\n" +
+	"\asources\x18\x05 \x03(\v2\x14.safebites.v1.SourceR\asources\"\xa7\x01\n" +
+	"\x11RecommendResponse\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12F\n" +
+	"\x0frecommendations\x18\x02 \x03(\v2\x1c.safebites.v1.RecommendationR\x0frecommendations\x12.\n" +
+	"\asources\x18\x03 \x03(\v2\x14.safebites.v1.SourceR\asources*\xc9\x01\n" +
+	"\rAnalysisStage\x12\x1e\n" +
+	"\x1aANALYSIS_STAGE_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cANALYSIS_STAGE_READING_LABEL\x10\x01\x12\x1d\n" +
+	"\x19ANALYSIS_STAGE_LABEL_READ\x10\x02\x12\x1c\n" +
+	"\x18ANALYSIS_STAGE_SEARCHING\x10\x03\x12\x1a\n" +
+	"\x16ANALYSIS_STAGE_SCORING\x10\x04\x12\x1d\n" +
+	"\x19ANALYSIS_STAGE_LOCALIZING\x10\x052\x80\x02\n" +
+	"\vUserService\x12@\n" +
+	"\x05GetMe\x12\x1a.safebites.v1.GetMeRequest\x1a\x1b.safebites.v1.GetMeResponse\x12I\n" +
+	"\bUpsertMe\x12\x1d.safebites.v1.UpsertMeRequest\x1a\x1e.safebites.v1.UpsertMeResponse\x12d\n" +
+	"\x11UpdatePreferences\x12&.safebites.v1.UpdatePreferencesRequest\x1a'.safebites.v1.UpdatePreferencesResponse2\xf7\x01\n" +
+	"\vScanService\x12L\n" +
+	"\tListScans\x12\x1e.safebites.v1.ListScansRequest\x1a\x1f.safebites.v1.ListScansResponse\x12O\n" +
+	"\n" +
+	"CreateScan\x12\x1f.safebites.v1.CreateScanRequest\x1a .safebites.v1.CreateScanResponse\x12I\n" +
+	"\bGetStats\x12\x1d.safebites.v1.GetStatsRequest\x1a\x1e.safebites.v1.GetStatsResponse2\xf6\x02\n" +
+	"\x0fFavoriteService\x12X\n" +
+	"\rListFavorites\x12\".safebites.v1.ListFavoritesRequest\x1a#.safebites.v1.ListFavoritesResponse\x12R\n" +
+	"\vAddFavorite\x12 .safebites.v1.AddFavoriteRequest\x1a!.safebites.v1.AddFavoriteResponse\x12[\n" +
+	"\x0eDeleteFavorite\x12#.safebites.v1.DeleteFavoriteRequest\x1a$.safebites.v1.DeleteFavoriteResponse\x12X\n" +
+	"\rCheckFavorite\x12\".safebites.v1.CheckFavoriteRequest\x1a#.safebites.v1.CheckFavoriteResponse2\xc5\x01\n" +
+	"\x0fTemplateService\x12X\n" +
+	"\rListTemplates\x12\".safebites.v1.ListTemplatesRequest\x1a#.safebites.v1.ListTemplatesResponse\x12X\n" +
+	"\rApplyTemplate\x12\".safebites.v1.ApplyTemplateRequest\x1a#.safebites.v1.ApplyTemplateResponse2\x99\x02\n" +
+	"\x0eAnalyzeService\x12O\n" +
+	"\fAnalyzeImage\x12!.safebites.v1.AnalyzeImageRequest\x1a\x1a.safebites.v1.AnalyzeEvent0\x01\x12S\n" +
+	"\x0eAnalyzeProduct\x12#.safebites.v1.AnalyzeProductRequest\x1a\x1a.safebites.v1.AnalyzeEvent0\x01\x12a\n" +
+	"\x10ScoreIngredients\x12%.safebites.v1.ScoreIngredientsRequest\x1a&.safebites.v1.ScoreIngredientsResponse2`\n" +
+	"\x10RecommendService\x12L\n" +
+	"\tRecommend\x12\x1e.safebites.v1.RecommendRequest\x1a\x1f.safebites.v1.RecommendResponseB>Z<github.com/safebites/backend-go/api/safebites/v1;safebitesv1b\x06proto3"
+
+var (
+	file_safebites_v1_safebites_proto_rawDescOnce sync.Once
+	file_safebites_v1_safebites_proto_rawDescData []byte
+)
+
+func file_safebites_v1_safebites_proto_rawDescGZIP() []byte {
+	file_safebites_v1_safebites_proto_rawDescOnce.Do(func() {
+		file_safebites_v1_safebites_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_safebites_v1_safebites_proto_rawDesc), len(file_safebites_v1_safebites_proto_rawDesc)))
+	})
+	return file_safebites_v1_safebites_proto_rawDescData
+}
+
+var file_safebites_v1_safebites_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_safebites_v1_safebites_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
+var file_safebites_v1_safebites_proto_goTypes = []any{
+	(AnalysisStage)(0),                // 0: safebites.v1.AnalysisStage
+	(*User)(nil),                      // 1: safebites.v1.User
+	(*Preferences)(nil),               // 2: safebites.v1.Preferences
+	(*GetMeRequest)(nil),              // 3: safebites.v1.GetMeRequest
+	(*GetMeResponse)(nil),             // 4: safebites.v1.GetMeResponse
+	(*UpsertMeRequest)(nil),           // 5: safebites.v1.UpsertMeRequest
+	(*UpsertMeResponse)(nil),          // 6: safebites.v1.UpsertMeResponse
+	(*UpdatePreferencesRequest)(nil),  // 7: safebites.v1.UpdatePreferencesRequest
+	(*UpdatePreferencesResponse)(nil), // 8: safebites.v1.UpdatePreferencesResponse
+	(*Scan)(nil),                      // 9: safebites.v1.Scan
+	(*ListScansRequest)(nil),          // 10: safebites.v1.ListScansRequest
+	(*ListScansResponse)(nil),         // 11: safebites.v1.ListScansResponse
+	(*CreateScanRequest)(nil),         // 12: safebites.v1.CreateScanRequest
+	(*CreateScanResponse)(nil),        // 13: safebites.v1.CreateScanResponse
+	(*GetStatsRequest)(nil),           // 14: safebites.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),          // 15: safebites.v1.GetStatsResponse
+	(*Favorite)(nil),                  // 16: safebites.v1.Favorite
+	(*ListFavoritesRequest)(nil),      // 17: safebites.v1.ListFavoritesRequest
+	(*ListFavoritesResponse)(nil),     // 18: safebites.v1.ListFavoritesResponse
+	(*AddFavoriteRequest)(nil),        // 19: safebites.v1.AddFavoriteRequest
+	(*AddFavoriteResponse)(nil),       // 20: safebites.v1.AddFavoriteResponse
+	(*DeleteFavoriteRequest)(nil),     // 21: safebites.v1.DeleteFavoriteRequest
+	(*DeleteFavoriteResponse)(nil),    // 22: safebites.v1.DeleteFavoriteResponse
+	(*CheckFavoriteRequest)(nil),      // 23: safebites.v1.CheckFavoriteRequest
+	(*CheckFavoriteResponse)(nil),     // 24: safebites.v1.CheckFavoriteResponse
+	(*DietaryTemplate)(nil),           // 25: safebites.v1.DietaryTemplate
+	(*ListTemplatesRequest)(nil),      // 26: safebites.v1.ListTemplatesRequest
+	(*ListTemplatesResponse)(nil),     // 27: safebites.v1.ListTemplatesResponse
+	(*ApplyTemplateRequest)(nil),      // 28: safebites.v1.ApplyTemplateRequest
+	(*ApplyTemplateResponse)(nil),     // 29: safebites.v1.ApplyTemplateResponse
+	(*Source)(nil),                    // 30: safebites.v1.Source
+	(*RegionStatus)(nil),              // 31: safebites.v1.RegionStatus
+	(*IngredientScore)(nil),           // 32: safebites.v1.IngredientScore
+	(*ScorerResult)(nil),              // 33: safebites.v1.ScorerResult
+	(*Confidence)(nil),                // 34: safebites.v1.Confidence
+	(*AnalyzeImageRequest)(nil),       // 35: safebites.v1.AnalyzeImageRequest
+	(*AnalyzeProductRequest)(nil),     // 36: safebites.v1.AnalyzeProductRequest
+	(*AnalyzeProgress)(nil),           // 37: safebites.v1.AnalyzeProgress
+	(*AnalysisResult)(nil),            // 38: safebites.v1.AnalysisResult
+	(*AnalyzeEvent)(nil),              // 39: safebites.v1.AnalyzeEvent
+	(*ScoreIngredientsRequest)(nil),   // 40: safebites.v1.ScoreIngredientsRequest
+	(*ScoreIngredientsResponse)(nil),  // 41: safebites.v1.ScoreIngredientsResponse
+	(*RecommendRequest)(nil),          // 42: safebites.v1.RecommendRequest
+	(*Recommendation)(nil),            // 43: safebites.v1.Recommendation
+	(*RecommendResponse)(nil),         // 44: safebites.v1.RecommendResponse
+	(*timestamppb.Timestamp)(nil),     // 45: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),           // 46: google.protobuf.Struct
+}
+var file_safebites_v1_safebites_proto_depIdxs = []int32{
+	2,  // 0: safebites.v1.User.preferences:type_name -> safebites.v1.Preferences
+	45, // 1: safebites.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	45, // 2: safebites.v1.User.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 3: safebites.v1.GetMeResponse.user:type_name -> safebites.v1.User
+	2,  // 4: safebites.v1.UpsertMeRequest.preferences:type_name -> safebites.v1.Preferences
+	1,  // 5: safebites.v1.UpsertMeResponse.user:type_name -> safebites.v1.User
+	2,  // 6: safebites.v1.UpdatePreferencesRequest.preferences:type_name -> safebites.v1.Preferences
+	1,  // 7: safebites.v1.UpdatePreferencesResponse.user:type_name -> safebites.v1.User
+	46, // 8: safebites.v1.Scan.ingredients:type_name -> google.protobuf.Struct
+	30, // 9: safebites.v1.Scan.sources:type_name -> safebites.v1.Source
+	34, // 10: safebites.v1.Scan.confidence:type_name -> safebites.v1.Confidence
+	45, // 11: safebites.v1.Scan.timestamp:type_name -> google.protobuf.Timestamp
+	9,  // 12: safebites.v1.ListScansResponse.scans:type_name -> safebites.v1.Scan
+	46, // 13: safebites.v1.CreateScanRequest.ingredients:type_name -> google.protobuf.Struct
+	30, // 14: safebites.v1.CreateScanRequest.sources:type_name -> safebites.v1.Source
+	34, // 15: safebites.v1.CreateScanRequest.confidence:type_name -> safebites.v1.Confidence
+	9,  // 16: safebites.v1.CreateScanResponse.scan:type_name -> safebites.v1.Scan
+	45, // 17: safebites.v1.Favorite.added_at:type_name -> google.protobuf.Timestamp
+	16, // 18: safebites.v1.ListFavoritesResponse.favorites:type_name -> safebites.v1.Favorite
+	16, // 19: safebites.v1.AddFavoriteResponse.favorite:type_name -> safebites.v1.Favorite
+	25, // 20: safebites.v1.ListTemplatesResponse.templates:type_name -> safebites.v1.DietaryTemplate
+	1,  // 21: safebites.v1.ApplyTemplateResponse.user:type_name -> safebites.v1.User
+	25, // 22: safebites.v1.ApplyTemplateResponse.template:type_name -> safebites.v1.DietaryTemplate
+	31, // 23: safebites.v1.IngredientScore.regulatory:type_name -> safebites.v1.RegionStatus
+	32, // 24: safebites.v1.ScorerResult.ingredient_scores:type_name -> safebites.v1.IngredientScore
+	0,  // 25: safebites.v1.AnalyzeProgress.stage:type_name -> safebites.v1.AnalysisStage
+	33, // 26: safebites.v1.AnalysisResult.ingredient_breakdown:type_name -> safebites.v1.ScorerResult
+	30, // 27: safebites.v1.AnalysisResult.sources:type_name -> safebites.v1.Source
+	34, // 28: safebites.v1.AnalysisResult.confidence:type_name -> safebites.v1.Confidence
+	37, // 29: safebites.v1.AnalyzeEvent.progress:type_name -> safebites.v1.AnalyzeProgress
+	38, // 30: safebites.v1.AnalyzeEvent.result:type_name -> safebites.v1.AnalysisResult
+	33, // 31: safebites.v1.ScoreIngredientsResponse.result:type_name -> safebites.v1.ScorerResult
+	33, // 32: safebites.v1.Recommendation.ingredient_breakdown:type_name -> safebites.v1.ScorerResult
+	30, // 33: safebites.v1.Recommendation.sources:type_name -> safebites.v1.Source
+	43, // 34: safebites.v1.RecommendResponse.recommendations:type_name -> safebites.v1.Recommendation
+	30, // 35: safebites.v1.RecommendResponse.sources:type_name -> safebites.v1.Source
+	3,  // 36: safebites.v1.UserService.GetMe:input_type -> safebites.v1.GetMeRequest
+	5,  // 37: safebites.v1.UserService.UpsertMe:input_type -> safebites.v1.UpsertMeRequest
+	7,  // 38: safebites.v1.UserService.UpdatePreferences:input_type -> safebites.v1.UpdatePreferencesRequest
+	10, // 39: safebites.v1.ScanService.ListScans:input_type -> safebites.v1.ListScansRequest
+	12, // 40: safebites.v1.ScanService.CreateScan:input_type -> safebites.v1.CreateScanRequest
+	14, // 41: safebites.v1.ScanService.GetStats:input_type -> safebites.v1.GetStatsRequest
+	17, // 42: safebites.v1.FavoriteService.ListFavorites:input_type -> safebites.v1.ListFavoritesRequest
+	19, // 43: safebites.v1.FavoriteService.AddFavorite:input_type -> safebites.v1.AddFavoriteRequest
+	21, // 44: safebites.v1.FavoriteService.DeleteFavorite:input_type -> safebites.v1.DeleteFavoriteRequest
+	23, // 45: safebites.v1.FavoriteService.CheckFavorite:input_type -> safebites.v1.CheckFavoriteRequest
+	26, // 46: safebites.v1.TemplateService.ListTemplates:input_type -> safebites.v1.ListTemplatesRequest
+	28, // 47: safebites.v1.TemplateService.ApplyTemplate:input_type -> safebites.v1.ApplyTemplateRequest
+	35, // 48: safebites.v1.AnalyzeService.AnalyzeImage:input_type -> safebites.v1.AnalyzeImageRequest
+	36, // 49: safebites.v1.AnalyzeService.AnalyzeProduct:input_type -> safebites.v1.AnalyzeProductRequest
+	40, // 50: safebites.v1.AnalyzeService.ScoreIngredients:input_type -> safebites.v1.ScoreIngredientsRequest
+	42, // 51: safebites.v1.RecommendService.Recommend:input_type -> safebites.v1.RecommendRequest
+	4,  // 52: safebites.v1.UserService.GetMe:output_type -> safebites.v1.GetMeResponse
+	6,  // 53: safebites.v1.UserService.UpsertMe:output_type -> safebites.v1.UpsertMeResponse
+	8,  // 54: safebites.v1.UserService.UpdatePreferences:output_type -> safebites.v1.UpdatePreferencesResponse
+	11, // 55: safebites.v1.ScanService.ListScans:output_type -> safebites.v1.ListScansResponse
+	13, // 56: safebites.v1.ScanService.CreateScan:output_type -> safebites.v1.CreateScanResponse
+	15, // 57: safebites.v1.ScanService.GetStats:output_type -> safebites.v1.GetStatsResponse
+	18, // 58: safebites.v1.FavoriteService.ListFavorites:output_type -> safebites.v1.ListFavoritesResponse
+	20, // 59: safebites.v1.FavoriteService.AddFavorite:output_type -> safebites.v1.AddFavoriteResponse
+	22, // 60: safebites.v1.FavoriteService.DeleteFavorite:output_type -> safebites.v1.DeleteFavoriteResponse
+	24, // 61: safebites.v1.FavoriteService.CheckFavorite:output_type -> safebites.v1.CheckFavoriteResponse
+	27, // 62: safebites.v1.TemplateService.ListTemplates:output_type -> safebites.v1.ListTemplatesResponse
+	29, // 63: safebites.v1.TemplateService.ApplyTemplate:output_type -> safebites.v1.ApplyTemplateResponse
+	39, // 64: safebites.v1.AnalyzeService.AnalyzeImage:output_type -> safebites.v1.AnalyzeEvent
+	39, // 65: safebites.v1.AnalyzeService.AnalyzeProduct:output_type -> safebites.v1.AnalyzeEvent
+	41, // 66: safebites.v1.AnalyzeService.ScoreIngredients:output_type -> safebites.v1.ScoreIngredientsResponse
+	44, // 67: safebites.v1.RecommendService.Recommend:output_type -> safebites.v1.RecommendResponse
+	52, // [52:68] is the sub-list for method output_type
+	36, // [36:52] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
+}
+
+func init() { file_safebites_v1_safebites_proto_init() }
+func file_safebites_v1_safebites_proto_init() {
+	if File_safebites_v1_safebites_proto != nil {
+		return
+	}
+	file_safebites_v1_safebites_proto_msgTypes[15].OneofWrappers = []any{}
+	file_safebites_v1_safebites_proto_msgTypes[18].OneofWrappers = []any{}
+	file_safebites_v1_safebites_proto_msgTypes[38].OneofWrappers = []any{
+		(*AnalyzeEvent_Progress)(nil),
+		(*AnalyzeEvent_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_safebites_v1_safebites_proto_rawDesc), len(file_safebites_v1_safebites_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   44,
+			NumExtensions: 0,
+			NumServices:   6,
+		},
+		GoTypes:           file_safebites_v1_safebites_proto_goTypes,
+		DependencyIndexes: file_safebites_v1_safebites_proto_depIdxs,
+		EnumInfos:         file_safebites_v1_safebites_proto_enumTypes,
+		MessageInfos:      file_safebites_v1_safebites_proto_msgTypes,
+	}.Build()
+	File_safebites_v1_safebites_proto = out.File
+	file_safebites_v1_safebites_proto_goTypes = nil
+	file_safebites_v1_safebites_proto_depIdxs = nil
+}
@@ -254,7 +254,7 @@ func (r *Runner) runScorer(ctx context.Context, rep *Report) error {
 		}
 		jsonValid = append(jsonValid, 1.0)
 
-		ar := metrics.AllergyRespected(got, c.Input.Prefs.Allergies)
+		ar := metrics.AllergyRespected(got, sbmodel.AllergyNames(c.Input.Prefs.Allergies))
 		da := metrics.ScoreDirectionAgreement(got, c.Expected.IngredientDirections)
 		allergy = append(allergy, boolToFloat(ar))
 		direction = append(direction, da)
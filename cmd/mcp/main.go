@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	sbagent "github.com/safebites/backend-go/internal/agent"
+	"github.com/safebites/backend-go/internal/config"
+	"github.com/safebites/backend-go/internal/mcpserver"
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/observability"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+func main() {
+	var (
+		transport = flag.String("transport", "stdio", "stdio|http")
+		addr      = flag.String("addr", ":8090", "listen address for the http transport")
+		userID    = flag.String("user", os.Getenv("MCP_USER_ID"), "user the stdio transport acts as (default $MCP_USER_ID)")
+	)
+	flag.Parse()
+
+	// On stdio, stdout carries the protocol; log keeps writing to stderr.
+	cfg := config.Load()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracer := observability.InitTracer(ctx, cfg.Langfuse)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracer(shutdownCtx); err != nil {
+			log.Printf("tracer shutdown error: %v", err)
+		}
+	}()
+
+	// The API server owns migrations; this binary only needs the schema to
+	// exist.
+	db, err := repository.NewDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("database init failed: %v", err)
+	}
+	defer db.Close()
+
+	srv, err := buildServer(ctx, cfg, db, *userID)
+	if err != nil {
+		log.Fatalf("mcp server init failed: %v", err)
+	}
+
+	switch *transport {
+	case "stdio":
+		log.Printf("serving MCP on stdio [user=%q]", *userID)
+		if err := srv.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+			log.Fatalf("mcp stdio error: %v", err)
+		}
+	case "http":
+		serveHTTP(ctx, srv, *addr)
+	default:
+		log.Fatalf("unknown transport %q, want stdio or http", *transport)
+	}
+}
+
+func buildServer(ctx context.Context, cfg *config.Config, db *repository.DB, localUserID string) (*mcp.Server, error) {
+	sbagent.SetSessionService(repository.NewAgentSessionService(db))
+
+	llm, err := sbagent.NewGeminiModel(ctx, cfg.GoogleAPIKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("initialize gemini model: %w", err)
+	}
+	orchestrator, err := sbagent.NewOrchestratorFromModel(llm, sbagent.WorkflowConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("initialize analysis orchestrator: %w", err)
+	}
+
+	// Tools analyze by name, so the analyze service needs neither OCR nor the
+	// image dedup cache.
+	analyzeService := service.NewAnalyzeService(nil, orchestrator, nil, service.AnalyzeConfig{
+		ConfidenceThreshold: cfg.ConfidenceThreshold,
+	})
+
+	// The API server seeds the built-in templates, as it runs the migrations.
+	templateRepo := repository.NewTemplateRepository(db)
+
+	return mcpserver.New(mcpserver.Config{
+		Analyze:     analyzeService,
+		Recommend:   service.NewRecommendService(orchestrator),
+		Scans:       service.NewScanService(repository.NewScanRepository(db)),
+		Users:       service.NewUserService(repository.NewUserRepository(db), templateRepo),
+		Templates:   service.NewTemplateService(templateRepo),
+		DevModeAuth: cfg.DevModeAuth(),
+		LocalUserID: localUserID,
+	}), nil
+}
+
+// serveHTTP serves the streamable HTTP transport on /mcp until ctx is done.
+// Callers authenticate with the same bearer tokens as the REST API; the tools
+// read them from each request.
+func serveHTTP(ctx context.Context, srv *mcp.Server, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil))
+
+	httpServer := &http.Server{
+		Addr:        addr,
+		Handler:     middleware.Logging(mux),
+		ReadTimeout: 60 * time.Second,
+		// No WriteTimeout: responses stream for as long as an analysis runs.
+		IdleTimeout: 120 * time.Second,
+	}
+
+	go func() {
+		log.Printf("serving MCP on http://%s/mcp", addr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("mcp http error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down mcp server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Fatalf("graceful shutdown failed: %v", err)
+	}
+}
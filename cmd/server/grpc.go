@@ -0,0 +1,25 @@
+package main
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/safebites/backend-go/internal/config"
+	"github.com/safebites/backend-go/internal/grpcserver"
+)
+
+// buildGRPCServer exposes the same services as buildRouter over gRPC.
+func buildGRPCServer(cfg *config.Config, svc *services) *grpc.Server {
+	return grpcserver.New(grpcserver.Config{
+		Users:       svc.users,
+		Templates:   svc.templates,
+		Scans:       svc.scans,
+		Favorites:   svc.favoriteRepo,
+		Analyze:     svc.analyze,
+		Recommend:   svc.recommend,
+		Localize:    svc.localize,
+		Images:      svc.imagePrep,
+		Blobs:       svc.blobStore,
+		Events:      svc.webhookDispatcher,
+		DevModeAuth: cfg.DevModeAuth(),
+	})
+}
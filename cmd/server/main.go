@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,7 +18,7 @@ import (
 
 func main() {
 	cfg := config.Load()
-	log.Printf("starting SafeBites Go backend [env=%s port=%s]", cfg.Env, cfg.Port)
+	log.Printf("starting SafeBites Go backend [env=%s port=%s grpc_port=%s]", cfg.Env, cfg.Port, cfg.GRPCPort)
 
 	ctx := context.Background()
 
@@ -40,18 +41,32 @@ func main() {
 	}
 	defer db.Close()
 
-	r, err := buildRouter(cfg, db)
+	svc, err := buildServices(cfg, db)
 	if err != nil {
-		log.Fatalf("router init failed: %v", err)
+		log.Fatalf("service init failed: %v", err)
 	}
+	// The delivery worker stops with the server; undelivered events stay
+	// queued in the database for the next start. Session retention runs
+	// alongside it.
+	workerCtx, stopWorker := context.WithCancel(ctx)
+	defer stopWorker()
+	go svc.webhookDispatcher.Run(workerCtx)
+	go svc.sessionRetention.Run(workerCtx)
+
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Port),
-		Handler:      r,
+		Handler:      buildRouter(cfg, svc),
 		ReadTimeout:  60 * time.Second,
 		WriteTimeout: 120 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
+	adminSrv := &http.Server{
+		Addr:              cfg.AdminAddr,
+		Handler:           buildAdminRouter(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -62,15 +77,51 @@ func main() {
 		}
 	}()
 
+	go func() {
+		log.Printf("admin server listening on %s", cfg.AdminAddr)
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("admin server error: %v", err)
+		}
+	}()
+
+	grpcServer := buildGRPCServer(cfg, svc)
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
+	if err != nil {
+		log.Fatalf("grpc listen failed: %v", err)
+	}
+	go func() {
+		log.Printf("grpc server listening on :%s", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("grpc server error: %v", err)
+		}
+	}()
+
 	<-quit
 	log.Println("shutting down server...")
+	stopWorker()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Analysis streams can outlive the HTTP shutdown window; cut them off
+	// when it runs out.
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("graceful shutdown failed: %v", err)
 	}
+	if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("admin server shutdown error: %v", err)
+	}
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
 
 	log.Println("server stopped")
 }
@@ -6,35 +6,57 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/safebites/backend-go/internal/additive"
 	sbagent "github.com/safebites/backend-go/internal/agent"
+	"github.com/safebites/backend-go/internal/blob"
 	"github.com/safebites/backend-go/internal/config"
 	"github.com/safebites/backend-go/internal/handler"
+	"github.com/safebites/backend-go/internal/imageprep"
 	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/observability"
 	"github.com/safebites/backend-go/internal/repository"
 	"github.com/safebites/backend-go/internal/service"
+	"github.com/safebites/backend-go/internal/webhook"
 )
 
-// buildRouter wires all middleware, routes, and handlers together.
-// Dependencies are injected here; each handler package receives only what it needs.
-//
-// Phase progression:
-//   - Phase 1: health check only (DB connectivity verified)
-//   - Phase 2: repository layer (models + SQL)
-//   - Phase 3: all REST handlers + auth middleware
-//   - Phase 4: agent layer (Gemini)
-//   - Phase 5: analyze + recommend endpoints wired end-to-end
-func buildRouter(cfg *config.Config, db *repository.DB) (*chi.Mux, error) {
-	r := chi.NewRouter()
+// services holds the repositories and services shared by the REST router and
+// the gRPC server, so both APIs run on the same service layer.
+type services struct {
+	userRepo     repository.UserRepository
+	scanRepo     repository.ScanRepository
+	favoriteRepo repository.FavoriteRepository
 
-	r.Use(chiMiddleware.RequestID)
-	r.Use(middleware.Logging)
-	r.Use(chiMiddleware.Recoverer)
-	r.Use(middleware.CORS(cfg))
-	r.Use(middleware.OptionalAuth(cfg))
+	users     service.UserService
+	templates service.TemplateService
+	scans     service.ScanService
+	analyze   service.AnalyzeService
+	batch     service.BatchService
+	recommend service.RecommendService
+	compare   service.CompareService
+	profiles  service.ProfileService
+	chat      service.ChatService
+	localize  service.LocalizeService
+	webhooks  service.WebhookService
+
+	// webhookDispatcher publishes events and runs the delivery worker.
+	webhookDispatcher *webhook.Dispatcher
+	// sessionRetention deletes agent sessions past AGENT_SESSION_RETENTION.
+	sessionRetention *service.SessionRetention
+
+	imagePrep  *imageprep.Preprocessor
+	blobStore  blob.Store
+	localBlobs *blob.LocalStore
+}
 
+// buildServices creates the repositories, agents, and services both APIs use.
+func buildServices(cfg *config.Config, db *repository.DB) (*services, error) {
 	userRepo := repository.NewUserRepository(db)
 	scanRepo := repository.NewScanRepository(db)
-	favoriteRepo := repository.NewFavoriteRepository(db)
+	imageAnalysisRepo := repository.NewImageAnalysisRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+	agentSessions := repository.NewAgentSessionService(db)
+	sbagent.SetSessionService(agentSessions)
 
 	llm, err := sbagent.NewGeminiModel(context.Background(), cfg.GoogleAPIKey, "")
 	if err != nil {
@@ -51,24 +73,104 @@ func buildRouter(cfg *config.Config, db *repository.DB) (*chi.Mux, error) {
 		return nil, fmt.Errorf("initialize analysis orchestrator: %w", err)
 	}
 
-	recommenderAgent, err := sbagent.NewRecommenderAgent(llm)
+	translator, err := sbagent.NewTranslatorAgent(llm)
+	if err != nil {
+		return nil, fmt.Errorf("initialize translator: %w", err)
+	}
+
+	chatAgent, err := sbagent.NewChatAgent(llm)
 	if err != nil {
-		return nil, fmt.Errorf("initialize recommender agent: %w", err)
+		return nil, fmt.Errorf("initialize chat agent: %w", err)
 	}
 
-	userService := service.NewUserService(userRepo)
-	analyzeService := service.NewAnalyzeService(visionOCR, orchestrator)
-	recommendService := service.NewRecommendService(recommenderAgent)
+	blobStore, localBlobs, err := newBlobStore(cfg.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("initialize blob storage: %w", err)
+	}
+
+	webhookDispatcher := webhook.New(webhookRepo, webhook.Config{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BaseDelay:   cfg.Webhooks.RetryBaseDelay,
+		MaxDelay:    cfg.Webhooks.RetryMaxDelay,
+		Timeout:     cfg.Webhooks.Timeout,
+		// Local receivers only make sense on a developer's machine.
+		AllowPrivateNetworks: cfg.IsDev(),
+	}, nil)
+
+	templateService := service.NewTemplateService(templateRepo)
+	if err := templateService.SeedBuiltIns(context.Background()); err != nil {
+		return nil, fmt.Errorf("seed dietary templates: %w", err)
+	}
+
+	analyzeService := service.NewAnalyzeService(visionOCR, orchestrator, imageAnalysisRepo, service.AnalyzeConfig{
+		ConfidenceThreshold: cfg.ConfidenceThreshold,
+		DedupMaxDistance:    cfg.ImageDedupMaxDistance,
+		DedupReuseAnalysis:  cfg.ImageDedupReuseAnalysis,
+		DedupTTL:            cfg.ImageDedupTTL,
+	})
+
+	return &services{
+		userRepo:     userRepo,
+		scanRepo:     scanRepo,
+		favoriteRepo: repository.NewFavoriteRepository(db),
+		users:        service.NewUserService(userRepo, templateRepo),
+		templates:    templateService,
+		scans:        service.NewScanService(scanRepo),
+		analyze:      analyzeService,
+		batch: service.NewBatchService(analyzeService, service.BatchConfig{
+			Concurrency: cfg.BatchConcurrency,
+			CacheTTL:    cfg.BatchCacheTTL,
+		}),
+		recommend:  service.NewRecommendService(orchestrator),
+		compare:    service.NewCompareService(visionOCR, orchestrator),
+		profiles:   service.NewProfileService(repository.NewProfileRepository(db), analyzeService),
+		chat:       service.NewChatService(scanRepo, chatAgent),
+		localize:   service.NewLocalizeService(translator),
+		webhooks:   service.NewWebhookService(webhookRepo, webhookDispatcher),
+		imagePrep:  imageprep.New(imageprep.Config{MaxDimension: cfg.MaxImageDimension}),
+		blobStore:  blobStore,
+		localBlobs: localBlobs,
+
+		webhookDispatcher: webhookDispatcher,
+		sessionRetention:  service.NewSessionRetention(agentSessions, cfg.AgentSessionRetention),
+	}, nil
+}
+
+// buildRouter wires all middleware, routes, and handlers together.
+// Dependencies are injected here; each handler package receives only what it needs.
+//
+// Phase progression:
+//   - Phase 1: health check only (DB connectivity verified)
+//   - Phase 2: repository layer (models + SQL)
+//   - Phase 3: all REST handlers + auth middleware
+//   - Phase 4: agent layer (Gemini)
+//   - Phase 5: analyze + recommend endpoints wired end-to-end
+func buildRouter(cfg *config.Config, svc *services) *chi.Mux {
+	r := chi.NewRouter()
+
+	r.Use(chiMiddleware.RequestID)
+	r.Use(middleware.Logging)
+	r.Use(chiMiddleware.Recoverer)
+	r.Use(middleware.CORS(cfg))
+	r.Use(middleware.OptionalAuth(cfg))
 
-	userHandler := &handler.UserHandler{Users: userRepo}
-	templateHandler := &handler.TemplateHandler{Users: userRepo}
+	userHandler := &handler.UserHandler{Users: svc.userRepo}
+	templateHandler := &handler.TemplateHandler{Templates: svc.templates, Users: svc.users}
 	scanHandler := &handler.ScanHandler{
-		Scans: scanRepo,
-		Users: userRepo,
+		Scans:  svc.scanRepo,
+		Users:  svc.userRepo,
+		Blobs:  svc.blobStore,
+		Events: svc.webhookDispatcher,
 	}
-	favoriteHandler := &handler.FavoriteHandler{Favorites: favoriteRepo}
-	analyzeHandler := &handler.AnalyzeHandler{Analyze: analyzeService, Users: userService}
-	recommendHandler := &handler.RecommendHandler{Recommend: recommendService}
+	favoriteHandler := &handler.FavoriteHandler{Favorites: svc.favoriteRepo}
+	analyzeHandler := &handler.AnalyzeHandler{Analyze: svc.analyze, Users: svc.users, Profiles: svc.profiles, Localize: svc.localize, Images: svc.imagePrep, Blobs: svc.blobStore, Events: svc.webhookDispatcher}
+	recommendHandler := &handler.RecommendHandler{Recommend: svc.recommend, Users: svc.users, Profiles: svc.profiles, Localize: svc.localize}
+	profileHandler := &handler.ProfileHandler{Profiles: svc.profiles}
+	chatHandler := &handler.ChatHandler{Chat: svc.chat, Users: svc.users}
+	batchHandler := &handler.BatchHandler{Batch: svc.batch, Users: svc.users}
+	compareHandler := &handler.CompareHandler{Compare: svc.compare, Users: svc.users, Images: svc.imagePrep, Localize: svc.localize}
+	additiveHandler := &handler.AdditiveHandler{Additives: additive.Default()}
+	webhookHandler := &handler.WebhookHandler{Webhooks: svc.webhooks, AllowPrivateURLs: cfg.IsDev()}
 
 	r.Get("/", handler.Health)
 
@@ -78,26 +180,112 @@ func buildRouter(cfg *config.Config, db *repository.DB) (*chi.Mux, error) {
 
 	r.Route("/api", func(api chi.Router) {
 		api.Post("/analyze", analyzeHandler.AnalyzeImage)
+		api.Post("/analyze/product", analyzeHandler.AnalyzeProduct)
+		api.Post("/analyze/ingredients", analyzeHandler.AnalyzeIngredients)
+		api.Post("/analyze/batch", batchHandler.AnalyzeBatch)
+		api.Post("/compare", compareHandler.CompareProducts)
+		api.Get("/additives/{code}", additiveHandler.Get)
+		if svc.localBlobs != nil {
+			imageHandler := &handler.ImageHandler{Store: svc.localBlobs}
+			api.Get("/images/{image_id}", imageHandler.Get)
+		}
 		api.Get("/reccomendations/{product_name}/{overall_score}", recommendHandler.RecommendProducts)
 
 		api.With(middleware.RequireAuth(cfg)).Get("/users/me", userHandler.GetMe)
+		api.Route("/users/me/profiles", func(profiles chi.Router) {
+			profiles.Use(middleware.RequireAuth(cfg))
+			profiles.Get("/", profileHandler.List)
+			profiles.Post("/", profileHandler.Create)
+			profiles.Put("/{profile_id}", profileHandler.Update)
+			profiles.Delete("/{profile_id}", profileHandler.Delete)
+		})
 
 		api.Get("/users/{user_id}", userHandler.GetByID)
 		api.Post("/users", userHandler.Upsert)
 		api.Post("/users/{user_id}/preferences", userHandler.UpdatePreferences)
+		api.Get("/users/{user_id}/preferences/history", userHandler.PreferenceHistory)
 
-		api.Get("/dietary-templates", templateHandler.List)
+		// Template keys double as share codes wherever a template is only read.
+		api.Route("/dietary-templates", func(templates chi.Router) {
+			templates.Get("/", templateHandler.List)
+			templates.Get("/{template_key}", templateHandler.Get)
+			templates.Group(func(own chi.Router) {
+				own.Use(middleware.RequireAuth(cfg))
+				own.Post("/", templateHandler.Create)
+				own.Put("/{template_key}", templateHandler.Update)
+				own.Delete("/{template_key}", templateHandler.Delete)
+				own.Post("/{template_key}/share", templateHandler.Publish)
+				own.Delete("/{template_key}/share", templateHandler.Unpublish)
+				own.Post("/{template_key}/fork", templateHandler.Fork)
+			})
+		})
 		api.Post("/users/{user_id}/apply-template/{template_key}", templateHandler.Apply)
+		api.Delete("/users/{user_id}/apply-template/{template_key}", templateHandler.Remove)
 
 		api.Get("/users/{user_id}/scans", scanHandler.ListByUser)
 		api.Post("/users/{user_id}/scans", scanHandler.Create)
 		api.Get("/users/{user_id}/stats", scanHandler.Stats)
+		api.Get("/users/{user_id}/scans/{scan_id}/chat", chatHandler.History)
+		api.Post("/users/{user_id}/scans/{scan_id}/chat", chatHandler.Ask)
 
 		api.Get("/users/{user_id}/favorites", favoriteHandler.ListByUser)
 		api.Post("/users/{user_id}/favorites", favoriteHandler.Create)
 		api.Delete("/users/{user_id}/favorites/{favorite_id}", favoriteHandler.Delete)
 		api.Get("/users/{user_id}/favorites/check/{product_name}", favoriteHandler.Check)
+
+		// Webhooks carry a signing secret, so they belong to the caller only.
+		api.Route("/webhooks", func(hooks chi.Router) {
+			hooks.Use(middleware.RequireAuth(cfg))
+			hooks.Get("/", webhookHandler.List)
+			hooks.Post("/", webhookHandler.Create)
+			hooks.Delete("/{subscription_id}", webhookHandler.Delete)
+			hooks.Get("/{subscription_id}/deliveries", webhookHandler.Deliveries)
+			hooks.Post("/deliveries/{delivery_id}/replay", webhookHandler.Replay)
+		})
 	})
 
-	return r, nil
+	return r
+}
+
+// buildAdminRouter serves operator-only endpoints. It runs on its own listener
+// at ADMIN_ADDR so nothing here is reachable through the public API port.
+func buildAdminRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(chiMiddleware.Recoverer)
+	r.Get("/debug/vars", observability.MetricsHandler().ServeHTTP)
+	return r
+}
+
+// newBlobStore builds the configured image store. The local store is also
+// returned on its own because the API serves its signed URLs.
+func newBlobStore(cfg config.BlobConfig) (blob.Store, *blob.LocalStore, error) {
+	switch cfg.Backend {
+	case "local":
+		local, err := blob.NewLocal(blob.LocalConfig{
+			Dir:     cfg.LocalDir,
+			BaseURL: cfg.PublicBaseURL,
+			Secret:  []byte(cfg.URLSecret),
+			TTL:     cfg.URLTTL,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return local, local, nil
+	case "s3":
+		store, err := blob.NewS3(blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+			TTL:             cfg.URLTTL,
+		}, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown BLOB_BACKEND %q, want local or s3", cfg.Backend)
+	}
 }
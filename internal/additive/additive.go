@@ -0,0 +1,199 @@
+// Package additive is an embedded reference of food additives keyed by their
+// E-number, used to give additive ingredients stable names and descriptions.
+package additive
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/safebites/backend-go/internal/model"
+)
+
+//go:embed additives.json
+var embeddedAdditives []byte
+
+type Additive struct {
+	// Code is the E-number, e.g. "E621" or "E150d". INS numbers map onto it.
+	Code            string   `json:"code"`
+	Name            string   `json:"name"`
+	Aliases         []string `json:"aliases"`
+	FunctionClass   string   `json:"function_class"`
+	Description     string   `json:"description"`
+	RegulatoryNotes string   `json:"regulatory_notes,omitempty"`
+}
+
+// Summary is the deterministic ingredient description used in place of the
+// search agent's wording.
+func (a Additive) Summary() string {
+	summary := fmt.Sprintf("%s (%s). %s", capitalize(a.FunctionClass), a.Code, a.Description)
+	if a.RegulatoryNotes != "" {
+		summary += " " + a.RegulatoryNotes
+	}
+	return summary
+}
+
+type Catalog struct {
+	byCode map[string]Additive
+	byName map[string]string
+}
+
+// Load parses a catalog in the format of the embedded additives.json.
+func Load(r io.Reader) (*Catalog, error) {
+	var doc struct {
+		Additives []Additive `json:"additives"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode additives: %w", err)
+	}
+
+	c := &Catalog{byCode: map[string]Additive{}, byName: map[string]string{}}
+	for _, a := range doc.Additives {
+		code, ok := parseCode(a.Code)
+		if !ok || code != a.Code {
+			return nil, fmt.Errorf("invalid additive code %q", a.Code)
+		}
+		if _, dup := c.byCode[code]; dup {
+			return nil, fmt.Errorf("duplicate additive code %q", code)
+		}
+		c.byCode[code] = a
+		for _, name := range append([]string{a.Name}, a.Aliases...) {
+			if key := normalizeName(name); key != "" {
+				c.byName[key] = code
+			}
+		}
+	}
+	return c, nil
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the catalog built from the embedded dataset.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		c, err := Load(strings.NewReader(string(embeddedAdditives)))
+		if err != nil {
+			panic(fmt.Sprintf("embedded additive catalog: %v", err))
+		}
+		defaultCatalog = c
+	})
+	return defaultCatalog
+}
+
+// Lookup finds an additive by code ("E621", "e-621", "INS 621") or by name.
+func (c *Catalog) Lookup(query string) (Additive, bool) {
+	if code, ok := parseCode(query); ok {
+		return c.byParsedCode(code)
+	}
+	return c.byNormalizedName(normalizeName(query))
+}
+
+// Match finds the additive an ingredient label refers to. Codes anywhere in
+// the text win ("Flavour enhancer (E621)"); otherwise the whole name, the part
+// after a colon, or a parenthesised part must be a known name or alias.
+func (c *Catalog) Match(ingredient string) (Additive, bool) {
+	if m := codePattern.FindString(ingredient); m != "" {
+		if code, ok := parseCode(m); ok {
+			if a, ok := c.byParsedCode(code); ok {
+				return a, true
+			}
+		}
+	}
+
+	candidates := []string{ingredient}
+	if i := strings.LastIndex(ingredient, ":"); i >= 0 {
+		candidates = append(candidates, ingredient[i+1:])
+	}
+	for _, m := range parenthesised.FindAllStringSubmatch(ingredient, -1) {
+		candidates = append(candidates, m[1])
+	}
+	for _, candidate := range candidates {
+		if a, ok := c.byNormalizedName(normalizeName(candidate)); ok {
+			return a, true
+		}
+	}
+	return Additive{}, false
+}
+
+// NormalizeIngredients returns a copy of ingredients in which recognized
+// additives carry their reference name, code, and description. The label
+// wording is kept in OriginalName when it differs.
+func (c *Catalog) NormalizeIngredients(ingredients []model.Ingredient) []model.Ingredient {
+	out := make([]model.Ingredient, len(ingredients))
+	for i, ing := range ingredients {
+		out[i] = ing
+		a, ok := c.Match(ing.Name)
+		if !ok {
+			continue
+		}
+		if ing.OriginalName == "" && !strings.EqualFold(strings.TrimSpace(ing.Name), a.Name) {
+			out[i].OriginalName = strings.TrimSpace(ing.Name)
+		}
+		out[i].Name = a.Name
+		out[i].AdditiveCode = a.Code
+		out[i].Description = a.Summary()
+	}
+	return out
+}
+
+func (c *Catalog) byParsedCode(code string) (Additive, bool) {
+	if a, ok := c.byCode[code]; ok {
+		return a, true
+	}
+	// Fall back from an unknown sub-type such as E330a to the base number.
+	if base := strings.TrimRightFunc(code, isLetter); base != code {
+		a, ok := c.byCode[base]
+		return a, ok
+	}
+	return Additive{}, false
+}
+
+func (c *Catalog) byNormalizedName(name string) (Additive, bool) {
+	code, ok := c.byName[name]
+	if !ok {
+		return Additive{}, false
+	}
+	return c.byCode[code], true
+}
+
+var (
+	// codePattern matches E-numbers and INS numbers with an optional sub-type
+	// letter and an optional roman-numeral variant, e.g. "E 150d", "INS 500(ii)".
+	codePattern   = regexp.MustCompile(`(?i)\b(?:e|ins)[\s-]?\d{3,4}[a-z]?(?:\s?\((?:i{1,3}|iv|v|vi)\))?\b`)
+	codeParts     = regexp.MustCompile(`(?i)^(?:e|ins)[\s-]?(\d{3,4})([a-z]?)(?:\s?\((?:i{1,3}|iv|v|vi)\))?$`)
+	parenthesised = regexp.MustCompile(`\(([^()]+)\)`)
+	nonNameChars  = regexp.MustCompile(`[^\p{L}\p{N}&'+,\- ]+`)
+)
+
+// parseCode returns the canonical E-number for a code like "ins 150d".
+func parseCode(raw string) (string, bool) {
+	m := codeParts.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return "", false
+	}
+	return "E" + m[1] + strings.ToLower(m[2]), true
+}
+
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	name = nonNameChars.ReplaceAllString(name, " ")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,96 @@
+package additive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCatalogLoads(t *testing.T) {
+	c := Default()
+	require.NotEmpty(t, c.byCode)
+	for code, a := range c.byCode {
+		require.NotEmpty(t, a.Name, code)
+		require.NotEmpty(t, a.FunctionClass, code)
+		require.NotEmpty(t, a.Description, code)
+	}
+}
+
+func TestCatalogLookup(t *testing.T) {
+	c := Default()
+	cases := map[string]string{
+		"E621":                    "E621",
+		"e 621":                   "E621",
+		"E-150d":                  "E150d",
+		"INS 330":                 "E330",
+		"ins330":                  "E330",
+		"E500(ii)":                "E500",
+		"E330a":                   "E330",
+		"monosodium glutamate":    "E621",
+		"  MSG ":                  "E621",
+		"Soy Lecithin":            "E322",
+		"FD&C Yellow 5":           "E102",
+		"caramel color":           "E150a",
+		"sulfite ammonia caramel": "E150d",
+	}
+	for query, want := range cases {
+		a, ok := c.Lookup(query)
+		require.True(t, ok, query)
+		require.Equal(t, want, a.Code, query)
+	}
+
+	for _, query := range []string{"sugar", "E9999", "vitamin", ""} {
+		_, ok := c.Lookup(query)
+		require.False(t, ok, query)
+	}
+}
+
+func TestCatalogMatchFindsCodesAndNamesInLabels(t *testing.T) {
+	c := Default()
+	cases := map[string]string{
+		"Flavour enhancer (E621)":         "E621",
+		"Colour: Caramel IV":              "E150d",
+		"Acidity regulator (citric acid)": "E330",
+		"Emulsifier: sunflower lecithin":  "E322",
+		"Preservative (INS 211)":          "E211",
+	}
+	for label, want := range cases {
+		a, ok := c.Match(label)
+		require.True(t, ok, label)
+		require.Equal(t, want, a.Code, label)
+	}
+
+	for _, label := range []string{"Raisins 330g", "Whole grain oats", "Vitamin E"} {
+		_, ok := c.Match(label)
+		require.False(t, ok, label)
+	}
+}
+
+func TestNormalizeIngredients(t *testing.T) {
+	c := Default()
+	in := []model.Ingredient{
+		{Name: "E621", Description: "A salt that makes things tasty"},
+		{Name: "Oats", Description: "Whole grain"},
+		{Name: "Glutamato monosódico (E621)", OriginalName: "Glutamato monosódico (E621)"},
+	}
+
+	out := c.NormalizeIngredients(in)
+	require.Equal(t, "Monosodium Glutamate", out[0].Name)
+	require.Equal(t, "E621", out[0].AdditiveCode)
+	require.Equal(t, "E621", out[0].OriginalName)
+	require.True(t, strings.HasPrefix(out[0].Description, "Flavour enhancer (E621). "))
+	require.Equal(t, in[1], out[1])
+	require.Equal(t, "Glutamato monosódico (E621)", out[2].OriginalName)
+
+	require.Equal(t, "E621", in[0].Name, "input must not be modified")
+}
+
+func TestLoadRejectsInvalidData(t *testing.T) {
+	_, err := Load(strings.NewReader(`{"additives":[{"code":"621","name":"MSG"}]}`))
+	require.ErrorContains(t, err, "invalid additive code")
+
+	_, err = Load(strings.NewReader(`{"additives":[{"code":"E621","name":"A"},{"code":"E621","name":"B"}]}`))
+	require.ErrorContains(t, err, "duplicate additive code")
+}
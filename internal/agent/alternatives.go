@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	sbmodel "github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/observability"
+)
+
+// RecommendAlternatives asks the recommender for alternatives and rescores each
+// one from its own ingredient list, so alternatives and the original product are
+// scored on the same basis.
+func (o *Orchestrator) RecommendAlternatives(ctx context.Context, productName string, score float64, prefs *sbmodel.UserPreferences) (*sbmodel.RecommenderResult, error) {
+	if o.searcher == nil || o.scorer == nil || o.recommender == nil {
+		return nil, fmt.Errorf("orchestrator requires searcher, scorer, and recommender")
+	}
+
+	ctx, span := observability.StartPipelineSpan(ctx, "recommend_alternatives")
+	defer span.End()
+	ctx = withPreferences(ctx, prefs)
+
+	recs, err := o.recommender.Recommend(ctx, productName, score)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Alternatives are still useful without a breakdown, so a failed rescore is
+	// recorded rather than failing the whole request.
+	if _, err := o.scoreAlternatives(ctx, recs.Recommendations, prefs); err != nil {
+		log.Printf("recommend alternatives rescore failed product=%q err=%v", productName, err)
+		span.RecordError(err)
+	}
+	return recs, nil
+}
+
+// scoreAlternatives runs search -> ingredient scoring for every recommendation
+// with at most cfg.MaxAlternativeConcurrency in flight, storing each result in
+// the recommendation's IngredientBreakdown. Alternatives whose lookup fails are
+// left without a breakdown; an error is returned only when none could be scored.
+// The returned summary has one entry per scored alternative and the best
+// alternative's score as its overall score.
+func (o *Orchestrator) scoreAlternatives(ctx context.Context, recs []sbmodel.Recommendation, prefs *sbmodel.UserPreferences) (*sbmodel.ScorerResult, error) {
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("recommender returned no alternatives")
+	}
+
+	sem := make(chan struct{}, o.cfg.MaxAlternativeConcurrency)
+	errs := make([]error, len(recs))
+	var wg sync.WaitGroup
+
+	for i := range recs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			breakdown, err := o.scoreProduct(ctx, recs[i].ProductName, prefs)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			recs[i].IngredientBreakdown = breakdown
+		}(i)
+	}
+	wg.Wait()
+
+	summary := &sbmodel.ScorerResult{IngredientScores: make([]sbmodel.IngredientScore, 0, len(recs))}
+	for i, rec := range recs {
+		if errs[i] != nil {
+			log.Printf("alternative rescore failed product=%q err=%v", rec.ProductName, errs[i])
+			continue
+		}
+		overall := rec.IngredientBreakdown.OverallScore
+		summary.IngredientScores = append(summary.IngredientScores, sbmodel.IngredientScore{
+			IngredientName: rec.ProductName,
+			SafetyScore:    sbmodel.FlexibleString(safetyLevel(overall)),
+			Reasoning:      fmt.Sprintf("Scored %.1f/10 across %d ingredients", overall, len(rec.IngredientBreakdown.IngredientScores)),
+		})
+		if overall > summary.OverallScore {
+			summary.OverallScore = overall
+		}
+	}
+
+	if len(summary.IngredientScores) == 0 {
+		return nil, fmt.Errorf("no recommended alternatives could be scored: %w", firstError(errs))
+	}
+	log.Printf("alternative rescore complete scored=%d total=%d best_score=%.2f", len(summary.IngredientScores), len(recs), summary.OverallScore)
+	return summary, nil
+}
+
+func (o *Orchestrator) scoreProduct(ctx context.Context, productName string, prefs *sbmodel.UserPreferences) (*sbmodel.ScorerResult, error) {
+	if strings.TrimSpace(productName) == "" {
+		return nil, fmt.Errorf("product name is required")
+	}
+	searchRes, err := o.searcher.Search(ctx, productName)
+	if err != nil {
+		return nil, fmt.Errorf("search alternative: %w", err)
+	}
+	scoreRes, err := o.scorer.ScoreIngredients(ctx, searchRes.ListOfIngredients, prefs)
+	if err != nil {
+		return nil, fmt.Errorf("score alternative: %w", err)
+	}
+	return scoreRes, nil
+}
+
+// safetyLevel maps a 0-10 overall score onto the LOW/MEDIUM/HIGH scale the
+// scorer uses for individual items.
+func safetyLevel(score float64) string {
+	switch {
+	case score >= DefaultMinAcceptableScore:
+		return "HIGH"
+	case score >= 4:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
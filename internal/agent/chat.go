@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	adkmodel "google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+
+	sbmodel "github.com/safebites/backend-go/internal/model"
+)
+
+const chatAppName = "safebites-chat"
+
+// ChatSessionID is the session a scan's follow-up conversation is kept in.
+func ChatSessionID(scanID string) string {
+	return scanID + "/chat"
+}
+
+// ChatAgent answers follow-up questions about a scan. The scan and the
+// user's preferences are rendered into the instruction on every turn rather
+// than into the history, so an edit to the preferences applies to the next
+// answer.
+type ChatAgent struct {
+	agent agent.Agent
+}
+
+type chatContextKey struct{}
+
+type chatContext struct {
+	ProductName string                   `json:"product_name"`
+	SafetyScore int                      `json:"safety_score"`
+	IsSafe      bool                     `json:"is_safe"`
+	Ingredients []map[string]interface{} `json:"ingredients"`
+	Preferences *sbmodel.UserPreferences `json:"user_preferences,omitempty"`
+}
+
+func NewChatAgent(llm adkmodel.LLM) (*ChatAgent, error) {
+	a, err := llmagent.New(llmagent.Config{
+		Name:                "chat_agent",
+		Model:               llm,
+		Description:         "Answers follow-up food-safety questions about a scanned product.",
+		InstructionProvider: chatInstruction,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create chat agent: %w", err)
+	}
+	return &ChatAgent{agent: a}, nil
+}
+
+func chatInstruction(ctx agent.ReadonlyContext) (string, error) {
+	cc, ok := ctx.Value(chatContextKey{}).(chatContext)
+	if !ok {
+		return "", fmt.Errorf("chat context is missing")
+	}
+	buf, err := json.Marshal(cc)
+	if err != nil {
+		return "", fmt.Errorf("marshal chat context: %w", err)
+	}
+	return chatAgentInstructions + "\n\nScan:\n" + string(buf), nil
+}
+
+// Ask answers question in the scan's conversation, continuing its history.
+// onDelta, when set, receives the answer as it streams.
+func (a *ChatAgent) Ask(ctx context.Context, userID string, scan *sbmodel.Scan, prefs *sbmodel.UserPreferences, question string, onDelta func(string) error) (string, error) {
+	if scan == nil || scan.ID == "" {
+		return "", fmt.Errorf("scan is required")
+	}
+	if strings.TrimSpace(question) == "" {
+		return "", fmt.Errorf("question is required")
+	}
+
+	ctx = WithSessionScope(ctx, SessionScope{UserID: userID, SessionID: ChatSessionID(scan.ID)})
+	ctx = context.WithValue(ctx, chatContextKey{}, chatContext{
+		ProductName: scan.ProductName,
+		SafetyScore: scan.SafetyScore,
+		IsSafe:      scan.IsSafe,
+		Ingredients: scan.Ingredients,
+		Preferences: prefs,
+	})
+
+	out, err := runAgentStream(ctx, chatAppName, a.agent, question, onDelta)
+	if err != nil {
+		return "", fmt.Errorf("run chat agent: %w", err)
+	}
+	return out.Text, nil
+}
+
+// History returns the conversation about a scan, oldest first, or an empty
+// history when nothing has been asked yet.
+func (a *ChatAgent) History(ctx context.Context, userID, scanID string) ([]sbmodel.ChatMessage, error) {
+	svc, err := currentSessionService(SessionScope{UserID: userID, SessionID: ChatSessionID(scanID)})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := svc.Get(ctx, &session.GetRequest{
+		AppName:   chatAppName,
+		UserID:    userID,
+		SessionID: ChatSessionID(scanID),
+	})
+	if err != nil {
+		// session.Service has no sentinel error; both the in-memory and the
+		// Postgres service report a missing session as "not found".
+		if strings.Contains(err.Error(), "not found") {
+			return []sbmodel.ChatMessage{}, nil
+		}
+		return nil, fmt.Errorf("load chat session: %w", err)
+	}
+
+	messages := make([]sbmodel.ChatMessage, 0, resp.Session.Events().Len())
+	for event := range resp.Session.Events().All() {
+		if event.Content == nil {
+			continue
+		}
+		var text strings.Builder
+		for _, part := range event.Content.Parts {
+			if !part.Thought {
+				text.WriteString(part.Text)
+			}
+		}
+		if strings.TrimSpace(text.String()) == "" {
+			continue
+		}
+		role := sbmodel.ChatRoleAssistant
+		if event.Author == "user" {
+			role = sbmodel.ChatRoleUser
+		}
+		messages = append(messages, sbmodel.ChatMessage{Role: role, Text: strings.TrimSpace(text.String()), Timestamp: event.Timestamp})
+	}
+	return messages, nil
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	adkmodel "google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/safebites/backend-go/internal/model"
+)
+
+// streamingLLM streams its answer in chunks and then yields the whole text,
+// the way the Gemini model does in SSE mode.
+type streamingLLM struct {
+	chunks []string
+}
+
+func (s *streamingLLM) Name() string { return "streaming-llm" }
+
+func (s *streamingLLM) GenerateContent(_ context.Context, _ *adkmodel.LLMRequest, stream bool) iter.Seq2[*adkmodel.LLMResponse, error] {
+	return func(yield func(*adkmodel.LLMResponse, error) bool) {
+		full := ""
+		for _, chunk := range s.chunks {
+			full += chunk
+			if stream && !yield(&adkmodel.LLMResponse{Content: genai.NewContentFromText(chunk, genai.RoleModel), Partial: true}, nil) {
+				return
+			}
+		}
+		yield(&adkmodel.LLMResponse{Content: genai.NewContentFromText(full, genai.RoleModel), TurnComplete: true}, nil)
+	}
+}
+
+func chatScan() *model.Scan {
+	return &model.Scan{
+		ID:          "scan-1",
+		ProductName: "Choco Crunch",
+		SafetyScore: 42,
+		Ingredients: []map[string]interface{}{{"name": "Sugar"}, {"name": "Hazelnuts"}},
+	}
+}
+
+func TestChatAgentAskKeepsHistoryAndScanContext(t *testing.T) {
+	useSessionService(t)
+	fake := newFakeLLM("Hazelnuts are the problem for a nut allergy.", "No, it is not suitable.")
+	a, err := NewChatAgent(fake)
+	require.NoError(t, err)
+
+	prefs := &model.UserPreferences{Allergies: model.Allergies("nuts")}
+	var deltas []string
+	onDelta := func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	}
+
+	answer, err := a.Ask(context.Background(), "user-1", chatScan(), prefs, "Which ingredient is the problem?", onDelta)
+	require.NoError(t, err)
+	require.Equal(t, "Hazelnuts are the problem for a nut allergy.", answer)
+	require.Equal(t, []string{answer}, deltas, "a non-streaming model delivers one chunk")
+
+	_, err = a.Ask(context.Background(), "user-1", chatScan(), prefs, "Is it OK for my 2-year-old?", nil)
+	require.NoError(t, err)
+
+	instruction := fake.requests[1].Config.SystemInstruction.Parts[0].Text
+	require.Contains(t, instruction, "Choco Crunch")
+	require.Contains(t, instruction, `"allergies":[{"name":"nuts","severity":"allergy","mayContain":false}]`)
+	require.Contains(t, instruction, chatOffTopicReply)
+	require.Len(t, fake.requests[1].Contents, 3, "the second turn sees the first")
+
+	history, err := a.History(context.Background(), "user-1", "scan-1")
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	require.Equal(t, model.ChatRoleUser, history[0].Role)
+	require.Equal(t, "Which ingredient is the problem?", history[0].Text)
+	require.Equal(t, model.ChatRoleAssistant, history[3].Role)
+	require.Equal(t, "No, it is not suitable.", history[3].Text)
+}
+
+func TestChatAgentAskStreamsChunks(t *testing.T) {
+	useSessionService(t)
+	a, err := NewChatAgent(&streamingLLM{chunks: []string{"Sugar is ", "fine in ", "moderation."}})
+	require.NoError(t, err)
+
+	var deltas []string
+	answer, err := a.Ask(context.Background(), "user-1", chatScan(), nil, "Is the sugar a concern?", func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Sugar is fine in moderation.", answer)
+	require.Equal(t, []string{"Sugar is ", "fine in ", "moderation."}, deltas)
+
+	history, err := a.History(context.Background(), "user-1", "scan-1")
+	require.NoError(t, err)
+	require.Len(t, history, 2, "partial chunks are not stored")
+}
+
+func TestChatAgentHistoryEmptyBeforeFirstQuestion(t *testing.T) {
+	useSessionService(t)
+	a, err := NewChatAgent(newFakeLLM())
+	require.NoError(t, err)
+
+	history, err := a.History(context.Background(), "user-1", "scan-unknown")
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
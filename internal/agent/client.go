@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"log"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"google.golang.org/genai"
@@ -22,8 +21,6 @@ import (
 
 const defaultGeminiModel = "gemini-2.5-flash"
 
-var runIDCounter uint64
-
 func NewGeminiModel(ctx context.Context, apiKey string, modelName string) (model.LLM, error) {
 	if strings.TrimSpace(apiKey) == "" {
 		return nil, fmt.Errorf("google api key is required")
@@ -34,7 +31,29 @@ func NewGeminiModel(ctx context.Context, apiKey string, modelName string) (model
 	return gemini.NewModel(ctx, modelName, &genai.ClientConfig{APIKey: apiKey})
 }
 
+// agentOutput is the final text of an agent run plus the web sources Gemini
+// grounded it on, when the agent used Google Search.
+type agentOutput struct {
+	Text      string
+	Grounding *genai.GroundingMetadata
+}
+
 func runAgentOnce(ctx context.Context, appName string, agnt agent.Agent, input string) (string, error) {
+	out, err := runAgent(ctx, appName, agnt, input)
+	if err != nil {
+		return "", err
+	}
+	return out.Text, nil
+}
+
+func runAgent(ctx context.Context, appName string, agnt agent.Agent, input string) (*agentOutput, error) {
+	return runAgentStream(ctx, appName, agnt, input, nil)
+}
+
+// runAgentStream runs the agent like runAgent. When onDelta is set the model
+// is asked to stream, and onDelta receives each chunk of text as it arrives;
+// a model that does not stream delivers its whole answer as one chunk.
+func runAgentStream(ctx context.Context, appName string, agnt agent.Agent, input string, onDelta func(string) error) (*agentOutput, error) {
 	ctx, span := observability.StartAgentSpan(ctx, appName)
 	defer span.End()
 	span.SetModel(defaultGeminiModel)
@@ -43,7 +62,32 @@ func runAgentOnce(ctx context.Context, appName string, agnt agent.Agent, input s
 	start := time.Now()
 	log.Printf("agent run start app=%s input_len=%d input_preview=%q", appName, len(input), previewText(input, 160))
 
-	sessionService := session.InMemoryService()
+	scope, _ := SessionScopeFromContext(ctx)
+	sessionService, err := currentSessionService(scope)
+	if err != nil {
+		log.Printf("agent run failed app=%s stage=session_service err=%v", appName, err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	userID, sessionID := runSessionIDs(ctx, appName)
+	span.SetSession(userID, sessionID)
+	if err := ensureSession(ctx, sessionService, appName, userID, sessionID); err != nil {
+		if scope.SessionID != "" {
+			log.Printf("agent run failed app=%s stage=session_create session=%s err=%v", appName, sessionID, err)
+			span.RecordError(err)
+			return nil, fmt.Errorf("create adk session: %w", err)
+		}
+		// A one-off run only records its session for inspection; losing
+		// that is no reason to fail the analysis.
+		log.Printf("agent session not recorded app=%s session=%s err=%v", appName, sessionID, err)
+		sessionService = session.InMemoryService()
+		if err := ensureSession(ctx, sessionService, appName, userID, sessionID); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("create adk session: %w", err)
+		}
+	}
+
 	r, err := runner.New(runner.Config{
 		AppName:        appName,
 		Agent:          agnt,
@@ -52,33 +96,46 @@ func runAgentOnce(ctx context.Context, appName string, agnt agent.Agent, input s
 	if err != nil {
 		log.Printf("agent run failed app=%s stage=runner_init err=%v", appName, err)
 		span.RecordError(err)
-		return "", fmt.Errorf("create adk runner: %w", err)
+		return nil, fmt.Errorf("create adk runner: %w", err)
 	}
 
-	runID := fmt.Sprintf("%d-%d", time.Now().UnixNano(), atomic.AddUint64(&runIDCounter, 1))
-	userID := "safebites-agent-" + runID
-	sessionID := "safebites-session-" + runID
-	_, err = sessionService.Create(ctx, &session.CreateRequest{
-		AppName:   appName,
-		UserID:    userID,
-		SessionID: sessionID,
-	})
-	if err != nil {
-		log.Printf("agent run failed app=%s stage=session_create err=%v", appName, err)
-		span.RecordError(err)
-		return "", fmt.Errorf("create adk session: %w", err)
+	runConfig := agent.RunConfig{}
+	if onDelta != nil {
+		runConfig.StreamingMode = agent.StreamingModeSSE
 	}
 
 	var out string
+	streamed := false
+	grounding := &genai.GroundingMetadata{}
 	eventCount := 0
 	partsCount := 0
-	for event, runErr := range r.Run(ctx, userID, sessionID, genai.NewContentFromText(input, genai.RoleUser), agent.RunConfig{}) {
+	for event, runErr := range r.Run(ctx, userID, sessionID, genai.NewContentFromText(input, genai.RoleUser), runConfig) {
 		if runErr != nil {
 			log.Printf("agent run failed app=%s stage=run_stream event_count=%d err=%v", appName, eventCount, runErr)
 			span.RecordError(runErr)
-			return "", runErr
+			return nil, runErr
+		}
+		if event == nil {
+			continue
+		}
+		if event.LLMResponse.Partial {
+			if onDelta == nil || event.LLMResponse.Content == nil {
+				continue
+			}
+			for _, part := range event.LLMResponse.Content.Parts {
+				if part.Text == "" || part.Thought {
+					continue
+				}
+				streamed = true
+				if err := onDelta(part.Text); err != nil {
+					span.RecordError(err)
+					return nil, err
+				}
+			}
+			continue
 		}
-		if event == nil || event.LLMResponse.Content == nil {
+		mergeGrounding(grounding, event.LLMResponse.GroundingMetadata)
+		if event.LLMResponse.Content == nil {
 			continue
 		}
 		eventCount++
@@ -94,13 +151,22 @@ func runAgentOnce(ctx context.Context, appName string, agnt agent.Agent, input s
 		log.Printf("agent run failed app=%s stage=empty_output duration=%s events=%d parts=%d", appName, time.Since(start), eventCount, partsCount)
 		emptyErr := fmt.Errorf("agent returned empty text")
 		span.RecordError(emptyErr)
-		return "", emptyErr
+		return nil, emptyErr
 	}
 
 	out = strings.TrimSpace(out)
+	if onDelta != nil && !streamed {
+		if err := onDelta(out); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
 	span.SetGenAIOutput(out)
+	if uris := groundingURIs(grounding); len(uris) > 0 {
+		span.SetSources(uris)
+	}
 	log.Printf("agent run complete app=%s duration=%s events=%d parts=%d output_len=%d output_preview=%q", appName, time.Since(start), eventCount, partsCount, len(out), previewText(out, 160))
-	return out, nil
+	return &agentOutput{Text: out, Grounding: grounding}, nil
 }
 
 func previewText(raw string, max int) string {
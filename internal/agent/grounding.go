@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+
+	sbmodel "github.com/safebites/backend-go/internal/model"
+)
+
+// groundingCollector gathers the grounding of every search made while one
+// agent answers, including searches run by its tools.
+type groundingCollector struct {
+	mu sync.Mutex
+	md genai.GroundingMetadata
+}
+
+type groundingCollectorKey struct{}
+
+// withGroundingCollector starts collecting grounding for the agent runs made
+// under the returned context.
+func withGroundingCollector(ctx context.Context) (context.Context, *groundingCollector) {
+	c := &groundingCollector{}
+	return context.WithValue(ctx, groundingCollectorKey{}, c), c
+}
+
+// groundingFrom returns the collector set on ctx; a nil collector discards
+// what it is given.
+func groundingFrom(ctx context.Context) *groundingCollector {
+	c, _ := ctx.Value(groundingCollectorKey{}).(*groundingCollector)
+	return c
+}
+
+func (c *groundingCollector) add(md *genai.GroundingMetadata) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	mergeGrounding(&c.md, md)
+}
+
+func (c *groundingCollector) metadata() *genai.GroundingMetadata {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	md := c.md
+	return &md
+}
+
+// mergeGrounding appends the chunks and supports of src to dst, shifting the
+// chunk indices of src's supports so they still point at the right chunks.
+func mergeGrounding(dst, src *genai.GroundingMetadata) {
+	if dst == nil || src == nil {
+		return
+	}
+	offset := int32(len(dst.GroundingChunks))
+	dst.GroundingChunks = append(dst.GroundingChunks, src.GroundingChunks...)
+	for _, support := range src.GroundingSupports {
+		if support == nil {
+			continue
+		}
+		shifted := *support
+		shifted.GroundingChunkIndices = make([]int32, len(support.GroundingChunkIndices))
+		for i, idx := range support.GroundingChunkIndices {
+			shifted.GroundingChunkIndices[i] = idx + offset
+		}
+		dst.GroundingSupports = append(dst.GroundingSupports, &shifted)
+	}
+	dst.WebSearchQueries = append(dst.WebSearchQueries, src.WebSearchQueries...)
+}
+
+// groundingSources returns every distinct web source in md, in the order the
+// model cited them.
+func groundingSources(md *genai.GroundingMetadata) []sbmodel.Source {
+	if md == nil {
+		return nil
+	}
+	indices := make([]int32, len(md.GroundingChunks))
+	for i := range indices {
+		indices[i] = int32(i)
+	}
+	return sourcesAt(md, indices)
+}
+
+// groundingSourcesFor returns the sources backing the parts of the response
+// that mention text, e.g. a single recommended product.
+func groundingSourcesFor(md *genai.GroundingMetadata, text string) []sbmodel.Source {
+	needle := strings.ToLower(strings.TrimSpace(text))
+	if md == nil || needle == "" {
+		return nil
+	}
+	var indices []int32
+	for _, support := range md.GroundingSupports {
+		if support == nil || support.Segment == nil {
+			continue
+		}
+		if strings.Contains(strings.ToLower(support.Segment.Text), needle) {
+			indices = append(indices, support.GroundingChunkIndices...)
+		}
+	}
+	return sourcesAt(md, indices)
+}
+
+// groundingAgreement averages the confidence scores attached to grounding
+// supports. It returns 0 when the model reported none.
+func groundingAgreement(md *genai.GroundingMetadata) float64 {
+	if md == nil {
+		return 0
+	}
+	var sum float64
+	var n int
+	for _, support := range md.GroundingSupports {
+		if support == nil {
+			continue
+		}
+		for _, score := range support.ConfidenceScores {
+			sum += float64(score)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+func sourcesAt(md *genai.GroundingMetadata, indices []int32) []sbmodel.Source {
+	seen := map[string]bool{}
+	var sources []sbmodel.Source
+	for _, idx := range indices {
+		if idx < 0 || int(idx) >= len(md.GroundingChunks) {
+			continue
+		}
+		chunk := md.GroundingChunks[idx]
+		if chunk == nil || chunk.Web == nil || chunk.Web.URI == "" || seen[chunk.Web.URI] {
+			continue
+		}
+		seen[chunk.Web.URI] = true
+		sources = append(sources, sbmodel.Source{Title: chunk.Web.Title, URI: chunk.Web.URI})
+	}
+	return sources
+}
+
+func groundingURIs(md *genai.GroundingMetadata) []string {
+	sources := groundingSources(md)
+	uris := make([]string, 0, len(sources))
+	for _, src := range sources {
+		uris = append(uris, src.URI)
+	}
+	return uris
+}
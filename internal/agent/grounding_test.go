@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	sbmodel "github.com/safebites/backend-go/internal/model"
+)
+
+func webChunk(title, uri string) *genai.GroundingChunk {
+	return &genai.GroundingChunk{Web: &genai.GroundingChunkWeb{Title: title, URI: uri}}
+}
+
+func TestSearchAgentSearchCapturesSources(t *testing.T) {
+	fake := newFakeLLM(`{"List_of_ingredients":[{"name":"Water","description":"Solvent"}]}`)
+	fake.grounding = []*genai.GroundingMetadata{{
+		GroundingChunks: []*genai.GroundingChunk{
+			webChunk("Brand site", "https://brand.example/water"),
+			webChunk("Retailer", "https://shop.example/water"),
+			webChunk("Brand site", "https://brand.example/water"),
+		},
+	}}
+	a, err := NewSearchAgent(fake)
+	require.NoError(t, err)
+
+	out, err := a.Search(context.Background(), "Sparkling Water")
+	require.NoError(t, err)
+	require.Equal(t, []sbmodel.Source{
+		{Title: "Brand site", URI: "https://brand.example/water"},
+		{Title: "Retailer", URI: "https://shop.example/water"},
+	}, out.Sources)
+}
+
+func TestRecommenderAgentAttributesSourcesPerProduct(t *testing.T) {
+	fake := newFakeLLM(`{"recommendations":[{"product_name":"Plain Oats","health_score":"HIGH","reason":"No added sugar"},{"product_name":"Muesli","health_score":"MEDIUM","reason":"Less sugar"}]}`)
+	fake.grounding = []*genai.GroundingMetadata{{
+		GroundingChunks: []*genai.GroundingChunk{
+			webChunk("Oats review", "https://reviews.example/oats"),
+			webChunk("Muesli label", "https://shop.example/muesli"),
+		},
+		GroundingSupports: []*genai.GroundingSupport{
+			{Segment: &genai.Segment{Text: `"product_name":"Plain Oats"`}, GroundingChunkIndices: []int32{0}},
+			{Segment: &genai.Segment{Text: `"product_name":"Muesli"`}, GroundingChunkIndices: []int32{1}},
+		},
+	}}
+	a, err := NewRecommenderAgent(fake)
+	require.NoError(t, err)
+
+	out, err := a.Recommend(context.Background(), "Granola", 4.5)
+	require.NoError(t, err)
+	require.Len(t, out.Sources, 2)
+	require.Len(t, out.Recommendations, 2)
+	require.Equal(t, []sbmodel.Source{{Title: "Oats review", URI: "https://reviews.example/oats"}}, out.Recommendations[0].Sources)
+	require.Equal(t, []sbmodel.Source{{Title: "Muesli label", URI: "https://shop.example/muesli"}}, out.Recommendations[1].Sources)
+}
+
+func TestMergeGroundingShiftsChunkIndices(t *testing.T) {
+	dst := &genai.GroundingMetadata{GroundingChunks: []*genai.GroundingChunk{webChunk("A", "https://a.example")}}
+	mergeGrounding(dst, &genai.GroundingMetadata{
+		GroundingChunks:   []*genai.GroundingChunk{webChunk("B", "https://b.example")},
+		GroundingSupports: []*genai.GroundingSupport{{Segment: &genai.Segment{Text: "beta"}, GroundingChunkIndices: []int32{0}}},
+	})
+
+	require.Equal(t, []sbmodel.Source{{Title: "B", URI: "https://b.example"}}, groundingSourcesFor(dst, "beta"))
+	require.Equal(t, []string{"https://a.example", "https://b.example"}, groundingURIs(dst))
+}
@@ -15,19 +15,55 @@ import (
 	sbmodel "github.com/safebites/backend-go/internal/model"
 )
 
+const searchAlternativesTool = "search_alternatives"
+
 type RecommenderAgent struct {
 	agent agent.Agent
 }
 
+type alternativesSearchArgs struct {
+	Query string `json:"query" jsonschema:"What to search for, e.g. low-sugar granola without nuts."`
+}
+
+type alternativesSearchResult struct {
+	Summary string           `json:"summary"`
+	Sources []sbmodel.Source `json:"sources"`
+}
+
+// NewRecommenderAgent gives the recommender the lookup tools and web search.
+// Gemini before version 3 rejects Google Search next to function tools, so
+// search runs in a grounded sub-agent behind the search_alternatives function
+// tool, and its sources are carried back to the recommendation.
 func NewRecommenderAgent(llm adkmodel.LLM) (*RecommenderAgent, error) {
+	searcher, err := llmagent.New(llmagent.Config{
+		Name:        "alternatives_search_agent",
+		Model:       llm,
+		Description: "Searches the web for food products.",
+		Instruction: alternativesSearchInstructions,
+		Tools:       []tool.Tool{geminitool.GoogleSearch{}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create alternatives search agent: %w", err)
+	}
+	search, err := newTracedTool(searchAlternativesTool,
+		"Searches the web for food products and returns a summary of what was found with its sources.",
+		func(ctx context.Context, args alternativesSearchArgs) (alternativesSearchResult, error) {
+			return searchAlternatives(ctx, searcher, args)
+		})
+	if err != nil {
+		return nil, err
+	}
+	lookups, err := lookupTools()
+	if err != nil {
+		return nil, err
+	}
+
 	a, err := llmagent.New(llmagent.Config{
 		Name:        "recommender_agent",
 		Model:       llm,
 		Description: "Finds healthier alternatives for a product.",
-		Instruction: recommenderAgentInstructions,
-		Tools: []tool.Tool{
-			geminitool.GoogleSearch{},
-		},
+		Instruction: recommenderAgentInstructions + recommenderToolInstructions,
+		Tools:       append([]tool.Tool{search}, lookups...),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create recommender agent: %w", err)
@@ -35,6 +71,24 @@ func NewRecommenderAgent(llm adkmodel.LLM) (*RecommenderAgent, error) {
 	return &RecommenderAgent{agent: a}, nil
 }
 
+// searchAlternatives runs one grounded search and adds its grounding to the
+// collector of the recommendation being made under ctx.
+func searchAlternatives(ctx context.Context, searcher agent.Agent, args alternativesSearchArgs) (alternativesSearchResult, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return alternativesSearchResult{}, fmt.Errorf("query is required")
+	}
+	res, err := runAgent(ctx, "safebites-recommender-search", searcher, args.Query)
+	if err != nil {
+		return alternativesSearchResult{}, err
+	}
+	groundingFrom(ctx).add(res.Grounding)
+	sources := groundingSources(res.Grounding)
+	if sources == nil {
+		sources = []sbmodel.Source{}
+	}
+	return alternativesSearchResult{Summary: res.Text, Sources: sources}, nil
+}
+
 func (a *RecommenderAgent) Recommend(ctx context.Context, productName string, score float64) (*sbmodel.RecommenderResult, error) {
 	if strings.TrimSpace(productName) == "" {
 		return nil, fmt.Errorf("product name is required")
@@ -49,12 +103,15 @@ func (a *RecommenderAgent) Recommend(ctx context.Context, productName string, sc
 		return nil, fmt.Errorf("marshal recommender payload: %w", err)
 	}
 
-	raw, err := runAgentOnce(ctx, "safebites-recommender", a.agent, string(buf))
+	ctx, grounding := withGroundingCollector(ctx)
+	res, err := runAgent(ctx, "safebites-recommender", a.agent, string(buf))
 	if err != nil {
 		return nil, err
 	}
+	grounding.add(res.Grounding)
+	md := grounding.metadata()
 
-	raw, err = extractJSONObject(raw)
+	raw, err := extractJSONObject(res.Text)
 	if err != nil {
 		return nil, fmt.Errorf("parse recommender result: %w", err)
 	}
@@ -63,6 +120,10 @@ func (a *RecommenderAgent) Recommend(ctx context.Context, productName string, sc
 	if err := json.Unmarshal([]byte(raw), &out); err != nil {
 		return nil, fmt.Errorf("parse recommender result: %w", err)
 	}
+	out.Sources = groundingSources(md)
+	for i := range out.Recommendations {
+		out.Recommendations[i].Sources = groundingSourcesFor(md, out.Recommendations[i].ProductName)
+	}
 
 	return &out, nil
 }
@@ -64,7 +64,8 @@ func TestOrchestratorStopsWhenScoreImproves(t *testing.T) {
 		`{"List_of_ingredients":[{"name":"Sugar","description":"Sweetener"}]}`,
 		`{"ingredient_scores":[{"ingredient_name":"Sugar","safety_score":"LOW","reasoning":"High sugar"}],"overall_score":2.1}`,
 		`{"recommendations":[{"product_name":"Unsweetened Oats","health_score":"HIGH","reason":"No added sugar"}]}`,
-		`{"ingredient_scores":[{"ingredient_name":"Unsweetened Oats","safety_score":"HIGH","reasoning":"Minimal processing"}],"overall_score":8.3}`,
+		`{"List_of_ingredients":[{"name":"Whole Grain Oats","description":"Cereal grain"}]}`,
+		`{"ingredient_scores":[{"ingredient_name":"Whole Grain Oats","safety_score":"HIGH","reasoning":"Minimal processing"}],"overall_score":8.3}`,
 	)
 
 	searcher, err := NewSearchAgent(fake)
@@ -80,6 +81,10 @@ func TestOrchestratorStopsWhenScoreImproves(t *testing.T) {
 	require.Equal(t, 2.1, res.InitialScore.OverallScore)
 	require.Equal(t, 8.3, res.FinalScore.OverallScore)
 	require.Len(t, res.Turns, 1)
+
+	alt := res.Turns[0].Recommendations.Recommendations[0]
+	require.NotNil(t, alt.IngredientBreakdown)
+	require.Equal(t, "Whole Grain Oats", alt.IngredientBreakdown.IngredientScores[0].IngredientName)
 }
 
 func TestOrchestratorMaxTwoRecommendationTurns(t *testing.T) {
@@ -87,9 +92,11 @@ func TestOrchestratorMaxTwoRecommendationTurns(t *testing.T) {
 		`{"List_of_ingredients":[{"name":"Additive","description":"Unknown blend"}]}`,
 		`{"ingredient_scores":[{"ingredient_name":"Additive","safety_score":"LOW","reasoning":"Unclear"}],"overall_score":2.0}`,
 		`{"recommendations":[{"product_name":"Alt1","health_score":"MEDIUM","reason":"Slightly better"}]}`,
-		`{"ingredient_scores":[{"ingredient_name":"Alt1","safety_score":"MEDIUM","reasoning":"Some concerns"}],"overall_score":4.0}`,
+		`{"List_of_ingredients":[{"name":"Palm Oil","description":"Vegetable fat"}]}`,
+		`{"ingredient_scores":[{"ingredient_name":"Palm Oil","safety_score":"MEDIUM","reasoning":"Some concerns"}],"overall_score":4.0}`,
 		`{"recommendations":[{"product_name":"Alt2","health_score":"MEDIUM","reason":"Better profile"}]}`,
-		`{"ingredient_scores":[{"ingredient_name":"Alt2","safety_score":"MEDIUM","reasoning":"Still not ideal"}],"overall_score":5.0}`,
+		`{"List_of_ingredients":[{"name":"Sunflower Oil","description":"Vegetable oil"}]}`,
+		`{"ingredient_scores":[{"ingredient_name":"Sunflower Oil","safety_score":"MEDIUM","reasoning":"Still not ideal"}],"overall_score":5.0}`,
 	)
 
 	searcher, err := NewSearchAgent(fake)
@@ -127,3 +134,50 @@ func TestOrchestratorSkipsLoopWhenInitialScoreIsHigh(t *testing.T) {
 	require.Len(t, res.Turns, 0)
 	require.Len(t, fake.requests, 2)
 }
+
+func TestOrchestratorRecommendAlternativesRescoresEachAlternative(t *testing.T) {
+	fake := newFakeLLM(
+		`{"recommendations":[{"product_name":"Alt1","health_score":"HIGH","reason":"Less sugar"},{"product_name":"Alt2","health_score":"HIGH","reason":"No dyes"}]}`,
+		`{"List_of_ingredients":[{"name":"Oats","description":"Whole grain"}]}`,
+		`{"ingredient_scores":[{"ingredient_name":"Oats","safety_score":"HIGH","reasoning":"Minimal processing"}],"overall_score":8.5}`,
+		`{"List_of_ingredients":[{"name":"Red 40","description":"Synthetic dye"}]}`,
+		`{"ingredient_scores":[{"ingredient_name":"Red 40","safety_score":"LOW","reasoning":"Artificial color"}],"overall_score":3.0}`,
+	)
+
+	searcher, err := NewSearchAgent(fake)
+	require.NoError(t, err)
+	scorer, err := NewScorerAgent(fake)
+	require.NoError(t, err)
+	recommender, err := NewRecommenderAgent(fake)
+	require.NoError(t, err)
+	orch := NewOrchestrator(searcher, scorer, recommender, WorkflowConfig{MaxAlternativeConcurrency: 1})
+
+	res, err := orch.RecommendAlternatives(context.Background(), "Sugary Cereal", 3.0, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Recommendations, 2)
+	require.Equal(t, 8.5, res.Recommendations[0].IngredientBreakdown.OverallScore)
+	require.Equal(t, 3.0, res.Recommendations[1].IngredientBreakdown.OverallScore)
+	require.Len(t, fake.requests, 5)
+}
+
+func TestOrchestratorRecommendAlternativesSkipsUnscorableAlternative(t *testing.T) {
+	fake := newFakeLLM(
+		`{"recommendations":[{"product_name":"Alt1","health_score":"HIGH","reason":"Less sugar"},{"product_name":"Alt2","health_score":"HIGH","reason":"No dyes"}]}`,
+		`not-json`,
+		`{"List_of_ingredients":[{"name":"Oats","description":"Whole grain"}]}`,
+		`{"ingredient_scores":[{"ingredient_name":"Oats","safety_score":"HIGH","reasoning":"Minimal processing"}],"overall_score":8.5}`,
+	)
+
+	searcher, err := NewSearchAgent(fake)
+	require.NoError(t, err)
+	scorer, err := NewScorerAgent(fake)
+	require.NoError(t, err)
+	recommender, err := NewRecommenderAgent(fake)
+	require.NoError(t, err)
+	orch := NewOrchestrator(searcher, scorer, recommender, WorkflowConfig{MaxAlternativeConcurrency: 1})
+
+	res, err := orch.RecommendAlternatives(context.Background(), "Sugary Cereal", 3.0, nil)
+	require.NoError(t, err)
+	require.Nil(t, res.Recommendations[0].IngredientBreakdown)
+	require.NotNil(t, res.Recommendations[1].IngredientBreakdown)
+}
@@ -10,55 +10,81 @@ import (
 	"google.golang.org/adk/agent/llmagent"
 	adkmodel "google.golang.org/adk/model"
 
+	"github.com/safebites/backend-go/internal/allergen"
 	sbmodel "github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/regulatory"
 )
 
 type ScorerAgent struct {
-	ingredientAgent     agent.Agent
-	recommendationAgent agent.Agent
+	ingredientAgent agent.Agent
+	regulatory      *regulatory.Catalog
+	allergens       *allergen.Catalog
 }
 
 func NewScorerAgent(llm adkmodel.LLM) (*ScorerAgent, error) {
+	tools, err := lookupTools()
+	if err != nil {
+		return nil, err
+	}
 	ingredientAgent, err := llmagent.New(llmagent.Config{
 		Name:        "ingredient_scorer_agent",
 		Model:       llm,
 		Description: "Scores product ingredient safety with user preferences.",
 		Instruction: scorerAgentInstructions,
+		Tools:       tools,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create ingredient scorer agent: %w", err)
 	}
 
-	recommendationAgent, err := llmagent.New(llmagent.Config{
-		Name:        "recommendation_scorer_agent",
-		Model:       llm,
-		Description: "Scores recommended alternatives with user preferences.",
-		Instruction: recommendationEvalSystemPrompt,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("create recommendation scorer agent: %w", err)
-	}
-
-	return &ScorerAgent{
-		ingredientAgent:     ingredientAgent,
-		recommendationAgent: recommendationAgent,
-	}, nil
+	return &ScorerAgent{ingredientAgent: ingredientAgent, regulatory: regulatory.Default(), allergens: allergen.Default()}, nil
 }
 
+// ScoreIngredients scores the canonical English names only; the label's
+// original wording and any additive code are copied back onto the matching
+// scores afterwards. "May contain" traces are not sent to the model; they are
+// scored by the allergen catalog, which also holds every ingredient to the
+// severity of the user's allergies. Regulatory statuses are attached last,
+// downgrading ingredients banned or restricted in the user's home region.
 func (a *ScorerAgent) ScoreIngredients(ctx context.Context, ingredients []sbmodel.Ingredient, prefs *sbmodel.UserPreferences) (*sbmodel.ScorerResult, error) {
-	payload := map[string]interface{}{"ingredients": ingredients}
-	return a.scoreFromPayload(ctx, a.ingredientAgent, payload, prefs)
-}
+	canonical := make([]sbmodel.Ingredient, 0, len(ingredients))
+	byName := map[string]sbmodel.Ingredient{}
+	var traces []string
+	for _, ing := range ingredients {
+		if ing.MayContain {
+			traces = append(traces, ing.Name)
+			continue
+		}
+		canonical = append(canonical, sbmodel.Ingredient{Name: ing.Name, Description: ing.Description})
+		byName[strings.ToLower(strings.TrimSpace(ing.Name))] = ing
+	}
 
-func (a *ScorerAgent) ScoreRecommendations(ctx context.Context, recommendations []sbmodel.Recommendation, prefs *sbmodel.UserPreferences) (*sbmodel.ScorerResult, error) {
-	payload := map[string]interface{}{"recommendations": recommendations}
-	return a.scoreFromPayload(ctx, a.recommendationAgent, payload, prefs)
+	payload := map[string]interface{}{"ingredients": canonical}
+	out, err := a.scoreFromPayload(ctx, a.ingredientAgent, payload, prefs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out.IngredientScores {
+		is := &out.IngredientScores[i]
+		if ing, ok := byName[strings.ToLower(strings.TrimSpace(is.IngredientName))]; ok {
+			is.OriginalName = ing.OriginalName
+			is.AdditiveCode = ing.AdditiveCode
+		}
+	}
+	a.allergens.Apply(out, traces, prefs)
+	homeRegion := ""
+	if prefs != nil {
+		homeRegion = prefs.HomeRegion
+	}
+	a.regulatory.Apply(out, homeRegion)
+	return out, nil
 }
 
 func (a *ScorerAgent) scoreFromPayload(ctx context.Context, agnt agent.Agent, payload map[string]interface{}, prefs *sbmodel.UserPreferences) (*sbmodel.ScorerResult, error) {
 	if prefs != nil {
 		payload["user_preferences"] = prefs
 	}
+	ctx = withPreferences(ctx, prefs)
 
 	buf, err := json.Marshal(payload)
 	if err != nil {
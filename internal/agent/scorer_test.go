@@ -13,24 +13,13 @@ func TestScorerScoreIngredients(t *testing.T) {
 	a, err := NewScorerAgent(fake)
 	require.NoError(t, err)
 
-	prefs := &model.UserPreferences{Allergies: []string{"nuts"}, DietGoals: []string{"keto"}}
+	prefs := &model.UserPreferences{Allergies: model.Allergies("nuts"), DietGoals: []string{"keto"}}
 	out, err := a.ScoreIngredients(context.Background(), []model.Ingredient{{Name: "Sugar", Description: "Sweetener"}}, prefs)
 	require.NoError(t, err)
 	require.Equal(t, 3.5, out.OverallScore)
 	require.Len(t, fake.requests, 1)
 }
 
-func TestScorerScoreRecommendations(t *testing.T) {
-	fake := newFakeLLM(`{"ingredient_scores":[{"ingredient_name":"Alt Product","safety_score":"HIGH","reasoning":"Cleaner profile"}],"overall_score":8.9}`)
-	a, err := NewScorerAgent(fake)
-	require.NoError(t, err)
-
-	recs := []model.Recommendation{{ProductName: "Alt Product", HealthScore: "HIGH", Reason: "Less additives"}}
-	out, err := a.ScoreRecommendations(context.Background(), recs, nil)
-	require.NoError(t, err)
-	require.Equal(t, 8.9, out.OverallScore)
-}
-
 func TestScorerNilPreferences(t *testing.T) {
 	fake := newFakeLLM(`{"ingredient_scores":[{"ingredient_name":"Salt","safety_score":"MEDIUM","reasoning":"Needs moderation"}],"overall_score":6.0}`)
 	a, err := NewScorerAgent(fake)
@@ -70,3 +59,61 @@ func TestScorerJSONWrappedInProse(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 6.0, out.OverallScore)
 }
+
+func TestScorerScoresCanonicalNamesAndKeepsOriginals(t *testing.T) {
+	fake := newFakeLLM(`{"ingredient_scores":[{"ingredient_name":"Wheat Flour","safety_score":"MEDIUM","reasoning":"Refined grain"}],"overall_score":5.5}`)
+	a, err := NewScorerAgent(fake)
+	require.NoError(t, err)
+
+	out, err := a.ScoreIngredients(context.Background(), []model.Ingredient{{Name: "Wheat Flour", OriginalName: "Harina de trigo", Description: "Milled wheat"}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Harina de trigo", out.IngredientScores[0].OriginalName)
+
+	require.Len(t, fake.requests, 1)
+	prompt := fake.requests[0].Contents[len(fake.requests[0].Contents)-1].Parts[0].Text
+	require.Contains(t, prompt, "Wheat Flour")
+	require.NotContains(t, prompt, "Harina de trigo")
+}
+
+func TestScorerAppliesHomeRegionRestrictions(t *testing.T) {
+	fake := newFakeLLM(`{"ingredient_scores":[{"ingredient_name":"Potassium Bromate","safety_score":"MEDIUM","reasoning":"Flour improver"},{"ingredient_name":"Wheat Flour","safety_score":"HIGH","reasoning":"Staple"}],"overall_score":7.0}`)
+	a, err := NewScorerAgent(fake)
+	require.NoError(t, err)
+
+	ingredients := []model.Ingredient{
+		{Name: "Potassium Bromate", AdditiveCode: "E924", Description: "Flour treatment agent"},
+		{Name: "Wheat Flour", Description: "Milled wheat"},
+	}
+	out, err := a.ScoreIngredients(context.Background(), ingredients, &model.UserPreferences{HomeRegion: "CA"})
+	require.NoError(t, err)
+	require.Equal(t, model.FlexibleString("LOW"), out.IngredientScores[0].SafetyScore)
+	require.NotEmpty(t, out.IngredientScores[0].Regulatory)
+	require.Empty(t, out.IngredientScores[1].Regulatory)
+	require.Equal(t, 4.5, out.OverallScore)
+}
+
+func TestScorerHoldsScoresToAllergySeverity(t *testing.T) {
+	fake := newFakeLLM(`{"ingredient_scores":[{"ingredient_name":"Skimmed Milk","safety_score":"LOW","reasoning":"Dairy"},{"ingredient_name":"Oats","safety_score":"HIGH","reasoning":"Whole grain"}],"overall_score":6.0}`)
+	a, err := NewScorerAgent(fake)
+	require.NoError(t, err)
+
+	prefs := &model.UserPreferences{Allergies: []model.Allergy{
+		{Name: "milk", Severity: model.SeverityIntolerance},
+		{Name: "peanuts", Severity: model.SeverityAllergy, MayContain: true},
+	}}
+	ingredients := []model.Ingredient{
+		{Name: "Skimmed Milk"},
+		{Name: "Oats"},
+		{Name: "Peanuts", MayContain: true},
+	}
+	out, err := a.ScoreIngredients(context.Background(), ingredients, prefs)
+	require.NoError(t, err)
+
+	prompt := fake.requests[0].Contents[len(fake.requests[0].Contents)-1].Parts[0].Text
+	require.NotContains(t, prompt, `"name":"Peanuts"`, "traces are scored without the model")
+	require.Equal(t, model.FlexibleString("LOW"), out.IngredientScores[0].SafetyScore, "the model's lower score stands")
+	require.Len(t, out.IngredientScores, 3)
+	require.True(t, out.IngredientScores[2].MayContain)
+	require.Equal(t, model.FlexibleString("MEDIUM"), out.IngredientScores[2].SafetyScore)
+	require.Equal(t, 4.3, out.OverallScore)
+}
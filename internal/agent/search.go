@@ -12,11 +12,13 @@ import (
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/geminitool"
 
+	"github.com/safebites/backend-go/internal/additive"
 	sbmodel "github.com/safebites/backend-go/internal/model"
 )
 
 type SearchAgent struct {
-	agent agent.Agent
+	agent     agent.Agent
+	additives *additive.Catalog
 }
 
 func NewSearchAgent(llm adkmodel.LLM) (*SearchAgent, error) {
@@ -32,7 +34,7 @@ func NewSearchAgent(llm adkmodel.LLM) (*SearchAgent, error) {
 	if err != nil {
 		return nil, fmt.Errorf("create search agent: %w", err)
 	}
-	return &SearchAgent{agent: a}, nil
+	return &SearchAgent{agent: a, additives: additive.Default()}, nil
 }
 
 func (a *SearchAgent) Search(ctx context.Context, productName string) (*sbmodel.WebSearchResult, error) {
@@ -40,12 +42,12 @@ func (a *SearchAgent) Search(ctx context.Context, productName string) (*sbmodel.
 		return nil, fmt.Errorf("product name is required")
 	}
 
-	raw, err := runAgentOnce(ctx, "safebites-search", a.agent, productName)
+	res, err := runAgent(ctx, "safebites-search", a.agent, productName)
 	if err != nil {
 		return nil, err
 	}
 
-	raw, err = extractJSONObject(raw)
+	raw, err := extractJSONObject(res.Text)
 	if err != nil {
 		return nil, fmt.Errorf("parse search result: %w", err)
 	}
@@ -54,6 +56,11 @@ func (a *SearchAgent) Search(ctx context.Context, productName string) (*sbmodel.
 	if err := json.Unmarshal([]byte(raw), &out); err != nil {
 		return nil, fmt.Errorf("parse search result: %w", err)
 	}
+	// Additives are described inconsistently between runs, so replace them with
+	// the reference entry before anything is scored.
+	out.ListOfIngredients = a.additives.NormalizeIngredients(out.ListOfIngredients)
+	out.Sources = groundingSources(res.Grounding)
+	out.SourceAgreement = groundingAgreement(res.Grounding)
 
 	return &out, nil
 }
@@ -60,3 +60,16 @@ func TestSearchAgentJSONWrappedInProse(t *testing.T) {
 	require.Len(t, out.ListOfIngredients, 1)
 	require.Equal(t, "Water", out.ListOfIngredients[0].Name)
 }
+
+func TestSearchAgentNormalizesAdditives(t *testing.T) {
+	fake := newFakeLLM(`{"List_of_ingredients":[{"name":"Flavour Enhancer (E621)","description":"Makes it savoury"},{"name":"Salt","description":"Seasoning"}]}`)
+	a, err := NewSearchAgent(fake)
+	require.NoError(t, err)
+
+	out, err := a.Search(context.Background(), "Instant Noodles")
+	require.NoError(t, err)
+	require.Equal(t, "Monosodium Glutamate", out.ListOfIngredients[0].Name)
+	require.Equal(t, "E621", out.ListOfIngredients[0].AdditiveCode)
+	require.Equal(t, "Flavour Enhancer (E621)", out.ListOfIngredients[0].OriginalName)
+	require.Equal(t, "Salt", out.ListOfIngredients[1].Name)
+}
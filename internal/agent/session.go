@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// AnonymousUserID owns agent sessions started without an authenticated user.
+const AnonymousUserID = "anonymous"
+
+// ErrNoSessionService is returned for a run that continues a session, such
+// as a chat turn, when no session service has been set.
+var ErrNoSessionService = errors.New("no agent session service configured")
+
+var (
+	sessionMu      sync.RWMutex
+	sessionService session.Service
+)
+
+// SetSessionService makes the agent runs worth keeping record their session
+// in svc, so the events survive the request: continued sessions, and the runs
+// of a signed-in user's scan. Every other run gets a throwaway in-memory
+// session. Pass nil to keep every run in memory; continued sessions then fail
+// with ErrNoSessionService.
+func SetSessionService(svc session.Service) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	sessionService = svc
+}
+
+// currentSessionService returns the service a run under scope records its
+// session in. A continued session has nowhere to keep its history without
+// the configured service.
+func currentSessionService(scope SessionScope) (session.Service, error) {
+	sessionMu.RLock()
+	defer sessionMu.RUnlock()
+	switch {
+	case scope.SessionID != "" && sessionService == nil:
+		return nil, ErrNoSessionService
+	case scope.SessionID != "":
+		return sessionService, nil
+	case sessionService != nil && scope.ScanID != "" && scope.UserID != "" && scope.UserID != AnonymousUserID:
+		return sessionService, nil
+	default:
+		return session.InMemoryService(), nil
+	}
+}
+
+// SessionScope names the user and scan an agent run belongs to.
+type SessionScope struct {
+	UserID string
+	// ScanID groups the runs of one analysis. Every run still gets its own
+	// session, "<ScanID>/<agent>/<run>", so concurrent agents never read each
+	// other's history.
+	ScanID string
+	// SessionID continues an existing session instead of starting a new one,
+	// so the agent sees the earlier turns. It is created on first use.
+	SessionID string
+}
+
+type sessionScopeKey struct{}
+
+// WithSessionScope attaches scope to ctx for the agent runs made under it.
+func WithSessionScope(ctx context.Context, scope SessionScope) context.Context {
+	return context.WithValue(ctx, sessionScopeKey{}, scope)
+}
+
+// SessionScopeFromContext returns the scope set by WithSessionScope.
+func SessionScopeFromContext(ctx context.Context) (SessionScope, bool) {
+	scope, ok := ctx.Value(sessionScopeKey{}).(SessionScope)
+	return scope, ok
+}
+
+var runIDCounter uint64
+
+// runSessionIDs resolves the user and session an agent run is recorded
+// under.
+func runSessionIDs(ctx context.Context, appName string) (userID, sessionID string) {
+	scope, _ := SessionScopeFromContext(ctx)
+	userID = scope.UserID
+	if userID == "" {
+		userID = AnonymousUserID
+	}
+	if scope.SessionID != "" {
+		return userID, scope.SessionID
+	}
+
+	runID := fmt.Sprintf("%d-%d", time.Now().UnixNano(), atomic.AddUint64(&runIDCounter, 1))
+	if scope.ScanID != "" {
+		return userID, scope.ScanID + "/" + appName + "/" + runID
+	}
+	return userID, appName + "/" + runID
+}
+
+// ensureSession fetches the session, creating it when it does not exist yet.
+func ensureSession(ctx context.Context, svc session.Service, appName, userID, sessionID string) error {
+	_, err := svc.Get(ctx, &session.GetRequest{AppName: appName, UserID: userID, SessionID: sessionID, NumRecentEvents: 1})
+	if err == nil {
+		return nil
+	}
+	_, createErr := svc.Create(ctx, &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID})
+	if createErr != nil {
+		return errors.Join(err, createErr)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/session"
+)
+
+func newEchoAgent(t *testing.T, fake *fakeLLM) agent.Agent {
+	t.Helper()
+	a, err := llmagent.New(llmagent.Config{Name: "echo_agent", Model: fake, Instruction: "Reply briefly."})
+	require.NoError(t, err)
+	return a
+}
+
+func useSessionService(t *testing.T) session.Service {
+	t.Helper()
+	svc := session.InMemoryService()
+	SetSessionService(svc)
+	t.Cleanup(func() { SetSessionService(nil) })
+	return svc
+}
+
+func TestRunAgentRecordsSessionUnderScanScope(t *testing.T) {
+	svc := useSessionService(t)
+	fake := newFakeLLM("first", "second")
+	a := newEchoAgent(t, fake)
+
+	ctx := WithSessionScope(context.Background(), SessionScope{UserID: "user-1", ScanID: "scan-1"})
+	_, err := runAgentOnce(ctx, "safebites-echo", a, "hello")
+	require.NoError(t, err)
+	_, err = runAgentOnce(ctx, "safebites-echo", a, "again")
+	require.NoError(t, err)
+
+	list, err := svc.List(context.Background(), &session.ListRequest{AppName: "safebites-echo", UserID: "user-1"})
+	require.NoError(t, err)
+	require.Len(t, list.Sessions, 2, "each run gets its own session")
+	for _, sess := range list.Sessions {
+		require.True(t, strings.HasPrefix(sess.ID(), "scan-1/safebites-echo/"), sess.ID())
+	}
+	// The second run must not have seen the first run's turns.
+	require.Len(t, fake.requests[1].Contents, 1)
+}
+
+func TestRunAgentContinuesSession(t *testing.T) {
+	svc := useSessionService(t)
+	fake := newFakeLLM("first", "second")
+	a := newEchoAgent(t, fake)
+
+	ctx := WithSessionScope(context.Background(), SessionScope{UserID: "user-1", SessionID: "chat-1"})
+	_, err := runAgentOnce(ctx, "safebites-echo", a, "hello")
+	require.NoError(t, err)
+	out, err := runAgentOnce(ctx, "safebites-echo", a, "again")
+	require.NoError(t, err)
+	require.Equal(t, "second", out)
+
+	resp, err := svc.Get(context.Background(), &session.GetRequest{AppName: "safebites-echo", UserID: "user-1", SessionID: "chat-1"})
+	require.NoError(t, err)
+	require.Equal(t, 4, resp.Session.Events().Len())
+	require.Len(t, fake.requests[1].Contents, 3, "the follow-up sees the earlier turn")
+}
+
+func TestRunAgentKeepsOnlyScansOfSignedInUsers(t *testing.T) {
+	svc := useSessionService(t)
+	a := newEchoAgent(t, newFakeLLM("ok", "ok", "ok"))
+
+	for _, scope := range []SessionScope{
+		{UserID: AnonymousUserID, ScanID: "scan-1"},
+		{UserID: "user-1"},
+		{},
+	} {
+		_, err := runAgentOnce(WithSessionScope(context.Background(), scope), "safebites-echo", a, "hello")
+		require.NoError(t, err)
+	}
+
+	for _, userID := range []string{AnonymousUserID, "user-1"} {
+		list, err := svc.List(context.Background(), &session.ListRequest{AppName: "safebites-echo", UserID: userID})
+		require.NoError(t, err)
+		require.Empty(t, list.Sessions, "runs outside a signed-in user's scan are not recorded")
+	}
+}
+
+// failingSessions is a session service that cannot create sessions, like a
+// Postgres service asked to record a user it does not know.
+type failingSessions struct {
+	session.Service
+}
+
+func (failingSessions) Create(context.Context, *session.CreateRequest) (*session.CreateResponse, error) {
+	return nil, errors.New("violates foreign key constraint")
+}
+
+func TestRunAgentFallsBackWhenScanSessionCannotBeRecorded(t *testing.T) {
+	SetSessionService(failingSessions{session.InMemoryService()})
+	t.Cleanup(func() { SetSessionService(nil) })
+	a := newEchoAgent(t, newFakeLLM("ok", "unused"))
+
+	out, err := runAgentOnce(WithSessionScope(context.Background(), SessionScope{UserID: "user-1", ScanID: "scan-1"}), "safebites-echo", a, "hello")
+	require.NoError(t, err)
+	require.Equal(t, "ok", out)
+
+	_, err = runAgentOnce(WithSessionScope(context.Background(), SessionScope{UserID: "user-1", SessionID: "chat-1"}), "safebites-echo", a, "hello")
+	require.ErrorContains(t, err, "create adk session", "a conversation cannot go on without its history")
+}
+
+func TestRunAgentContinuedSessionRequiresService(t *testing.T) {
+	fake := newFakeLLM("unused")
+	a := newEchoAgent(t, fake)
+
+	ctx := WithSessionScope(context.Background(), SessionScope{UserID: "user-1", SessionID: "chat-1"})
+	_, err := runAgentOnce(ctx, "safebites-echo", a, "hello")
+	require.ErrorIs(t, err, ErrNoSessionService)
+	require.Empty(t, fake.requests, "the model is not called without somewhere to keep the turn")
+
+	_, err = runAgentOnce(context.Background(), "safebites-echo", a, "hello")
+	require.NoError(t, err, "one-off runs still get a throwaway session")
+}
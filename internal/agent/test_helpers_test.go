@@ -15,6 +15,8 @@ type fakeLLM struct {
 	mu        sync.Mutex
 	responses []string
 	requests  []*adkmodel.LLMRequest
+	// grounding is attached to the response at the same index, if any.
+	grounding []*genai.GroundingMetadata
 }
 
 func newFakeLLM(responses ...string) *fakeLLM {
@@ -34,21 +36,27 @@ func (f *fakeLLM) GenerateContent(_ context.Context, req *adkmodel.LLMRequest, _
 	}
 	resp := f.responses[0]
 	f.responses = f.responses[1:]
+	var grounding *genai.GroundingMetadata
+	if len(f.grounding) > 0 {
+		grounding = f.grounding[0]
+		f.grounding = f.grounding[1:]
+	}
 	f.mu.Unlock()
 
 	return func(yield func(*adkmodel.LLMResponse, error) bool) {
-		yield(&adkmodel.LLMResponse{Content: genai.NewContentFromText(resp, genai.RoleModel)}, nil)
+		yield(&adkmodel.LLMResponse{Content: genai.NewContentFromText(resp, genai.RoleModel), GroundingMetadata: grounding}, nil)
 	}
 }
 
 type fakeVisionClient struct {
-	text string
-	err  error
+	text        string
+	avgLogprobs float64
+	err         error
 }
 
 func (f *fakeVisionClient) GenerateContent(context.Context, string, []*genai.Content, *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
 	if f.err != nil {
 		return nil, f.err
 	}
-	return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(f.text, genai.RoleModel)}}}, nil
+	return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(f.text, genai.RoleModel), AvgLogprobs: f.avgLogprobs}}}, nil
 }
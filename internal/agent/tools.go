@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/safebites/backend-go/internal/additive"
+	"github.com/safebites/backend-go/internal/allergen"
+	sbmodel "github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/observability"
+)
+
+const (
+	lookupAllergensTool       = "lookup_allergens"
+	lookupAdditiveTool        = "lookup_additive"
+	lookupUserPreferencesTool = "lookup_user_preferences"
+)
+
+type preferencesKey struct{}
+
+// withPreferences makes prefs available to the lookup_user_preferences tool
+// for the agent runs made under ctx.
+func withPreferences(ctx context.Context, prefs *sbmodel.UserPreferences) context.Context {
+	if prefs == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, preferencesKey{}, prefs)
+}
+
+func preferencesFrom(ctx context.Context) *sbmodel.UserPreferences {
+	prefs, _ := ctx.Value(preferencesKey{}).(*sbmodel.UserPreferences)
+	return prefs
+}
+
+type allergenLookupArgs struct {
+	Ingredient string `json:"ingredient" jsonschema:"Ingredient name as printed on the label, e.g. Skimmed Milk Powder."`
+}
+
+type allergenLookupResult struct {
+	Ingredient string           `json:"ingredient"`
+	Allergens  []allergen.Match `json:"allergens"`
+}
+
+type additiveLookupArgs struct {
+	Query string `json:"query" jsonschema:"E-number, INS number, or additive name, e.g. E621 or monosodium glutamate."`
+}
+
+type additiveLookupResult struct {
+	Found           bool   `json:"found"`
+	Code            string `json:"code,omitempty"`
+	Name            string `json:"name,omitempty"`
+	FunctionClass   string `json:"function_class,omitempty"`
+	Description     string `json:"description,omitempty"`
+	RegulatoryNotes string `json:"regulatory_notes,omitempty"`
+}
+
+type preferenceLookupArgs struct {
+	Ingredient string `json:"ingredient,omitempty" jsonschema:"Optional ingredient to check against the user's allergies and avoided ingredients."`
+}
+
+type preferenceLookupResult struct {
+	HasPreferences   bool                         `json:"has_preferences"`
+	Allergies        []sbmodel.Allergy            `json:"allergies"`
+	DietGoals        []string                     `json:"diet_goals"`
+	AvoidIngredients []string                     `json:"avoid_ingredients"`
+	HomeRegion       string                       `json:"home_region,omitempty"`
+	Conflicts        []sbmodel.PreferenceConflict `json:"conflicts"`
+}
+
+// lookupTools builds the function tools the scorer and recommender call for
+// facts that live in our own reference data rather than in the model.
+func lookupTools() ([]tool.Tool, error) {
+	allergens, err := newTracedTool(lookupAllergensTool,
+		"Lists the major allergen groups (milk, eggs, peanuts, tree nuts, gluten, soy, ...) an ingredient contains, including hidden names such as casein or semolina.",
+		lookupAllergens)
+	if err != nil {
+		return nil, err
+	}
+	additives, err := newTracedTool(lookupAdditiveTool,
+		"Looks up a food additive by E-number or name and returns its function, description, and regulatory notes.",
+		lookupAdditive)
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := newTracedTool(lookupUserPreferencesTool,
+		"Returns the user's allergies with their severity and may-contain sensitivity, diet goals, and avoided ingredients, and, given an ingredient, which of them it conflicts with.",
+		lookupUserPreferences)
+	if err != nil {
+		return nil, err
+	}
+	return []tool.Tool{allergens, additives, prefs}, nil
+}
+
+// newTracedTool wraps fn as a function tool whose every call is recorded as a
+// "tool:<name>" span under the calling agent's span, with the arguments as
+// its input and the result as its output.
+func newTracedTool[TArgs, TResults any](name, description string, fn func(context.Context, TArgs) (TResults, error)) (tool.Tool, error) {
+	t, err := functiontool.New(functiontool.Config{Name: name, Description: description}, func(tc tool.Context, args TArgs) (TResults, error) {
+		ctx, span := observability.StartToolSpan(tc, name)
+		defer span.End()
+
+		if buf, err := json.Marshal(args); err == nil {
+			span.SetGenAIInput(string(buf))
+		}
+		out, err := fn(ctx, args)
+		if err != nil {
+			span.RecordError(err)
+			return out, err
+		}
+		if buf, err := json.Marshal(out); err == nil {
+			span.SetGenAIOutput(string(buf))
+		}
+		return out, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create %s tool: %w", name, err)
+	}
+	return t, nil
+}
+
+func lookupAllergens(_ context.Context, args allergenLookupArgs) (allergenLookupResult, error) {
+	if strings.TrimSpace(args.Ingredient) == "" {
+		return allergenLookupResult{}, fmt.Errorf("ingredient is required")
+	}
+	matches := allergen.Default().Find(args.Ingredient)
+	if matches == nil {
+		matches = []allergen.Match{}
+	}
+	return allergenLookupResult{Ingredient: args.Ingredient, Allergens: matches}, nil
+}
+
+func lookupAdditive(_ context.Context, args additiveLookupArgs) (additiveLookupResult, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return additiveLookupResult{}, fmt.Errorf("query is required")
+	}
+	catalog := additive.Default()
+	a, ok := catalog.Lookup(args.Query)
+	if !ok {
+		a, ok = catalog.Match(args.Query)
+	}
+	if !ok {
+		return additiveLookupResult{}, nil
+	}
+	return additiveLookupResult{
+		Found:           true,
+		Code:            a.Code,
+		Name:            a.Name,
+		FunctionClass:   a.FunctionClass,
+		Description:     a.Description,
+		RegulatoryNotes: a.RegulatoryNotes,
+	}, nil
+}
+
+func lookupUserPreferences(ctx context.Context, args preferenceLookupArgs) (preferenceLookupResult, error) {
+	out := preferenceLookupResult{
+		Allergies:        []sbmodel.Allergy{},
+		DietGoals:        []string{},
+		AvoidIngredients: []string{},
+		Conflicts:        []sbmodel.PreferenceConflict{},
+	}
+	prefs := preferencesFrom(ctx)
+	if prefs == nil {
+		return out, nil
+	}
+	out.HasPreferences = true
+	out.Allergies = append(out.Allergies, prefs.Allergies...)
+	out.DietGoals = append(out.DietGoals, prefs.DietGoals...)
+	out.AvoidIngredients = append(out.AvoidIngredients, prefs.AvoidIngredients...)
+	out.HomeRegion = prefs.HomeRegion
+	if strings.TrimSpace(args.Ingredient) != "" {
+		out.Conflicts = allergen.Default().Conflicts(args.Ingredient, prefs)
+	}
+	return out, nil
+}
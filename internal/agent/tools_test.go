@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	adkmodel "google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/observability"
+)
+
+// scriptedLLM replies with prepared contents in order, so a test can make the
+// model call a tool before answering.
+type scriptedLLM struct {
+	mu       sync.Mutex
+	name     string
+	replies  []*genai.Content
+	requests []*adkmodel.LLMRequest
+	// grounding is attached to the reply at the same index, if any.
+	grounding map[int]*genai.GroundingMetadata
+}
+
+func (s *scriptedLLM) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	return "scripted-llm"
+}
+
+func (s *scriptedLLM) GenerateContent(_ context.Context, req *adkmodel.LLMRequest, _ bool) iter.Seq2[*adkmodel.LLMResponse, error] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	if len(s.replies) == 0 {
+		return func(yield func(*adkmodel.LLMResponse, error) bool) {
+			yield(nil, fmt.Errorf("no scripted replies left"))
+		}
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	grounding := s.grounding[len(s.requests)-1]
+	return func(yield func(*adkmodel.LLMResponse, error) bool) {
+		yield(&adkmodel.LLMResponse{Content: reply, GroundingMetadata: grounding}, nil)
+	}
+}
+
+func functionCall(name string, args map[string]any) *genai.Content {
+	return &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: name, Args: args}}}}
+}
+
+func functionResponse(t *testing.T, req *adkmodel.LLMRequest, name string) map[string]any {
+	t.Helper()
+	for _, content := range req.Contents {
+		for _, part := range content.Parts {
+			if part.FunctionResponse != nil && part.FunctionResponse.Name == name {
+				return part.FunctionResponse.Response
+			}
+		}
+	}
+	t.Fatalf("request has no %s response", name)
+	return nil
+}
+
+func TestScorerCallsToolsAndRecordsSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	observability.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { observability.SetTracerProvider(nil) })
+
+	llm := &scriptedLLM{replies: []*genai.Content{
+		functionCall(lookupUserPreferencesTool, map[string]any{"ingredient": "Skimmed Milk Powder"}),
+		genai.NewContentFromText(`{"ingredient_scores":[{"ingredient_name":"Skimmed Milk Powder","safety_score":"LOW","reasoning":"Dairy allergy"}],"overall_score":2.0}`, genai.RoleModel),
+	}}
+	a, err := NewScorerAgent(llm)
+	require.NoError(t, err)
+
+	prefs := &model.UserPreferences{Allergies: model.Allergies("dairy")}
+	out, err := a.ScoreIngredients(context.Background(), []model.Ingredient{{Name: "Skimmed Milk Powder"}}, prefs)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, out.OverallScore)
+
+	require.Len(t, llm.requests, 2)
+	var declared []string
+	for _, tl := range llm.requests[0].Config.Tools {
+		for _, decl := range tl.FunctionDeclarations {
+			declared = append(declared, decl.Name)
+		}
+	}
+	require.ElementsMatch(t, []string{lookupAllergensTool, lookupAdditiveTool, lookupUserPreferencesTool}, declared)
+
+	resp := functionResponse(t, llm.requests[1], lookupUserPreferencesTool)
+	require.Equal(t, true, resp["has_preferences"])
+	conflicts, ok := resp["conflicts"].([]any)
+	require.True(t, ok)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, "dairy", conflicts[0].(map[string]any)["preference"])
+
+	var toolSpan sdktrace.ReadOnlySpan
+	spansByID := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		spansByID[s.SpanContext().SpanID().String()] = s
+		if s.Name() == "tool:"+lookupUserPreferencesTool {
+			toolSpan = s
+		}
+	}
+	require.NotNil(t, toolSpan, "tool call is recorded as a span")
+	// ADK's own execute_tool span sits between the tool and the agent run.
+	var ancestors []string
+	for s := toolSpan; s.Parent().IsValid(); {
+		parent, ok := spansByID[s.Parent().SpanID().String()]
+		if !ok {
+			break
+		}
+		ancestors = append(ancestors, parent.Name())
+		s = parent
+	}
+	require.Contains(t, ancestors, "safebites-scorer")
+
+	attrs := map[attribute.Key]string{}
+	for _, kv := range toolSpan.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	require.Contains(t, attrs[observability.AttrGenAIPrompt], "Skimmed Milk Powder")
+	require.Contains(t, attrs[observability.AttrGenAICompletion], `"kind":"allergy"`)
+}
+
+func declaredTools(req *adkmodel.LLMRequest) (functions []string, googleSearch bool) {
+	for _, tl := range req.Config.Tools {
+		for _, decl := range tl.FunctionDeclarations {
+			functions = append(functions, decl.Name)
+		}
+		googleSearch = googleSearch || tl.GoogleSearch != nil
+	}
+	return functions, googleSearch
+}
+
+func TestRecommenderUsesToolsOnDefaultModel(t *testing.T) {
+	llm := &scriptedLLM{
+		name: defaultGeminiModel,
+		replies: []*genai.Content{
+			functionCall(searchAlternativesTool, map[string]any{"query": "granola without nuts"}),
+			genai.NewContentFromText("Plain Oats by Oatco: rolled oats, nothing else.", genai.RoleModel),
+			functionCall(lookupUserPreferencesTool, map[string]any{"ingredient": "oats"}),
+			genai.NewContentFromText(`{"recommendations":[{"product_name":"Plain Oats","health_score":"HIGH","reason":"No added sugar"}]}`, genai.RoleModel),
+		},
+		grounding: map[int]*genai.GroundingMetadata{1: {
+			GroundingChunks:   []*genai.GroundingChunk{webChunk("Oatco", "https://oatco.example/plain")},
+			GroundingSupports: []*genai.GroundingSupport{{Segment: &genai.Segment{Text: "Plain Oats by Oatco"}, GroundingChunkIndices: []int32{0}}},
+		}},
+	}
+	a, err := NewRecommenderAgent(llm)
+	require.NoError(t, err)
+
+	ctx := withPreferences(context.Background(), &model.UserPreferences{Allergies: model.Allergies("nuts")})
+	out, err := a.Recommend(ctx, "Granola", 4.0)
+	require.NoError(t, err)
+
+	require.Len(t, llm.requests, 4)
+	functions, googleSearch := declaredTools(llm.requests[0])
+	require.ElementsMatch(t, []string{searchAlternativesTool, lookupAllergensTool, lookupAdditiveTool, lookupUserPreferencesTool}, functions)
+	require.False(t, googleSearch, "Gemini 2 rejects Google Search next to function tools")
+	require.Contains(t, llm.requests[0].Config.SystemInstruction.Parts[0].Text, lookupUserPreferencesTool)
+
+	functions, googleSearch = declaredTools(llm.requests[1])
+	require.Empty(t, functions)
+	require.True(t, googleSearch, "the search sub-agent is grounded")
+
+	resp := functionResponse(t, llm.requests[2], searchAlternativesTool)
+	require.Contains(t, resp["summary"], "Plain Oats")
+	require.Equal(t, true, functionResponse(t, llm.requests[3], lookupUserPreferencesTool)["has_preferences"])
+
+	oatco := []model.Source{{Title: "Oatco", URI: "https://oatco.example/plain"}}
+	require.Equal(t, oatco, out.Sources)
+	require.Equal(t, oatco, out.Recommendations[0].Sources)
+}
+
+func TestLookupAllergens(t *testing.T) {
+	out, err := lookupAllergens(context.Background(), allergenLookupArgs{Ingredient: "Durum Wheat Semolina"})
+	require.NoError(t, err)
+	require.Len(t, out.Allergens, 1)
+	require.Equal(t, "gluten", out.Allergens[0].Key)
+
+	out, err = lookupAllergens(context.Background(), allergenLookupArgs{Ingredient: "Sugar"})
+	require.NoError(t, err)
+	require.NotNil(t, out.Allergens)
+	require.Empty(t, out.Allergens)
+
+	_, err = lookupAllergens(context.Background(), allergenLookupArgs{Ingredient: " "})
+	require.Error(t, err)
+}
+
+func TestLookupAdditive(t *testing.T) {
+	out, err := lookupAdditive(context.Background(), additiveLookupArgs{Query: "e-621"})
+	require.NoError(t, err)
+	require.True(t, out.Found)
+	require.Equal(t, "E621", out.Code)
+
+	out, err = lookupAdditive(context.Background(), additiveLookupArgs{Query: "Flavour enhancer (E621)"})
+	require.NoError(t, err)
+	require.True(t, out.Found)
+
+	out, err = lookupAdditive(context.Background(), additiveLookupArgs{Query: "unicorn dust"})
+	require.NoError(t, err)
+	require.False(t, out.Found)
+}
+
+func TestLookupUserPreferences(t *testing.T) {
+	out, err := lookupUserPreferences(context.Background(), preferenceLookupArgs{Ingredient: "Peanuts"})
+	require.NoError(t, err)
+	require.False(t, out.HasPreferences)
+	require.Empty(t, out.Conflicts)
+
+	ctx := withPreferences(context.Background(), &model.UserPreferences{
+		Allergies:        model.Allergies("nuts", "kiwi"),
+		AvoidIngredients: []string{"palm oil"},
+		DietGoals:        []string{"low sugar"},
+	})
+	out, err = lookupUserPreferences(ctx, preferenceLookupArgs{Ingredient: "Hazelnut Paste with Palm Oil"})
+	require.NoError(t, err)
+	require.True(t, out.HasPreferences)
+	require.Equal(t, []string{"low sugar"}, out.DietGoals)
+	require.Len(t, out.Conflicts, 2)
+	require.Equal(t, "allergy", out.Conflicts[0].Kind)
+	require.Equal(t, "avoid", out.Conflicts[1].Kind)
+
+	out, err = lookupUserPreferences(ctx, preferenceLookupArgs{Ingredient: "Kiwi Puree"})
+	require.NoError(t, err)
+	require.Len(t, out.Conflicts, 1)
+	require.Equal(t, "kiwi", out.Conflicts[0].Preference)
+
+	out, err = lookupUserPreferences(ctx, preferenceLookupArgs{Ingredient: "Coconut Milk"})
+	require.NoError(t, err)
+	require.Empty(t, out.Conflicts)
+}
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	adkmodel "google.golang.org/adk/model"
+)
+
+type TranslatorAgent struct {
+	agent agent.Agent
+}
+
+func NewTranslatorAgent(llm adkmodel.LLM) (*TranslatorAgent, error) {
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "translator_agent",
+		Model:       llm,
+		Description: "Translates analysis texts into the user's language.",
+		Instruction: translatorAgentInstructions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create translator agent: %w", err)
+	}
+	return &TranslatorAgent{agent: a}, nil
+}
+
+// Translate returns texts translated into targetLanguage (ISO 639-1), in the
+// same order. Empty strings are passed through without asking the model.
+func (a *TranslatorAgent) Translate(ctx context.Context, texts []string, targetLanguage string) ([]string, error) {
+	if strings.TrimSpace(targetLanguage) == "" {
+		return nil, fmt.Errorf("target language is required")
+	}
+
+	out := make([]string, len(texts))
+	var pending []string
+	var positions []int
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			out[i] = text
+			continue
+		}
+		pending = append(pending, text)
+		positions = append(positions, i)
+	}
+	if len(pending) == 0 {
+		return out, nil
+	}
+
+	buf, err := json.Marshal(map[string]interface{}{
+		"target_language": targetLanguage,
+		"texts":           pending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal translator payload: %w", err)
+	}
+
+	raw, err := runAgentOnce(ctx, "safebites-translator", a.agent, string(buf))
+	if err != nil {
+		return nil, err
+	}
+	raw, err = extractJSONObject(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse translator result: %w", err)
+	}
+
+	var parsed struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("parse translator result: %w", err)
+	}
+	if len(parsed.Translations) != len(pending) {
+		return nil, fmt.Errorf("translator returned %d translations for %d texts", len(parsed.Translations), len(pending))
+	}
+
+	for i, pos := range positions {
+		out[pos] = parsed.Translations[i]
+	}
+	return out, nil
+}
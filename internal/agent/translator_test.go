@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslatorTranslateKeepsOrderAndSkipsEmpty(t *testing.T) {
+	fake := newFakeLLM(`{"translations":["Alto contenido de azúcar","Conservante"]}`)
+	a, err := NewTranslatorAgent(fake)
+	require.NoError(t, err)
+
+	out, err := a.Translate(context.Background(), []string{"High added sugar", "", "Preservative"}, "es")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alto contenido de azúcar", "", "Conservante"}, out)
+}
+
+func TestTranslatorTranslateCountMismatch(t *testing.T) {
+	fake := newFakeLLM(`{"translations":["Uno"]}`)
+	a, err := NewTranslatorAgent(fake)
+	require.NoError(t, err)
+
+	_, err = a.Translate(context.Background(), []string{"One", "Two"}, "es")
+	require.ErrorContains(t, err, "translator returned 1 translations for 2 texts")
+}
+
+func TestTranslatorTranslateNothingToDo(t *testing.T) {
+	fake := newFakeLLM()
+	a, err := NewTranslatorAgent(fake)
+	require.NoError(t, err)
+
+	out, err := a.Translate(context.Background(), []string{"", " "}, "fr")
+	require.NoError(t, err)
+	require.Equal(t, []string{"", " "}, out)
+	require.Empty(t, fake.requests)
+}
@@ -2,16 +2,21 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 
 	"google.golang.org/genai"
 
+	"github.com/safebites/backend-go/internal/guard"
+	sbmodel "github.com/safebites/backend-go/internal/model"
 	"github.com/safebites/backend-go/internal/observability"
 )
 
 // VisionOCR is intentionally not modeled as an agent.
-// It is a direct Gemini OCR call that extracts product name from image bytes.
+// It is a direct Gemini OCR call that extracts the product name and label
+// language from image bytes.
 type VisionOCR struct {
 	client VisionClient
 	model  string
@@ -45,17 +50,27 @@ func NewVisionOCRFromAPIKey(apiKey string) (*VisionOCR, error) {
 }
 
 func (v *VisionOCR) ExtractProductName(ctx context.Context, imageBytes []byte, mimeType string) (string, error) {
+	reading, err := v.ReadLabel(ctx, imageBytes, mimeType)
+	if err != nil {
+		return "", err
+	}
+	return reading.ProductName, nil
+}
+
+// ReadLabel returns the product name as printed, the language the label is
+// written in, and how confident the model was in what it read.
+func (v *VisionOCR) ReadLabel(ctx context.Context, imageBytes []byte, mimeType string) (*sbmodel.LabelReading, error) {
 	if len(imageBytes) == 0 {
-		return "", fmt.Errorf("image bytes are required")
+		return nil, fmt.Errorf("image bytes are required")
 	}
 	if v.client == nil {
-		return "", fmt.Errorf("vision client is required")
+		return nil, fmt.Errorf("vision client is required")
 	}
 	if strings.TrimSpace(mimeType) == "" {
 		mimeType = "image/jpeg"
 	}
 	if !isSupportedVisionMimeType(mimeType) {
-		return "", fmt.Errorf("unsupported image mime type: %s", mimeType)
+		return nil, fmt.Errorf("unsupported image mime type: %s", mimeType)
 	}
 
 	ctx, span := observability.StartAgentSpan(ctx, "VisionOCR")
@@ -71,13 +86,13 @@ func (v *VisionOCR) ExtractProductName(ctx context.Context, imageBytes []byte, m
 	}, &genai.GenerateContentConfig{})
 	if err != nil {
 		span.RecordError(err)
-		return "", err
+		return nil, err
 	}
 
 	if resp == nil || strings.TrimSpace(resp.Text()) == "" {
 		err := fmt.Errorf("vision response is empty")
 		span.RecordError(err)
-		return "", err
+		return nil, err
 	}
 
 	out := strings.TrimSpace(resp.Text())
@@ -85,7 +100,57 @@ func (v *VisionOCR) ExtractProductName(ctx context.Context, imageBytes []byte, m
 	if resp.UsageMetadata != nil {
 		span.SetTokens(int64(resp.UsageMetadata.PromptTokenCount), int64(resp.UsageMetadata.CandidatesTokenCount))
 	}
-	return out, nil
+
+	reading := parseLabelReading(out)
+	if reading.ProductName == "" {
+		err := fmt.Errorf("vision response is empty")
+		span.RecordError(err)
+		return nil, err
+	}
+	reading.Confidence = ocrConfidence(resp, reading.ProductName)
+	span.SetConfidence(reading.Confidence)
+
+	// The label text is attacker-controlled and becomes the search prompt.
+	guarded, err := guard.ProductName(ctx, guard.SourceOCR, reading.ProductName)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	reading.ProductName = guarded.Value
+	return reading, nil
+}
+
+// parseLabelReading accepts the JSON object the prompt asks for and falls back
+// to treating the whole response as the product name when the model ignores
+// the format.
+func parseLabelReading(out string) *sbmodel.LabelReading {
+	if raw, err := extractJSONObject(out); err == nil {
+		var parsed struct {
+			ProductName string `json:"product_name"`
+			Language    string `json:"language"`
+		}
+		if err := json.Unmarshal([]byte(raw), &parsed); err == nil && strings.TrimSpace(parsed.ProductName) != "" {
+			return &sbmodel.LabelReading{
+				ProductName: strings.TrimSpace(parsed.ProductName),
+				Language:    strings.ToLower(strings.TrimSpace(parsed.Language)),
+			}
+		}
+	}
+	return &sbmodel.LabelReading{ProductName: out}
+}
+
+// ocrConfidence turns the candidate's average token log-probability into a
+// probability and penalizes answers that do not look like a single product
+// name. Responses without log-probabilities are trusted fully.
+func ocrConfidence(resp *genai.GenerateContentResponse, name string) float64 {
+	confidence := 1.0
+	if len(resp.Candidates) > 0 && resp.Candidates[0] != nil && resp.Candidates[0].AvgLogprobs < 0 {
+		confidence = math.Exp(resp.Candidates[0].AvgLogprobs)
+	}
+	if len([]rune(name)) < 3 || strings.Contains(name, "\n") {
+		confidence *= 0.5
+	}
+	return confidence
 }
 
 func isSupportedVisionMimeType(mimeType string) bool {
@@ -14,18 +14,24 @@ import (
 	adkmodel "google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 
+	"github.com/safebites/backend-go/internal/additive"
 	sbmodel "github.com/safebites/backend-go/internal/model"
 	"github.com/safebites/backend-go/internal/observability"
+	"github.com/safebites/backend-go/internal/progress"
 )
 
 const (
-	DefaultMinAcceptableScore  = 7.0
-	DefaultMaxRecommendationTx = 2
+	DefaultMinAcceptableScore        = 7.0
+	DefaultMaxRecommendationTx       = 2
+	DefaultMaxAlternativeConcurrency = 3
 )
 
 type WorkflowConfig struct {
 	MinAcceptableScore  float64
 	MaxRecommendationTx int
+	// MaxAlternativeConcurrency bounds how many recommended alternatives are
+	// searched and scored at the same time.
+	MaxAlternativeConcurrency int
 }
 
 type LoopTurn struct {
@@ -54,6 +60,9 @@ func NewOrchestrator(searcher *SearchAgent, scorer *ScorerAgent, recommender *Re
 	if cfg.MaxRecommendationTx <= 0 {
 		cfg.MaxRecommendationTx = DefaultMaxRecommendationTx
 	}
+	if cfg.MaxAlternativeConcurrency <= 0 {
+		cfg.MaxAlternativeConcurrency = DefaultMaxAlternativeConcurrency
+	}
 
 	return &Orchestrator{
 		searcher:    searcher,
@@ -102,6 +111,7 @@ func (o *Orchestrator) AnalyzeOnly(ctx context.Context, productName string, pref
 		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
 			return func(yield func(*session.Event, error) bool) {
 				log.Printf("analyze_only step=search product=%q", productName)
+				progress.Report(ctx, progress.Event{Stage: progress.StageSearching, ProductName: productName})
 				result, searchErr := o.searcher.Search(ic, productName)
 				if searchErr != nil {
 					yield(nil, searchErr)
@@ -127,6 +137,7 @@ func (o *Orchestrator) AnalyzeOnly(ctx context.Context, productName string, pref
 					return
 				}
 				log.Printf("analyze_only step=score ingredients=%d", len(searchRes.ListOfIngredients))
+				progress.Report(ctx, progress.Event{Stage: progress.StageScoring, ProductName: productName, IngredientCount: len(searchRes.ListOfIngredients)})
 				result, scoreErr := o.scorer.ScoreIngredients(ic, searchRes.ListOfIngredients, prefs)
 				if scoreErr != nil {
 					yield(nil, scoreErr)
@@ -166,8 +177,26 @@ func (o *Orchestrator) AnalyzeOnly(ctx context.Context, productName string, pref
 	return searchRes, initialScore, nil
 }
 
+// ScoreIngredients scores an ingredient list supplied by the caller instead of
+// one found by search. Additives are normalized first, as search does.
+func (o *Orchestrator) ScoreIngredients(ctx context.Context, ingredients []sbmodel.Ingredient, prefs *sbmodel.UserPreferences) (*sbmodel.ScorerResult, error) {
+	if o.scorer == nil {
+		return nil, fmt.Errorf("orchestrator requires scorer")
+	}
+
+	ctx, span := observability.StartPipelineSpan(ctx, "score_ingredients")
+	defer span.End()
+
+	result, err := o.scorer.ScoreIngredients(ctx, additive.Default().NormalizeIngredients(ingredients), prefs)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return result, nil
+}
+
 // AnalyzeAndImprove executes:
-// OCR (outside this orchestrator) -> search -> scorer -> (recommender -> scorer) loop up to max turns.
+// OCR (outside this orchestrator) -> search -> scorer -> (recommender -> search + scorer per alternative) loop up to max turns.
 func (o *Orchestrator) AnalyzeAndImprove(ctx context.Context, productName string, prefs *sbmodel.UserPreferences) (*WorkflowResult, error) {
 	if o.searcher == nil || o.scorer == nil || o.recommender == nil {
 		return nil, fmt.Errorf("orchestrator requires searcher, scorer, and recommender")
@@ -175,6 +204,8 @@ func (o *Orchestrator) AnalyzeAndImprove(ctx context.Context, productName string
 
 	ctx, span := observability.StartPipelineSpan(ctx, "analyze_and_improve")
 	defer span.End()
+	// The recommender reads prefs through lookup_user_preferences.
+	ctx = withPreferences(ctx, prefs)
 
 	log.Printf("workflow analyze start product=%q min_score=%.2f max_turns=%d has_prefs=%t", productName, o.cfg.MinAcceptableScore, o.cfg.MaxRecommendationTx, prefs != nil)
 
@@ -301,7 +332,7 @@ func (o *Orchestrator) AnalyzeAndImprove(ctx context.Context, productName string
 					return
 				}
 				log.Printf("workflow step start step=rescore recommendations=%d", len(latestRec.Recommendations))
-				scoreResult, scoreErr := o.scorer.ScoreRecommendations(ic, latestRec.Recommendations, prefs)
+				scoreResult, scoreErr := o.scoreAlternatives(ic, latestRec.Recommendations, prefs)
 				if scoreErr != nil {
 					log.Printf("workflow step failed step=rescore err=%v", scoreErr)
 					yield(nil, scoreErr)
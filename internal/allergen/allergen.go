@@ -0,0 +1,302 @@
+// Package allergen is an embedded reference of the major food allergen
+// groups, with the ingredient names each one hides behind on labels.
+package allergen
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/safebites/backend-go/internal/model"
+)
+
+//go:embed allergens.json
+var embeddedAllergens []byte
+
+type Allergen struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// Aliases are the words people use for the whole group in their
+	// preferences, e.g. "dairy" for milk.
+	Aliases []string `json:"aliases,omitempty"`
+	// Synonyms are ingredient names that contain the allergen.
+	Synonyms []string `json:"synonyms"`
+	// Exclusions are ingredient names that contain a synonym without
+	// containing the allergen, such as "coconut milk" or "nutmeg".
+	Exclusions []string `json:"exclusions,omitempty"`
+}
+
+// Match is an allergen found in an ingredient name and the synonym that
+// gave it away.
+type Match struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Synonym string `json:"synonym"`
+}
+
+type Catalog struct {
+	allergens []Allergen
+	byTerm    map[string][]int
+}
+
+// Load parses a catalog in the format of the embedded allergens.json.
+func Load(r io.Reader) (*Catalog, error) {
+	var doc struct {
+		Allergens []Allergen `json:"allergens"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode allergens: %w", err)
+	}
+
+	c := &Catalog{byTerm: map[string][]int{}}
+	keys := map[string]bool{}
+	for i, a := range doc.Allergens {
+		if a.Key == "" || len(a.Synonyms) == 0 {
+			return nil, fmt.Errorf("allergen %d needs a key and synonyms", i)
+		}
+		if keys[a.Key] {
+			return nil, fmt.Errorf("duplicate allergen %q", a.Key)
+		}
+		keys[a.Key] = true
+		// Aliases may name several groups ("shellfish"); names and keys
+		// must not.
+		for _, term := range append([]string{a.Key, a.Name}, a.Aliases...) {
+			term = normalizeName(term)
+			if len(c.byTerm[term]) > 0 && c.byTerm[term][len(c.byTerm[term])-1] == i {
+				continue
+			}
+			c.byTerm[term] = append(c.byTerm[term], i)
+		}
+	}
+	c.allergens = doc.Allergens
+	return c, nil
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the catalog built from the embedded dataset.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		c, err := Load(strings.NewReader(string(embeddedAllergens)))
+		if err != nil {
+			panic(fmt.Sprintf("embedded allergen catalog: %v", err))
+		}
+		defaultCatalog = c
+	})
+	return defaultCatalog
+}
+
+// Find returns the allergen groups an ingredient name contains. Synonyms
+// match as whole words anywhere in the name, so "Skimmed Milk Powder"
+// contains milk, after the group's exclusions are removed from it.
+func (c *Catalog) Find(ingredient string) []Match {
+	if c == nil {
+		return nil
+	}
+	name := " " + normalizeName(ingredient) + " "
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+
+	var matches []Match
+	for _, a := range c.allergens {
+		text := name
+		for _, ex := range a.Exclusions {
+			text = strings.ReplaceAll(text, " "+normalizeName(ex)+" ", "  ")
+		}
+		for _, syn := range a.Synonyms {
+			if strings.Contains(text, " "+normalizeName(syn)+" ") {
+				matches = append(matches, Match{Key: a.Key, Name: a.Name, Synonym: syn})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// Resolve maps a term from a user's allergy list, such as "dairy" or
+// "nuts", to the allergen groups it names.
+func (c *Catalog) Resolve(term string) []Allergen {
+	if c == nil {
+		return nil
+	}
+	var out []Allergen
+	for _, i := range c.byTerm[normalizeName(term)] {
+		out = append(out, c.allergens[i])
+	}
+	return out
+}
+
+// Conflicts checks an ingredient against the allergies and avoided
+// ingredients in prefs. An allergy that names an allergen group ("dairy"
+// catches casein) is matched by group, any other allergy and every avoided
+// ingredient by plain containment.
+func (c *Catalog) Conflicts(ingredient string, prefs *model.UserPreferences) []model.PreferenceConflict {
+	conflicts := []model.PreferenceConflict{}
+	if prefs == nil {
+		return conflicts
+	}
+	found := c.Find(ingredient)
+	lower := strings.ToLower(ingredient)
+
+	for _, allergy := range prefs.Allergies {
+		groups := c.Resolve(allergy.Name)
+		if len(groups) == 0 {
+			term := strings.ToLower(strings.TrimSpace(allergy.Name))
+			if term != "" && strings.Contains(lower, term) {
+				conflicts = append(conflicts, model.PreferenceConflict{Kind: model.ConflictAllergy, Preference: allergy.Name, Severity: allergy.Severity, Reason: fmt.Sprintf("%q mentions %s", ingredient, allergy.Name)})
+			}
+			continue
+		}
+		for _, m := range found {
+			if containsAllergen(groups, m.Key) {
+				conflicts = append(conflicts, model.PreferenceConflict{Kind: model.ConflictAllergy, Preference: allergy.Name, Severity: allergy.Severity, Reason: fmt.Sprintf("%s contains %s (%s)", ingredient, m.Name, m.Synonym)})
+				break
+			}
+		}
+	}
+	for _, avoid := range prefs.AvoidIngredients {
+		term := strings.ToLower(strings.TrimSpace(avoid))
+		if term != "" && strings.Contains(lower, term) {
+			conflicts = append(conflicts, model.PreferenceConflict{Kind: model.ConflictAvoid, Preference: avoid, Reason: fmt.Sprintf("%q mentions %s", ingredient, avoid)})
+		}
+	}
+	return conflicts
+}
+
+// TraceConflicts checks an allergen named in a "may contain" statement
+// against the allergies in prefs that count traces. Avoided ingredients are
+// not checked, since traces are not ingredients.
+func (c *Catalog) TraceConflicts(trace string, prefs *model.UserPreferences) []model.PreferenceConflict {
+	if prefs == nil {
+		return []model.PreferenceConflict{}
+	}
+	traced := &model.UserPreferences{}
+	for _, allergy := range prefs.Allergies {
+		if allergy.MayContain {
+			traced.Allergies = append(traced.Allergies, allergy)
+		}
+	}
+	return c.Conflicts(trace, traced)
+}
+
+// Apply holds the scores in result to the severity of the allergies in prefs.
+// An ingredient that breaks an allergy keeps at most the allergy's
+// model.Allergy.ScoreLimit, whatever the scorer gave it. Each trace, an
+// allergen the product may contain, that matches an allergy counting traces
+// is added as a score of its own at the allergy's TraceLimit; other traces
+// are left out. The overall score falls by the same share as the lowered
+// scores, as for regulatory downgrades. Scores are never raised.
+func (c *Catalog) Apply(result *model.ScorerResult, traces []string, prefs *model.UserPreferences) {
+	if c == nil || result == nil || prefs == nil || len(prefs.Allergies) == 0 {
+		return
+	}
+
+	var penalty float64
+	for i := range result.IngredientScores {
+		is := &result.IngredientScores[i]
+		conflicts := c.Conflicts(is.IngredientName, prefs)
+		if len(conflicts) == 0 && is.OriginalName != "" {
+			conflicts = c.Conflicts(is.OriginalName, prefs)
+		}
+		limit, note := strictest(conflicts, false)
+		if limit == "" {
+			continue
+		}
+		before := levelValue(string(is.SafetyScore))
+		if after := levelValue(limit); after < before {
+			is.SafetyScore = model.FlexibleString(limit)
+			penalty += before - after
+		}
+		is.Reasoning = strings.TrimSpace(is.Reasoning + " " + note)
+	}
+
+	for _, trace := range traces {
+		limit, note := strictest(c.TraceConflicts(trace, prefs), true)
+		if limit == "" {
+			continue
+		}
+		result.IngredientScores = append(result.IngredientScores, model.IngredientScore{
+			IngredientName: trace,
+			SafetyScore:    model.FlexibleString(limit),
+			Reasoning:      "The product may contain " + trace + ". " + note,
+			MayContain:     true,
+		})
+		penalty += levelValue("HIGH") - levelValue(limit)
+	}
+
+	if penalty > 0 && len(result.IngredientScores) > 0 {
+		overall := result.OverallScore - penalty/float64(len(result.IngredientScores))
+		result.OverallScore = math.Round(math.Max(overall, 0)*10) / 10
+	}
+}
+
+// strictest returns the lowest score limit among the allergy conflicts and a
+// note naming the allergy behind it, or "" when none is an allergy.
+func strictest(conflicts []model.PreferenceConflict, trace bool) (string, string) {
+	limit, note := "", ""
+	for _, conflict := range conflicts {
+		if conflict.Kind != model.ConflictAllergy {
+			continue
+		}
+		allergy := model.Allergy{Name: conflict.Preference, Severity: conflict.Severity, MayContain: trace}
+		l := allergy.ScoreLimit()
+		if trace {
+			l = allergy.TraceLimit()
+		}
+		if limit == "" || levelValue(l) < levelValue(limit) {
+			limit, note = l, severityNote(allergy)
+		}
+	}
+	return limit, note
+}
+
+func severityNote(a model.Allergy) string {
+	switch a.Severity {
+	case model.SeverityAnaphylactic:
+		return fmt.Sprintf("You have a severe (anaphylactic) allergy to %s.", a.Name)
+	case model.SeverityIntolerance:
+		return fmt.Sprintf("You are intolerant to %s.", a.Name)
+	case model.SeverityPreference:
+		return fmt.Sprintf("You prefer to avoid %s.", a.Name)
+	default:
+		return fmt.Sprintf("You are allergic to %s.", a.Name)
+	}
+}
+
+// levelValue maps a safety level onto the 0-10 overall scale.
+func levelValue(level string) float64 {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "HIGH":
+		return 10
+	case "MEDIUM":
+		return 5
+	default:
+		return 0
+	}
+}
+
+func containsAllergen(groups []Allergen, key string) bool {
+	for _, g := range groups {
+		if g.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+var nonNameChars = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	name = nonNameChars.ReplaceAllString(name, " ")
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,129 @@
+package allergen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func keys(matches []Match) []string {
+	out := make([]string, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, m.Key)
+	}
+	return out
+}
+
+func TestDefaultCatalogLoads(t *testing.T) {
+	c := Default()
+	require.Len(t, c.allergens, 14)
+}
+
+func TestCatalogFind(t *testing.T) {
+	c := Default()
+
+	for ingredient, want := range map[string][]string{
+		"Skimmed Milk Powder":        {"milk"},
+		"Sodium Caseinate":           {"milk"},
+		"Coconut Milk":               nil,
+		"Cocoa Butter":               nil,
+		"Almond Milk":                {"tree_nuts"},
+		"Peanut Butter":              {"peanuts"},
+		"Nutmeg":                     nil,
+		"Buckwheat Flour":            nil,
+		"Wheat Flour (Gluten)":       {"gluten"},
+		"Soya Lecithin":              {"soy"},
+		"Sodium Metabisulphite":      {"sulphites"},
+		"Preservative: E223":         {"sulphites"},
+		"Hazelnut Paste, Whole Milk": {"milk", "tree_nuts"},
+		"Sugar":                      nil,
+	} {
+		require.ElementsMatch(t, want, keys(c.Find(ingredient)), ingredient)
+	}
+}
+
+func TestCatalogResolve(t *testing.T) {
+	c := Default()
+
+	dairy := c.Resolve("Dairy")
+	require.Len(t, dairy, 1)
+	require.Equal(t, "milk", dairy[0].Key)
+
+	shellfish := c.Resolve("shellfish")
+	require.Len(t, shellfish, 2)
+
+	require.Empty(t, c.Resolve("pineapple"))
+}
+
+func TestCatalogConflicts(t *testing.T) {
+	c := Default()
+	prefs := &model.UserPreferences{
+		Allergies:        model.Allergies("dairy", "kiwi"),
+		AvoidIngredients: []string{"palm oil"},
+	}
+
+	conflicts := c.Conflicts("Whey Powder", prefs)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, model.ConflictAllergy, conflicts[0].Kind)
+	require.Equal(t, "dairy", conflicts[0].Preference)
+
+	// Allergies outside the catalog fall back to a plain substring match.
+	conflicts = c.Conflicts("Kiwi Puree", prefs)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, "kiwi", conflicts[0].Preference)
+
+	conflicts = c.Conflicts("Palm Oil", prefs)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, model.ConflictAvoid, conflicts[0].Kind)
+
+	require.Empty(t, c.Conflicts("Sugar", prefs))
+	require.NotNil(t, c.Conflicts("Whey Powder", nil))
+}
+
+func TestCatalogApplyCapsBySeverity(t *testing.T) {
+	prefs := &model.UserPreferences{Allergies: []model.Allergy{
+		{Name: "peanuts", Severity: model.SeverityAnaphylactic, MayContain: true},
+		{Name: "dairy", Severity: model.SeverityIntolerance},
+		{Name: "sesame", Severity: model.SeverityAllergy},
+	}}
+	result := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{
+			{IngredientName: "Whey Powder", SafetyScore: "HIGH"},
+			{IngredientName: "Peanut Butter", SafetyScore: "MEDIUM"},
+			{IngredientName: "Sugar", SafetyScore: "HIGH"},
+		},
+		OverallScore: 8,
+	}
+
+	Default().Apply(result, []string{"peanuts", "sesame"}, prefs)
+	require.Len(t, result.IngredientScores, 4, "only traces the user counts are scored")
+	require.Equal(t, model.FlexibleString("MEDIUM"), result.IngredientScores[0].SafetyScore, "an intolerance is capped at MEDIUM")
+	require.Contains(t, result.IngredientScores[0].Reasoning, "intolerant to dairy")
+	require.Equal(t, model.FlexibleString("LOW"), result.IngredientScores[1].SafetyScore)
+	require.Equal(t, model.FlexibleString("HIGH"), result.IngredientScores[2].SafetyScore)
+	trace := result.IngredientScores[3]
+	require.True(t, trace.MayContain)
+	require.Equal(t, "peanuts", trace.IngredientName)
+	require.Equal(t, model.FlexibleString("LOW"), trace.SafetyScore)
+	require.Equal(t, 3.0, result.OverallScore)
+}
+
+func TestCatalogApplyNeverRaisesScores(t *testing.T) {
+	prefs := &model.UserPreferences{Allergies: []model.Allergy{{Name: "dairy", Severity: model.SeverityPreference}}}
+	result := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{{IngredientName: "Whole Milk", SafetyScore: "LOW"}},
+		OverallScore:     2,
+	}
+
+	Default().Apply(result, []string{"milk"}, prefs)
+	require.Len(t, result.IngredientScores, 1)
+	require.Equal(t, model.FlexibleString("LOW"), result.IngredientScores[0].SafetyScore)
+	require.Equal(t, 2.0, result.OverallScore)
+}
+
+func TestLoadRejectsDuplicateKeys(t *testing.T) {
+	_, err := Load(strings.NewReader(`{"allergens":[{"key":"milk","name":"Milk","synonyms":["milk"]},{"key":"milk","name":"Dairy","synonyms":["cream"]}]}`))
+	require.ErrorContains(t, err, "duplicate allergen")
+}
@@ -0,0 +1,53 @@
+// Package blob stores uploaded images outside the database. Images are keyed
+// by a content-derived ID, so uploading the same bytes twice yields the same
+// ID, and are handed to clients as signed download URLs that expire.
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"time"
+)
+
+// DefaultURLTTL is how long a signed download URL stays valid.
+const DefaultURLTTL = 15 * time.Minute
+
+var (
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidID is returned for IDs that ImageID could not have produced,
+	// which keeps client-supplied IDs from reaching file paths or object keys.
+	ErrInvalidID = errors.New("invalid image id")
+)
+
+// Store persists image bytes under their ID.
+type Store interface {
+	// Put stores data under id. Storing the same id again overwrites it,
+	// which is harmless because the ID is derived from the content.
+	Put(ctx context.Context, id string, data []byte, contentType string) error
+	Get(ctx context.Context, id string) (*Object, error)
+	Exists(ctx context.Context, id string) (bool, error)
+	// SignedURL returns a download URL for id that stops working after the
+	// store's configured TTL.
+	SignedURL(ctx context.Context, id string) (string, error)
+}
+
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+var idPattern = regexp.MustCompile(`^img_[0-9a-f]{32}$`)
+
+// ImageID derives the stable ID of an image from its bytes.
+func ImageID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "img_" + hex.EncodeToString(sum[:16])
+}
+
+// ValidID reports whether id has the shape ImageID produces.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
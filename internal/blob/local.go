@@ -0,0 +1,157 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrExpired          = errors.New("signed url expired")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+type LocalConfig struct {
+	Dir string
+	// BaseURL is the public URL of this server; signed URLs point at
+	// BaseURL/api/images/{id}.
+	BaseURL string
+	// Secret signs download URLs. When empty a random secret is generated,
+	// so URLs stop working when the process restarts.
+	Secret []byte
+	TTL    time.Duration
+}
+
+// LocalStore keeps images as files in a directory, each next to a small
+// file holding its content type. Downloads are served by the API itself.
+type LocalStore struct {
+	cfg LocalConfig
+	now func() time.Time
+}
+
+func NewLocal(cfg LocalConfig) (*LocalStore, error) {
+	if strings.TrimSpace(cfg.Dir) == "" {
+		return nil, fmt.Errorf("local blob directory is required")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create blob directory: %w", err)
+	}
+	if len(cfg.Secret) == 0 {
+		cfg.Secret = make([]byte, 32)
+		if _, err := rand.Read(cfg.Secret); err != nil {
+			return nil, fmt.Errorf("generate url secret: %w", err)
+		}
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultURLTTL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &LocalStore{cfg: cfg, now: time.Now}, nil
+}
+
+func (s *LocalStore) Put(_ context.Context, id string, data []byte, contentType string) error {
+	if !ValidID(id) {
+		return ErrInvalidID
+	}
+	if err := s.writeFile(id+".type", []byte(contentType)); err != nil {
+		return err
+	}
+	return s.writeFile(id, data)
+}
+
+// writeFile writes through a temporary file so readers never see a
+// partially written image.
+func (s *LocalStore) writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(s.cfg.Dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write blob file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write blob file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.cfg.Dir, name)); err != nil {
+		return fmt.Errorf("store blob file: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Get(_ context.Context, id string) (*Object, error) {
+	if !ValidID(id) {
+		return nil, ErrInvalidID
+	}
+	data, err := os.ReadFile(filepath.Join(s.cfg.Dir, id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("read blob: %w", err)
+	}
+	contentType, err := os.ReadFile(filepath.Join(s.cfg.Dir, id+".type"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read blob content type: %w", err)
+	}
+	return &Object{Data: data, ContentType: string(contentType)}, nil
+}
+
+func (s *LocalStore) Exists(_ context.Context, id string) (bool, error) {
+	if !ValidID(id) {
+		return false, ErrInvalidID
+	}
+	_, err := os.Stat(filepath.Join(s.cfg.Dir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stat blob: %w", err)
+	}
+	return true, nil
+}
+
+func (s *LocalStore) SignedURL(_ context.Context, id string) (string, error) {
+	if !ValidID(id) {
+		return "", ErrInvalidID
+	}
+	expires := strconv.FormatInt(s.now().Add(s.cfg.TTL).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(id, expires)}}
+	return s.cfg.BaseURL + "/api/images/" + id + "?" + query.Encode(), nil
+}
+
+// Verify checks the expires and signature query parameters of a URL that
+// SignedURL produced for id.
+func (s *LocalStore) Verify(id, expires, signature string) error {
+	if !ValidID(id) {
+		return ErrInvalidID
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(id, expires))) {
+		return ErrInvalidSignature
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if s.now().After(time.Unix(unix, 0)) {
+		return ErrExpired
+	}
+	return nil
+}
+
+func (s *LocalStore) sign(id, expires string) string {
+	mac := hmac.New(sha256.New, s.cfg.Secret)
+	mac.Write([]byte(id + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
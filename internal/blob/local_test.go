@@ -0,0 +1,75 @@
+package blob
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageIDIsStableAndValid(t *testing.T) {
+	id := ImageID([]byte("label"))
+	require.Equal(t, id, ImageID([]byte("label")))
+	require.NotEqual(t, id, ImageID([]byte("other label")))
+	require.True(t, ValidID(id))
+	require.False(t, ValidID("../etc/passwd"))
+	require.False(t, ValidID(strings.ToUpper(id)))
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(LocalConfig{Dir: dir, BaseURL: "https://api.example.com/"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	data := []byte("\x89PNG image")
+	id := ImageID(data)
+	exists, err := store.Exists(ctx, id)
+	require.NoError(t, err)
+	require.False(t, exists)
+	_, err = store.Get(ctx, id)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, id, data, "image/png"))
+	require.NoError(t, store.Put(ctx, id, data, "image/png"))
+	obj, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, data, obj.Data)
+	require.Equal(t, "image/png", obj.ContentType)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "temporary files are cleaned up")
+
+	require.ErrorIs(t, store.Put(ctx, "../escape", data, "image/png"), ErrInvalidID)
+	_, err = os.Stat(filepath.Join(filepath.Dir(dir), "escape"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestLocalStoreSignedURLs(t *testing.T) {
+	store, err := NewLocal(LocalConfig{Dir: t.TempDir(), BaseURL: "https://api.example.com", Secret: []byte("secret"), TTL: time.Minute})
+	require.NoError(t, err)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	id := ImageID([]byte("label"))
+	signed, err := store.SignedURL(context.Background(), id)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	require.Equal(t, "/api/images/"+id, u.Path)
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	require.NoError(t, store.Verify(id, expires, signature))
+	require.ErrorIs(t, store.Verify(id, expires, strings.Repeat("0", len(signature))), ErrInvalidSignature)
+	require.ErrorIs(t, store.Verify(ImageID([]byte("other")), expires, signature), ErrInvalidSignature)
+	require.ErrorIs(t, store.Verify(id, "9999999999", signature), ErrInvalidSignature)
+
+	now = now.Add(2 * time.Minute)
+	require.ErrorIs(t, store.Verify(id, expires, signature), ErrExpired)
+}
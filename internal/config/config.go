@@ -3,7 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,9 +22,43 @@ func (l LangfuseConfig) Enabled() bool {
 	return strings.TrimSpace(l.PublicKey) != "" && strings.TrimSpace(l.SecretKey) != ""
 }
 
+// BlobConfig selects and configures where uploaded images are stored.
+type BlobConfig struct {
+	// Backend is "local" or "s3".
+	Backend  string
+	LocalDir string
+	// URLSecret signs local download URLs. Empty means a random secret per
+	// process, so URLs do not survive a restart.
+	URLSecret string
+	URLTTL    time.Duration
+	// PublicBaseURL is where clients reach this server; local download URLs
+	// are built on it.
+	PublicBaseURL     string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
+}
+
+// WebhookConfig tunes outbound webhook delivery.
+type WebhookConfig struct {
+	// MaxAttempts is how often a delivery is tried before it is marked failed.
+	MaxAttempts int
+	// RetryBaseDelay doubles after every failed attempt, up to RetryMaxDelay.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	Timeout        time.Duration
+}
+
 // Config holds all application configuration loaded from environment variables.
 type Config struct {
-	Port             string
+	Port     string
+	GRPCPort string
+	// AdminAddr is where the operator-only listener for metrics binds. It
+	// defaults to loopback so the counters are not reachable from outside.
+	AdminAddr        string
 	Env              string
 	DatabaseURL      string
 	MigrationsPath   string
@@ -31,6 +67,29 @@ type Config struct {
 	Auth0APIAudience string
 	CORSOrigins      []string
 	Langfuse         LangfuseConfig
+	// ConfidenceThreshold is the overall analysis confidence (0–1) below which
+	// responses are flagged as needing verification; 0 turns flagging off.
+	ConfidenceThreshold float64
+	// MaxImageDimension caps the longer side, in pixels, of uploaded images
+	// after preprocessing.
+	MaxImageDimension int
+	// ImageDedupMaxDistance is the perceptual-hash distance (0–11 bits) within
+	// which an upload reuses a cached analysis; negative disables dedup.
+	ImageDedupMaxDistance int
+	// ImageDedupReuseAnalysis reuses the whole cached analysis, not just the
+	// product name, when the preferences match.
+	ImageDedupReuseAnalysis bool
+	ImageDedupTTL           time.Duration
+	// BatchConcurrency is how many batch product analyses run at once,
+	// across all requests.
+	BatchConcurrency int
+	// BatchCacheTTL is how long batch analyses of a product name are reused.
+	BatchCacheTTL time.Duration
+	// AgentSessionRetention is how long recorded agent sessions, chat
+	// transcripts included, are kept after their last update.
+	AgentSessionRetention time.Duration
+	Blob                  BlobConfig
+	Webhooks              WebhookConfig
 }
 
 // Load reads configuration from environment variables, loading .env if present.
@@ -40,6 +99,8 @@ func Load() *Config {
 
 	cfg := &Config{
 		Port:             getEnv("PORT", "8080"),
+		GRPCPort:         getEnv("GRPC_PORT", "9090"),
+		AdminAddr:        getEnv("ADMIN_ADDR", "127.0.0.1:9100"),
 		Env:              getEnv("ENV", "development"),
 		DatabaseURL:      requireEnv("DATABASE_URL"),
 		MigrationsPath:   getEnv("MIGRATIONS_PATH", "migrations"),
@@ -52,6 +113,38 @@ func Load() *Config {
 			SecretKey: getEnv("LANGFUSE_SECRET_KEY", ""),
 			Host:      getEnv("LANGFUSE_BASE_URL", "https://us.cloud.langfuse.com"),
 		},
+		ConfidenceThreshold: getEnvFloat("CONFIDENCE_THRESHOLD", 0.6),
+		MaxImageDimension:   getEnvInt("MAX_IMAGE_DIMENSION", 1600),
+
+		ImageDedupMaxDistance:   getEnvInt("IMAGE_DEDUP_MAX_DISTANCE", 6),
+		ImageDedupReuseAnalysis: getEnvBool("IMAGE_DEDUP_REUSE_ANALYSIS", false),
+		ImageDedupTTL:           getEnvDuration("IMAGE_DEDUP_TTL", 720*time.Hour),
+
+		BatchConcurrency: getEnvInt("BATCH_CONCURRENCY", 4),
+		BatchCacheTTL:    getEnvDuration("BATCH_CACHE_TTL", time.Hour),
+
+		AgentSessionRetention: getEnvDuration("AGENT_SESSION_RETENTION", 720*time.Hour),
+
+		Blob: BlobConfig{
+			Backend:           strings.ToLower(getEnv("BLOB_BACKEND", "local")),
+			LocalDir:          getEnv("BLOB_LOCAL_DIR", "data/images"),
+			URLSecret:         getEnv("BLOB_URL_SECRET", ""),
+			URLTTL:            getEnvDuration("BLOB_URL_TTL", 15*time.Minute),
+			PublicBaseURL:     getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+			S3Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			S3Region:          getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:          getEnv("S3_BUCKET", ""),
+			S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:       getEnvBool("S3_PATH_STYLE", false),
+		},
+
+		Webhooks: WebhookConfig{
+			MaxAttempts:    getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBaseDelay: getEnvDuration("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second),
+			RetryMaxDelay:  getEnvDuration("WEBHOOK_RETRY_MAX_DELAY", time.Hour),
+			Timeout:        getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		},
 	}
 
 	return cfg
@@ -74,6 +167,54 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvFloat(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Fatalf("environment variable %q must be a number: %v", key, err)
+	}
+	return f
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("environment variable %q must be an integer: %v", key, err)
+	}
+	return n
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("environment variable %q must be a boolean: %v", key, err)
+	}
+	return b
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("environment variable %q must be a duration such as 720h: %v", key, err)
+	}
+	return d
+}
+
 func requireEnv(key string) string {
 	v := os.Getenv(key)
 	if v == "" {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestDevModeAuthRequiresBothAuth0Fields(t *testing.T) {
@@ -64,3 +65,79 @@ func TestLoad_LangfuseDisabledWhenKeysMissing(t *testing.T) {
 		t.Error("Enabled() = true with no keys, want false")
 	}
 }
+
+func TestLoad_ConfidenceThreshold(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://x")
+	t.Setenv("GOOGLE_API_KEY", "k")
+
+	t.Setenv("CONFIDENCE_THRESHOLD", "")
+	if got := Load().ConfidenceThreshold; got != 0.6 {
+		t.Errorf("ConfidenceThreshold = %v, want default 0.6", got)
+	}
+
+	t.Setenv("CONFIDENCE_THRESHOLD", "0.75")
+	if got := Load().ConfidenceThreshold; got != 0.75 {
+		t.Errorf("ConfidenceThreshold = %v, want 0.75", got)
+	}
+
+	t.Setenv("CONFIDENCE_THRESHOLD", "0")
+	if got := Load().ConfidenceThreshold; got != 0 {
+		t.Errorf("ConfidenceThreshold = %v, want 0 to turn flagging off", got)
+	}
+}
+
+func TestLoad_MaxImageDimension(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://x")
+	t.Setenv("GOOGLE_API_KEY", "k")
+
+	t.Setenv("MAX_IMAGE_DIMENSION", "")
+	if got := Load().MaxImageDimension; got != 1600 {
+		t.Errorf("MaxImageDimension = %v, want default 1600", got)
+	}
+
+	t.Setenv("MAX_IMAGE_DIMENSION", "1024")
+	if got := Load().MaxImageDimension; got != 1024 {
+		t.Errorf("MaxImageDimension = %v, want 1024", got)
+	}
+}
+
+func TestLoad_ImageDedup(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://x")
+	t.Setenv("GOOGLE_API_KEY", "k")
+
+	t.Setenv("IMAGE_DEDUP_MAX_DISTANCE", "")
+	t.Setenv("IMAGE_DEDUP_REUSE_ANALYSIS", "")
+	t.Setenv("IMAGE_DEDUP_TTL", "")
+	cfg := Load()
+	if cfg.ImageDedupMaxDistance != 6 || cfg.ImageDedupReuseAnalysis || cfg.ImageDedupTTL != 720*time.Hour {
+		t.Errorf("defaults = (%v, %v, %v), want (6, false, 720h)", cfg.ImageDedupMaxDistance, cfg.ImageDedupReuseAnalysis, cfg.ImageDedupTTL)
+	}
+
+	t.Setenv("IMAGE_DEDUP_MAX_DISTANCE", "-1")
+	t.Setenv("IMAGE_DEDUP_REUSE_ANALYSIS", "true")
+	t.Setenv("IMAGE_DEDUP_TTL", "24h")
+	cfg = Load()
+	if cfg.ImageDedupMaxDistance != -1 || !cfg.ImageDedupReuseAnalysis || cfg.ImageDedupTTL != 24*time.Hour {
+		t.Errorf("overrides = (%v, %v, %v), want (-1, true, 24h)", cfg.ImageDedupMaxDistance, cfg.ImageDedupReuseAnalysis, cfg.ImageDedupTTL)
+	}
+}
+
+func TestLoad_Blob(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://x")
+	t.Setenv("GOOGLE_API_KEY", "k")
+
+	t.Setenv("BLOB_BACKEND", "")
+	t.Setenv("BLOB_URL_TTL", "")
+	cfg := Load().Blob
+	if cfg.Backend != "local" || cfg.LocalDir != "data/images" || cfg.URLTTL != 15*time.Minute {
+		t.Errorf("defaults = (%q, %q, %v), want (local, data/images, 15m)", cfg.Backend, cfg.LocalDir, cfg.URLTTL)
+	}
+
+	t.Setenv("BLOB_BACKEND", "S3")
+	t.Setenv("S3_BUCKET", "scans")
+	t.Setenv("S3_PATH_STYLE", "true")
+	cfg = Load().Blob
+	if cfg.Backend != "s3" || cfg.S3Bucket != "scans" || !cfg.S3PathStyle {
+		t.Errorf("s3 = (%q, %q, %v), want (s3, scans, true)", cfg.Backend, cfg.S3Bucket, cfg.S3PathStyle)
+	}
+}
@@ -0,0 +1,194 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/blob"
+	"github.com/safebites/backend-go/internal/imageprep"
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/progress"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+var pbStages = map[progress.Stage]pb.AnalysisStage{
+	progress.StageReadingLabel: pb.AnalysisStage_ANALYSIS_STAGE_READING_LABEL,
+	progress.StageLabelRead:    pb.AnalysisStage_ANALYSIS_STAGE_LABEL_READ,
+	progress.StageSearching:    pb.AnalysisStage_ANALYSIS_STAGE_SEARCHING,
+	progress.StageScoring:      pb.AnalysisStage_ANALYSIS_STAGE_SCORING,
+	progress.StageLocalizing:   pb.AnalysisStage_ANALYSIS_STAGE_LOCALIZING,
+}
+
+type analyzeServer struct {
+	pb.UnimplementedAnalyzeServiceServer
+	cfg Config
+}
+
+func (s *analyzeServer) AnalyzeImage(req *pb.AnalyzeImageRequest, stream grpc.ServerStreamingServer[pb.AnalyzeEvent]) error {
+	if s.cfg.Analyze == nil {
+		return status.Error(codes.Unimplemented, "analyze service is not configured")
+	}
+	imageBytes := req.GetImage()
+	if len(imageBytes) == 0 {
+		return invalidArgument("image must not be empty")
+	}
+	if len(imageBytes) > MaxImageBytes {
+		return status.Error(codes.ResourceExhausted, "image too large")
+	}
+	mimeType := strings.TrimSpace(req.GetMimeType())
+	if mimeType == "" {
+		mimeType = http.DetectContentType(imageBytes)
+	}
+
+	ctx := stream.Context()
+	if s.cfg.Images != nil {
+		img, err := s.cfg.Images.Process(ctx, imageBytes, mimeType)
+		if err != nil {
+			if errors.Is(err, imageprep.ErrInvalidImage) {
+				return invalidArgument(err.Error())
+			}
+			return serviceError(ctx, "failed to process image", err)
+		}
+		imageBytes, mimeType = img.Bytes, img.MimeType
+	}
+
+	result, scanID, err := s.run(stream, req.GetLanguage(), func(ctx context.Context, prefs *model.UserPreferences) (*model.AnalysisResult, error) {
+		return s.cfg.Analyze.Analyze(ctx, imageBytes, mimeType, prefs)
+	})
+	if err != nil {
+		return err
+	}
+	imageID, imageURL := s.storeUpload(ctx, imageBytes, mimeType)
+	s.publish(ctx, scanID, result, imageID)
+
+	out := toPBAnalysis(scanID, result)
+	out.ImageId, out.ImageUrl = imageID, imageURL
+	return stream.Send(&pb.AnalyzeEvent{Event: &pb.AnalyzeEvent_Result{Result: out}})
+}
+
+func (s *analyzeServer) AnalyzeProduct(req *pb.AnalyzeProductRequest, stream grpc.ServerStreamingServer[pb.AnalyzeEvent]) error {
+	if s.cfg.Analyze == nil {
+		return status.Error(codes.Unimplemented, "analyze service is not configured")
+	}
+	productName := strings.TrimSpace(req.GetProductName())
+	if productName == "" {
+		return invalidArgument("product_name is required")
+	}
+
+	result, scanID, err := s.run(stream, req.GetLanguage(), func(ctx context.Context, prefs *model.UserPreferences) (*model.AnalysisResult, error) {
+		return s.cfg.Analyze.AnalyzeProduct(ctx, productName, prefs)
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(stream.Context(), scanID, result, "")
+	return stream.Send(&pb.AnalyzeEvent{Event: &pb.AnalyzeEvent_Result{Result: toPBAnalysis(scanID, result)}})
+}
+
+// run performs an analysis under a fresh scan ID, streaming its progress,
+// and localizes the result into the requested language.
+func (s *analyzeServer) run(stream grpc.ServerStreamingServer[pb.AnalyzeEvent], language string, analyze func(context.Context, *model.UserPreferences) (*model.AnalysisResult, error)) (*model.AnalysisResult, string, error) {
+	ctx := stream.Context()
+	prefs, err := callerPreferences(ctx, s.cfg.Users)
+	if err != nil {
+		return nil, "", serviceError(ctx, "failed to fetch user preferences", err)
+	}
+
+	// Progress is best-effort: a failed send means the client has gone, and
+	// the canceled context stops the analysis soon after.
+	var mu sync.Mutex
+	report := func(e progress.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = stream.Send(&pb.AnalyzeEvent{Event: &pb.AnalyzeEvent_Progress{Progress: &pb.AnalyzeProgress{
+			Stage:           pbStages[e.Stage],
+			ProductName:     e.ProductName,
+			IngredientCount: int32(e.IngredientCount),
+		}}})
+	}
+
+	scanID := uuid.NewString()
+	ctx = progress.WithListener(agentContext(ctx, scanID), report)
+	result, err := analyze(ctx, prefs)
+	if err != nil {
+		return nil, "", serviceError(ctx, "failed to analyze product", err)
+	}
+
+	lang := service.ResponseLanguage(language)
+	if s.cfg.Localize != nil && lang != model.DefaultLanguage {
+		progress.Report(ctx, progress.Event{Stage: progress.StageLocalizing, ProductName: result.ProductName})
+		localized, err := s.cfg.Localize.LocalizeAnalysis(ctx, result, lang)
+		if err != nil {
+			// An English result beats none, as on the REST API.
+			log.Printf("localize analysis failed lang=%s err=%v", lang, err)
+		} else {
+			result = localized
+		}
+	}
+	return result, scanID, nil
+}
+
+// publish raises analysis.completed for a signed-in caller.
+func (s *analyzeServer) publish(ctx context.Context, scanID string, result *model.AnalysisResult, imageID string) {
+	if userID, ok := middleware.UserIDFromContext(ctx); ok && s.cfg.Events != nil {
+		s.cfg.Events.Publish(ctx, userID, model.EventAnalysisCompleted, model.NewAnalysisCompleted(scanID, result, imageID))
+	}
+}
+
+// storeUpload keeps the analyzed image so a scan can link to it. Like the
+// REST API, a storage failure only costs the image.
+func (s *analyzeServer) storeUpload(ctx context.Context, imageBytes []byte, mimeType string) (string, string) {
+	if s.cfg.Blobs == nil {
+		return "", ""
+	}
+	id := blob.ImageID(imageBytes)
+	if err := s.cfg.Blobs.Put(ctx, id, imageBytes, mimeType); err != nil {
+		log.Printf("store uploaded image failed id=%s err=%v", id, err)
+		return "", ""
+	}
+	url, err := s.cfg.Blobs.SignedURL(ctx, id)
+	if err != nil {
+		log.Printf("sign image url failed id=%s err=%v", id, err)
+		return id, ""
+	}
+	return id, url
+}
+
+func (s *analyzeServer) ScoreIngredients(ctx context.Context, req *pb.ScoreIngredientsRequest) (*pb.ScoreIngredientsResponse, error) {
+	if s.cfg.Analyze == nil {
+		return nil, status.Error(codes.Unimplemented, "analyze service is not configured")
+	}
+	var names []string
+	for _, name := range req.GetIngredients() {
+		if strings.TrimSpace(name) != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, invalidArgument("ingredients are required")
+	}
+	if len(names) > service.MaxScoredIngredients {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ingredients can be scored", service.MaxScoredIngredients)
+	}
+
+	prefs, err := callerPreferences(ctx, s.cfg.Users)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to fetch user preferences", err)
+	}
+	result, err := s.cfg.Analyze.ScoreIngredients(agentContext(ctx, ""), names, prefs)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to score ingredients", err)
+	}
+	return &pb.ScoreIngredientsResponse{Result: toPBScorerResult(result)}, nil
+}
@@ -0,0 +1,84 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/middleware"
+)
+
+// servicePrefix marks our own RPCs; everything else, such as reflection, is
+// left alone by the authenticator.
+const servicePrefix = "/safebites.v1."
+
+// publicMethods serve anonymous callers and personalize results for
+// authenticated ones, like the REST routes behind only
+// middleware.OptionalAuth. Every other SafeBites RPC requires auth.
+var publicMethods = map[string]bool{
+	pb.TemplateService_ListTemplates_FullMethodName:   true,
+	pb.AnalyzeService_AnalyzeImage_FullMethodName:     true,
+	pb.AnalyzeService_AnalyzeProduct_FullMethodName:   true,
+	pb.AnalyzeService_ScoreIngredients_FullMethodName: true,
+	pb.RecommendService_Recommend_FullMethodName:      true,
+}
+
+// authenticator reads the bearer token from the "authorization" metadata key
+// and applies the REST API's rules to it.
+type authenticator struct {
+	devModeAuth bool
+}
+
+func (a *authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if !strings.HasPrefix(fullMethod, servicePrefix) {
+		return ctx, nil
+	}
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			header = values[0]
+		}
+	}
+
+	if publicMethods[fullMethod] {
+		if userID, ok := middleware.UserIDFromAuthorization(header); ok {
+			return middleware.WithUserID(ctx, userID), nil
+		}
+		return ctx, nil
+	}
+	userID, err := middleware.RequiredUserID(header, a.devModeAuth)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return middleware.WithUserID(ctx, userID), nil
+}
+
+func (a *authenticator) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
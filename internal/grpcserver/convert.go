@@ -0,0 +1,218 @@
+package grpcserver
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toPBUser(u *model.User) *pb.User {
+	if u == nil {
+		return nil
+	}
+	return &pb.User{
+		Id:      u.ID,
+		Email:   u.Email,
+		Name:    u.Name,
+		Picture: u.Picture,
+		Preferences: &pb.Preferences{
+			Allergies:        model.AllergyNames(u.Allergies),
+			DietGoals:        u.DietGoals,
+			AvoidIngredients: u.AvoidIngredients,
+			HomeRegion:       u.HomeRegion,
+		},
+		CreatedAt: timestamp(u.CreatedAt),
+		UpdatedAt: timestamp(u.UpdatedAt),
+	}
+}
+
+// fromPBPreferences reads allergies as SeverityAllergy, since the proto only
+// carries their names.
+func fromPBPreferences(p *pb.Preferences) model.UserPreferences {
+	return model.UserPreferences{
+		Allergies:        model.Allergies(p.GetAllergies()...),
+		DietGoals:        p.GetDietGoals(),
+		AvoidIngredients: p.GetAvoidIngredients(),
+		HomeRegion:       p.GetHomeRegion(),
+	}
+}
+
+func toPBScan(s *model.Scan) (*pb.Scan, error) {
+	ingredients, err := toPBIngredients(s.Ingredients)
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", s.ID, err)
+	}
+	return &pb.Scan{
+		Id:          s.ID,
+		UserId:      s.UserID,
+		ProductName: s.ProductName,
+		Brand:       s.Brand,
+		Image:       s.Image,
+		ImageId:     s.ImageID,
+		SafetyScore: int32(s.SafetyScore),
+		IsSafe:      s.IsSafe,
+		Ingredients: ingredients,
+		Sources:     toPBSources(s.Sources),
+		Confidence:  toPBConfidence(s.Confidence),
+		Timestamp:   timestamp(s.Timestamp),
+	}, nil
+}
+
+// toPBIngredients converts the free-form ingredient objects clients store
+// with their scans.
+func toPBIngredients(ingredients []map[string]interface{}) ([]*structpb.Struct, error) {
+	out := make([]*structpb.Struct, 0, len(ingredients))
+	for i, ingredient := range ingredients {
+		s, err := structpb.NewStruct(ingredient)
+		if err != nil {
+			return nil, fmt.Errorf("ingredient %d: %w", i, err)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func fromPBIngredients(ingredients []*structpb.Struct) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		out = append(out, ingredient.AsMap())
+	}
+	return out
+}
+
+func toPBFavorite(f *model.Favorite) *pb.Favorite {
+	out := &pb.Favorite{
+		Id:          int64(f.ID),
+		UserId:      f.UserID,
+		ProductName: f.ProductName,
+		Brand:       f.Brand,
+		Image:       f.Image,
+		AddedAt:     timestamp(f.AddedAt),
+	}
+	if f.SafetyScore != nil {
+		score := int32(*f.SafetyScore)
+		out.SafetyScore = &score
+	}
+	return out
+}
+
+func toPBTemplate(t model.DietaryTemplate) *pb.DietaryTemplate {
+	return &pb.DietaryTemplate{
+		Key:              t.Key,
+		Name:             t.Name,
+		Description:      t.Description,
+		Allergies:        model.AllergyNames(t.Allergies),
+		DietGoals:        t.DietGoals,
+		AvoidIngredients: t.AvoidIngredients,
+	}
+}
+
+func toPBSources(sources []model.Source) []*pb.Source {
+	out := make([]*pb.Source, 0, len(sources))
+	for _, s := range sources {
+		out = append(out, &pb.Source{Title: s.Title, Uri: s.URI})
+	}
+	return out
+}
+
+func fromPBSources(sources []*pb.Source) []model.Source {
+	out := make([]model.Source, 0, len(sources))
+	for _, s := range sources {
+		out = append(out, model.Source{Title: s.GetTitle(), URI: s.GetUri()})
+	}
+	return out
+}
+
+func toPBConfidence(c *model.Confidence) *pb.Confidence {
+	if c == nil {
+		return nil
+	}
+	return &pb.Confidence{
+		Ocr:               c.OCR,
+		Sources:           c.Sources,
+		Scorer:            c.Scorer,
+		Overall:           c.Overall,
+		NeedsVerification: c.NeedsVerification,
+		Notes:             c.Notes,
+	}
+}
+
+func fromPBConfidence(c *pb.Confidence) *model.Confidence {
+	if c == nil {
+		return nil
+	}
+	return &model.Confidence{
+		OCR:               c.GetOcr(),
+		Sources:           c.GetSources(),
+		Scorer:            c.GetScorer(),
+		Overall:           c.GetOverall(),
+		NeedsVerification: c.GetNeedsVerification(),
+		Notes:             c.GetNotes(),
+	}
+}
+
+func toPBScorerResult(r *model.ScorerResult) *pb.ScorerResult {
+	if r == nil {
+		return nil
+	}
+	out := &pb.ScorerResult{
+		IngredientScores: make([]*pb.IngredientScore, 0, len(r.IngredientScores)),
+		OverallScore:     r.OverallScore,
+	}
+	for _, s := range r.IngredientScores {
+		score := &pb.IngredientScore{
+			IngredientName: s.IngredientName,
+			OriginalName:   s.OriginalName,
+			AdditiveCode:   s.AdditiveCode,
+			SafetyScore:    string(s.SafetyScore),
+			Reasoning:      s.Reasoning,
+		}
+		for _, rs := range s.Regulatory {
+			score.Regulatory = append(score.Regulatory, &pb.RegionStatus{Region: rs.Region, Status: string(rs.Status), Note: rs.Note})
+		}
+		out.IngredientScores = append(out.IngredientScores, score)
+	}
+	return out
+}
+
+func toPBAnalysis(scanID string, r *model.AnalysisResult) *pb.AnalysisResult {
+	return &pb.AnalysisResult{
+		ScanId:              scanID,
+		ProductName:         r.ProductName,
+		DetectedLanguage:    r.DetectedLanguage,
+		Language:            r.Language,
+		IngredientBreakdown: toPBScorerResult(r.IngredientBreakdown),
+		Sources:             toPBSources(r.Sources),
+		Confidence:          toPBConfidence(r.Confidence),
+	}
+}
+
+func toPBRecommendations(r *model.RecommenderResult, lang string) *pb.RecommendResponse {
+	out := &pb.RecommendResponse{Language: lang}
+	if r == nil {
+		return out
+	}
+	for _, rec := range r.Recommendations {
+		out.Recommendations = append(out.Recommendations, &pb.Recommendation{
+			ProductName:         rec.ProductName,
+			HealthScore:         string(rec.HealthScore),
+			Reason:              rec.Reason,
+			IngredientBreakdown: toPBScorerResult(rec.IngredientBreakdown),
+			Sources:             toPBSources(rec.Sources),
+		})
+	}
+	out.Sources = toPBSources(r.Sources)
+	return out
+}
@@ -0,0 +1,50 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/safebites/backend-go/internal/guard"
+)
+
+// errorDomain is the ErrorInfo domain of machine-readable error reasons.
+const errorDomain = "safebites"
+
+func invalidArgument(message string) error {
+	return status.Error(codes.InvalidArgument, message)
+}
+
+// serviceError maps a service failure to a status the way the REST handlers
+// map them to HTTP responses: guard rejections become INVALID_ARGUMENT with
+// guard.ErrorCode as the ErrorInfo reason, and anything else is logged and
+// answered with message as INTERNAL.
+func serviceError(ctx context.Context, message string, err error) error {
+	if st := rejectedInput(err); st != nil {
+		return st.Err()
+	}
+	method, _ := grpc.Method(ctx)
+	log.Printf("grpc error method=%s message=%q err=%v", method, message, err)
+	return status.Error(codes.Internal, message)
+}
+
+func rejectedInput(err error) *status.Status {
+	var rejected *guard.RejectedError
+	if !errors.As(err, &rejected) {
+		return nil
+	}
+	message := "product name was rejected as unsafe input"
+	if rejected.Source == guard.SourceOCR {
+		message = "label text was rejected as unsafe input"
+	}
+	st := status.New(codes.InvalidArgument, message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: guard.ErrorCode, Domain: errorDomain}); err == nil {
+		return detailed
+	}
+	return st
+}
@@ -0,0 +1,99 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+type favoriteServer struct {
+	pb.UnimplementedFavoriteServiceServer
+	cfg Config
+}
+
+func (s *favoriteServer) ListFavorites(ctx context.Context, _ *pb.ListFavoritesRequest) (*pb.ListFavoritesResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := s.cfg.Favorites.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to fetch favorites", err)
+	}
+	resp := &pb.ListFavoritesResponse{Favorites: make([]*pb.Favorite, 0, len(favorites))}
+	for i := range favorites {
+		resp.Favorites = append(resp.Favorites, toPBFavorite(&favorites[i]))
+	}
+	return resp, nil
+}
+
+func (s *favoriteServer) AddFavorite(ctx context.Context, req *pb.AddFavoriteRequest) (*pb.AddFavoriteResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetProductName()) == "" {
+		return nil, invalidArgument("product_name is required")
+	}
+	var safetyScore *int
+	if req.SafetyScore != nil {
+		if req.GetSafetyScore() < 0 || req.GetSafetyScore() > 100 {
+			return nil, invalidArgument("safety_score must be between 0 and 100")
+		}
+		score := int(req.GetSafetyScore())
+		safetyScore = &score
+	}
+
+	created, err := s.cfg.Favorites.Create(ctx, &model.Favorite{
+		UserID:      userID,
+		ProductName: req.GetProductName(),
+		Brand:       req.GetBrand(),
+		SafetyScore: safetyScore,
+		Image:       req.GetImage(),
+	})
+	if err != nil {
+		return nil, serviceError(ctx, "failed to add favorite", err)
+	}
+	return &pb.AddFavoriteResponse{Favorite: toPBFavorite(created)}, nil
+}
+
+func (s *favoriteServer) DeleteFavorite(ctx context.Context, req *pb.DeleteFavoriteRequest) (*pb.DeleteFavoriteResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetFavoriteId() <= 0 {
+		return nil, invalidArgument("favorite_id must be positive")
+	}
+
+	if err := s.cfg.Favorites.Delete(ctx, userID, int(req.GetFavoriteId())); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "favorite not found")
+		}
+		return nil, serviceError(ctx, "failed to delete favorite", err)
+	}
+	return &pb.DeleteFavoriteResponse{}, nil
+}
+
+func (s *favoriteServer) CheckFavorite(ctx context.Context, req *pb.CheckFavoriteRequest) (*pb.CheckFavoriteResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetProductName()) == "" {
+		return nil, invalidArgument("product_name is required")
+	}
+
+	exists, err := s.cfg.Favorites.Exists(ctx, userID, req.GetProductName())
+	if err != nil {
+		return nil, serviceError(ctx, "failed to check favorite", err)
+	}
+	return &pb.CheckFavoriteResponse{IsFavorite: exists}, nil
+}
@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+type recommendServer struct {
+	pb.UnimplementedRecommendServiceServer
+	cfg Config
+}
+
+func (s *recommendServer) Recommend(ctx context.Context, req *pb.RecommendRequest) (*pb.RecommendResponse, error) {
+	if s.cfg.Recommend == nil {
+		return nil, status.Error(codes.Unimplemented, "recommend service is not configured")
+	}
+	productName := strings.TrimSpace(req.GetProductName())
+	if productName == "" {
+		return nil, invalidArgument("product_name is required")
+	}
+	if req.GetOverallScore() < 0 || req.GetOverallScore() > 10 {
+		return nil, invalidArgument("overall_score must be between 0 and 10")
+	}
+
+	prefs, err := callerPreferences(ctx, s.cfg.Users)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to fetch user preferences", err)
+	}
+	agentCtx := agentContext(ctx, "")
+	result, err := s.cfg.Recommend.Recommend(agentCtx, productName, req.GetOverallScore(), prefs)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to generate recommendations", err)
+	}
+
+	lang := service.ResponseLanguage(req.GetLanguage())
+	if s.cfg.Localize == nil {
+		lang = model.DefaultLanguage
+	}
+	if lang != model.DefaultLanguage {
+		localized, err := s.cfg.Localize.LocalizeRecommendations(agentCtx, result, lang)
+		if err != nil {
+			log.Printf("localize recommendations failed lang=%s err=%v", lang, err)
+			lang = model.DefaultLanguage
+		} else {
+			result = localized
+		}
+	}
+	return toPBRecommendations(result, lang), nil
+}
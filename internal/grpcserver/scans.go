@@ -0,0 +1,159 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/blob"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+const (
+	defaultScanLimit = 20
+	maxScanLimit     = 100
+)
+
+type scanServer struct {
+	pb.UnimplementedScanServiceServer
+	cfg Config
+}
+
+func (s *scanServer) ListScans(ctx context.Context, req *pb.ListScansRequest) (*pb.ListScansResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	switch {
+	case limit < 0:
+		return nil, invalidArgument("limit must not be negative")
+	case limit == 0:
+		limit = defaultScanLimit
+	case limit > maxScanLimit:
+		limit = maxScanLimit
+	}
+
+	page, err := s.cfg.Scans.ListByUser(ctx, userID, model.ScanFilter{Limit: limit})
+	if err != nil {
+		return nil, serviceError(ctx, "failed to fetch scans", err)
+	}
+	resp := &pb.ListScansResponse{Scans: make([]*pb.Scan, 0, len(page.Scans))}
+	for i := range page.Scans {
+		scan, err := s.toPB(ctx, &page.Scans[i])
+		if err != nil {
+			return nil, serviceError(ctx, "failed to fetch scans", err)
+		}
+		resp.Scans = append(resp.Scans, scan)
+	}
+	return resp, nil
+}
+
+func (s *scanServer) CreateScan(ctx context.Context, req *pb.CreateScanRequest) (*pb.CreateScanResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetProductName()) == "" {
+		return nil, invalidArgument("product_name is required")
+	}
+	if req.GetSafetyScore() < 0 || req.GetSafetyScore() > 100 {
+		return nil, invalidArgument("safety_score must be between 0 and 100")
+	}
+	if req.GetImageId() != "" {
+		if err := s.checkImageID(ctx, req.GetImageId()); err != nil {
+			return nil, err
+		}
+	}
+
+	scanID := req.GetId()
+	if scanID == "" {
+		scanID = uuid.NewString()
+	}
+	created, err := s.cfg.Scans.Create(ctx, &model.Scan{
+		ID:          scanID,
+		UserID:      userID,
+		ProductName: req.GetProductName(),
+		Brand:       req.GetBrand(),
+		Image:       req.GetImage(),
+		ImageID:     req.GetImageId(),
+		SafetyScore: int(req.GetSafetyScore()),
+		IsSafe:      req.GetIsSafe(),
+		Ingredients: fromPBIngredients(req.GetIngredients()),
+		Sources:     fromPBSources(req.GetSources()),
+		Confidence:  fromPBConfidence(req.GetConfidence()),
+	})
+	if err != nil {
+		return nil, serviceError(ctx, "failed to create scan", err)
+	}
+	if s.cfg.Events != nil {
+		s.cfg.Events.Publish(ctx, userID, model.EventScanCreated, created)
+	}
+	scan, err := s.toPB(ctx, created)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to create scan", err)
+	}
+	return &pb.CreateScanResponse{Scan: scan}, nil
+}
+
+func (s *scanServer) GetStats(ctx context.Context, _ *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.cfg.Users.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, serviceError(ctx, "failed to fetch user", err)
+	}
+
+	stats, err := s.cfg.Scans.GetStats(ctx, userID)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to fetch stats", err)
+	}
+	return &pb.GetStatsResponse{
+		TotalScans:   int32(stats.TotalScans),
+		TodayScans:   int32(stats.TodayScans),
+		SafeToday:    int32(stats.SafeToday),
+		RiskyToday:   int32(stats.RiskyToday),
+		AverageScore: stats.AverageScore,
+	}, nil
+}
+
+// checkImageID makes sure a scan only links to an image that was actually
+// stored.
+func (s *scanServer) checkImageID(ctx context.Context, imageID string) error {
+	if s.cfg.Blobs == nil {
+		return status.Error(codes.FailedPrecondition, "image storage is not configured")
+	}
+	exists, err := s.cfg.Blobs.Exists(ctx, imageID)
+	if errors.Is(err, blob.ErrInvalidID) || (err == nil && !exists) {
+		return invalidArgument("image_id does not reference a stored image")
+	}
+	if err != nil {
+		return serviceError(ctx, "failed to look up image", err)
+	}
+	return nil
+}
+
+// toPB converts a scan, replacing the image of one that references a stored
+// image with a fresh signed URL.
+func (s *scanServer) toPB(ctx context.Context, scan *model.Scan) (*pb.Scan, error) {
+	if s.cfg.Blobs != nil && scan.ImageID != "" {
+		url, err := s.cfg.Blobs.SignedURL(ctx, scan.ImageID)
+		if err != nil {
+			log.Printf("sign scan image url failed scan=%s id=%s err=%v", scan.ID, scan.ImageID, err)
+		} else {
+			scan.Image = url
+		}
+	}
+	return toPBScan(scan)
+}
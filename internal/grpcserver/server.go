@@ -0,0 +1,144 @@
+// Package grpcserver serves the SafeBites API over gRPC for backend services,
+// on the same services and repositories as the REST router. The RPCs are
+// defined in api/safebites/v1/safebites.proto.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/agent"
+	"github.com/safebites/backend-go/internal/blob"
+	"github.com/safebites/backend-go/internal/imageprep"
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+// MaxImageBytes caps label photos sent to AnalyzeImage, as the REST upload
+// limit does.
+const MaxImageBytes = 10 << 20
+
+type Config struct {
+	Users     service.UserService
+	Templates service.TemplateService
+	Scans     service.ScanService
+	Favorites repository.FavoriteRepository
+	Analyze   service.AnalyzeService
+	Recommend service.RecommendService
+	Localize  service.LocalizeService
+	Images    *imageprep.Preprocessor
+	// Blobs keeps analyzed uploads and signs scan image URLs; nil disables
+	// both.
+	Blobs blob.Store
+	// Events notifies webhook subscribers of saved scans and finished
+	// analyses; nil skips it.
+	Events service.EventPublisher
+	// DevModeAuth lets callers without a token act as middleware.DevUserID,
+	// as middleware.RequireAuth does.
+	DevModeAuth bool
+}
+
+// New builds a gRPC server with every SafeBites service registered, plus
+// server reflection for tools like grpcurl. opts are applied after the
+// server's own options.
+func New(cfg Config, opts ...grpc.ServerOption) *grpc.Server {
+	auth := &authenticator{devModeAuth: cfg.DevModeAuth}
+	opts = append([]grpc.ServerOption{
+		// Leave room for the rest of the message around the largest image.
+		grpc.MaxRecvMsgSize(MaxImageBytes + 1<<20),
+		grpc.ChainUnaryInterceptor(logUnary, auth.unary),
+		grpc.ChainStreamInterceptor(logStream, auth.stream),
+	}, opts...)
+
+	srv := grpc.NewServer(opts...)
+	pb.RegisterUserServiceServer(srv, &userServer{cfg: cfg})
+	pb.RegisterScanServiceServer(srv, &scanServer{cfg: cfg})
+	pb.RegisterFavoriteServiceServer(srv, &favoriteServer{cfg: cfg})
+	pb.RegisterTemplateServiceServer(srv, &templateServer{cfg: cfg})
+	pb.RegisterAnalyzeServiceServer(srv, &analyzeServer{cfg: cfg})
+	pb.RegisterRecommendServiceServer(srv, &recommendServer{cfg: cfg})
+	reflection.Register(srv)
+	return srv
+}
+
+// logUnary logs each call like middleware.Logging logs requests, and turns a
+// panic into an INTERNAL status instead of a crashed process.
+func logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	start := time.Now()
+	defer func() {
+		err = recoverPanic(info.FullMethod, recover(), err)
+		log.Printf("grpc %s %s %s", status.Code(err), info.FullMethod, time.Since(start).Round(time.Millisecond))
+	}()
+	return handler(ctx, req)
+}
+
+func logStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	defer func() {
+		err = recoverPanic(info.FullMethod, recover(), err)
+		log.Printf("grpc %s %s %s", status.Code(err), info.FullMethod, time.Since(start).Round(time.Millisecond))
+	}()
+	return handler(srv, ss)
+}
+
+func recoverPanic(method string, recovered any, err error) error {
+	if recovered == nil {
+		return err
+	}
+	log.Printf("grpc panic method=%s panic=%v\n%s", method, recovered, debug.Stack())
+	return status.Error(codes.Internal, "internal error")
+}
+
+// callerPreferences loads the stored preferences of the authenticated caller,
+// or nil when the call is anonymous or the caller has no profile yet.
+func callerPreferences(ctx context.Context, users service.UserService) (*model.UserPreferences, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok || users == nil {
+		return nil, nil
+	}
+	user, err := users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &model.UserPreferences{
+		Allergies:        user.Allergies,
+		DietGoals:        user.DietGoals,
+		AvoidIngredients: user.AvoidIngredients,
+		HomeRegion:       user.HomeRegion,
+		Version:          user.PreferenceVersion,
+	}, nil
+}
+
+// agentContext scopes the agent runs of a call to the caller, or the
+// anonymous user, and to scanID when the runs belong to a scan.
+func agentContext(ctx context.Context, scanID string) context.Context {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		userID = agent.AnonymousUserID
+	}
+	return agent.WithSessionScope(ctx, agent.SessionScope{UserID: userID, ScanID: scanID})
+}
+
+// callerID returns the user an authenticated RPC acts for. The auth
+// interceptor has already rejected unauthenticated calls to these RPCs.
+func callerID(ctx context.Context) (string, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, middleware.ErrMissingToken.Error())
+	}
+	return userID, nil
+}
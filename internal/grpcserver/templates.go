@@ -0,0 +1,59 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+type templateServer struct {
+	pb.UnimplementedTemplateServiceServer
+	cfg Config
+}
+
+// ListTemplates returns the built-in templates, followed by the caller's own
+// when they are signed in.
+func (s *templateServer) ListTemplates(ctx context.Context, _ *pb.ListTemplatesRequest) (*pb.ListTemplatesResponse, error) {
+	if s.cfg.Templates == nil {
+		return nil, status.Error(codes.Unimplemented, "template service is not configured")
+	}
+	userID, _ := middleware.UserIDFromContext(ctx)
+	templates, err := s.cfg.Templates.List(ctx, userID)
+	if err != nil {
+		return nil, serviceError(ctx, "failed to fetch templates", err)
+	}
+	resp := &pb.ListTemplatesResponse{Templates: make([]*pb.DietaryTemplate, 0, len(templates))}
+	for _, tpl := range templates {
+		resp.Templates = append(resp.Templates, toPBTemplate(tpl))
+	}
+	return resp, nil
+}
+
+func (s *templateServer) ApplyTemplate(ctx context.Context, req *pb.ApplyTemplateRequest) (*pb.ApplyTemplateResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, template, err := s.cfg.Users.ApplyTemplate(ctx, userID, req.GetTemplateKey())
+	if err != nil {
+		// ErrTemplateNotFound matches ErrNotFound too, so it goes first.
+		if errors.Is(err, service.ErrTemplateNotFound) {
+			return nil, status.Error(codes.NotFound, "template not found")
+		}
+		if errors.Is(err, service.ErrTooManyLayers) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, serviceError(ctx, "failed to apply template", err)
+	}
+	return &pb.ApplyTemplateResponse{User: toPBUser(user), Template: toPBTemplate(template)}, nil
+}
@@ -0,0 +1,122 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/safebites/backend-go/api/safebites/v1"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	cfg Config
+}
+
+func (s *userServer) GetMe(ctx context.Context, _ *pb.GetMeRequest) (*pb.GetMeResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.cfg.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, serviceError(ctx, "failed to fetch user", err)
+	}
+	return &pb.GetMeResponse{User: toPBUser(user)}, nil
+}
+
+func (s *userServer) UpsertMe(ctx context.Context, req *pb.UpsertMeRequest) (*pb.UpsertMeResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetEmail()) == "" {
+		return nil, invalidArgument("email is required")
+	}
+	if _, err := mail.ParseAddress(req.GetEmail()); err != nil {
+		return nil, invalidArgument("email is invalid")
+	}
+	prefs := fromPBPreferences(req.GetPreferences())
+	if prefs.HomeRegion, err = homeRegion(prefs.HomeRegion); err != nil {
+		return nil, err
+	}
+
+	user, err := s.cfg.Users.Upsert(ctx, &model.User{
+		ID:               userID,
+		Email:            req.GetEmail(),
+		Name:             req.GetName(),
+		Picture:          req.GetPicture(),
+		Allergies:        prefs.Allergies,
+		DietGoals:        prefs.DietGoals,
+		AvoidIngredients: prefs.AvoidIngredients,
+		HomeRegion:       prefs.HomeRegion,
+	})
+	if err != nil {
+		return nil, serviceError(ctx, "failed to upsert user", err)
+	}
+	return &pb.UpsertMeResponse{User: toPBUser(user)}, nil
+}
+
+func (s *userServer) UpdatePreferences(ctx context.Context, req *pb.UpdatePreferencesRequest) (*pb.UpdatePreferencesResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefs := fromPBPreferences(req.GetPreferences())
+	if prefs.HomeRegion, err = homeRegion(prefs.HomeRegion); err != nil {
+		return nil, err
+	}
+	current, err := s.cfg.Users.GetByID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, serviceError(ctx, "failed to update preferences", err)
+	}
+	if current != nil {
+		prefs.Allergies = keepSeverities(prefs.Allergies, current.Custom.Allergies)
+	}
+
+	user, err := s.cfg.Users.UpdatePreferences(ctx, userID, prefs)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, serviceError(ctx, "failed to update preferences", err)
+	}
+	return &pb.UpdatePreferencesResponse{User: toPBUser(user)}, nil
+}
+
+// keepSeverities carries the severity and trace sensitivity the user set for
+// an allergy over to the same allergy sent again by name.
+func keepSeverities(allergies, current []model.Allergy) []model.Allergy {
+	for i, a := range allergies {
+		for _, c := range current {
+			if model.SameAllergen(a, c) {
+				allergies[i].Severity = c.Severity
+				allergies[i].MayContain = c.MayContain
+				break
+			}
+		}
+	}
+	return allergies
+}
+
+// homeRegion normalizes an optional home region, rejecting regions outside
+// model.Regions.
+func homeRegion(region string) (string, error) {
+	if strings.TrimSpace(region) == "" {
+		return "", nil
+	}
+	normalized, ok := model.NormalizeRegion(region)
+	if !ok {
+		return "", invalidArgument("home_region must be one of " + strings.Join(model.Regions, ", "))
+	}
+	return normalized, nil
+}
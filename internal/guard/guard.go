@@ -0,0 +1,226 @@
+// Package guard screens untrusted text before it is placed into an agent
+// prompt. It normalizes the text, enforces length and character limits, and
+// detects instruction-like content such as "ignore previous instructions".
+package guard
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/safebites/backend-go/internal/observability"
+)
+
+// ErrorCode is the machine-readable code returned to clients whose input was
+// rejected.
+const ErrorCode = "input_rejected"
+
+// MaxProductNameLength is the longest product name, in characters, that is
+// passed on to the agents.
+const MaxProductNameLength = 120
+
+// MaxQuestionLength is the longest follow-up chat question, in characters.
+const MaxQuestionLength = 500
+
+type Verdict string
+
+const (
+	VerdictClean     Verdict = "clean"
+	VerdictSanitized Verdict = "sanitized"
+	VerdictRejected  Verdict = "rejected"
+)
+
+// Reasons recorded on the guard span and returned in RejectedError.
+const (
+	ReasonControlCharacters    = "control_characters"
+	ReasonDisallowedCharacters = "disallowed_characters"
+	ReasonInstructionLike      = "instruction_like"
+	ReasonTruncated            = "truncated"
+	ReasonTooLong              = "too_long"
+	ReasonEmpty                = "empty"
+)
+
+// Source says where the text came from, which decides how strict the guard is.
+type Source string
+
+const (
+	// SourceOCR is text read off a label image. Instruction-like text is cut
+	// off and over-long text truncated, because the rest of the label is
+	// usually still a usable product name.
+	SourceOCR Source = "ocr"
+	// SourceParam is a product name typed by the client, e.g. a path
+	// parameter. Instruction-like or over-long input is rejected outright.
+	SourceParam Source = "param"
+	// SourceChat is a follow-up question about a scan. It is rejected like
+	// SourceParam, but only for injection attempts: asking how a product
+	// was scored is the point of the chat.
+	SourceChat Source = "chat"
+)
+
+type Result struct {
+	Value   string
+	Verdict Verdict
+	Reasons []string
+}
+
+// RejectedError reports input the guard refused to pass on.
+type RejectedError struct {
+	Source  Source
+	Reasons []string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s input rejected: %s", e.Source, strings.Join(e.Reasons, ", "))
+}
+
+// injectionPatterns catch attempts to take over any prompt.
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override|bypass)\b.{0,30}\b(instructions?|prompts?|rules|messages?|context|above|previous)\b`),
+	regexp.MustCompile(`(?i)\b(system|developer|assistant)\s*(prompt|message|instructions?)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|real)\s+instructions?\b`),
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\b|\bact\s+as\b|\bpretend\s+(to\s+be|you)\b|\bjailbreak\b`),
+	regexp.MustCompile(`(?i)<\|?/?\s*(system|user|assistant|im_start|im_end)\b|\[/?inst\]|` + "```"),
+}
+
+// instructionPatterns add the phrases that only make sense as attempts to
+// steer the scoring prompts a product name is placed into.
+var instructionPatterns = append([]*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(respond|reply|answer|output)\s+(only\s+)?with\b`),
+	regexp.MustCompile(`(?i)\b(rate|score|mark)\s+(this|it|the\s+product|all\s+ingredients)\b`),
+	regexp.MustCompile(`(?i)\b(safety_score|overall_score|ingredient_scores|list_of_ingredients|recommendations)\b`),
+}, injectionPatterns...)
+
+// ProductName screens a product name before it reaches the search or
+// recommender prompt, recording the verdict on an input_guard span.
+func ProductName(ctx context.Context, source Source, input string) (Result, error) {
+	_, span := observability.StartAgentSpan(ctx, "input_guard")
+	defer span.End()
+
+	res, err := check(source, input)
+	span.SetGuard(string(source), string(res.Verdict), res.Reasons)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return res, err
+}
+
+// Question screens a follow-up chat question before it reaches the chat
+// agent, recording the verdict on an input_guard span.
+func Question(ctx context.Context, input string) (Result, error) {
+	_, span := observability.StartAgentSpan(ctx, "input_guard")
+	defer span.End()
+
+	res, err := check(SourceChat, input)
+	span.SetGuard(string(SourceChat), string(res.Verdict), res.Reasons)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return res, err
+}
+
+func check(source Source, input string) (Result, error) {
+	var reasons []string
+	reject := func(reason string) (Result, error) {
+		reasons = append(reasons, reason)
+		return Result{Verdict: VerdictRejected, Reasons: reasons}, &RejectedError{Source: source, Reasons: reasons}
+	}
+
+	text, changed := stripControl(norm.NFKC.String(input))
+	if changed {
+		reasons = append(reasons, ReasonControlCharacters)
+	}
+
+	patterns, maxLength := instructionPatterns, MaxProductNameLength
+	if source == SourceChat {
+		patterns, maxLength = injectionPatterns, MaxQuestionLength
+	}
+
+	if loc := firstInstruction(patterns, text); loc >= 0 {
+		if source != SourceOCR {
+			return reject(ReasonInstructionLike)
+		}
+		text = strings.TrimRight(text[:loc], " .,;:-/")
+		reasons = append(reasons, ReasonInstructionLike)
+	}
+
+	text, changed = stripDisallowed(text)
+	if changed {
+		reasons = append(reasons, ReasonDisallowedCharacters)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if runes := []rune(text); len(runes) > maxLength {
+		if source != SourceOCR {
+			return reject(ReasonTooLong)
+		}
+		text = truncateWords(runes, maxLength)
+		reasons = append(reasons, ReasonTruncated)
+	}
+	if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return reject(ReasonEmpty)
+	}
+
+	verdict := VerdictClean
+	if len(reasons) > 0 {
+		verdict = VerdictSanitized
+	}
+	return Result{Value: text, Verdict: verdict, Reasons: reasons}, nil
+}
+
+func firstInstruction(patterns []*regexp.Regexp, text string) int {
+	first := -1
+	for _, p := range patterns {
+		if loc := p.FindStringIndex(text); loc != nil && (first < 0 || loc[0] < first) {
+			first = loc[0]
+		}
+	}
+	return first
+}
+
+// stripControl replaces line breaks and tabs with spaces and drops other
+// control and format characters, such as zero-width spaces used to hide text.
+func stripControl(text string) (string, bool) {
+	changed := false
+	out := strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			changed = true
+			return -1
+		default:
+			return r
+		}
+	}, text)
+	return out, changed
+}
+
+// stripDisallowed keeps letters, marks, digits, spaces, and the punctuation
+// that shows up in real product names.
+func stripDisallowed(text string) (string, bool) {
+	changed := false
+	out := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == ' ':
+			return r
+		case strings.ContainsRune("&'’-.,()/+%!?:®™°", r):
+			return r
+		default:
+			changed = true
+			return ' '
+		}
+	}, text)
+	return out, changed
+}
+
+func truncateWords(runes []rune, limit int) string {
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,:-/")
+}
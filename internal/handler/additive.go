@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/additive"
+)
+
+type AdditiveHandler struct {
+	Additives *additive.Catalog
+}
+
+// Get looks up an additive by E-number, INS number, or name.
+func (h *AdditiveHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.Additives == nil {
+		writeError(w, http.StatusInternalServerError, "additive catalog is not configured")
+		return
+	}
+
+	query := strings.TrimSpace(chi.URLParam(r, "code"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "missing code")
+		return
+	}
+
+	found, ok := h.Additives.Lookup(query)
+	if !ok {
+		writeError(w, http.StatusNotFound, "additive not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"additive": found,
+	})
+}
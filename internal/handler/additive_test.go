@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/additive"
+	"github.com/stretchr/testify/require"
+)
+
+func makeAdditiveRequest(code string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/additives/"+url.PathEscape(code), nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("code", code)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdditiveHandlerGet(t *testing.T) {
+	h := &AdditiveHandler{Additives: additive.Default()}
+
+	rr := httptest.NewRecorder()
+	h.Get(rr, makeAdditiveRequest("INS 621"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"code":"E621"`)
+	require.Contains(t, rr.Body.String(), `"name":"Monosodium Glutamate"`)
+	require.Contains(t, rr.Body.String(), `"function_class":"flavour enhancer"`)
+}
+
+func TestAdditiveHandlerGetNotFound(t *testing.T) {
+	h := &AdditiveHandler{Additives: additive.Default()}
+
+	rr := httptest.NewRecorder()
+	h.Get(rr, makeAdditiveRequest("E9999"))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Contains(t, rr.Body.String(), "additive not found")
+}
+
+func TestAdditiveHandlerGetMissingCode(t *testing.T) {
+	h := &AdditiveHandler{Additives: additive.Default()}
+
+	rr := httptest.NewRecorder()
+	h.Get(rr, makeAdditiveRequest(" "))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
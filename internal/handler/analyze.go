@@ -1,21 +1,35 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/safebites/backend-go/internal/blob"
+	"github.com/safebites/backend-go/internal/imageprep"
 	"github.com/safebites/backend-go/internal/middleware"
 	"github.com/safebites/backend-go/internal/model"
-	"github.com/safebites/backend-go/internal/repository"
 	"github.com/safebites/backend-go/internal/service"
 )
 
 const maxAnalyzeImageBytes = 10 << 20
 
 type AnalyzeHandler struct {
-	Analyze service.AnalyzeService
-	Users   service.UserService
+	Analyze  service.AnalyzeService
+	Users    service.UserService
+	Localize service.LocalizeService
+	Images   *imageprep.Preprocessor
+	// Blobs keeps the processed upload so scans can link to it; nil skips it.
+	Blobs blob.Store
+	// Events notifies the signed-in user's webhook subscribers of finished
+	// analyses; nil skips it.
+	Events service.EventPublisher
+	// Profiles judges the product for the household profiles picked with the
+	// profiles query parameter.
+	Profiles service.ProfileService
 }
 
 func (h *AnalyzeHandler) AnalyzeImage(w http.ResponseWriter, r *http.Request) {
@@ -55,32 +69,174 @@ func (h *AnalyzeHandler) AnalyzeImage(w http.ResponseWriter, r *http.Request) {
 		mimeType = http.DetectContentType(imageBytes)
 	}
 
-	var prefs *model.UserPreferences
-	if userID, ok := middleware.UserIDFromContext(r.Context()); ok && h.Users != nil {
-		user, userErr := h.Users.GetByID(r.Context(), userID)
-		if userErr != nil {
-			if userErr != repository.ErrNotFound {
-				writeInternalError(w, r, "failed to fetch user preferences", userErr)
-				return
-			}
-		} else {
-			prefs = &model.UserPreferences{
-				Allergies:        user.Allergies,
-				DietGoals:        user.DietGoals,
-				AvoidIngredients: user.AvoidIngredients,
-			}
+	imageBytes, mimeType, ok := preprocessImage(w, r, h.Images, imageBytes, mimeType)
+	if !ok {
+		return
+	}
+
+	prefs, err := requestPreferences(r, h.Users)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch user preferences", err)
+		return
+	}
+	profiles, ok := requestProfiles(w, r, h.Profiles)
+	if !ok {
+		return
+	}
+
+	// The scan ID is minted here so the agent sessions of this analysis can
+	// be found again once the client saves the scan under it.
+	scanID := uuid.NewString()
+	ctx := agentContext(r, scanID)
+	result, err := h.Analyze.Analyze(ctx, imageBytes, mimeType, prefs)
+	if err != nil {
+		if writeRejectedInput(w, err) {
+			return
 		}
+		writeInternalError(w, r, "failed to analyze product", err)
+		return
+	}
+
+	imageID, imageURL := storeUpload(r.Context(), h.Blobs, imageBytes, mimeType)
+	h.respond(w, r, scanID, result, imageID, imageURL, prefs, profiles)
+}
+
+type analyzeProductRequest struct {
+	ProductName string `json:"productName"`
+}
+
+// AnalyzeProduct analyzes a product the client names, skipping label OCR. The
+// response has the same shape as AnalyzeImage without the image fields.
+func (h *AnalyzeHandler) AnalyzeProduct(w http.ResponseWriter, r *http.Request) {
+	if h.Analyze == nil {
+		writeError(w, http.StatusInternalServerError, "analyze service is not configured")
+		return
+	}
+
+	var req analyzeProductRequest
+	if ok := readJSON(w, r, &req); !ok {
+		return
+	}
+	if strings.TrimSpace(req.ProductName) == "" {
+		writeError(w, http.StatusBadRequest, "productName is required")
+		return
 	}
 
-	productName, scorerResult, err := h.Analyze.Analyze(r.Context(), imageBytes, mimeType, prefs)
+	prefs, err := requestPreferences(r, h.Users)
 	if err != nil {
+		writeInternalError(w, r, "failed to fetch user preferences", err)
+		return
+	}
+	profiles, ok := requestProfiles(w, r, h.Profiles)
+	if !ok {
+		return
+	}
+
+	scanID := uuid.NewString()
+	result, err := h.Analyze.AnalyzeProduct(agentContext(r, scanID), req.ProductName, prefs)
+	if err != nil {
+		if writeRejectedInput(w, err) {
+			return
+		}
 		writeInternalError(w, r, "failed to analyze product", err)
 		return
 	}
+	h.respond(w, r, scanID, result, "", "", prefs, profiles)
+}
+
+type analyzeIngredientsRequest struct {
+	ProductName    string `json:"productName"`
+	IngredientText string `json:"ingredientText"`
+}
+
+// AnalyzeIngredients scores ingredient text pasted from a label, skipping
+// OCR and the web search. The response has the same shape as AnalyzeImage
+// without the image fields.
+func (h *AnalyzeHandler) AnalyzeIngredients(w http.ResponseWriter, r *http.Request) {
+	if h.Analyze == nil {
+		writeError(w, http.StatusInternalServerError, "analyze service is not configured")
+		return
+	}
+
+	var req analyzeIngredientsRequest
+	if ok := readJSON(w, r, &req); !ok {
+		return
+	}
+	if strings.TrimSpace(req.IngredientText) == "" {
+		writeError(w, http.StatusBadRequest, "ingredientText is required")
+		return
+	}
+	if len(req.IngredientText) > service.MaxIngredientTextLength {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("ingredientText must be at most %d bytes", service.MaxIngredientTextLength))
+		return
+	}
+
+	prefs, err := requestPreferences(r, h.Users)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch user preferences", err)
+		return
+	}
+	profiles, ok := requestProfiles(w, r, h.Profiles)
+	if !ok {
+		return
+	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	scanID := uuid.NewString()
+	result, err := h.Analyze.AnalyzeIngredients(agentContext(r, scanID), req.ProductName, req.IngredientText, prefs)
+	if err != nil {
+		if writeRejectedInput(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrNoIngredients):
+			writeError(w, http.StatusBadRequest, "ingredientText does not list any ingredients")
+			return
+		case errors.Is(err, service.ErrTooManyIngredients):
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("ingredientText lists more than %d ingredients", service.MaxScoredIngredients))
+			return
+		}
+		writeInternalError(w, r, "failed to analyze ingredients", err)
+		return
+	}
+	h.respond(w, r, scanID, result, "", "", prefs, profiles)
+}
+
+// respond judges result for the selected profiles, localizes it, tells
+// webhook subscribers about it, and writes the analyze response. imageID and
+// imageURL are left out when empty, and profiles when none were requested.
+// preference_version names the caller's preferences the result was scored
+// against, for the client to send back when it saves the scan.
+func (h *AnalyzeHandler) respond(w http.ResponseWriter, r *http.Request, scanID string, result *model.AnalysisResult, imageID, imageURL string, prefs *model.UserPreferences, profiles []model.Profile) {
+	ctx := agentContext(r, scanID)
+	var verdicts []model.ProfileVerdict
+	if profiles != nil {
+		verdicts = h.Profiles.Evaluate(ctx, profiles, homeRegion(prefs), result.Ingredients, result.IngredientBreakdown)
+	}
+	result = localizeAnalysis(ctx, h.Localize, result, requestLanguage(r))
+	if userID, ok := middleware.UserIDFromContext(r.Context()); ok && h.Events != nil {
+		h.Events.Publish(r.Context(), userID, model.EventAnalysisCompleted, model.NewAnalysisCompleted(scanID, result, imageID))
+	}
+
+	w.Header().Set("Content-Language", result.Language)
+	response := map[string]interface{}{
 		"status":               "success",
-		"product_name":         productName,
-		"ingredient_breakdown": scorerResult,
-	})
+		"scan_id":              scanID,
+		"product_name":         result.ProductName,
+		"detected_language":    result.DetectedLanguage,
+		"language":             result.Language,
+		"ingredient_breakdown": result.IngredientBreakdown,
+		"sources":              result.Sources,
+		"confidence":           result.Confidence,
+	}
+	if imageID != "" {
+		response["image_id"] = imageID
+		response["image_url"] = imageURL
+	}
+	if verdicts != nil {
+		response["profiles"] = verdicts
+	}
+	if prefs != nil && prefs.Version > 0 {
+		response["preference_version"] = prefs.Version
+	}
+	writeJSON(w, http.StatusOK, response)
 }
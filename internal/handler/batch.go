@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+type BatchHandler struct {
+	Batch service.BatchService
+	Users service.UserService
+}
+
+type batchRequest struct {
+	ProductNames []string `json:"productNames"`
+}
+
+// AnalyzeBatch scores a list of product names against the caller's
+// preferences. Items that fail carry their own error; the request itself
+// only fails on bad input.
+func (h *BatchHandler) AnalyzeBatch(w http.ResponseWriter, r *http.Request) {
+	if h.Batch == nil {
+		writeError(w, http.StatusInternalServerError, "batch service is not configured")
+		return
+	}
+
+	var req batchRequest
+	if ok := readJSON(w, r, &req); !ok {
+		return
+	}
+	if len(req.ProductNames) == 0 || len(req.ProductNames) > model.MaxBatchProducts {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("between 1 and %d productNames are required", model.MaxBatchProducts))
+		return
+	}
+	names := make([]string, 0, len(req.ProductNames))
+	for _, name := range req.ProductNames {
+		if strings.TrimSpace(name) == "" {
+			writeError(w, http.StatusBadRequest, "productNames must not contain empty values")
+			return
+		}
+		names = append(names, strings.TrimSpace(name))
+	}
+
+	prefs, err := requestPreferences(r, h.Users)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch user preferences", err)
+		return
+	}
+
+	result, err := h.Batch.AnalyzeBatch(agentContext(r, ""), names, prefs)
+	if err != nil {
+		writeInternalError(w, r, "failed to analyze products", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    "success",
+		"items":     result.Items,
+		"succeeded": result.Succeeded,
+		"failed":    result.Failed,
+	})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBatchService struct {
+	analyzeBatch func(ctx context.Context, productNames []string, prefs *model.UserPreferences) (*model.BatchResult, error)
+}
+
+func (m *mockBatchService) AnalyzeBatch(ctx context.Context, productNames []string, prefs *model.UserPreferences) (*model.BatchResult, error) {
+	return m.analyzeBatch(ctx, productNames, prefs)
+}
+
+func batchRequestBody(t *testing.T, body interface{}) *bytes.Reader {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	return bytes.NewReader(raw)
+}
+
+func TestBatchHandlerAnalyzeBatchAppliesPreferences(t *testing.T) {
+	prefs := model.UserPreferences{Allergies: model.Allergies("peanuts")}
+	h := &BatchHandler{
+		Users: &mockAnalyzeUserService{getByID: func(_ context.Context, userID string) (*model.User, error) {
+			require.Equal(t, "auth0|abc", userID)
+			return &model.User{ID: userID, Allergies: prefs.Allergies}, nil
+		}},
+		Batch: &mockBatchService{analyzeBatch: func(_ context.Context, names []string, got *model.UserPreferences) (*model.BatchResult, error) {
+			require.Equal(t, []string{"Oat Milk", "Peanut Bar"}, names)
+			require.Equal(t, &prefs, got)
+			return &model.BatchResult{
+				Items: []model.BatchItem{
+					{ProductName: "Oat Milk", Result: &model.AnalysisResult{ProductName: "Oat Milk"}},
+					{ProductName: "Peanut Bar", Error: "failed to analyze product"},
+				},
+				Succeeded: 1,
+				Failed:    1,
+			}, nil
+		}},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze/batch", batchRequestBody(t, map[string]interface{}{
+		"productNames": []string{" Oat Milk ", "Peanut Bar"},
+	}))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "auth0|abc"))
+	rr := httptest.NewRecorder()
+	h.AnalyzeBatch(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Status    string            `json:"status"`
+		Items     []model.BatchItem `json:"items"`
+		Succeeded int               `json:"succeeded"`
+		Failed    int               `json:"failed"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "success", resp.Status)
+	require.Len(t, resp.Items, 2)
+	require.Equal(t, "failed to analyze product", resp.Items[1].Error)
+	require.Equal(t, 1, resp.Succeeded)
+	require.Equal(t, 1, resp.Failed)
+}
+
+func TestBatchHandlerAnalyzeBatchValidation(t *testing.T) {
+	h := &BatchHandler{Batch: &mockBatchService{analyzeBatch: func(context.Context, []string, *model.UserPreferences) (*model.BatchResult, error) {
+		t.Fatal("batch should not run for invalid input")
+		return nil, nil
+	}}}
+
+	tooMany := make([]string, model.MaxBatchProducts+1)
+	for i := range tooMany {
+		tooMany[i] = "Oat Milk"
+	}
+	cases := map[string]interface{}{
+		"empty list":     map[string]interface{}{"productNames": []string{}},
+		"too many":       map[string]interface{}{"productNames": tooMany},
+		"blank name":     map[string]interface{}{"productNames": []string{"Oat Milk", "  "}},
+		"unknown fields": map[string]interface{}{"productNames": []string{"Oat Milk"}, "image": "x"},
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.AnalyzeBatch(rr, httptest.NewRequest(http.MethodPost, "/api/analyze/batch", batchRequestBody(t, body)))
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	h.AnalyzeBatch(rr, httptest.NewRequest(http.MethodPost, "/api/analyze/batch", strings.NewReader("{")))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
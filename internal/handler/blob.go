@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/blob"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+// ImageHandler serves images from the local blob store through the signed
+// URLs it hands out. S3-compatible stores sign URLs that point at the bucket
+// itself, so this handler is only mounted for the local store.
+type ImageHandler struct {
+	Store *blob.LocalStore
+}
+
+func (h *ImageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "image_id")
+	query := r.URL.Query()
+	if err := h.Store.Verify(id, query.Get("expires"), query.Get("signature")); err != nil {
+		switch {
+		case errors.Is(err, blob.ErrInvalidID):
+			writeError(w, http.StatusNotFound, "image not found")
+		case errors.Is(err, blob.ErrExpired):
+			writeError(w, http.StatusForbidden, "image url has expired")
+		default:
+			writeError(w, http.StatusForbidden, "invalid image url signature")
+		}
+		return
+	}
+
+	obj, err := h.Store.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "image not found")
+			return
+		}
+		writeInternalError(w, r, "failed to read image", err)
+		return
+	}
+
+	contentType := obj.ContentType
+	if contentType == "" {
+		contentType = http.DetectContentType(obj.Data)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.Data)))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(obj.Data)
+}
+
+// storeUpload persists an analyzed image and returns its ID and a signed URL.
+// Storage is best-effort: a failure is logged and the analysis is returned
+// without an image.
+func storeUpload(ctx context.Context, store blob.Store, imageBytes []byte, mimeType string) (string, string) {
+	if store == nil {
+		return "", ""
+	}
+	id := blob.ImageID(imageBytes)
+	if err := store.Put(ctx, id, imageBytes, mimeType); err != nil {
+		log.Printf("store uploaded image failed id=%s err=%v", id, err)
+		return "", ""
+	}
+	url, err := store.SignedURL(ctx, id)
+	if err != nil {
+		log.Printf("sign image url failed id=%s err=%v", id, err)
+		return id, ""
+	}
+	return id, url
+}
+
+// signScanImages replaces the image of each scan that references a stored
+// image with a fresh signed URL.
+func signScanImages(ctx context.Context, store blob.Store, scans ...*model.Scan) {
+	if store == nil {
+		return
+	}
+	for _, scan := range scans {
+		if scan.ImageID == "" {
+			continue
+		}
+		url, err := store.SignedURL(ctx, scan.ImageID)
+		if err != nil {
+			log.Printf("sign scan image url failed scan=%s id=%s err=%v", scan.ID, scan.ImageID, err)
+			continue
+		}
+		scan.Image = url
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/blob"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlobStore(t *testing.T) *blob.LocalStore {
+	t.Helper()
+	store, err := blob.NewLocal(blob.LocalConfig{Dir: t.TempDir(), BaseURL: "http://localhost:8080", Secret: []byte("test"), TTL: time.Minute})
+	require.NoError(t, err)
+	return store
+}
+
+func TestAnalyzeHandlerStoresUploadedImage(t *testing.T) {
+	store := newTestBlobStore(t)
+	h := &AnalyzeHandler{
+		Blobs: store,
+		Analyze: &mockAnalyzeService{
+			analyze: func(context.Context, []byte, string, *model.UserPreferences) (*model.AnalysisResult, error) {
+				return &model.AnalysisResult{ProductName: "Product A", IngredientBreakdown: &model.ScorerResult{}}, nil
+			},
+		},
+	}
+
+	data := []byte("fake jpeg bytes")
+	rr := httptest.NewRecorder()
+	h.AnalyzeImage(rr, makeAnalyzeImageRequest(t, "image/jpeg", data))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, blob.ImageID(data), resp["image_id"])
+	require.True(t, strings.HasPrefix(resp["image_url"].(string), "http://localhost:8080/api/images/"+blob.ImageID(data)+"?"))
+
+	obj, err := store.Get(context.Background(), blob.ImageID(data))
+	require.NoError(t, err)
+	require.Equal(t, data, obj.Data)
+	require.Equal(t, "image/jpeg", obj.ContentType)
+}
+
+func TestImageHandlerServesSignedURLs(t *testing.T) {
+	store := newTestBlobStore(t)
+	data := []byte("\x89PNG stored image")
+	id := blob.ImageID(data)
+	require.NoError(t, store.Put(context.Background(), id, data, "image/png"))
+	signed, err := store.SignedURL(context.Background(), id)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+
+	h := &ImageHandler{Store: store}
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("image_id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		h.Get(rr, req)
+		return rr
+	}
+
+	rr := get(u.RequestURI())
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+	require.Equal(t, data, rr.Body.Bytes())
+
+	rr = get("/api/images/" + id + "?expires=" + u.Query().Get("expires") + "&signature=bad")
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestScanHandlerCreateLinksStoredImage(t *testing.T) {
+	store := newTestBlobStore(t)
+	data := []byte("label photo")
+	id := blob.ImageID(data)
+	require.NoError(t, store.Put(context.Background(), id, data, "image/jpeg"))
+
+	h := &ScanHandler{Blobs: store, Users: &mockUserRepo{}, Scans: &mockScanRepo{
+		create: func(_ context.Context, scan *model.Scan) (*model.Scan, error) {
+			require.Equal(t, id, scan.ImageID)
+			return scan, nil
+		},
+		listByUser: func(context.Context, string, model.ScanFilter) (*model.ScanPage, error) {
+			return &model.ScanPage{Scans: []model.Scan{{ID: "scan-1", ImageID: id}, {ID: "scan-2", Image: "https://cdn.example.com/x.jpg"}}}, nil
+		},
+	}}
+
+	create := func(imageID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"productName": "Granola Bar", "safetyScore": 80, "imageId": imageID})
+		req := httptest.NewRequest(http.MethodPost, "/api/users/user-1/scans", bytes.NewBuffer(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("user_id", "user-1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		h.Create(rr, req)
+		return rr
+	}
+
+	rr := create(id)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var created struct {
+		Scan model.Scan `json:"scan"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	require.Equal(t, id, created.Scan.ImageID)
+	require.Contains(t, created.Scan.Image, "/api/images/"+id+"?expires=")
+
+	require.Equal(t, http.StatusBadRequest, create(blob.ImageID([]byte("never uploaded"))).Code)
+	require.Equal(t, http.StatusBadRequest, create("../../etc/passwd").Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/user-1/scans", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user_id", "user-1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr = httptest.NewRecorder()
+	h.ListByUser(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var listed struct {
+		Scans []model.Scan `json:"scans"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	require.Contains(t, listed.Scans[0].Image, "/api/images/"+id)
+	require.Equal(t, "https://cdn.example.com/x.jpg", listed.Scans[1].Image)
+}
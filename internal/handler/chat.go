@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+type ChatHandler struct {
+	Chat  service.ChatService
+	Users service.UserService
+}
+
+type chatRequest struct {
+	Question string `json:"question"`
+}
+
+// Ask answers a follow-up question about a saved scan. Clients that accept
+// text/event-stream get the answer as server-sent events: "delta" events
+// with chunks of text, then one "done" event with the complete message.
+// Everyone else gets the complete message as JSON.
+func (h *ChatHandler) Ask(w http.ResponseWriter, r *http.Request) {
+	if h.Chat == nil {
+		writeError(w, http.StatusInternalServerError, "chat service is not configured")
+		return
+	}
+	userID, scanID, ok := chatParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req chatRequest
+	if ok := readJSON(w, r, &req); !ok {
+		return
+	}
+	if strings.TrimSpace(req.Question) == "" {
+		writeError(w, http.StatusBadRequest, "question is required")
+		return
+	}
+
+	prefs, err := userPreferences(r.Context(), h.Users, userID)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch user preferences", err)
+		return
+	}
+
+	var stream *sseWriter
+	var onDelta func(string) error
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		stream = &sseWriter{w: w}
+		onDelta = func(text string) error {
+			return stream.send("delta", map[string]string{"text": text})
+		}
+	}
+
+	ctx := agentContext(r, scanID)
+	reply, err := h.Chat.Ask(ctx, userID, scanID, req.Question, prefs, onDelta)
+	if err != nil {
+		// Once the stream has started the status line is gone; the failure
+		// can only be reported as an event.
+		if stream != nil && stream.started {
+			log.Printf("handler error method=%s path=%s stage=chat_stream err=%v", r.Method, r.URL.Path, err)
+			_ = stream.send("error", map[string]string{"error": "failed to answer question"})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "scan not found")
+			return
+		}
+		if writeRejectedInput(w, err) {
+			return
+		}
+		writeInternalError(w, r, "failed to answer question", err)
+		return
+	}
+
+	if stream != nil {
+		_ = stream.send("done", reply)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": reply,
+	})
+}
+
+// History returns the conversation about a scan, oldest message first.
+func (h *ChatHandler) History(w http.ResponseWriter, r *http.Request) {
+	if h.Chat == nil {
+		writeError(w, http.StatusInternalServerError, "chat service is not configured")
+		return
+	}
+	userID, scanID, ok := chatParams(w, r)
+	if !ok {
+		return
+	}
+
+	messages, err := h.Chat.History(r.Context(), userID, scanID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "scan not found")
+			return
+		}
+		writeInternalError(w, r, "failed to fetch chat history", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"messages": messages})
+}
+
+func chatParams(w http.ResponseWriter, r *http.Request) (userID, scanID string, ok bool) {
+	userID = chi.URLParam(r, "user_id")
+	if strings.TrimSpace(userID) == "" {
+		writeError(w, http.StatusBadRequest, "missing user_id")
+		return "", "", false
+	}
+	scanID = chi.URLParam(r, "scan_id")
+	if strings.TrimSpace(scanID) == "" {
+		writeError(w, http.StatusBadRequest, "missing scan_id")
+		return "", "", false
+	}
+	return userID, scanID, true
+}
+
+// sseWriter writes server-sent events, sending the headers with the first
+// event so that errors before it can still be answered with a status code.
+type sseWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (s *sseWriter) send(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", event, err)
+	}
+	if !s.started {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.Header().Set("X-Accel-Buffering", "no")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
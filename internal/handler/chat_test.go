@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/agent"
+	"github.com/safebites/backend-go/internal/guard"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type mockChatService struct {
+	ask     func(ctx context.Context, userID, scanID, question string, prefs *model.UserPreferences, onDelta func(string) error) (*model.ChatMessage, error)
+	history func(ctx context.Context, userID, scanID string) ([]model.ChatMessage, error)
+}
+
+func (m *mockChatService) Ask(ctx context.Context, userID, scanID, question string, prefs *model.UserPreferences, onDelta func(string) error) (*model.ChatMessage, error) {
+	return m.ask(ctx, userID, scanID, question, prefs, onDelta)
+}
+
+func (m *mockChatService) History(ctx context.Context, userID, scanID string) ([]model.ChatMessage, error) {
+	return m.history(ctx, userID, scanID)
+}
+
+func makeChatRequest(method, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/users/user-1/scans/scan-1/chat", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user_id", "user-1")
+	rctx.URLParams.Add("scan_id", "scan-1")
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestChatHandlerAskJSON(t *testing.T) {
+	h := &ChatHandler{
+		Chat: &mockChatService{
+			ask: func(ctx context.Context, userID, scanID, question string, prefs *model.UserPreferences, onDelta func(string) error) (*model.ChatMessage, error) {
+				require.Equal(t, "user-1", userID)
+				require.Equal(t, "scan-1", scanID)
+				require.Equal(t, "Is it OK for my 2-year-old?", question)
+				require.Equal(t, model.Allergies("nuts"), prefs.Allergies)
+				require.Nil(t, onDelta)
+				scope, _ := agent.SessionScopeFromContext(ctx)
+				require.Equal(t, "scan-1", scope.ScanID)
+				return &model.ChatMessage{Role: model.ChatRoleAssistant, Text: "Not with a nut allergy."}, nil
+			},
+		},
+		Users: &mockAnalyzeUserService{
+			getByID: func(_ context.Context, userID string) (*model.User, error) {
+				require.Equal(t, "user-1", userID)
+				return &model.User{ID: userID, Allergies: model.Allergies("nuts")}, nil
+			},
+		},
+	}
+
+	rr := httptest.NewRecorder()
+	h.Ask(rr, makeChatRequest(http.MethodPost, `{"question":"Is it OK for my 2-year-old?"}`))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"text":"Not with a nut allergy."`)
+}
+
+func TestChatHandlerAskStreams(t *testing.T) {
+	h := &ChatHandler{Chat: &mockChatService{
+		ask: func(_ context.Context, _, _, _ string, _ *model.UserPreferences, onDelta func(string) error) (*model.ChatMessage, error) {
+			require.NoError(t, onDelta("Hazelnuts "))
+			require.NoError(t, onDelta("are the problem."))
+			return &model.ChatMessage{Role: model.ChatRoleAssistant, Text: "Hazelnuts are the problem."}, nil
+		},
+	}}
+
+	req := makeChatRequest(http.MethodPost, `{"question":"Which ingredient is the problem?"}`)
+	req.Header.Set("Accept", "text/event-stream")
+	rr := httptest.NewRecorder()
+	h.Ask(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	require.Equal(t, "event: delta\ndata: {\"text\":\"Hazelnuts \"}\n\n"+
+		"event: delta\ndata: {\"text\":\"are the problem.\"}\n\n"+
+		"event: done\ndata: {\"role\":\"assistant\",\"text\":\"Hazelnuts are the problem.\",\"timestamp\":\"0001-01-01T00:00:00Z\"}\n\n", rr.Body.String())
+}
+
+func TestChatHandlerAskStreamFailureIsAnEvent(t *testing.T) {
+	h := &ChatHandler{Chat: &mockChatService{
+		ask: func(_ context.Context, _, _, _ string, _ *model.UserPreferences, onDelta func(string) error) (*model.ChatMessage, error) {
+			require.NoError(t, onDelta("Partial"))
+			return nil, errors.New("model went away")
+		},
+	}}
+
+	req := makeChatRequest(http.MethodPost, `{"question":"Is it vegan?"}`)
+	req.Header.Set("Accept", "text/event-stream")
+	rr := httptest.NewRecorder()
+	h.Ask(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.True(t, strings.HasSuffix(rr.Body.String(), "event: error\ndata: {\"error\":\"failed to answer question\"}\n\n"))
+}
+
+func TestChatHandlerAskErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body   string
+		err    error
+		status int
+	}{
+		"missing question": {body: `{"question":"  "}`, status: http.StatusBadRequest},
+		"unknown scan":     {body: `{"question":"Is it vegan?"}`, err: repository.ErrNotFound, status: http.StatusNotFound},
+		"rejected":         {body: `{"question":"Is it vegan?"}`, err: &guard.RejectedError{Source: guard.SourceChat}, status: http.StatusUnprocessableEntity},
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := &ChatHandler{Chat: &mockChatService{
+				ask: func(context.Context, string, string, string, *model.UserPreferences, func(string) error) (*model.ChatMessage, error) {
+					return nil, tc.err
+				},
+			}}
+
+			req := makeChatRequest(http.MethodPost, tc.body)
+			req.Header.Set("Accept", "text/event-stream")
+			rr := httptest.NewRecorder()
+			h.Ask(rr, req)
+			require.Equal(t, tc.status, rr.Code)
+			require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestChatHandlerHistory(t *testing.T) {
+	h := &ChatHandler{Chat: &mockChatService{
+		history: func(_ context.Context, userID, scanID string) ([]model.ChatMessage, error) {
+			require.Equal(t, "user-1", userID)
+			require.Equal(t, "scan-1", scanID)
+			return []model.ChatMessage{{Role: model.ChatRoleUser, Text: "Is it vegan?"}}, nil
+		},
+	}}
+
+	rr := httptest.NewRecorder()
+	h.History(rr, makeChatRequest(http.MethodGet, ""))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"messages":[{"role":"user","text":"Is it vegan?"`)
+}
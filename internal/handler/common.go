@@ -8,6 +8,8 @@ import (
 	"mime"
 	"net/http"
 	"strings"
+
+	"github.com/safebites/backend-go/internal/middleware"
 )
 
 const maxJSONBodyBytes int64 = 10 << 20
@@ -23,6 +25,13 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
 
+// writeErrorCode is writeError with a machine-readable code alongside the
+// message, for errors clients are expected to branch on.
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	log.Printf("handler client error status=%d code=%s message=%q", status, code, message)
+	writeJSON(w, status, map[string]string{"error": message, "code": code})
+}
+
 func writeRequestError(w http.ResponseWriter, r *http.Request, status int, message string) {
 	log.Printf(
 		"handler client error method=%s path=%s status=%d content_length=%d message=%q",
@@ -84,3 +93,14 @@ func readJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
 
 	return true
 }
+
+// callerID returns the signed-in user, writing a 401 response when there is
+// none.
+func callerID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "missing authenticated user")
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/safebites/backend-go/internal/imageprep"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+// maxCompareFormBytes leaves room for the maximum number of full-size images.
+const maxCompareFormBytes = model.MaxCompareProducts * maxAnalyzeImageBytes
+
+// maxCompareNameBytes caps one productNames field of a multipart form.
+const maxCompareNameBytes = 1 << 10
+
+type CompareHandler struct {
+	Compare  service.CompareService
+	Users    service.UserService
+	Images   *imageprep.Preprocessor
+	Localize service.LocalizeService
+}
+
+type compareRequest struct {
+	ProductNames []string `json:"productNames"`
+}
+
+// CompareProducts accepts either a JSON body of product names or a multipart
+// form with repeated "images" files and/or "productNames" fields. Products
+// are compared in the order they were sent.
+func (h *CompareHandler) CompareProducts(w http.ResponseWriter, r *http.Request) {
+	if h.Compare == nil {
+		writeError(w, http.StatusInternalServerError, "compare service is not configured")
+		return
+	}
+
+	var inputs []model.CompareInput
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		parsed, ok := readCompareMultipart(w, r, h.Images)
+		if !ok {
+			return
+		}
+		inputs = parsed
+	} else {
+		var req compareRequest
+		if ok := readJSON(w, r, &req); !ok {
+			return
+		}
+		for _, name := range req.ProductNames {
+			if strings.TrimSpace(name) == "" {
+				writeRequestError(w, r, http.StatusBadRequest, "productNames must not contain empty values")
+				return
+			}
+			inputs = append(inputs, model.CompareInput{ProductName: strings.TrimSpace(name)})
+		}
+	}
+
+	if len(inputs) < model.MinCompareProducts || len(inputs) > model.MaxCompareProducts {
+		writeRequestError(w, r, http.StatusBadRequest, fmt.Sprintf("between %d and %d products are required", model.MinCompareProducts, model.MaxCompareProducts))
+		return
+	}
+
+	prefs, err := requestPreferences(r, h.Users)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch user preferences", err)
+		return
+	}
+
+	ctx := agentContext(r, "")
+	result, err := h.Compare.Compare(ctx, inputs, prefs)
+	if err != nil {
+		if writeRejectedInput(w, err) {
+			return
+		}
+		writeInternalError(w, r, "failed to compare products", err)
+		return
+	}
+
+	result, lang := localizeComparison(ctx, h.Localize, result, requestLanguage(r))
+
+	w.Header().Set("Content-Language", lang)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     "success",
+		"language":   lang,
+		"comparison": result,
+	})
+}
+
+// readCompareMultipart reads the form part by part, so names and images keep
+// the order the client sent them in. Other fields are ignored.
+func readCompareMultipart(w http.ResponseWriter, r *http.Request, images *imageprep.Preprocessor) ([]model.CompareInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCompareFormBytes+(1<<20))
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, "invalid multipart form data")
+		return nil, false
+	}
+
+	var inputs []model.CompareInput
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeRequestError(w, r, http.StatusBadRequest, "invalid multipart form data")
+			return nil, false
+		}
+
+		var input model.CompareInput
+		switch part.FormName() {
+		case "productNames":
+			name, err := io.ReadAll(io.LimitReader(part, maxCompareNameBytes+1))
+			if err != nil {
+				writeRequestError(w, r, http.StatusBadRequest, "invalid multipart form data")
+				return nil, false
+			}
+			if len(name) > maxCompareNameBytes {
+				writeRequestError(w, r, http.StatusBadRequest, "productNames value too long")
+				return nil, false
+			}
+			if strings.TrimSpace(string(name)) == "" {
+				writeRequestError(w, r, http.StatusBadRequest, "productNames must not contain empty values")
+				return nil, false
+			}
+			input.ProductName = strings.TrimSpace(string(name))
+		case "images":
+			imageBytes, mimeType, ok := readUploadedImage(w, r, part)
+			if !ok {
+				return nil, false
+			}
+			imageBytes, mimeType, ok = preprocessImage(w, r, images, imageBytes, mimeType)
+			if !ok {
+				return nil, false
+			}
+			input.ImageBytes, input.MimeType = imageBytes, mimeType
+		default:
+			continue
+		}
+
+		if len(inputs) == model.MaxCompareProducts {
+			writeRequestError(w, r, http.StatusBadRequest, fmt.Sprintf("between %d and %d products are required", model.MinCompareProducts, model.MaxCompareProducts))
+			return nil, false
+		}
+		inputs = append(inputs, input)
+	}
+
+	return inputs, true
+}
+
+func readUploadedImage(w http.ResponseWriter, r *http.Request, part *multipart.Part) ([]byte, string, bool) {
+	imageBytes, err := io.ReadAll(io.LimitReader(part, maxAnalyzeImageBytes+1))
+	if err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, "invalid multipart form data")
+		return nil, "", false
+	}
+	if len(imageBytes) == 0 {
+		writeRequestError(w, r, http.StatusBadRequest, "image file must not be empty")
+		return nil, "", false
+	}
+	if len(imageBytes) > maxAnalyzeImageBytes {
+		writeRequestError(w, r, http.StatusRequestEntityTooLarge, "image file too large")
+		return nil, "", false
+	}
+
+	mimeType := strings.TrimSpace(part.Header.Get("Content-Type"))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(imageBytes)
+	}
+	return imageBytes, mimeType, true
+}
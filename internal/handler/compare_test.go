@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCompareService struct {
+	compare func(ctx context.Context, inputs []model.CompareInput, prefs *model.UserPreferences) (*model.ComparisonResult, error)
+}
+
+func (m *mockCompareService) Compare(ctx context.Context, inputs []model.CompareInput, prefs *model.UserPreferences) (*model.ComparisonResult, error) {
+	return m.compare(ctx, inputs, prefs)
+}
+
+func TestCompareHandlerCompareProductsJSON(t *testing.T) {
+	h := &CompareHandler{
+		Compare: &mockCompareService{
+			compare: func(_ context.Context, inputs []model.CompareInput, _ *model.UserPreferences) (*model.ComparisonResult, error) {
+				require.Len(t, inputs, 2)
+				require.Equal(t, "Oat Bar", inputs[0].ProductName)
+				return &model.ComparisonResult{Verdict: model.ComparisonVerdict{BestProduct: "Oat Bar"}}, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/compare", strings.NewReader(`{"productNames":["Oat Bar","Candy Bar"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	h.CompareProducts(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"best_product":"Oat Bar"`)
+}
+
+func TestCompareHandlerLocalizesResponse(t *testing.T) {
+	h := &CompareHandler{
+		Compare: &mockCompareService{
+			compare: func(context.Context, []model.CompareInput, *model.UserPreferences) (*model.ComparisonResult, error) {
+				return &model.ComparisonResult{Verdict: model.ComparisonVerdict{BestProduct: "Oat Bar", Summary: "Less sugar"}}, nil
+			},
+		},
+		Localize: &mockLocalizeService{
+			localizeComparison: func(_ context.Context, result *model.ComparisonResult, lang string) (*model.ComparisonResult, error) {
+				require.Equal(t, "es", lang)
+				out := *result
+				out.Verdict.Summary = "Menos azúcar"
+				return &out, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/compare", strings.NewReader(`{"productNames":["Oat Bar","Candy Bar"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "es-ES,es;q=0.9")
+	rr := httptest.NewRecorder()
+
+	h.CompareProducts(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "es", rr.Header().Get("Content-Language"))
+	require.Contains(t, rr.Body.String(), `"language":"es"`)
+	require.Contains(t, rr.Body.String(), `"summary":"Menos azúcar"`)
+}
+
+func TestCompareHandlerCompareProductsMultipart(t *testing.T) {
+	h := &CompareHandler{
+		Compare: &mockCompareService{
+			compare: func(_ context.Context, inputs []model.CompareInput, _ *model.UserPreferences) (*model.ComparisonResult, error) {
+				require.Len(t, inputs, 2)
+				require.Equal(t, "Oat Bar", inputs[0].ProductName)
+				require.Equal(t, []byte("fake-image-bytes"), inputs[1].ImageBytes)
+				return &model.ComparisonResult{}, nil
+			},
+		},
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("productNames", "Oat Bar"))
+	part, err := writer.CreateFormFile("images", "label.png")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("fake-image-bytes"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/compare", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	h.CompareProducts(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestCompareHandlerCompareProductsMultipartKeepsPartOrder(t *testing.T) {
+	h := &CompareHandler{
+		Compare: &mockCompareService{
+			compare: func(_ context.Context, inputs []model.CompareInput, _ *model.UserPreferences) (*model.ComparisonResult, error) {
+				require.Len(t, inputs, 3)
+				require.Equal(t, []byte("first-image"), inputs[0].ImageBytes)
+				require.Equal(t, "Oat Bar", inputs[1].ProductName)
+				require.Equal(t, []byte("second-image"), inputs[2].ImageBytes)
+				return &model.ComparisonResult{}, nil
+			},
+		},
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("images", "first.png")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("first-image"))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteField("productNames", "Oat Bar"))
+	require.NoError(t, writer.WriteField("note", "ignored"))
+	part, err = writer.CreateFormFile("images", "second.png")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("second-image"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/compare", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	h.CompareProducts(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestCompareHandlerCompareProductsTooFewProducts(t *testing.T) {
+	h := &CompareHandler{
+		Compare: &mockCompareService{
+			compare: func(_ context.Context, _ []model.CompareInput, _ *model.UserPreferences) (*model.ComparisonResult, error) {
+				t.Fatal("service should not be called")
+				return nil, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/compare", strings.NewReader(`{"productNames":["Oat Bar"]}`))
+	rr := httptest.NewRecorder()
+
+	h.CompareProducts(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "between 2 and 5 products are required")
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/safebites/backend-go/internal/guard"
+)
+
+// writeRejectedInput answers 422 with guard.ErrorCode when err, possibly
+// wrapped, is an input guard rejection, and reports whether it did.
+func writeRejectedInput(w http.ResponseWriter, err error) bool {
+	var rejected *guard.RejectedError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	message := "product name was rejected as unsafe input"
+	switch rejected.Source {
+	case guard.SourceOCR:
+		message = "label text was rejected as unsafe input"
+	case guard.SourceChat:
+		message = "question was rejected as unsafe input"
+	}
+	writeErrorCode(w, http.StatusUnprocessableEntity, guard.ErrorCode, message)
+	return true
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/safebites/backend-go/internal/imageprep"
+)
+
+// preprocessImage runs an upload through prep, answering 415 when the bytes
+// are not the image type they claim to be. A nil prep passes the upload
+// through unchanged.
+func preprocessImage(w http.ResponseWriter, r *http.Request, prep *imageprep.Preprocessor, imageBytes []byte, mimeType string) ([]byte, string, bool) {
+	if prep == nil {
+		return imageBytes, mimeType, true
+	}
+	img, err := prep.Process(r.Context(), imageBytes, mimeType)
+	if err != nil {
+		if errors.Is(err, imageprep.ErrInvalidImage) {
+			writeRequestError(w, r, http.StatusUnsupportedMediaType, err.Error())
+			return nil, "", false
+		}
+		writeInternalError(w, r, "failed to process image", err)
+		return nil, "", false
+	}
+	return img.Bytes, img.MimeType, true
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/imageprep"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func makeAnalyzeImageRequest(t *testing.T, contentType string, data []byte) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="image"; filename="label"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestAnalyzeHandlerPreprocessesImage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 32))))
+
+	h := &AnalyzeHandler{
+		Images: imageprep.New(imageprep.Config{MaxDimension: 16}),
+		Analyze: &mockAnalyzeService{
+			analyze: func(_ context.Context, imageBytes []byte, mimeType string, _ *model.UserPreferences) (*model.AnalysisResult, error) {
+				require.Equal(t, "image/png", mimeType)
+				cfg, err := png.DecodeConfig(bytes.NewReader(imageBytes))
+				require.NoError(t, err)
+				require.Equal(t, 16, cfg.Width)
+				return &model.AnalysisResult{ProductName: "Product A", IngredientBreakdown: &model.ScorerResult{}}, nil
+			},
+		},
+	}
+
+	rr := httptest.NewRecorder()
+	h.AnalyzeImage(rr, makeAnalyzeImageRequest(t, "image/png", buf.Bytes()))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestAnalyzeHandlerRejectsMismatchedImage(t *testing.T) {
+	h := &AnalyzeHandler{
+		Images: imageprep.New(imageprep.Config{}),
+		Analyze: &mockAnalyzeService{
+			analyze: func(_ context.Context, _ []byte, _ string, _ *model.UserPreferences) (*model.AnalysisResult, error) {
+				t.Fatal("service should not be called")
+				return nil, nil
+			},
+		},
+	}
+
+	rr := httptest.NewRecorder()
+	h.AnalyzeImage(rr, makeAnalyzeImageRequest(t, "image/jpeg", []byte("fake-image-bytes")))
+	require.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid image")
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+// requestLanguage picks the best supported response language from the
+// request's Accept-Language header, defaulting to English.
+func requestLanguage(r *http.Request) string {
+	return service.ResponseLanguage(r.Header.Get("Accept-Language"))
+}
+
+// localizeAnalysis translates result into lang. Translation failures are
+// logged and the English result is served instead of failing the request.
+func localizeAnalysis(ctx context.Context, localize service.LocalizeService, result *model.AnalysisResult, lang string) *model.AnalysisResult {
+	if localize == nil || lang == model.DefaultLanguage {
+		return result
+	}
+	localized, err := localize.LocalizeAnalysis(ctx, result, lang)
+	if err != nil {
+		log.Printf("localize analysis failed lang=%s err=%v", lang, err)
+		return result
+	}
+	return localized
+}
+
+// localizeRecommendations is the recommender counterpart of localizeAnalysis.
+// It also returns the language the result ended up in.
+func localizeRecommendations(ctx context.Context, localize service.LocalizeService, result *model.RecommenderResult, lang string) (*model.RecommenderResult, string) {
+	if localize == nil || lang == model.DefaultLanguage {
+		return result, model.DefaultLanguage
+	}
+	localized, err := localize.LocalizeRecommendations(ctx, result, lang)
+	if err != nil {
+		log.Printf("localize recommendations failed lang=%s err=%v", lang, err)
+		return result, model.DefaultLanguage
+	}
+	return localized, lang
+}
+
+// localizeComparison is the comparison counterpart of localizeAnalysis. It
+// also returns the language the result ended up in.
+func localizeComparison(ctx context.Context, localize service.LocalizeService, result *model.ComparisonResult, lang string) (*model.ComparisonResult, string) {
+	if localize == nil || lang == model.DefaultLanguage {
+		return result, model.DefaultLanguage
+	}
+	localized, err := localize.LocalizeComparison(ctx, result, lang)
+	if err != nil {
+		log.Printf("localize comparison failed lang=%s err=%v", lang, err)
+		return result, model.DefaultLanguage
+	}
+	return localized, lang
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLocalizeService struct {
+	localizeAnalysis        func(ctx context.Context, result *model.AnalysisResult, lang string) (*model.AnalysisResult, error)
+	localizeRecommendations func(ctx context.Context, result *model.RecommenderResult, lang string) (*model.RecommenderResult, error)
+	localizeComparison      func(ctx context.Context, result *model.ComparisonResult, lang string) (*model.ComparisonResult, error)
+}
+
+func (m *mockLocalizeService) LocalizeAnalysis(ctx context.Context, result *model.AnalysisResult, lang string) (*model.AnalysisResult, error) {
+	return m.localizeAnalysis(ctx, result, lang)
+}
+
+func (m *mockLocalizeService) LocalizeRecommendations(ctx context.Context, result *model.RecommenderResult, lang string) (*model.RecommenderResult, error) {
+	return m.localizeRecommendations(ctx, result, lang)
+}
+
+func (m *mockLocalizeService) LocalizeComparison(ctx context.Context, result *model.ComparisonResult, lang string) (*model.ComparisonResult, error) {
+	return m.localizeComparison(ctx, result, lang)
+}
+
+func TestRequestLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                          "en",
+		"es-MX,es;q=0.9,en;q=0.8":   "es",
+		"fr-CA":                     "fr",
+		"ja":                        "ja",
+		"en-GB,de;q=0.5":            "en",
+		"pt-BR":                     "en",
+		"de;q=0.2, fr;q=0.7":        "fr",
+		"not a ;; valid header ===": "en",
+	}
+	for header, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set("Accept-Language", header)
+		}
+		require.Equal(t, want, requestLanguage(req), "Accept-Language %q", header)
+	}
+}
+
+func TestAnalyzeHandlerLocalizesResponse(t *testing.T) {
+	h := &AnalyzeHandler{
+		Analyze: &mockAnalyzeService{
+			analyze: func(context.Context, []byte, string, *model.UserPreferences) (*model.AnalysisResult, error) {
+				return &model.AnalysisResult{
+					ProductName:      "Galletas María",
+					DetectedLanguage: "es",
+					Language:         model.DefaultLanguage,
+					IngredientBreakdown: &model.ScorerResult{
+						IngredientScores: []model.IngredientScore{{IngredientName: "Sugar", OriginalName: "Azúcar", SafetyScore: "LOW", Reasoning: "Added sugar"}},
+					},
+				}, nil
+			},
+		},
+		Localize: &mockLocalizeService{
+			localizeAnalysis: func(_ context.Context, result *model.AnalysisResult, lang string) (*model.AnalysisResult, error) {
+				require.Equal(t, "es", lang)
+				out := *result
+				out.Language = lang
+				out.IngredientBreakdown = &model.ScorerResult{
+					IngredientScores: []model.IngredientScore{{IngredientName: "Sugar", OriginalName: "Azúcar", SafetyScore: "LOW", Reasoning: "Azúcar añadido"}},
+				}
+				return &out, nil
+			},
+		},
+	}
+
+	req := makeAnalyzeMultipartRequest(t, true)
+	req.Header.Set("Accept-Language", "es-ES,es;q=0.9")
+	rr := httptest.NewRecorder()
+
+	h.AnalyzeImage(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "es", rr.Header().Get("Content-Language"))
+	require.Contains(t, rr.Body.String(), `"detected_language":"es"`)
+	require.Contains(t, rr.Body.String(), `"language":"es"`)
+	require.Contains(t, rr.Body.String(), `"original_name":"Azúcar"`)
+	require.Contains(t, rr.Body.String(), `"reasoning":"Azúcar añadido"`)
+}
+
+func TestRecommendHandlerFallsBackToEnglishWhenLocalizationFails(t *testing.T) {
+	h := &RecommendHandler{
+		Recommend: &mockRecommendService{
+			recommend: func(context.Context, string, float64, *model.UserPreferences) (*model.RecommenderResult, error) {
+				return &model.RecommenderResult{Recommendations: []model.Recommendation{{ProductName: "Oats", Reason: "Lower sugar"}}}, nil
+			},
+		},
+		Localize: &mockLocalizeService{
+			localizeRecommendations: func(context.Context, *model.RecommenderResult, string) (*model.RecommenderResult, error) {
+				return nil, errors.New("translator unavailable")
+			},
+		},
+	}
+
+	req := makeRecommendRequest("Granola", "4.5")
+	req.Header.Set("Accept-Language", "fr")
+	rr := httptest.NewRecorder()
+
+	h.RecommendProducts(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "en", rr.Header().Get("Content-Language"))
+	require.Contains(t, rr.Body.String(), `"reason":"Lower sugar"`)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+// requestPreferences returns the dietary preferences of the authenticated user,
+// or nil when the request is anonymous or the user has no profile yet.
+func requestPreferences(r *http.Request, users service.UserService) (*model.UserPreferences, error) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return nil, nil
+	}
+	return userPreferences(r.Context(), users, userID)
+}
+
+// userPreferences loads the stored preferences of userID, or nil when the
+// user or the user service does not exist.
+func userPreferences(ctx context.Context, users service.UserService, userID string) (*model.UserPreferences, error) {
+	if users == nil {
+		return nil, nil
+	}
+
+	user, err := users.GetByID(ctx, userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &model.UserPreferences{
+		Allergies:        user.Allergies,
+		DietGoals:        user.DietGoals,
+		AvoidIngredients: user.AvoidIngredients,
+		HomeRegion:       user.HomeRegion,
+		Version:          user.PreferenceVersion,
+	}, nil
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+const maxProfileNameLength = 50
+
+// ProfileHandler manages the household profiles of the signed-in user.
+// Routes using it must sit behind RequireAuth.
+type ProfileHandler struct {
+	Profiles service.ProfileService
+}
+
+type profileRequest struct {
+	Name             string         `json:"name"`
+	Allergies        []allergyInput `json:"allergies"`
+	DietGoals        []string       `json:"dietGoals"`
+	AvoidIngredients []string       `json:"avoidIngredients"`
+}
+
+func (h *ProfileHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	profiles, err := h.Profiles.List(r.Context(), userID)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch profiles", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"profiles": profiles})
+}
+
+func (h *ProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+	profile, ok := readProfile(w, r, nil)
+	if !ok {
+		return
+	}
+
+	created, err := h.Profiles.Create(r.Context(), userID, profile)
+	if err != nil {
+		if writeProfileConflict(w, err) {
+			return
+		}
+		writeInternalError(w, r, "failed to create profile", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"profile": created,
+		"status":  "created",
+	})
+}
+
+func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+	profileID := chi.URLParam(r, "profile_id")
+	profile, ok := readProfile(w, r, func() ([]model.Allergy, bool) {
+		current, err := h.Profiles.Select(r.Context(), userID, []string{profileID})
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "profile not found")
+				return nil, false
+			}
+			writeInternalError(w, r, "failed to update profile", err)
+			return nil, false
+		}
+		return current[0].Allergies, true
+	})
+	if !ok {
+		return
+	}
+	profile.ID = profileID
+
+	updated, err := h.Profiles.Update(r.Context(), userID, profile)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "profile not found")
+			return
+		}
+		if writeProfileConflict(w, err) {
+			return
+		}
+		writeInternalError(w, r, "failed to update profile", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"profile": updated,
+		"status":  "updated",
+	})
+}
+
+func (h *ProfileHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.Profiles.Delete(r.Context(), userID, chi.URLParam(r, "profile_id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "profile not found")
+			return
+		}
+		writeInternalError(w, r, "failed to delete profile", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// readProfile decodes and cleans a profile body, writing a 400 response when
+// it is invalid. stored loads the allergies being replaced, as for
+// cleanAllergies.
+func readProfile(w http.ResponseWriter, r *http.Request, stored func() ([]model.Allergy, bool)) (model.Profile, bool) {
+	var req profileRequest
+	if ok := readJSON(w, r, &req); !ok {
+		return model.Profile{}, false
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return model.Profile{}, false
+	}
+	if len(name) > maxProfileNameLength {
+		writeError(w, http.StatusBadRequest, "name must be at most 50 characters")
+		return model.Profile{}, false
+	}
+	allergies, ok := cleanAllergies(w, req.Allergies, stored)
+	if !ok {
+		return model.Profile{}, false
+	}
+	return model.Profile{
+		Name:             name,
+		Allergies:        allergies,
+		DietGoals:        cleanList(req.DietGoals),
+		AvoidIngredients: cleanList(req.AvoidIngredients),
+	}, true
+}
+
+func writeProfileConflict(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrDuplicateProfile), errors.Is(err, service.ErrProfileLimit):
+		writeError(w, http.StatusConflict, err.Error())
+		return true
+	}
+	return false
+}
+
+// cleanList trims the entries of a preference list and drops blank ones.
+// The result is never nil, so it encodes as [].
+func cleanList(values []string) []string {
+	out := []string{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// allergyInput is an allergy as sent in a request body. Bare marks the plain
+// name clients sent before allergies had a severity, which says nothing about
+// the severity the user set.
+type allergyInput struct {
+	model.Allergy
+	Bare bool
+}
+
+func (a *allergyInput) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	a.Bare = len(data) > 0 && data[0] == '"'
+	return a.Allergy.UnmarshalJSON(data)
+}
+
+// cleanAllergies trims allergy names and drops blank ones like cleanList,
+// writing a 400 response for an unknown severity. An allergy sent as a plain
+// name keeps the severity and trace sensitivity of the same allergy in the
+// list it replaces, so older clients saving it back do not reset them. stored
+// loads that list, writing an error response when it cannot; it is only
+// called for plain names, and is nil when nothing is being replaced.
+func cleanAllergies(w http.ResponseWriter, allergies []allergyInput, stored func() ([]model.Allergy, bool)) ([]model.Allergy, bool) {
+	var current []model.Allergy
+	loaded := stored == nil
+	out := []model.Allergy{}
+	for _, in := range allergies {
+		a := in.Allergy
+		if a.Name = strings.TrimSpace(a.Name); a.Name == "" {
+			continue
+		}
+		if in.Bare {
+			if !loaded {
+				var ok bool
+				if current, ok = stored(); !ok {
+					return nil, false
+				}
+				loaded = true
+			}
+			for _, c := range current {
+				if model.SameAllergen(a, c) {
+					a.Severity = c.Severity
+					a.MayContain = c.MayContain
+					break
+				}
+			}
+		}
+		if a.Severity == "" {
+			a.Severity = model.SeverityAllergy
+		}
+		if !model.ValidSeverity(a.Severity) {
+			names := make([]string, 0, len(model.Severities))
+			for _, s := range model.Severities {
+				names = append(names, string(s))
+			}
+			writeError(w, http.StatusBadRequest, "allergy severity must be one of "+strings.Join(names, ", "))
+			return nil, false
+		}
+		out = append(out, a)
+	}
+	return out, true
+}
+
+// requestProfiles loads the household profiles picked by the profiles query
+// parameter: "all" or a comma-separated list of profile IDs. It returns nil
+// when the parameter is absent and writes an error response when the
+// selection cannot be served.
+func requestProfiles(w http.ResponseWriter, r *http.Request, profiles service.ProfileService) ([]model.Profile, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get("profiles"))
+	if raw == "" {
+		return nil, true
+	}
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "profiles require an authenticated user")
+		return nil, false
+	}
+	if profiles == nil {
+		writeError(w, http.StatusInternalServerError, "profile service is not configured")
+		return nil, false
+	}
+
+	var ids []string
+	if raw != "all" {
+		for _, id := range strings.Split(raw, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			writeError(w, http.StatusBadRequest, `profiles must be "all" or a comma-separated list of profile IDs`)
+			return nil, false
+		}
+	}
+
+	selected, err := profiles.Select(r.Context(), userID, ids)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "profile not found")
+			return nil, false
+		}
+		writeInternalError(w, r, "failed to fetch profiles", err)
+		return nil, false
+	}
+	return selected, true
+}
+
+// homeRegion is the region of the account, which its profiles share.
+func homeRegion(prefs *model.UserPreferences) string {
+	if prefs == nil {
+		return ""
+	}
+	return prefs.HomeRegion
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+type mockProfileService struct {
+	list     func(ctx context.Context, userID string) ([]model.Profile, error)
+	create   func(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error)
+	update   func(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error)
+	delete   func(ctx context.Context, userID, profileID string) error
+	selected func(ctx context.Context, userID string, profileIDs []string) ([]model.Profile, error)
+	evaluate func(ctx context.Context, profiles []model.Profile, homeRegion string, ingredients []model.Ingredient, breakdown *model.ScorerResult) []model.ProfileVerdict
+	judge    func(profiles []model.Profile, homeRegion string, breakdown *model.ScorerResult) []model.ProfileVerdict
+}
+
+func (m *mockProfileService) List(ctx context.Context, userID string) ([]model.Profile, error) {
+	return m.list(ctx, userID)
+}
+
+func (m *mockProfileService) Create(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error) {
+	return m.create(ctx, userID, profile)
+}
+
+func (m *mockProfileService) Update(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error) {
+	return m.update(ctx, userID, profile)
+}
+
+func (m *mockProfileService) Delete(ctx context.Context, userID, profileID string) error {
+	return m.delete(ctx, userID, profileID)
+}
+
+func (m *mockProfileService) Select(ctx context.Context, userID string, profileIDs []string) ([]model.Profile, error) {
+	return m.selected(ctx, userID, profileIDs)
+}
+
+func (m *mockProfileService) Evaluate(ctx context.Context, profiles []model.Profile, homeRegion string, ingredients []model.Ingredient, breakdown *model.ScorerResult) []model.ProfileVerdict {
+	return m.evaluate(ctx, profiles, homeRegion, ingredients, breakdown)
+}
+
+func (m *mockProfileService) Judge(profiles []model.Profile, homeRegion string, breakdown *model.ScorerResult) []model.ProfileVerdict {
+	return m.judge(profiles, homeRegion, breakdown)
+}
+
+func withProfileID(req *http.Request, profileID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("profile_id", profileID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func signedIn(req *http.Request) *http.Request {
+	return req.WithContext(middleware.WithUserID(req.Context(), "auth0|abc"))
+}
+
+func TestProfileHandlerCreate(t *testing.T) {
+	h := &ProfileHandler{Profiles: &mockProfileService{create: func(_ context.Context, userID string, profile model.Profile) (*model.Profile, error) {
+		require.Equal(t, "auth0|abc", userID)
+		require.Equal(t, "Mia", profile.Name)
+		require.Equal(t, model.Allergies("peanuts"), profile.Allergies)
+		require.Equal(t, []string{}, profile.DietGoals)
+		profile.ID = "p-1"
+		profile.UserID = userID
+		return &profile, nil
+	}}}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/me/profiles", strings.NewReader(`{"name":" Mia ","allergies":["peanuts"," "]}`))
+	rr := httptest.NewRecorder()
+	h.Create(rr, signedIn(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Status  string        `json:"status"`
+		Profile model.Profile `json:"profile"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "created", resp.Status)
+	require.Equal(t, "p-1", resp.Profile.ID)
+}
+
+func TestProfileHandlerCreateValidation(t *testing.T) {
+	h := &ProfileHandler{Profiles: &mockProfileService{create: func(context.Context, string, model.Profile) (*model.Profile, error) {
+		t.Fatal("service should not be called")
+		return nil, nil
+	}}}
+
+	for _, body := range []string{`{"name":"  "}`, `{"name":"` + strings.Repeat("a", 51) + `"}`, `{"name":"Mia","age":4}`} {
+		rr := httptest.NewRecorder()
+		h.Create(rr, signedIn(httptest.NewRequest(http.MethodPost, "/api/users/me/profiles", strings.NewReader(body))))
+		require.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+
+	rr := httptest.NewRecorder()
+	h.Create(rr, httptest.NewRequest(http.MethodPost, "/api/users/me/profiles", strings.NewReader(`{"name":"Mia"}`)))
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestProfileHandlerConflicts(t *testing.T) {
+	for _, err := range []error{service.ErrDuplicateProfile, service.ErrProfileLimit} {
+		h := &ProfileHandler{Profiles: &mockProfileService{create: func(context.Context, string, model.Profile) (*model.Profile, error) {
+			return nil, err
+		}}}
+		rr := httptest.NewRecorder()
+		h.Create(rr, signedIn(httptest.NewRequest(http.MethodPost, "/api/users/me/profiles", strings.NewReader(`{"name":"Mia"}`))))
+		require.Equal(t, http.StatusConflict, rr.Code)
+	}
+}
+
+func TestProfileHandlerUpdateAndDeleteNotFound(t *testing.T) {
+	h := &ProfileHandler{Profiles: &mockProfileService{
+		update: func(_ context.Context, _ string, profile model.Profile) (*model.Profile, error) {
+			require.Equal(t, "p-9", profile.ID)
+			return nil, repository.ErrNotFound
+		},
+		delete: func(_ context.Context, _ string, profileID string) error {
+			require.Equal(t, "p-9", profileID)
+			return repository.ErrNotFound
+		},
+	}}
+
+	req := withProfileID(httptest.NewRequest(http.MethodPut, "/api/users/me/profiles/p-9", strings.NewReader(`{"name":"Mia"}`)), "p-9")
+	rr := httptest.NewRecorder()
+	h.Update(rr, signedIn(req))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	req = withProfileID(httptest.NewRequest(http.MethodDelete, "/api/users/me/profiles/p-9", nil), "p-9")
+	rr = httptest.NewRecorder()
+	h.Delete(rr, signedIn(req))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestProfileHandlerUpdateKeepsStoredSeverities(t *testing.T) {
+	var got []model.Allergy
+	h := &ProfileHandler{Profiles: &mockProfileService{
+		selected: func(_ context.Context, _ string, profileIDs []string) ([]model.Profile, error) {
+			require.Equal(t, []string{"p-1"}, profileIDs)
+			return []model.Profile{{ID: "p-1", Allergies: []model.Allergy{
+				{Name: "peanuts", Severity: model.SeverityAnaphylactic, MayContain: true},
+			}}}, nil
+		},
+		update: func(_ context.Context, _ string, profile model.Profile) (*model.Profile, error) {
+			got = profile.Allergies
+			return &profile, nil
+		},
+	}}
+
+	req := withProfileID(httptest.NewRequest(http.MethodPut, "/api/users/me/profiles/p-1", strings.NewReader(`{"name":"Mia","allergies":["peanuts","sesame"]}`)), "p-1")
+	rr := httptest.NewRecorder()
+	h.Update(rr, signedIn(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, []model.Allergy{
+		{Name: "peanuts", Severity: model.SeverityAnaphylactic, MayContain: true},
+		{Name: "sesame", Severity: model.SeverityAllergy},
+	}, got)
+}
+
+func TestRequestProfiles(t *testing.T) {
+	profiles := &mockProfileService{selected: func(_ context.Context, _ string, ids []string) ([]model.Profile, error) {
+		if len(ids) == 0 {
+			return []model.Profile{{ID: "p-1"}, {ID: "p-2"}}, nil
+		}
+		if ids[0] == "p-9" {
+			return nil, repository.ErrNotFound
+		}
+		require.Equal(t, []string{"p-1", "p-2"}, ids)
+		return []model.Profile{{ID: "p-1"}, {ID: "p-2"}}, nil
+	}}
+
+	rr := httptest.NewRecorder()
+	selected, ok := requestProfiles(rr, httptest.NewRequest(http.MethodPost, "/api/analyze/product", nil), profiles)
+	require.True(t, ok)
+	require.Nil(t, selected)
+
+	for _, query := range []string{"all", "p-1,%20p-2"} {
+		rr = httptest.NewRecorder()
+		selected, ok = requestProfiles(rr, signedIn(httptest.NewRequest(http.MethodPost, "/api/analyze/product?profiles="+query, nil)), profiles)
+		require.True(t, ok, query)
+		require.Len(t, selected, 2)
+	}
+
+	for query, code := range map[string]int{"p-9": http.StatusNotFound, ",": http.StatusBadRequest} {
+		rr = httptest.NewRecorder()
+		_, ok = requestProfiles(rr, signedIn(httptest.NewRequest(http.MethodPost, "/api/analyze/product?profiles="+query, nil)), profiles)
+		require.False(t, ok)
+		require.Equal(t, code, rr.Code, query)
+	}
+
+	rr = httptest.NewRecorder()
+	_, ok = requestProfiles(rr, httptest.NewRequest(http.MethodPost, "/api/analyze/product?profiles=all", nil), profiles)
+	require.False(t, ok)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestAnalyzeHandlerAnalyzeProductForProfiles(t *testing.T) {
+	household := []model.Profile{{ID: "p-1", Name: "Mia", Allergies: model.Allergies("peanuts")}}
+	h := &AnalyzeHandler{
+		Users: &mockAnalyzeUserService{getByID: func(_ context.Context, userID string) (*model.User, error) {
+			return &model.User{ID: userID, HomeRegion: "EU"}, nil
+		}},
+		Analyze: &mockAnalyzeService{analyzeProduct: func(context.Context, string, *model.UserPreferences) (*model.AnalysisResult, error) {
+			return &model.AnalysisResult{ProductName: "Peanut Bar", IngredientBreakdown: &model.ScorerResult{OverallScore: 6}, Ingredients: []model.Ingredient{{Name: "Oats"}, {Name: "Peanuts", MayContain: true}}}, nil
+		}},
+		Profiles: &mockProfileService{
+			selected: func(context.Context, string, []string) ([]model.Profile, error) { return household, nil },
+			evaluate: func(_ context.Context, profiles []model.Profile, region string, ingredients []model.Ingredient, breakdown *model.ScorerResult) []model.ProfileVerdict {
+				require.Equal(t, household, profiles)
+				require.Equal(t, "EU", region)
+				require.Equal(t, []model.Ingredient{{Name: "Oats"}, {Name: "Peanuts", MayContain: true}}, ingredients)
+				require.Equal(t, 6.0, breakdown.OverallScore)
+				return []model.ProfileVerdict{{ProfileID: "p-1", Name: "Mia", Verdict: model.VerdictAvoid, Conflicts: []model.PreferenceConflict{}}}
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze/product?profiles=all", strings.NewReader(`{"productName":"Peanut Bar"}`))
+	rr := httptest.NewRecorder()
+	h.AnalyzeProduct(rr, signedIn(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Profiles []model.ProfileVerdict `json:"profiles"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Profiles, 1)
+	require.Equal(t, model.VerdictAvoid, resp.Profiles[0].Verdict)
+}
+
+func TestRecommendHandlerRecommendProductsForProfiles(t *testing.T) {
+	household := []model.Profile{{ID: "p-1", Name: "Mia", Allergies: model.Allergies("peanuts")}}
+	h := &RecommendHandler{
+		Users: &mockAnalyzeUserService{getByID: func(_ context.Context, userID string) (*model.User, error) {
+			return &model.User{ID: userID, Allergies: model.Allergies("milk")}, nil
+		}},
+		Recommend: &mockRecommendService{recommend: func(_ context.Context, _ string, _ float64, prefs *model.UserPreferences) (*model.RecommenderResult, error) {
+			require.ElementsMatch(t, model.Allergies("milk", "peanuts"), prefs.Allergies)
+			return &model.RecommenderResult{Recommendations: []model.Recommendation{{ProductName: "Oat Bar"}}}, nil
+		}},
+		Profiles: &mockProfileService{
+			selected: func(context.Context, string, []string) ([]model.Profile, error) { return household, nil },
+			judge: func(profiles []model.Profile, _ string, _ *model.ScorerResult) []model.ProfileVerdict {
+				return []model.ProfileVerdict{{ProfileID: profiles[0].ID, Name: profiles[0].Name, Verdict: model.VerdictSuitable}}
+			},
+		},
+	}
+
+	req := makeRecommendRequest("Peanut Bar", "3")
+	req.URL.RawQuery = "profiles=p-1"
+	rr := httptest.NewRecorder()
+	h.RecommendProducts(rr, signedIn(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"profiles":[{"profile_id":"p-1","name":"Mia","verdict":"suitable"`)
+}
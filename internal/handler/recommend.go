@@ -11,6 +11,11 @@ import (
 
 type RecommendHandler struct {
 	Recommend service.RecommendService
+	Users     service.UserService
+	Localize  service.LocalizeService
+	// Profiles judges the alternatives for the household profiles picked
+	// with the profiles query parameter.
+	Profiles service.ProfileService
 }
 
 func (h *RecommendHandler) RecommendProducts(w http.ResponseWriter, r *http.Request) {
@@ -37,14 +42,45 @@ func (h *RecommendHandler) RecommendProducts(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	result, err := h.Recommend.Recommend(r.Context(), productName, overallScore)
+	prefs, err := requestPreferences(r, h.Users)
 	if err != nil {
+		writeInternalError(w, r, "failed to fetch user preferences", err)
+		return
+	}
+	profiles, ok := requestProfiles(w, r, h.Profiles)
+	if !ok {
+		return
+	}
+	// Alternatives for a household have to suit everyone, so they are looked
+	// for and scored against everyone's preferences at once.
+	household := prefs
+	if profiles != nil {
+		household = service.MergePreferences(prefs, profiles)
+	}
+
+	ctx := agentContext(r, "")
+	result, err := h.Recommend.Recommend(ctx, productName, overallScore, household)
+	if err != nil {
+		if writeRejectedInput(w, err) {
+			return
+		}
 		writeInternalError(w, r, "failed to generate recommendations", err)
 		return
 	}
 
+	if profiles != nil {
+		for i := range result.Recommendations {
+			rec := &result.Recommendations[i]
+			rec.Profiles = h.Profiles.Judge(profiles, homeRegion(prefs), rec.IngredientBreakdown)
+		}
+	}
+
+	result, lang := localizeRecommendations(ctx, h.Localize, result, requestLanguage(r))
+
+	w.Header().Set("Content-Language", lang)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"status":           "success",
+		"language":         lang,
 		"reccomender_data": result,
 	})
 }
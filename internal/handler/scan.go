@@ -1,19 +1,27 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/safebites/backend-go/internal/blob"
 	"github.com/safebites/backend-go/internal/model"
 	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
 )
 
 type ScanHandler struct {
 	Scans repository.ScanRepository
 	Users repository.UserRepository
+	// Blobs resolves imageId references to signed URLs; nil disables them.
+	Blobs blob.Store
+	// Events notifies webhook subscribers of saved scans; nil skips it.
+	Events service.EventPublisher
 }
 
 type createScanRequest struct {
@@ -21,11 +29,20 @@ type createScanRequest struct {
 	ProductName string                   `json:"productName"`
 	Brand       string                   `json:"brand"`
 	Image       string                   `json:"image"`
+	ImageID     string                   `json:"imageId"`
 	SafetyScore int                      `json:"safetyScore"`
 	IsSafe      bool                     `json:"isSafe"`
 	Ingredients []map[string]interface{} `json:"ingredients"`
+	Sources     []model.Source           `json:"sources"`
+	Confidence  *model.Confidence        `json:"confidence"`
+	// PreferenceVersion is the preference_version of the analyze response.
+	// Without it the scan is saved against the user's current preferences.
+	PreferenceVersion int `json:"preferenceVersion"`
 }
 
+// ListByUser returns one page of the user's scans. The query selects the
+// page with limit and cursor, filters with from, to, safe, minScore,
+// maxScore, brand and product, and orders with sort.
 func (h *ScanHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "user_id")
 	if strings.TrimSpace(userID) == "" {
@@ -33,26 +50,133 @@ func (h *ScanHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit := 20
-	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+	filter, ok := readScanFilter(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.Scans.ListByUser(r.Context(), userID, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "cursor is invalid for this sort")
+			return
+		}
+		writeInternalError(w, r, "failed to fetch scans", err)
+		return
+	}
+	for i := range page.Scans {
+		signScanImages(r.Context(), h.Blobs, &page.Scans[i])
+	}
+
+	response := map[string]interface{}{"scans": page.Scans}
+	if page.NextCursor != "" {
+		response["nextCursor"] = page.NextCursor
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// readScanFilter reads the scan list query parameters. On invalid input it
+// writes a 400 response and returns false.
+func readScanFilter(w http.ResponseWriter, r *http.Request) (model.ScanFilter, bool) {
+	query := r.URL.Query()
+	filter := model.ScanFilter{
+		Limit:   20,
+		Cursor:  strings.TrimSpace(query.Get("cursor")),
+		Brand:   strings.TrimSpace(query.Get("brand")),
+		Product: strings.TrimSpace(query.Get("product")),
+		Sort:    model.ScanSortNewest,
+	}
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
 		parsed, err := strconv.Atoi(rawLimit)
 		if err != nil || parsed <= 0 {
 			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
-			return
+			return filter, false
 		}
-		if parsed > 100 {
-			parsed = 100
+		if parsed > model.MaxScanPage {
+			parsed = model.MaxScanPage
+		}
+		filter.Limit = parsed
+	}
+
+	if rawSort := query.Get("sort"); rawSort != "" {
+		filter.Sort = model.ScanSort(rawSort)
+		if !model.ValidScanSort(filter.Sort) {
+			writeError(w, http.StatusBadRequest, "sort must be one of newest, oldest, score_desc, score_asc")
+			return filter, false
+		}
+	}
+
+	if rawSafe := query.Get("safe"); rawSafe != "" {
+		safe, err := strconv.ParseBool(rawSafe)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "safe must be true or false")
+			return filter, false
 		}
-		limit = parsed
+		filter.Safe = &safe
 	}
 
-	scans, err := h.Scans.ListByUser(r.Context(), userID, limit)
+	var err error
+	if filter.MinScore, err = parseScoreBound(query.Get("minScore")); err != nil {
+		writeError(w, http.StatusBadRequest, "minScore must be an integer between 0 and 100")
+		return filter, false
+	}
+	if filter.MaxScore, err = parseScoreBound(query.Get("maxScore")); err != nil {
+		writeError(w, http.StatusBadRequest, "maxScore must be an integer between 0 and 100")
+		return filter, false
+	}
+	if filter.MinScore != nil && filter.MaxScore != nil && *filter.MinScore > *filter.MaxScore {
+		writeError(w, http.StatusBadRequest, "minScore must not be greater than maxScore")
+		return filter, false
+	}
+
+	if filter.From, err = parseScanTime(query.Get("from"), false); err != nil {
+		writeError(w, http.StatusBadRequest, "from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		return filter, false
+	}
+	if filter.To, err = parseScanTime(query.Get("to"), true); err != nil {
+		writeError(w, http.StatusBadRequest, "to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		return filter, false
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		writeError(w, http.StatusBadRequest, "from must be before to")
+		return filter, false
+	}
+	return filter, true
+}
+
+// parseScoreBound reads a minScore or maxScore bound; empty means none.
+func parseScoreBound(raw string) (*int, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	score, err := strconv.Atoi(raw)
 	if err != nil {
-		writeInternalError(w, r, "failed to fetch scans", err)
-		return
+		return nil, err
+	}
+	if score < 0 || score > 100 {
+		return nil, errors.New("score out of range")
 	}
+	return &score, nil
+}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{"scans": scans})
+// parseScanTime reads a from or to bound. A bare date is a whole UTC day, so
+// as the exclusive upper bound it means the start of the following day.
+func parseScanTime(raw string, end bool) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
 }
 
 func (h *ScanHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -74,6 +198,16 @@ func (h *ScanHandler) Create(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "safetyScore must be between 0 and 100")
 		return
 	}
+	if req.PreferenceVersion < 0 {
+		writeError(w, http.StatusBadRequest, "preferenceVersion must not be negative")
+		return
+	}
+
+	if req.ImageID != "" {
+		if ok := h.checkImageID(w, r, req.ImageID); !ok {
+			return
+		}
+	}
 
 	scanID := req.ID
 	if scanID == "" {
@@ -81,19 +215,31 @@ func (h *ScanHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	created, err := h.Scans.Create(r.Context(), &model.Scan{
-		ID:          scanID,
-		UserID:      userID,
-		ProductName: req.ProductName,
-		Brand:       req.Brand,
-		Image:       req.Image,
-		SafetyScore: req.SafetyScore,
-		IsSafe:      req.IsSafe,
-		Ingredients: req.Ingredients,
+		ID:                scanID,
+		UserID:            userID,
+		ProductName:       req.ProductName,
+		Brand:             req.Brand,
+		Image:             req.Image,
+		ImageID:           req.ImageID,
+		SafetyScore:       req.SafetyScore,
+		IsSafe:            req.IsSafe,
+		Ingredients:       req.Ingredients,
+		Sources:           req.Sources,
+		Confidence:        req.Confidence,
+		PreferenceVersion: req.PreferenceVersion,
 	})
 	if err != nil {
+		if errors.Is(err, repository.ErrUnknownPreferenceVersion) {
+			writeError(w, http.StatusBadRequest, "preferenceVersion does not match a version of the user's preferences")
+			return
+		}
 		writeInternalError(w, r, "failed to create scan", err)
 		return
 	}
+	if h.Events != nil {
+		h.Events.Publish(r.Context(), userID, model.EventScanCreated, created)
+	}
+	signScanImages(r.Context(), h.Blobs, created)
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"scan":   created,
@@ -101,6 +247,25 @@ func (h *ScanHandler) Create(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// checkImageID makes sure a scan only links to an image that was actually
+// stored, answering 400 otherwise.
+func (h *ScanHandler) checkImageID(w http.ResponseWriter, r *http.Request, imageID string) bool {
+	if h.Blobs == nil {
+		writeError(w, http.StatusBadRequest, "image storage is not configured")
+		return false
+	}
+	exists, err := h.Blobs.Exists(r.Context(), imageID)
+	if errors.Is(err, blob.ErrInvalidID) || (err == nil && !exists) {
+		writeError(w, http.StatusBadRequest, "imageId does not reference a stored image")
+		return false
+	}
+	if err != nil {
+		writeInternalError(w, r, "failed to look up image", err)
+		return false
+	}
+	return true
+}
+
 func (h *ScanHandler) Stats(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "user_id")
 	if strings.TrimSpace(userID) == "" {
@@ -11,17 +11,23 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
 	"github.com/stretchr/testify/require"
 )
 
 type mockScanRepo struct {
-	listByUser func(ctx context.Context, userID string, limit int) ([]model.Scan, error)
+	listByUser func(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error)
+	getByID    func(ctx context.Context, userID, scanID string) (*model.Scan, error)
 	create     func(ctx context.Context, scan *model.Scan) (*model.Scan, error)
 	getStats   func(ctx context.Context, userID string) (*model.UserStats, error)
 }
 
-func (m *mockScanRepo) ListByUser(ctx context.Context, userID string, limit int) ([]model.Scan, error) {
-	return m.listByUser(ctx, userID, limit)
+func (m *mockScanRepo) ListByUser(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
+	return m.listByUser(ctx, userID, filter)
+}
+
+func (m *mockScanRepo) GetByID(ctx context.Context, userID, scanID string) (*model.Scan, error) {
+	return m.getByID(ctx, userID, scanID)
 }
 
 func (m *mockScanRepo) Create(ctx context.Context, scan *model.Scan) (*model.Scan, error) {
@@ -34,10 +40,11 @@ func (m *mockScanRepo) GetStats(ctx context.Context, userID string) (*model.User
 
 func TestScanHandlerListByUser(t *testing.T) {
 	h := &ScanHandler{Scans: &mockScanRepo{
-		listByUser: func(_ context.Context, userID string, limit int) ([]model.Scan, error) {
+		listByUser: func(_ context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
 			require.Equal(t, "user-1", userID)
-			require.Equal(t, 5, limit)
-			return []model.Scan{{ID: "scan-1", UserID: userID}}, nil
+			require.Equal(t, 5, filter.Limit)
+			require.Equal(t, model.ScanSortNewest, filter.Sort)
+			return &model.ScanPage{Scans: []model.Scan{{ID: "scan-1", UserID: userID}}}, nil
 		},
 		create:   nil,
 		getStats: nil,
@@ -83,7 +90,7 @@ func TestScanHandlerCreate(t *testing.T) {
 
 func TestScanHandlerListByUserInvalidLimit(t *testing.T) {
 	h := &ScanHandler{Scans: &mockScanRepo{
-		listByUser: func(_ context.Context, _ string, _ int) ([]model.Scan, error) {
+		listByUser: func(_ context.Context, _ string, _ model.ScanFilter) (*model.ScanPage, error) {
 			t.Fatal("listByUser should not be called for invalid limit")
 			return nil, nil
 		},
@@ -101,6 +108,60 @@ func TestScanHandlerListByUserInvalidLimit(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, rr.Code)
 }
 
+func TestScanHandlerListByUserFilters(t *testing.T) {
+	var got model.ScanFilter
+	h := &ScanHandler{Scans: &mockScanRepo{
+		listByUser: func(_ context.Context, _ string, filter model.ScanFilter) (*model.ScanPage, error) {
+			got = filter
+			if filter.Cursor == "stale" {
+				return nil, repository.ErrInvalidCursor
+			}
+			return &model.ScanPage{Scans: []model.Scan{{ID: "scan-1"}}, NextCursor: "next"}, nil
+		},
+	}, Users: &mockUserRepo{}}
+
+	list := func(query string) *httptest.ResponseRecorder {
+		req := withURLParams(httptest.NewRequest(http.MethodGet, "/api/users/user-1/scans?"+query, nil), map[string]string{"user_id": "user-1"})
+		rr := httptest.NewRecorder()
+		h.ListByUser(rr, req)
+		return rr
+	}
+
+	rr := list("sort=score_asc&safe=false&minScore=10&maxScore=60&brand=+Acme+&product=bar&from=2026-10-01&to=2026-10-31&cursor=abc&limit=500")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"nextCursor":"next"`)
+	require.Equal(t, model.ScanSortScoreAsc, got.Sort)
+	require.Equal(t, model.MaxScanPage, got.Limit)
+	require.False(t, *got.Safe)
+	require.Equal(t, 10, *got.MinScore)
+	require.Equal(t, 60, *got.MaxScore)
+	require.Equal(t, "Acme", got.Brand)
+	require.Equal(t, "bar", got.Product)
+	require.Equal(t, "abc", got.Cursor)
+	require.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), got.From)
+	// A date as the upper bound includes that whole day.
+	require.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), got.To)
+
+	rr = list("from=2026-10-01T12:00:00%2B02:00")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.True(t, got.From.Equal(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)))
+	require.True(t, got.To.IsZero())
+	require.Nil(t, got.Safe)
+
+	for _, query := range []string{
+		"sort=name",
+		"safe=maybe",
+		"minScore=-1",
+		"maxScore=101",
+		"minScore=70&maxScore=20",
+		"from=yesterday",
+		"from=2026-10-05&to=2026-10-01",
+		"cursor=stale",
+	} {
+		require.Equal(t, http.StatusBadRequest, list(query).Code, query)
+	}
+}
+
 func TestScanHandlerCreateInvalidSafetyScore(t *testing.T) {
 	h := &ScanHandler{Scans: &mockScanRepo{
 		listByUser: nil,
@@ -125,3 +186,65 @@ func TestScanHandlerCreateInvalidSafetyScore(t *testing.T) {
 	h.Create(rr, req)
 	require.Equal(t, http.StatusBadRequest, rr.Code)
 }
+
+func TestScanHandlerCreatePublishesEvent(t *testing.T) {
+	events := &recordingPublisher{}
+	h := &ScanHandler{Scans: &mockScanRepo{
+		create: func(_ context.Context, scan *model.Scan) (*model.Scan, error) {
+			return scan, nil
+		},
+	}, Users: &mockUserRepo{}, Events: events}
+
+	body, _ := json.Marshal(map[string]interface{}{"productName": "Granola Bar", "safetyScore": 80})
+	req := httptest.NewRequest(http.MethodPost, "/api/users/user-1/scans", bytes.NewBuffer(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user_id", "user-1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, events.events, 1)
+	require.Equal(t, "user-1", events.events[0].userID)
+	require.Equal(t, model.EventScanCreated, events.events[0].eventType)
+	require.Equal(t, "Granola Bar", events.events[0].data.(*model.Scan).ProductName)
+}
+
+func TestScanHandlerCreatePreferenceVersion(t *testing.T) {
+	var got int
+	h := &ScanHandler{Scans: &mockScanRepo{
+		create: func(_ context.Context, scan *model.Scan) (*model.Scan, error) {
+			got = scan.PreferenceVersion
+			if scan.PreferenceVersion > 3 {
+				return nil, repository.ErrUnknownPreferenceVersion
+			}
+			return scan, nil
+		},
+	}, Users: &mockUserRepo{}}
+
+	for _, tc := range []struct {
+		version int
+		code    int
+	}{
+		{3, http.StatusOK},
+		{4, http.StatusBadRequest},
+		{-1, http.StatusBadRequest},
+	} {
+		got = 0
+		body, _ := json.Marshal(map[string]interface{}{
+			"productName":       "Granola Bar",
+			"safetyScore":       80,
+			"preferenceVersion": tc.version,
+		})
+		req := withURLParams(httptest.NewRequest(http.MethodPost, "/api/users/user-1/scans", bytes.NewBuffer(body)), map[string]string{"user_id": "user-1"})
+		rr := httptest.NewRecorder()
+
+		h.Create(rr, req)
+		require.Equal(t, tc.code, rr.Code, tc.version)
+		if tc.version > 0 {
+			require.Equal(t, tc.version, got)
+		} else {
+			require.Zero(t, got)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/safebites/backend-go/internal/agent"
+	"github.com/safebites/backend-go/internal/middleware"
+)
+
+// agentContext scopes the agent runs of a request to the signed-in user, or
+// the anonymous user, and to scanID when the runs belong to a scan.
+func agentContext(r *http.Request, scanID string) context.Context {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		userID = agent.AnonymousUserID
+	}
+	return agent.WithSessionScope(r.Context(), agent.SessionScope{UserID: userID, ScanID: scanID})
+}
@@ -1,51 +1,200 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"sort"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/middleware"
 	"github.com/safebites/backend-go/internal/model"
 	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
 )
 
+const (
+	maxTemplateNameLength        = 50
+	maxTemplateDescriptionLength = 200
+)
+
+// TemplateHandler serves dietary templates. Wherever a template is looked up
+// rather than changed, {template_key} may also be the share code of a
+// published template.
 type TemplateHandler struct {
-	Users repository.UserRepository
+	Templates service.TemplateService
+	Users     service.UserService
+}
+
+type templateRequest struct {
+	Name             string         `json:"name"`
+	Description      string         `json:"description"`
+	Allergies        []allergyInput `json:"allergies"`
+	DietGoals        []string       `json:"dietGoals"`
+	AvoidIngredients []string       `json:"avoidIngredients"`
 }
 
-func (h *TemplateHandler) List(w http.ResponseWriter, _ *http.Request) {
-	templates := make([]model.DietaryTemplate, 0, len(model.DietaryTemplates))
-	for _, tpl := range model.DietaryTemplates {
-		templates = append(templates, tpl)
+// List returns the built-in templates, followed by the caller's own when
+// they are signed in.
+func (h *TemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	templates, err := h.Templates.List(r.Context(), userID)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch templates", err)
+		return
 	}
-	sort.Slice(templates, func(i, j int) bool {
-		return templates[i].Key < templates[j].Key
+	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": templates})
+}
+
+func (h *TemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	template, err := h.Templates.Get(r.Context(), userID, chi.URLParam(r, "template_key"))
+	if err != nil {
+		writeTemplateError(w, r, "failed to fetch template", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"template": template})
+}
+
+func (h *TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+	template, ok := readTemplate(w, r, nil)
+	if !ok {
+		return
+	}
+
+	created, err := h.Templates.Create(r.Context(), userID, template)
+	if err != nil {
+		writeTemplateError(w, r, "failed to create template", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"template": created,
+		"status":   "created",
 	})
+}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": templates})
+func (h *TemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+	key := chi.URLParam(r, "template_key")
+	template, ok := readTemplate(w, r, func() ([]model.Allergy, bool) {
+		current, err := h.Templates.Get(r.Context(), userID, key)
+		if err != nil {
+			writeTemplateError(w, r, "failed to update template", err)
+			return nil, false
+		}
+		return current.Allergies, true
+	})
+	if !ok {
+		return
+	}
+	template.Key = key
+
+	updated, err := h.Templates.Update(r.Context(), userID, template)
+	if err != nil {
+		writeTemplateError(w, r, "failed to update template", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"template": updated,
+		"status":   "updated",
+	})
 }
 
-func (h *TemplateHandler) Apply(w http.ResponseWriter, r *http.Request) {
-	userID := chi.URLParam(r, "user_id")
-	templateKey := chi.URLParam(r, "template_key")
+func (h *TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Templates.Delete(r.Context(), userID, chi.URLParam(r, "template_key")); err != nil {
+		writeTemplateError(w, r, "failed to delete template", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
 
-	template, ok := model.DietaryTemplates[templateKey]
+// Publish gives one of the caller's templates a share code. Publishing an
+// already published template returns its current code.
+func (h *TemplateHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
 	if !ok {
-		writeError(w, http.StatusNotFound, "template not found")
 		return
 	}
 
-	updated, err := h.Users.UpdatePreferences(r.Context(), userID, model.UserPreferences{
-		Allergies:        template.Allergies,
-		DietGoals:        template.DietGoals,
-		AvoidIngredients: template.AvoidIngredients,
+	template, err := h.Templates.Publish(r.Context(), userID, chi.URLParam(r, "template_key"))
+	if err != nil {
+		writeTemplateError(w, r, "failed to publish template", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"template": template,
+		"status":   "published",
 	})
+}
+
+// Unpublish revokes the share code of one of the caller's templates. Copies
+// forked from it are kept.
+func (h *TemplateHandler) Unpublish(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.Templates.Unpublish(r.Context(), userID, chi.URLParam(r, "template_key"))
+	if err != nil {
+		writeTemplateError(w, r, "failed to unpublish template", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"template": template,
+		"status":   "unpublished",
+	})
+}
+
+// Fork copies a built-in, own, or shared template into the caller's
+// templates, where it can be edited.
+func (h *TemplateHandler) Fork(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.Templates.Fork(r.Context(), userID, chi.URLParam(r, "template_key"))
+	if err != nil {
+		writeTemplateError(w, r, "failed to fork template", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"template": template,
+		"status":   "created",
+	})
+}
+
+// Apply layers a template on top of the user's preferences. The template's
+// entries are merged with the user's own and those of templates applied
+// before; applying the same template again changes nothing.
+func (h *TemplateHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+	templateKey := chi.URLParam(r, "template_key")
+
+	updated, template, err := h.Users.ApplyTemplate(r.Context(), userID, templateKey)
 	if err != nil {
-		if err == repository.ErrNotFound {
+		switch {
+		case errors.Is(err, service.ErrTemplateNotFound):
+			writeError(w, http.StatusNotFound, "template not found")
+		case errors.Is(err, service.ErrTooManyLayers):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, repository.ErrNotFound):
 			writeError(w, http.StatusNotFound, "user not found")
-			return
+		default:
+			writeInternalError(w, r, "failed to apply template", err)
 		}
-		writeError(w, http.StatusInternalServerError, "failed to apply template")
 		return
 	}
 
@@ -54,3 +203,71 @@ func (h *TemplateHandler) Apply(w http.ResponseWriter, r *http.Request) {
 		"template": template,
 	})
 }
+
+// Remove takes one applied template off the user's preferences. The user's
+// own entries and the other templates stay.
+func (h *TemplateHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+	templateKey := chi.URLParam(r, "template_key")
+
+	updated, err := h.Users.RemoveTemplate(r.Context(), userID, templateKey)
+	if err != nil {
+		if errors.Is(err, service.ErrTemplateNotApplied) {
+			writeError(w, http.StatusNotFound, "template not applied")
+			return
+		}
+		writeInternalError(w, r, "failed to remove template", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user":   updated,
+		"status": "removed",
+	})
+}
+
+// readTemplate decodes and cleans a template body, writing a 400 response
+// when it is invalid. stored loads the allergies being replaced, as for
+// cleanAllergies.
+func readTemplate(w http.ResponseWriter, r *http.Request, stored func() ([]model.Allergy, bool)) (model.DietaryTemplate, bool) {
+	var req templateRequest
+	if ok := readJSON(w, r, &req); !ok {
+		return model.DietaryTemplate{}, false
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return model.DietaryTemplate{}, false
+	}
+	if len(name) > maxTemplateNameLength {
+		writeError(w, http.StatusBadRequest, "name must be at most 50 characters")
+		return model.DietaryTemplate{}, false
+	}
+	description := strings.TrimSpace(req.Description)
+	if len(description) > maxTemplateDescriptionLength {
+		writeError(w, http.StatusBadRequest, "description must be at most 200 characters")
+		return model.DietaryTemplate{}, false
+	}
+	allergies, ok := cleanAllergies(w, req.Allergies, stored)
+	if !ok {
+		return model.DietaryTemplate{}, false
+	}
+	return model.DietaryTemplate{
+		Name:             name,
+		Description:      description,
+		Allergies:        allergies,
+		DietGoals:        cleanList(req.DietGoals),
+		AvoidIngredients: cleanList(req.AvoidIngredients),
+	}, true
+}
+
+func writeTemplateError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		writeError(w, http.StatusNotFound, "template not found")
+	case errors.Is(err, service.ErrTemplateLimit):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeInternalError(w, r, message, err)
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTemplateService struct {
+	service.TemplateService
+	list   func(ctx context.Context, userID string) ([]model.DietaryTemplate, error)
+	create func(ctx context.Context, userID string, tpl model.DietaryTemplate) (*model.DietaryTemplate, error)
+	fork   func(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error)
+}
+
+func (m *mockTemplateService) List(ctx context.Context, userID string) ([]model.DietaryTemplate, error) {
+	return m.list(ctx, userID)
+}
+
+func (m *mockTemplateService) Create(ctx context.Context, userID string, tpl model.DietaryTemplate) (*model.DietaryTemplate, error) {
+	return m.create(ctx, userID, tpl)
+}
+
+func (m *mockTemplateService) Fork(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error) {
+	return m.fork(ctx, userID, ref)
+}
+
+func withURLParams(req *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTemplateHandlerListIncludesCallerTemplates(t *testing.T) {
+	var gotUser string
+	h := &TemplateHandler{Templates: &mockTemplateService{list: func(_ context.Context, userID string) ([]model.DietaryTemplate, error) {
+		gotUser = userID
+		return model.SortedDietaryTemplates(), nil
+	}}}
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/api/dietary-templates", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, gotUser)
+	require.Contains(t, rr.Body.String(), `"key":"vegan"`)
+
+	rr = httptest.NewRecorder()
+	h.List(rr, signedIn(httptest.NewRequest(http.MethodGet, "/api/dietary-templates", nil)))
+	require.Equal(t, "auth0|abc", gotUser)
+}
+
+func TestTemplateHandlerCreate(t *testing.T) {
+	h := &TemplateHandler{Templates: &mockTemplateService{create: func(_ context.Context, userID string, tpl model.DietaryTemplate) (*model.DietaryTemplate, error) {
+		require.Equal(t, "auth0|abc", userID)
+		require.Equal(t, "Low FODMAP", tpl.Name)
+		require.Equal(t, []string{"onion", "garlic"}, tpl.AvoidIngredients)
+		tpl.Key = "tpl-1"
+		tpl.OwnerID = userID
+		return &tpl, nil
+	}}}
+
+	body := `{"name":"Low FODMAP","avoidIngredients":["onion"," garlic ",""]}`
+	rr := httptest.NewRecorder()
+	h.Create(rr, signedIn(httptest.NewRequest(http.MethodPost, "/api/dietary-templates", strings.NewReader(body))))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"status":"created"`)
+	require.Contains(t, rr.Body.String(), `"key":"tpl-1"`)
+
+	for _, invalid := range []string{`{"name":""}`, `{"name":"Low FODMAP","description":"` + strings.Repeat("x", 201) + `"}`, `{"name":"Low FODMAP","key":"vegan"}`} {
+		rr = httptest.NewRecorder()
+		h.Create(rr, signedIn(httptest.NewRequest(http.MethodPost, "/api/dietary-templates", strings.NewReader(invalid))))
+		require.Equal(t, http.StatusBadRequest, rr.Code, invalid)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Create(rr, httptest.NewRequest(http.MethodPost, "/api/dietary-templates", strings.NewReader(body)))
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestTemplateHandlerForkErrors(t *testing.T) {
+	for err, code := range map[error]int{
+		service.ErrTemplateNotFound: http.StatusNotFound,
+		service.ErrTemplateLimit:    http.StatusConflict,
+	} {
+		h := &TemplateHandler{Templates: &mockTemplateService{fork: func(context.Context, string, string) (*model.DietaryTemplate, error) {
+			return nil, err
+		}}}
+		req := withURLParams(httptest.NewRequest(http.MethodPost, "/api/dietary-templates/K7QX2M9P/fork", nil), map[string]string{"template_key": "K7QX2M9P"})
+		rr := httptest.NewRecorder()
+		h.Fork(rr, signedIn(req))
+		require.Equal(t, code, rr.Code)
+	}
+}
+
+func TestTemplateHandlerApply(t *testing.T) {
+	h := &TemplateHandler{Users: &mockAnalyzeUserService{applyTemplate: func(_ context.Context, userID, templateKey string) (*model.User, model.DietaryTemplate, error) {
+		switch templateKey {
+		case "carnivore":
+			return nil, model.DietaryTemplate{}, service.ErrTemplateNotFound
+		case "paleo":
+			return nil, model.DietaryTemplate{}, service.ErrTooManyLayers
+		case "vegan":
+			if userID == "missing" {
+				return nil, model.DietaryTemplate{}, repository.ErrNotFound
+			}
+		}
+		tpl := model.DietaryTemplates[templateKey]
+		return &model.User{ID: userID, DietGoals: tpl.DietGoals}, tpl, nil
+	}}}
+
+	for _, tc := range []struct {
+		userID, templateKey string
+		code                int
+		body                string
+	}{
+		{"user-1", "vegan", http.StatusOK, `"dietGoals":["vegan"]`},
+		{"user-1", "carnivore", http.StatusNotFound, "template not found"},
+		{"user-1", "paleo", http.StatusConflict, "at most 10 templates"},
+		{"missing", "vegan", http.StatusNotFound, "user not found"},
+	} {
+		req := withURLParams(httptest.NewRequest(http.MethodPost, "/api/users/"+tc.userID+"/apply-template/"+tc.templateKey, nil), map[string]string{
+			"user_id":      tc.userID,
+			"template_key": tc.templateKey,
+		})
+		rr := httptest.NewRecorder()
+		h.Apply(rr, req)
+		require.Equal(t, tc.code, rr.Code, tc.templateKey)
+		require.Contains(t, rr.Body.String(), tc.body)
+	}
+}
+
+func TestTemplateHandlerRemove(t *testing.T) {
+	h := &TemplateHandler{Users: &mockAnalyzeUserService{removeTemplate: func(_ context.Context, userID, templateKey string) (*model.User, error) {
+		if templateKey != "vegan" {
+			return nil, service.ErrTemplateNotApplied
+		}
+		user := &model.User{ID: userID, Custom: model.PreferenceLists{Allergies: model.Allergies("peanuts")}}
+		model.MergeLayers(user)
+		return user, nil
+	}}}
+
+	for _, tc := range []struct {
+		templateKey string
+		code        int
+		body        string
+	}{
+		{"vegan", http.StatusOK, `"allergies":{"peanuts":["custom"]}`},
+		{"keto", http.StatusNotFound, "template not applied"},
+	} {
+		req := withURLParams(httptest.NewRequest(http.MethodDelete, "/api/users/user-1/apply-template/"+tc.templateKey, nil), map[string]string{
+			"user_id":      "user-1",
+			"template_key": tc.templateKey,
+		})
+		rr := httptest.NewRecorder()
+		h.Remove(rr, req)
+		require.Equal(t, tc.code, rr.Code, tc.templateKey)
+		require.Contains(t, rr.Body.String(), tc.body)
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"net/mail"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -16,13 +17,23 @@ type UserHandler struct {
 }
 
 type createUserRequest struct {
-	ID               string   `json:"id"`
-	Email            string   `json:"email"`
-	Name             string   `json:"name"`
-	Picture          string   `json:"picture"`
-	Allergies        []string `json:"allergies"`
-	DietGoals        []string `json:"dietGoals"`
-	AvoidIngredients []string `json:"avoidIngredients"`
+	ID               string         `json:"id"`
+	Email            string         `json:"email"`
+	Name             string         `json:"name"`
+	Picture          string         `json:"picture"`
+	Allergies        []allergyInput `json:"allergies"`
+	DietGoals        []string       `json:"dietGoals"`
+	AvoidIngredients []string       `json:"avoidIngredients"`
+	HomeRegion       string         `json:"homeRegion"`
+}
+
+type updatePreferencesRequest struct {
+	Allergies        []allergyInput `json:"allergies"`
+	DietGoals        []string       `json:"dietGoals"`
+	AvoidIngredients []string       `json:"avoidIngredients"`
+	// HomeRegion is nil when the field is left out, which keeps the stored
+	// region; an empty string clears it.
+	HomeRegion *string `json:"homeRegion"`
 }
 
 func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
@@ -81,14 +92,24 @@ func (h *UserHandler) Upsert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	homeRegion, ok := readHomeRegion(w, req.HomeRegion)
+	if !ok {
+		return
+	}
+	allergies, ok := cleanAllergies(w, req.Allergies, nil)
+	if !ok {
+		return
+	}
+
 	created, err := h.Users.Upsert(r.Context(), &model.User{
 		ID:               req.ID,
 		Email:            req.Email,
 		Name:             req.Name,
 		Picture:          req.Picture,
-		Allergies:        req.Allergies,
+		Allergies:        allergies,
 		DietGoals:        req.DietGoals,
 		AvoidIngredients: req.AvoidIngredients,
+		HomeRegion:       homeRegion,
 	})
 	if err != nil {
 		writeInternalError(w, r, "failed to upsert user", err)
@@ -105,10 +126,57 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var preferences model.UserPreferences
-	if ok := readJSON(w, r, &preferences); !ok {
+	var req updatePreferencesRequest
+	if ok := readJSON(w, r, &req); !ok {
 		return
 	}
+	// Clients that predate home regions and allergy severities must not
+	// clear them on every save, so the stored user fills in what they leave
+	// out. It is loaded at most once, and only when needed.
+	var current *model.User
+	loadCurrent := func() (*model.User, bool) {
+		if current != nil {
+			return current, true
+		}
+		user, err := h.Users.GetByID(r.Context(), userID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				writeError(w, http.StatusNotFound, "user not found")
+				return nil, false
+			}
+			writeInternalError(w, r, "failed to update preferences", err)
+			return nil, false
+		}
+		current = user
+		return current, true
+	}
+
+	allergies, ok := cleanAllergies(w, req.Allergies, func() ([]model.Allergy, bool) {
+		user, ok := loadCurrent()
+		if !ok {
+			return nil, false
+		}
+		return user.Custom.Allergies, true
+	})
+	if !ok {
+		return
+	}
+	preferences := model.UserPreferences{
+		Allergies:        allergies,
+		DietGoals:        req.DietGoals,
+		AvoidIngredients: req.AvoidIngredients,
+	}
+	if req.HomeRegion != nil {
+		if preferences.HomeRegion, ok = readHomeRegion(w, *req.HomeRegion); !ok {
+			return
+		}
+	} else {
+		user, ok := loadCurrent()
+		if !ok {
+			return
+		}
+		preferences.HomeRegion = user.HomeRegion
+	}
 
 	updated, err := h.Users.UpdatePreferences(r.Context(), userID, preferences)
 	if err != nil {
@@ -122,3 +190,47 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{"user": updated})
 }
+
+// PreferenceHistory lists the versions of the user's preferences, newest
+// first, so a scan's preferenceVersion can be looked up.
+func (h *UserHandler) PreferenceHistory(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+	if strings.TrimSpace(userID) == "" {
+		writeError(w, http.StatusBadRequest, "missing user_id")
+		return
+	}
+
+	limit := 20
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsed > model.MaxPreferenceHistory {
+			parsed = model.MaxPreferenceHistory
+		}
+		limit = parsed
+	}
+
+	history, err := h.Users.ListPreferenceHistory(r.Context(), userID, limit)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch preference history", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"history": history})
+}
+
+// readHomeRegion normalizes an optional home region, writing a 400 response
+// when it is not one of the supported regions.
+func readHomeRegion(w http.ResponseWriter, region string) (string, bool) {
+	if strings.TrimSpace(region) == "" {
+		return "", true
+	}
+	normalized, ok := model.NormalizeRegion(region)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "homeRegion must be one of "+strings.Join(model.Regions, ", "))
+		return "", false
+	}
+	return normalized, true
+}
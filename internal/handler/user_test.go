@@ -19,6 +19,7 @@ type mockUserRepo struct {
 	getByID           func(ctx context.Context, userID string) (*model.User, error)
 	upsert            func(ctx context.Context, user *model.User) (*model.User, error)
 	updatePreferences func(ctx context.Context, userID string, preferences model.UserPreferences) (*model.User, error)
+	history           func(ctx context.Context, userID string, limit int) ([]model.PreferenceVersion, error)
 }
 
 func (m *mockUserRepo) GetByID(ctx context.Context, userID string) (*model.User, error) {
@@ -33,6 +34,18 @@ func (m *mockUserRepo) UpdatePreferences(ctx context.Context, userID string, pre
 	return m.updatePreferences(ctx, userID, preferences)
 }
 
+func (m *mockUserRepo) AddTemplateLayer(context.Context, string, model.TemplateLayer) error {
+	return nil
+}
+
+func (m *mockUserRepo) RemoveTemplateLayer(context.Context, string, string) error {
+	return nil
+}
+
+func (m *mockUserRepo) ListPreferenceHistory(ctx context.Context, userID string, limit int) ([]model.PreferenceVersion, error) {
+	return m.history(ctx, userID, limit)
+}
+
 func TestUserHandlerGetByID(t *testing.T) {
 	h := &UserHandler{Users: &mockUserRepo{
 		getByID: func(_ context.Context, userID string) (*model.User, error) {
@@ -85,7 +98,7 @@ func TestUserHandlerUpdatePreferencesNotFound(t *testing.T) {
 		},
 	}}
 
-	body, _ := json.Marshal(map[string]interface{}{"dietGoals": []string{"keto"}})
+	body, _ := json.Marshal(map[string]interface{}{"dietGoals": []string{"keto"}, "homeRegion": ""})
 	req := httptest.NewRequest(http.MethodPost, "/api/users/user-1/preferences", bytes.NewBuffer(body))
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add("user_id", "user-1")
@@ -135,3 +148,179 @@ func TestUserHandlerUpsertInvalidEmail(t *testing.T) {
 	h.Upsert(rr, req)
 	require.Equal(t, http.StatusBadRequest, rr.Code)
 }
+
+func TestUserHandlerUpdatePreferencesNormalizesHomeRegion(t *testing.T) {
+	h := &UserHandler{Users: &mockUserRepo{
+		getByID: nil,
+		upsert:  nil,
+		updatePreferences: func(_ context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
+			require.Equal(t, "UK", preferences.HomeRegion)
+			return &model.User{ID: userID, HomeRegion: preferences.HomeRegion}, nil
+		},
+	}}
+
+	body, _ := json.Marshal(map[string]interface{}{"homeRegion": " uk "})
+	req := httptest.NewRequest(http.MethodPost, "/api/users/user-1/preferences", bytes.NewBuffer(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user_id", "user-1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	h.UpdatePreferences(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"homeRegion":"UK"`)
+}
+
+func TestUserHandlerUpdatePreferencesKeepsOmittedHomeRegion(t *testing.T) {
+	var got []string
+	h := &UserHandler{Users: &mockUserRepo{
+		getByID: func(_ context.Context, userID string) (*model.User, error) {
+			return &model.User{ID: userID, HomeRegion: "EU"}, nil
+		},
+		updatePreferences: func(_ context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
+			got = append(got, preferences.HomeRegion)
+			return &model.User{ID: userID, HomeRegion: preferences.HomeRegion}, nil
+		},
+	}}
+
+	for _, body := range []string{`{"dietGoals":["keto"]}`, `{"dietGoals":["keto"],"homeRegion":""}`} {
+		req := withURLParams(httptest.NewRequest(http.MethodPost, "/api/users/user-1/preferences", bytes.NewBufferString(body)), map[string]string{"user_id": "user-1"})
+		rr := httptest.NewRecorder()
+		h.UpdatePreferences(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, body)
+	}
+	require.Equal(t, []string{"EU", ""}, got, "only an explicit empty region clears it")
+}
+
+func TestUserHandlerUpsertUnsupportedHomeRegion(t *testing.T) {
+	h := &UserHandler{Users: &mockUserRepo{
+		getByID: nil,
+		upsert: func(_ context.Context, _ *model.User) (*model.User, error) {
+			t.Fatal("repo should not be called")
+			return nil, nil
+		},
+		updatePreferences: nil,
+	}}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":         "user-1",
+		"email":      "user@example.com",
+		"homeRegion": "Mars",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/users", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	h.Upsert(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "homeRegion must be one of")
+}
+
+func TestUserHandlerUpdatePreferencesAllergySeverities(t *testing.T) {
+	var got []model.Allergy
+	h := &UserHandler{Users: &mockUserRepo{
+		getByID: func(_ context.Context, userID string) (*model.User, error) {
+			return &model.User{ID: userID}, nil
+		},
+		updatePreferences: func(_ context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
+			got = preferences.Allergies
+			return &model.User{ID: userID, Allergies: preferences.Allergies}, nil
+		},
+	}}
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"allergies":[" sesame ",{"name":"peanuts","severity":"anaphylactic","mayContain":true},{"name":"lactose","severity":"intolerance"}]}`, http.StatusOK},
+		{`{"allergies":[{"name":"peanuts","severity":"mild"}]}`, http.StatusBadRequest},
+		{`{"allergies":[{"name":"peanuts","level":"allergy"}]}`, http.StatusBadRequest},
+	} {
+		req := withURLParams(httptest.NewRequest(http.MethodPost, "/api/users/user-1/preferences", bytes.NewBufferString(tc.body)), map[string]string{"user_id": "user-1"})
+		rr := httptest.NewRecorder()
+		h.UpdatePreferences(rr, req)
+		require.Equal(t, tc.code, rr.Code, tc.body)
+	}
+
+	// Bare names, the format before severities, read as plain allergies.
+	require.Equal(t, []model.Allergy{
+		{Name: "sesame", Severity: model.SeverityAllergy},
+		{Name: "peanuts", Severity: model.SeverityAnaphylactic, MayContain: true},
+		{Name: "lactose", Severity: model.SeverityIntolerance},
+	}, got)
+}
+
+func TestUserHandlerUpdatePreferencesKeepsStoredSeverities(t *testing.T) {
+	var got []model.Allergy
+	loads := 0
+	h := &UserHandler{Users: &mockUserRepo{
+		getByID: func(_ context.Context, userID string) (*model.User, error) {
+			loads++
+			return &model.User{ID: userID, HomeRegion: "EU", Custom: model.PreferenceLists{Allergies: []model.Allergy{
+				{Name: "peanuts", Severity: model.SeverityAnaphylactic, MayContain: true},
+				{Name: "lactose", Severity: model.SeverityIntolerance},
+			}}}, nil
+		},
+		updatePreferences: func(_ context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
+			got = preferences.Allergies
+			return &model.User{ID: userID, Allergies: preferences.Allergies}, nil
+		},
+	}}
+
+	// An older client saves the names it was given back; an object still
+	// sets the severity it names.
+	body := `{"allergies":["Peanuts",{"name":"lactose","severity":"allergy"},"sesame"]}`
+	req := withURLParams(httptest.NewRequest(http.MethodPost, "/api/users/user-1/preferences", bytes.NewBufferString(body)), map[string]string{"user_id": "user-1"})
+	rr := httptest.NewRecorder()
+	h.UpdatePreferences(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, []model.Allergy{
+		{Name: "Peanuts", Severity: model.SeverityAnaphylactic, MayContain: true},
+		{Name: "lactose", Severity: model.SeverityAllergy},
+		{Name: "sesame", Severity: model.SeverityAllergy},
+	}, got)
+	require.Equal(t, 1, loads, "the stored user is loaded once for allergies and home region")
+}
+
+func TestUserHandlerPreferenceHistory(t *testing.T) {
+	var gotLimit int
+	h := &UserHandler{Users: &mockUserRepo{
+		history: func(_ context.Context, userID string, limit int) ([]model.PreferenceVersion, error) {
+			require.Equal(t, "user-1", userID)
+			gotLimit = limit
+			return []model.PreferenceVersion{
+				{Version: 2, Source: "vegan", Action: model.PreferenceTemplateApplied, Templates: []string{"vegan"}},
+				{Version: 1, Source: model.PreferenceSourceManual, Action: model.PreferenceCreated, Templates: []string{}},
+			}, nil
+		},
+	}}
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		limit int
+	}{
+		{"", http.StatusOK, 20},
+		{"?limit=500", http.StatusOK, model.MaxPreferenceHistory},
+		{"?limit=0", http.StatusBadRequest, 0},
+	} {
+		gotLimit = 0
+		req := withURLParams(httptest.NewRequest(http.MethodGet, "/api/users/user-1/preferences/history"+tc.query, nil), map[string]string{"user_id": "user-1"})
+		rr := httptest.NewRecorder()
+		h.PreferenceHistory(rr, req)
+		require.Equal(t, tc.code, rr.Code, tc.query)
+		require.Equal(t, tc.limit, gotLimit, tc.query)
+	}
+
+	req := withURLParams(httptest.NewRequest(http.MethodGet, "/api/users/user-1/preferences/history", nil), map[string]string{"user_id": "user-1"})
+	rr := httptest.NewRecorder()
+	h.PreferenceHistory(rr, req)
+
+	var body struct {
+		History []model.PreferenceVersion `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.History, 2)
+	require.Equal(t, "vegan", body.History[0].Source)
+	require.Equal(t, model.PreferenceCreated, body.History[1].Action)
+}
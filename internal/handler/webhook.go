@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+	"github.com/safebites/backend-go/internal/webhook"
+)
+
+const maxWebhookAppNameLength = 100
+
+// WebhookHandler manages the signed-in user's webhook subscriptions. Routes
+// using it must sit behind RequireAuth.
+type WebhookHandler struct {
+	Webhooks service.WebhookService
+	// AllowPrivateURLs accepts plain http and loopback or private hosts, for
+	// receivers on a developer's machine. Only set it in development.
+	AllowPrivateURLs bool
+}
+
+type createWebhookRequest struct {
+	AppName string   `json:"appName"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	var req createWebhookRequest
+	if ok := readJSON(w, r, &req); !ok {
+		return
+	}
+	appName := strings.TrimSpace(req.AppName)
+	if appName == "" {
+		writeError(w, http.StatusBadRequest, "appName is required")
+		return
+	}
+	if len(appName) > maxWebhookAppNameLength {
+		writeError(w, http.StatusBadRequest, "appName must be at most 100 characters")
+		return
+	}
+	endpoint := strings.TrimSpace(req.URL)
+	if !validWebhookURL(endpoint, h.AllowPrivateURLs) {
+		if h.AllowPrivateURLs {
+			writeError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		} else {
+			writeError(w, http.StatusBadRequest, "url must be an absolute https URL on a public host")
+		}
+		return
+	}
+	events, ok := webhookEvents(req.Events)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "events must only contain "+strings.Join(model.WebhookEventTypes, ", "))
+		return
+	}
+
+	created, err := h.Webhooks.Subscribe(r.Context(), userID, appName, endpoint, events)
+	if err != nil {
+		writeInternalError(w, r, "failed to create webhook", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscription": created,
+		"status":       "created",
+	})
+}
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	subs, err := h.Webhooks.ListSubscriptions(r.Context(), userID)
+	if err != nil {
+		writeInternalError(w, r, "failed to fetch webhooks", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"subscriptions": subs})
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.Webhooks.Unsubscribe(r.Context(), userID, chi.URLParam(r, "subscription_id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		writeInternalError(w, r, "failed to delete webhook", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// Deliveries lists the delivery log of a subscription, newest first.
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsed > 200 {
+			parsed = 200
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.Webhooks.ListDeliveries(r.Context(), userID, chi.URLParam(r, "subscription_id"), limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		writeInternalError(w, r, "failed to fetch webhook deliveries", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+// Replay queues a logged delivery to be sent again.
+func (h *WebhookHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "delivery_id"), 10, 64)
+	if err != nil || deliveryID <= 0 {
+		writeError(w, http.StatusBadRequest, "delivery_id must be a positive integer")
+		return
+	}
+
+	delivery, err := h.Webhooks.Replay(r.Context(), userID, deliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "delivery not found")
+			return
+		}
+		writeInternalError(w, r, "failed to replay webhook delivery", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"delivery": delivery,
+		"status":   "queued",
+	})
+}
+
+// validWebhookURL rejects endpoints we must not call from inside our
+// network. Hostnames are checked again by the dispatcher when it connects,
+// since they can resolve anywhere.
+func validWebhookURL(raw string, allowPrivate bool) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	if allowPrivate {
+		return u.Scheme == "http" || u.Scheme == "https"
+	}
+	if u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return webhook.PublicIP(ip)
+	}
+	return true
+}
+
+// webhookEvents validates and deduplicates the requested event types; none
+// means every event.
+func webhookEvents(requested []string) ([]string, bool) {
+	if len(requested) == 0 {
+		return slices.Clone(model.WebhookEventTypes), true
+	}
+	events := make([]string, 0, len(requested))
+	for _, e := range requested {
+		if !slices.Contains(model.WebhookEventTypes, e) {
+			return nil, false
+		}
+		if !slices.Contains(events, e) {
+			events = append(events, e)
+		}
+	}
+	return events, true
+}
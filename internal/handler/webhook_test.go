@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+type mockWebhookService struct {
+	service.WebhookService
+	subscribe func(ctx context.Context, userID, appName, url string, events []string) (*model.WebhookSubscription, error)
+	replay    func(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error)
+}
+
+func (m *mockWebhookService) Subscribe(ctx context.Context, userID, appName, url string, events []string) (*model.WebhookSubscription, error) {
+	return m.subscribe(ctx, userID, appName, url, events)
+}
+
+func (m *mockWebhookService) Replay(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error) {
+	return m.replay(ctx, userID, deliveryID)
+}
+
+type publishedEvent struct {
+	userID    string
+	eventType string
+	data      interface{}
+}
+
+type recordingPublisher struct {
+	events []publishedEvent
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, userID, eventType string, data interface{}) {
+	p.events = append(p.events, publishedEvent{userID: userID, eventType: eventType, data: data})
+}
+
+func webhookRequest(method, target string, body interface{}, userID string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	if userID != "" {
+		req = req.WithContext(middleware.WithUserID(req.Context(), userID))
+	}
+	return req
+}
+
+func TestWebhookHandlerCreate(t *testing.T) {
+	var gotEvents []string
+	h := &WebhookHandler{Webhooks: &mockWebhookService{
+		subscribe: func(_ context.Context, userID, appName, url string, events []string) (*model.WebhookSubscription, error) {
+			require.Equal(t, "auth0|abc", userID)
+			require.Equal(t, "Pantry", appName)
+			require.Equal(t, "https://pantry.example.com/hook", url)
+			gotEvents = events
+			return &model.WebhookSubscription{ID: "sub-1", Secret: "whsec_x", Events: events}, nil
+		},
+	}}
+
+	rr := httptest.NewRecorder()
+	h.Create(rr, webhookRequest(http.MethodPost, "/api/webhooks", map[string]interface{}{
+		"appName": " Pantry ",
+		"url":     "https://pantry.example.com/hook",
+	}, "auth0|abc"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, model.WebhookEventTypes, gotEvents)
+	require.Contains(t, rr.Body.String(), "whsec_x")
+}
+
+func TestWebhookHandlerCreateValidation(t *testing.T) {
+	h := &WebhookHandler{Webhooks: &mockWebhookService{
+		subscribe: func(context.Context, string, string, string, []string) (*model.WebhookSubscription, error) {
+			t.Fatal("subscribe should not be called for invalid input")
+			return nil, nil
+		},
+	}}
+
+	cases := map[string]map[string]interface{}{
+		"missing app":    {"url": "https://pantry.example.com/hook"},
+		"relative url":   {"appName": "Pantry", "url": "/hook"},
+		"ftp url":        {"appName": "Pantry", "url": "ftp://pantry.example.com/hook"},
+		"plain http":     {"appName": "Pantry", "url": "http://pantry.example.com/hook"},
+		"loopback":       {"appName": "Pantry", "url": "https://127.0.0.1:8080/hook"},
+		"localhost":      {"appName": "Pantry", "url": "https://localhost/hook"},
+		"metadata":       {"appName": "Pantry", "url": "https://169.254.169.254/latest/meta-data"},
+		"private":        {"appName": "Pantry", "url": "https://[fd00::1]/hook"},
+		"unknown event":  {"appName": "Pantry", "url": "https://pantry.example.com/hook", "events": []string{"scan.deleted"}},
+		"unknown fields": {"appName": "Pantry", "url": "https://pantry.example.com/hook", "secret": "mine"},
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.Create(rr, webhookRequest(http.MethodPost, "/api/webhooks", body, "auth0|abc"))
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	h.Create(rr, webhookRequest(http.MethodPost, "/api/webhooks", cases["missing app"], ""))
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestWebhookHandlerCreateAllowsLocalReceiversInDev(t *testing.T) {
+	h := &WebhookHandler{AllowPrivateURLs: true, Webhooks: &mockWebhookService{
+		subscribe: func(_ context.Context, _, _, url string, events []string) (*model.WebhookSubscription, error) {
+			return &model.WebhookSubscription{ID: "sub-1", URL: url, Events: events}, nil
+		},
+	}}
+
+	rr := httptest.NewRecorder()
+	h.Create(rr, webhookRequest(http.MethodPost, "/api/webhooks", map[string]interface{}{
+		"appName": "Pantry",
+		"url":     "http://localhost:9000/hook",
+	}, "auth0|abc"))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestWebhookHandlerReplay(t *testing.T) {
+	h := &WebhookHandler{Webhooks: &mockWebhookService{
+		replay: func(_ context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error) {
+			if deliveryID == 404 {
+				return nil, repository.ErrNotFound
+			}
+			replayOf := deliveryID
+			return &model.WebhookDelivery{ID: 12, ReplayOf: &replayOf, Status: model.DeliveryPending}, nil
+		},
+	}}
+
+	replay := func(id string) *httptest.ResponseRecorder {
+		req := webhookRequest(http.MethodPost, "/api/webhooks/deliveries/"+id+"/replay", nil, "auth0|abc")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("delivery_id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		h.Replay(rr, req)
+		return rr
+	}
+
+	rr := replay("7")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Delivery model.WebhookDelivery `json:"delivery"`
+		Status   string                `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "queued", resp.Status)
+	require.Equal(t, int64(7), *resp.Delivery.ReplayOf)
+
+	require.Equal(t, http.StatusNotFound, replay("404").Code)
+	require.Equal(t, http.StatusBadRequest, replay("abc").Code)
+}
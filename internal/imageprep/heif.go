@@ -0,0 +1,388 @@
+package imageprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// heifBox is one ISOBMFF box. offset is the absolute position of payload in
+// the file so item extents can be mapped back onto the original bytes.
+type heifBox struct {
+	typ     string
+	payload []byte
+	offset  int
+}
+
+type heifExtent struct {
+	start, end int
+}
+
+// stripHEIF checks that data is a HEIF container with a primary image item
+// and returns a copy in which the Exif and XMP items are zeroed. Blanking the
+// payloads in place keeps every iloc offset valid, so the coded image is
+// untouched. The dimensions come from the primary item's ispe property.
+func stripHEIF(data []byte) (out []byte, width, height int, err error) {
+	top, err := readBoxes(data, 0)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	meta, ok := findBox(top, "meta")
+	if !ok || len(meta.payload) < 4 {
+		return nil, 0, 0, fmt.Errorf("%w: HEIF container has no meta box", ErrInvalidImage)
+	}
+	children, err := readBoxes(meta.payload[4:], meta.offset+4)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	pitm, ok := findBox(children, "pitm")
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("%w: missing primary item", ErrInvalidImage)
+	}
+	primary, err := parsePrimaryItem(pitm)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	iinf, ok := findBox(children, "iinf")
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("%w: missing item info", ErrInvalidImage)
+	}
+	types, err := parseItemTypes(iinf)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if t, ok := types[primary]; !ok || isHEIFMetadata(t) {
+		return nil, 0, 0, fmt.Errorf("%w: primary item %d is not an image", ErrInvalidImage, primary)
+	}
+
+	idat := -1
+	if b, ok := findBox(children, "idat"); ok {
+		idat = b.offset
+	}
+	iloc, ok := findBox(children, "iloc")
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("%w: missing item locations", ErrInvalidImage)
+	}
+	extents, err := parseItemLocations(iloc, idat, len(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if _, ok := extents[primary]; !ok {
+		return nil, 0, 0, fmt.Errorf("%w: primary item %d has no data", ErrInvalidImage, primary)
+	}
+
+	iprp, ok := findBox(children, "iprp")
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("%w: missing item properties", ErrInvalidImage)
+	}
+	width, height, err = primaryDimensions(iprp, primary)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	out = bytes.Clone(data)
+	for id, t := range types {
+		if !isHEIFMetadata(t) {
+			continue
+		}
+		locs, ok := extents[id]
+		if !ok {
+			continue
+		}
+		for _, e := range locs {
+			if e.start < 0 {
+				return nil, 0, 0, fmt.Errorf("%w: metadata item %d is not stored inline", ErrInvalidImage, id)
+			}
+			clear(out[e.start:e.end])
+		}
+	}
+	return out, width, height, nil
+}
+
+// isHEIFMetadata reports whether an item type carries Exif or XMP; XMP is
+// stored as a "mime" item.
+func isHEIFMetadata(itemType string) bool {
+	return itemType == "Exif" || itemType == "mime"
+}
+
+func readBoxes(data []byte, base int) ([]heifBox, error) {
+	var boxes []heifBox
+	for pos := 0; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, fmt.Errorf("%w: truncated box header", ErrInvalidImage)
+		}
+		size := uint64(binary.BigEndian.Uint32(data[pos : pos+4]))
+		typ := string(data[pos+4 : pos+8])
+		header := 8
+		switch size {
+		case 0:
+			size = uint64(len(data) - pos)
+		case 1:
+			if pos+16 > len(data) {
+				return nil, fmt.Errorf("%w: truncated %q box header", ErrInvalidImage, typ)
+			}
+			size = binary.BigEndian.Uint64(data[pos+8 : pos+16])
+			header = 16
+		}
+		if size < uint64(header) || size > uint64(len(data)-pos) {
+			return nil, fmt.Errorf("%w: %q box overruns its parent", ErrInvalidImage, typ)
+		}
+		end := pos + int(size)
+		boxes = append(boxes, heifBox{typ: typ, payload: data[pos+header : end], offset: base + pos + header})
+		pos = end
+	}
+	return boxes, nil
+}
+
+func findBox(boxes []heifBox, typ string) (heifBox, bool) {
+	for _, b := range boxes {
+		if b.typ == typ {
+			return b, true
+		}
+	}
+	return heifBox{}, false
+}
+
+// heifReader reads big-endian fields and remembers the first overrun, so a
+// parser can check once at the end instead of after every field.
+type heifReader struct {
+	b   []byte
+	pos int
+	bad bool
+}
+
+func (r *heifReader) uint(n int) uint64 {
+	if n == 0 {
+		return 0
+	}
+	if r.bad || r.pos+n > len(r.b) {
+		r.bad = true
+		return 0
+	}
+	var v uint64
+	for _, c := range r.b[r.pos : r.pos+n] {
+		v = v<<8 | uint64(c)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *heifReader) fourCC() string {
+	if r.bad || r.pos+4 > len(r.b) {
+		r.bad = true
+		return ""
+	}
+	s := string(r.b[r.pos : r.pos+4])
+	r.pos += 4
+	return s
+}
+
+func (r *heifReader) skipString() {
+	i := bytes.IndexByte(r.b[min(r.pos, len(r.b)):], 0)
+	if r.bad || i < 0 {
+		r.bad = true
+		return
+	}
+	r.pos += i + 1
+}
+
+func parsePrimaryItem(pitm heifBox) (uint32, error) {
+	r := &heifReader{b: pitm.payload}
+	version := r.uint(1)
+	r.uint(3)
+	var id uint64
+	if version == 0 {
+		id = r.uint(2)
+	} else {
+		id = r.uint(4)
+	}
+	if r.bad {
+		return 0, fmt.Errorf("%w: truncated pitm box", ErrInvalidImage)
+	}
+	return uint32(id), nil
+}
+
+// parseItemTypes maps item IDs to their four-character item type.
+func parseItemTypes(iinf heifBox) (map[uint32]string, error) {
+	r := &heifReader{b: iinf.payload}
+	version := r.uint(1)
+	r.uint(3)
+	if version == 0 {
+		r.uint(2)
+	} else {
+		r.uint(4)
+	}
+	if r.bad {
+		return nil, fmt.Errorf("%w: truncated iinf box", ErrInvalidImage)
+	}
+	entries, err := readBoxes(iinf.payload[r.pos:], iinf.offset+r.pos)
+	if err != nil {
+		return nil, err
+	}
+	types := map[uint32]string{}
+	for _, e := range entries {
+		if e.typ != "infe" {
+			continue
+		}
+		er := &heifReader{b: e.payload}
+		v := er.uint(1)
+		er.uint(3)
+		if v < 2 {
+			// Versions 0 and 1 predate item types and never describe images.
+			continue
+		}
+		var id uint64
+		if v == 2 {
+			id = er.uint(2)
+		} else {
+			id = er.uint(4)
+		}
+		er.uint(2)
+		t := er.fourCC()
+		er.skipString()
+		if er.bad {
+			return nil, fmt.Errorf("%w: truncated infe box", ErrInvalidImage)
+		}
+		types[uint32(id)] = t
+	}
+	return types, nil
+}
+
+// parseItemLocations resolves each item's extents to absolute file ranges.
+// Extents that point into another item (construction method 2) get a start
+// of -1; they are only acceptable for image items, which are never blanked.
+func parseItemLocations(iloc heifBox, idat, fileLen int) (map[uint32][]heifExtent, error) {
+	r := &heifReader{b: iloc.payload}
+	version := r.uint(1)
+	r.uint(3)
+	if version > 2 {
+		return nil, fmt.Errorf("%w: unsupported iloc version %d", ErrInvalidImage, version)
+	}
+	sizes := r.uint(2)
+	offsetSize, lengthSize := int(sizes>>12&0xF), int(sizes>>8&0xF)
+	baseSize, indexSize := int(sizes>>4&0xF), int(sizes&0xF)
+	if version == 0 {
+		indexSize = 0
+	}
+	var count uint64
+	if version < 2 {
+		count = r.uint(2)
+	} else {
+		count = r.uint(4)
+	}
+
+	locs := map[uint32][]heifExtent{}
+	for i := uint64(0); i < count && !r.bad; i++ {
+		var id uint64
+		if version < 2 {
+			id = r.uint(2)
+		} else {
+			id = r.uint(4)
+		}
+		method := uint64(0)
+		if version > 0 {
+			method = r.uint(2) & 0xF
+		}
+		r.uint(2)
+		base := r.uint(baseSize)
+		extentCount := r.uint(2)
+		for j := uint64(0); j < extentCount && !r.bad; j++ {
+			r.uint(indexSize)
+			offset, length := r.uint(offsetSize), r.uint(lengthSize)
+			e := heifExtent{start: -1, end: -1}
+			switch method {
+			case 0, 1:
+				if base > uint64(fileLen) || offset > uint64(fileLen) {
+					return nil, fmt.Errorf("%w: item %d extent lies outside the file", ErrInvalidImage, id)
+				}
+				start := base + offset
+				if method == 1 {
+					if idat < 0 {
+						return nil, fmt.Errorf("%w: item %d refers to a missing idat box", ErrInvalidImage, id)
+					}
+					start += uint64(idat)
+				}
+				if length == 0 || start > uint64(fileLen) || length > uint64(fileLen)-start {
+					return nil, fmt.Errorf("%w: item %d extent lies outside the file", ErrInvalidImage, id)
+				}
+				e = heifExtent{start: int(start), end: int(start + length)}
+			case 2:
+			default:
+				return nil, fmt.Errorf("%w: item %d has unknown construction method %d", ErrInvalidImage, id, method)
+			}
+			locs[uint32(id)] = append(locs[uint32(id)], e)
+		}
+	}
+	if r.bad {
+		return nil, fmt.Errorf("%w: truncated iloc box", ErrInvalidImage)
+	}
+	return locs, nil
+}
+
+// primaryDimensions returns the size from the ispe property associated with
+// the primary item, rejecting sizes the decoders downstream would choke on.
+func primaryDimensions(iprp heifBox, primary uint32) (int, int, error) {
+	children, err := readBoxes(iprp.payload, iprp.offset)
+	if err != nil {
+		return 0, 0, err
+	}
+	ipco, ok := findBox(children, "ipco")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: missing ipco box", ErrInvalidImage)
+	}
+	props, err := readBoxes(ipco.payload, ipco.offset)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, ipma := range children {
+		if ipma.typ != "ipma" {
+			continue
+		}
+		r := &heifReader{b: ipma.payload}
+		version := r.uint(1)
+		flags := r.uint(3)
+		count := r.uint(4)
+		for i := uint64(0); i < count && !r.bad; i++ {
+			var id uint64
+			if version < 1 {
+				id = r.uint(2)
+			} else {
+				id = r.uint(4)
+			}
+			associations := r.uint(1)
+			for j := uint64(0); j < associations && !r.bad; j++ {
+				var index uint64
+				if flags&1 != 0 {
+					index = r.uint(2) & 0x7FFF
+				} else {
+					index = r.uint(1) & 0x7F
+				}
+				if uint32(id) != primary || index == 0 || index > uint64(len(props)) {
+					continue
+				}
+				if p := props[index-1]; p.typ == "ispe" {
+					return ispeDimensions(p)
+				}
+			}
+		}
+		if r.bad {
+			return 0, 0, fmt.Errorf("%w: truncated ipma box", ErrInvalidImage)
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: primary item %d has no dimensions", ErrInvalidImage, primary)
+}
+
+func ispeDimensions(ispe heifBox) (int, int, error) {
+	r := &heifReader{b: ispe.payload}
+	r.uint(4)
+	w, h := r.uint(4), r.uint(4)
+	if r.bad {
+		return 0, 0, fmt.Errorf("%w: truncated ispe box", ErrInvalidImage)
+	}
+	if w == 0 || h == 0 || w*h > maxPixels {
+		return 0, 0, fmt.Errorf("%w: %dx%d is outside the supported size", ErrInvalidImage, w, h)
+	}
+	return int(w), int(h), nil
+}
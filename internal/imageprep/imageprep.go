@@ -0,0 +1,210 @@
+// Package imageprep normalizes uploaded label photos before they are sent to
+// the vision model: it checks that the bytes really are the declared image
+// type, applies the EXIF orientation, downscales large photos, and re-encodes
+// them, which drops EXIF and GPS metadata. HEIC/HEIF photos, which have no
+// decoder here, keep their pixels but have their metadata items blanked. It
+// also computes the perceptual hashes used to recognize repeated uploads of
+// the same label.
+package imageprep
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/safebites/backend-go/internal/observability"
+)
+
+const (
+	DefaultMaxDimension = 1600
+	DefaultJPEGQuality  = 85
+	// maxPixels rejects images whose header claims dimensions that would
+	// exhaust memory when decoded.
+	maxPixels = 60_000_000
+)
+
+// ErrInvalidImage is returned, wrapped with details, when the bytes are not a
+// decodable image of the declared type.
+var ErrInvalidImage = errors.New("invalid image")
+
+type Config struct {
+	// MaxDimension caps the longer side of the processed image, in pixels.
+	MaxDimension int
+	JPEGQuality  int
+}
+
+type Preprocessor struct {
+	cfg Config
+}
+
+func New(cfg Config) *Preprocessor {
+	if cfg.MaxDimension <= 0 {
+		cfg.MaxDimension = DefaultMaxDimension
+	}
+	if cfg.JPEGQuality <= 0 || cfg.JPEGQuality > 100 {
+		cfg.JPEGQuality = DefaultJPEGQuality
+	}
+	return &Preprocessor{cfg: cfg}
+}
+
+// Image is a processed upload. HEIC/HEIF images cannot be decoded here, so
+// their container is parsed instead: the Exif and XMP items are blanked and
+// the image is sent at its original size with Processed unset.
+type Image struct {
+	Bytes          []byte
+	MimeType       string
+	Width          int
+	Height         int
+	OriginalBytes  int
+	OriginalWidth  int
+	OriginalHeight int
+	// Orientation is the EXIF orientation (1-8) that was applied, or 0.
+	Orientation int
+	Processed   bool
+}
+
+// Process verifies data against declaredMime and returns the normalized
+// image. An empty declaredMime accepts whatever type the bytes contain.
+func (p *Preprocessor) Process(ctx context.Context, data []byte, declaredMime string) (*Image, error) {
+	_, span := observability.StartAgentSpan(ctx, "ImagePreprocess")
+	defer span.End()
+
+	img, err := p.process(data, declaredMime)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetImageBytes(img.OriginalBytes, len(img.Bytes))
+	span.SetImageDimensions(img.OriginalWidth, img.OriginalHeight, img.Width, img.Height)
+	return img, nil
+}
+
+func (p *Preprocessor) process(data []byte, declaredMime string) (*Image, error) {
+	detected := sniff(data)
+	if detected == "" {
+		return nil, fmt.Errorf("%w: unrecognized image format", ErrInvalidImage)
+	}
+	if declared := normalizeMime(declaredMime); declared != "" && family(declared) != family(detected) {
+		return nil, fmt.Errorf("%w: declared %s but content is %s", ErrInvalidImage, declared, detected)
+	}
+
+	out := &Image{Bytes: data, MimeType: detected, OriginalBytes: len(data)}
+	if detected == "image/heic" || detected == "image/heif" {
+		stripped, w, h, err := stripHEIF(data)
+		if err != nil {
+			return nil, err
+		}
+		out.Bytes = stripped
+		out.Width, out.Height = w, h
+		out.OriginalWidth, out.OriginalHeight = w, h
+		return out, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: content does not decode as %s", ErrInvalidImage, detected)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is outside the supported size", ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: content does not decode as %s", ErrInvalidImage, detected)
+	}
+	out.OriginalWidth, out.OriginalHeight = src.Bounds().Dx(), src.Bounds().Dy()
+
+	rgba := p.downscale(src)
+	if detected == "image/jpeg" {
+		if o := jpegOrientation(data); o > 1 {
+			rgba = orient(rgba, o)
+			out.Orientation = o
+		}
+	}
+	out.Width, out.Height = rgba.Bounds().Dx(), rgba.Bounds().Dy()
+
+	// PNG stays lossless; everything else, including WebP which has no
+	// encoder here, becomes JPEG.
+	var buf bytes.Buffer
+	if detected == "image/png" {
+		err = png.Encode(&buf, rgba)
+	} else {
+		err = jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: p.cfg.JPEGQuality})
+		out.MimeType = "image/jpeg"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode processed image: %w", err)
+	}
+	out.Bytes = buf.Bytes()
+	out.Processed = true
+	return out, nil
+}
+
+// downscale fits src within MaxDimension on its longer side, returning an
+// RGBA copy either way so orientation can work on raw pixels.
+func (p *Preprocessor) downscale(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if longest := max(w, h); longest > p.cfg.MaxDimension {
+		w = max(1, w*p.cfg.MaxDimension/longest)
+		h = max(1, h*p.cfg.MaxDimension/longest)
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+		return dst
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// sniff identifies the image type from its magic bytes.
+func sniff(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "image/webp"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		switch string(data[8:12]) {
+		case "heic", "heix", "heim", "heis", "hevc", "hevx":
+			return "image/heic"
+		case "mif1", "msf1":
+			return "image/heif"
+		}
+	}
+	return ""
+}
+
+func normalizeMime(raw string) string {
+	mediaType, _, err := mime.ParseMediaType(raw)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(raw))
+	}
+	switch mediaType {
+	case "image/jpg":
+		return "image/jpeg"
+	case "application/octet-stream":
+		// Generic uploads say nothing about the type, so the bytes decide.
+		return ""
+	}
+	return mediaType
+}
+
+// family treats HEIC and HEIF as one type; the ftyp brand does not reliably
+// tell them apart.
+func family(mimeType string) string {
+	if mimeType == "image/heif" {
+		return "image/heic"
+	}
+	return mimeType
+}
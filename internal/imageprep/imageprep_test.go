@@ -0,0 +1,239 @@
+package imageprep
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/safebites/backend-go/internal/observability"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	// Mark the top-left corner so orientation can be checked.
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 segment carrying an orientation tag and a GPS IFD
+// pointer right after the JPEG's SOI marker.
+func withEXIF(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = append(tiff, 0x00, 0x02)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = append(tiff, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0x00, 0x00)
+	tiff = append(tiff, 0x88, 0x25, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestProcessDownscalesLargeImages(t *testing.T) {
+	p := New(Config{MaxDimension: 100})
+	data := encodeJPEG(t, testImage(400, 200))
+
+	img, err := p.Process(context.Background(), data, "image/jpeg")
+	require.NoError(t, err)
+	require.True(t, img.Processed)
+	require.Equal(t, "image/jpeg", img.MimeType)
+	require.Equal(t, 400, img.OriginalWidth)
+	require.Equal(t, 200, img.OriginalHeight)
+	require.Equal(t, 100, img.Width)
+	require.Equal(t, 50, img.Height)
+	require.Less(t, len(img.Bytes), img.OriginalBytes)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(img.Bytes))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 100, 50), decoded.Bounds())
+}
+
+func TestProcessAppliesOrientationAndStripsEXIF(t *testing.T) {
+	p := New(Config{MaxDimension: 1000})
+	data := withEXIF(encodeJPEG(t, testImage(40, 20)), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	img, err := p.Process(context.Background(), data, "image/jpg")
+	require.NoError(t, err)
+	require.Equal(t, 6, img.Orientation)
+	require.Equal(t, 20, img.Width)
+	require.Equal(t, 40, img.Height)
+	require.NotContains(t, string(img.Bytes), "Exif")
+	require.Equal(t, 0, jpegOrientation(img.Bytes))
+
+	// Rotating 90° clockwise moves the marked top-left block to the top-right.
+	decoded, err := jpeg.Decode(bytes.NewReader(img.Bytes))
+	require.NoError(t, err)
+	r, _, _, _ := decoded.At(17, 2).RGBA()
+	require.Greater(t, r>>8, uint32(150))
+}
+
+func TestProcessKeepsPNG(t *testing.T) {
+	p := New(Config{})
+	img, err := p.Process(context.Background(), encodePNG(t, testImage(30, 30)), "image/png")
+	require.NoError(t, err)
+	require.Equal(t, "image/png", img.MimeType)
+	require.Equal(t, 30, img.Width)
+}
+
+func TestProcessRejectsMismatchedOrUndecodableContent(t *testing.T) {
+	p := New(Config{})
+
+	_, err := p.Process(context.Background(), encodeJPEG(t, testImage(10, 10)), "image/png")
+	require.ErrorIs(t, err, ErrInvalidImage)
+	require.ErrorContains(t, err, "declared image/png but content is image/jpeg")
+
+	_, err = p.Process(context.Background(), []byte("not an image at all"), "image/jpeg")
+	require.ErrorIs(t, err, ErrInvalidImage)
+
+	truncated := encodePNG(t, testImage(10, 10))[:40]
+	_, err = p.Process(context.Background(), truncated, "image/png")
+	require.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestProcessAcceptsGenericDeclaredType(t *testing.T) {
+	p := New(Config{})
+	img, err := p.Process(context.Background(), encodePNG(t, testImage(10, 10)), "application/octet-stream")
+	require.NoError(t, err)
+	require.Equal(t, "image/png", img.MimeType)
+}
+
+func isoBox(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(out, typ...), body...)
+}
+
+func isoFullBox(typ string, version byte, payload ...[]byte) []byte {
+	return isoBox(typ, append([][]byte{{version, 0, 0, 0}}, payload...)...)
+}
+
+func u16(v int) []byte { return binary.BigEndian.AppendUint16(nil, uint16(v)) }
+func u32(v int) []byte { return binary.BigEndian.AppendUint32(nil, uint32(v)) }
+
+// testHEIC builds a minimal HEIC with a coded image item and an Exif item
+// carrying a GPS marker, both stored in mdat.
+func testHEIC(coded, exif []byte, exifLength int) []byte {
+	ftyp := isoBox("ftyp", []byte("heic"), u32(0), []byte("mif1heic"))
+	meta := func(codedAt, exifAt int) []byte {
+		return isoFullBox("meta", 0,
+			isoFullBox("hdlr", 0, u32(0), []byte("pict"), make([]byte, 12), []byte{0}),
+			isoFullBox("pitm", 0, u16(1)),
+			isoFullBox("iinf", 0, u16(2),
+				isoFullBox("infe", 2, u16(1), u16(0), []byte("hvc1\x00")),
+				isoFullBox("infe", 2, u16(2), u16(0), []byte("Exif\x00")),
+			),
+			isoFullBox("iloc", 0, []byte{0x44, 0x00}, u16(2),
+				u16(1), u16(0), u16(1), u32(codedAt), u32(len(coded)),
+				u16(2), u16(0), u16(1), u32(exifAt), u32(exifLength),
+			),
+			isoBox("iprp",
+				isoBox("ipco", isoFullBox("ispe", 0, u32(4032), u32(3024))),
+				isoFullBox("ipma", 0, u32(1), u16(1), []byte{1, 0x81}),
+			),
+		)
+	}
+	mdatAt := len(ftyp) + len(meta(0, 0)) + 8
+	return bytes.Join([][]byte{
+		ftyp,
+		meta(mdatAt, mdatAt+len(coded)),
+		isoBox("mdat", coded, exif),
+	}, nil)
+}
+
+func TestProcessBlanksHEICMetadata(t *testing.T) {
+	coded := []byte("HEVC-CODED-IMAGE")
+	exif := []byte("\x00\x00\x00\x06Exif\x00\x00MM\x00\x2aGPS-51.5007N-0.1246W")
+	data := testHEIC(coded, exif, len(exif))
+	original := bytes.Clone(data)
+
+	img, err := New(Config{}).Process(context.Background(), data, "image/heif")
+	require.NoError(t, err)
+	require.False(t, img.Processed)
+	require.Equal(t, "image/heic", img.MimeType)
+	require.Equal(t, 4032, img.Width)
+	require.Equal(t, 3024, img.Height)
+	require.Len(t, img.Bytes, len(data))
+	require.Contains(t, string(img.Bytes), string(coded))
+	require.NotContains(t, string(img.Bytes), "GPS-51.5007N")
+	require.Equal(t, original, data, "the caller's buffer is left untouched")
+}
+
+func TestProcessRejectsMalformedHEIC(t *testing.T) {
+	exif := []byte("Exif\x00\x00GPS")
+	cases := map[string][]byte{
+		"bare ftyp":         append([]byte{0x00, 0x00, 0x00, 0x18}, []byte("ftypheic\x00\x00\x00\x00mif1heic")...),
+		"extent past end":   testHEIC([]byte("HEVC"), exif, 4096),
+		"truncated payload": testHEIC([]byte("HEVC"), exif, len(exif))[:60],
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(Config{}).Process(context.Background(), data, "image/heic")
+			require.ErrorIs(t, err, ErrInvalidImage)
+		})
+	}
+}
+
+func TestProcessRecordsSizesOnSpan(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	observability.SetTracerProvider(tp)
+	t.Cleanup(func() { observability.SetTracerProvider(nil) })
+
+	data := encodeJPEG(t, testImage(300, 150))
+	img, err := New(Config{MaxDimension: 150}).Process(context.Background(), data, "image/jpeg")
+	require.NoError(t, err)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "ImagePreprocess", spans[0].Name())
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	require.Equal(t, int64(len(data)), attrs[observability.AttrImageOriginalBytes].AsInt64())
+	require.Equal(t, int64(len(img.Bytes)), attrs[observability.AttrImageProcessedBytes].AsInt64())
+	require.Equal(t, int64(300), attrs[observability.AttrImageOriginalWidth].AsInt64())
+	require.Equal(t, int64(75), attrs[observability.AttrImageProcessedHeight].AsInt64())
+}
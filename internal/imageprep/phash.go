@@ -0,0 +1,98 @@
+package imageprep
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// hashSize is the side of the grayscale thumbnail the DCT runs on.
+	hashSize = 32
+	// hashBand is the side of the low-frequency block that becomes the hash.
+	hashBand = 8
+)
+
+var dctCos = func() [hashSize][hashSize]float64 {
+	var t [hashSize][hashSize]float64
+	for u := 0; u < hashSize; u++ {
+		for x := 0; x < hashSize; x++ {
+			t[u][x] = math.Cos(float64((2*x+1)*u) * math.Pi / (2 * hashSize))
+		}
+	}
+	return t
+}()
+
+// PerceptualHash returns a 64-bit DCT hash of an image. Photos of the same
+// label taken from nearly the same angle hash within a few bits of each
+// other, while re-encoding, resizing, and EXIF rotation do not change the
+// hash at all. HEIC/HEIF cannot be decoded and return ErrInvalidImage.
+func PerceptualHash(data []byte) (uint64, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, hashSize, hashSize))
+	draw.ApproxBiLinear.Scale(thumb, thumb.Bounds(), src, src.Bounds(), draw.Src, nil)
+	if format == "jpeg" {
+		if o := jpegOrientation(data); o > 1 {
+			thumb = orient(thumb, o)
+		}
+	}
+
+	var gray [hashSize][hashSize]float64
+	for y := 0; y < hashSize; y++ {
+		for x := 0; x < hashSize; x++ {
+			i := thumb.PixOffset(x, y)
+			p := thumb.Pix[i : i+3]
+			gray[y][x] = 0.299*float64(p[0]) + 0.587*float64(p[1]) + 0.114*float64(p[2])
+		}
+	}
+
+	// Separable 2D DCT, keeping only the low-frequency band.
+	var rows [hashSize][hashBand]float64
+	for y := 0; y < hashSize; y++ {
+		for u := 0; u < hashBand; u++ {
+			var sum float64
+			for x := 0; x < hashSize; x++ {
+				sum += gray[y][x] * dctCos[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+	coeffs := make([]float64, 0, hashBand*hashBand)
+	for v := 0; v < hashBand; v++ {
+		for u := 0; u < hashBand; u++ {
+			var sum float64
+			for y := 0; y < hashSize; y++ {
+				sum += rows[y][u] * dctCos[v][y]
+			}
+			coeffs = append(coeffs, sum)
+		}
+	}
+
+	// The DC term only tracks overall brightness, so it is left out of the
+	// median the bits are compared against.
+	sorted := append([]float64(nil), coeffs[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash uint64
+	for i, c := range coeffs {
+		if c > median {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash, nil
+}
+
+// HammingDistance counts the bits that differ between two perceptual hashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
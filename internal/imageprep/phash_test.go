@@ -0,0 +1,65 @@
+package imageprep
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// labelImage draws a smooth pattern with a dark block in it, offset by shift
+// pixels, so that small shifts look like the same photo taken again.
+func labelImage(w, h, shift int, invert bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			fx := float64(x+shift) / float64(w)
+			fy := float64(y) / float64(h)
+			v := 128 + 60*math.Sin(3*math.Pi*fx) + 50*math.Cos(2*math.Pi*fy*fx)
+			if fx > 0.55 && fx < 0.8 && fy > 0.2 && fy < 0.5 {
+				v -= 90
+			}
+			if invert {
+				v = 255 - v
+			}
+			c := uint8(math.Max(0, math.Min(255, v)))
+			img.Set(x, y, color.RGBA{R: c, G: c, B: c, A: 255})
+		}
+	}
+	return img
+}
+
+func TestPerceptualHashToleratesResizingAndReencoding(t *testing.T) {
+	original, err := PerceptualHash(encodePNG(t, labelImage(320, 240, 0, false)))
+	require.NoError(t, err)
+
+	resized, err := PerceptualHash(encodeJPEG(t, labelImage(160, 120, 0, false)))
+	require.NoError(t, err)
+	require.LessOrEqual(t, HammingDistance(original, resized), 4)
+
+	shifted, err := PerceptualHash(encodePNG(t, labelImage(320, 240, 6, false)))
+	require.NoError(t, err)
+	require.LessOrEqual(t, HammingDistance(original, shifted), 6)
+
+	different, err := PerceptualHash(encodePNG(t, labelImage(320, 240, 0, true)))
+	require.NoError(t, err)
+	require.Greater(t, HammingDistance(original, different), 20)
+}
+
+func TestPerceptualHashAppliesOrientation(t *testing.T) {
+	upright, err := PerceptualHash(encodeJPEG(t, labelImage(60, 40, 0, false)))
+	require.NoError(t, err)
+
+	// The same photo stored rotated, with EXIF telling viewers to rotate it back.
+	rotated := orient(labelImage(60, 40, 0, false), 8)
+	tagged, err := PerceptualHash(withEXIF(encodeJPEG(t, rotated), 6))
+	require.NoError(t, err)
+	require.LessOrEqual(t, HammingDistance(upright, tagged), 4)
+}
+
+func TestPerceptualHashRejectsUndecodableData(t *testing.T) {
+	_, err := PerceptualHash([]byte("img"))
+	require.ErrorIs(t, err, ErrInvalidImage)
+}
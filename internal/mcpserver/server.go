@@ -0,0 +1,293 @@
+// Package mcpserver exposes SafeBites to assistants over the Model Context
+// Protocol. The tools call the same services as the REST API and accept the
+// same bearer tokens.
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/safebites/backend-go/internal/agent"
+	"github.com/safebites/backend-go/internal/guard"
+	"github.com/safebites/backend-go/internal/middleware"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+const (
+	serverName    = "safebites"
+	serverVersion = "1.0.0"
+
+	defaultScanLimit = 20
+	maxScanLimit     = 100
+)
+
+var errAuthRequired = errors.New("authentication required: send the same bearer token as the REST API")
+
+type Config struct {
+	Analyze   service.AnalyzeService
+	Recommend service.RecommendService
+	Scans     service.ScanService
+	Users     service.UserService
+	Templates service.TemplateService
+	// DevModeAuth lets HTTP callers without a token act as
+	// middleware.DevUserID, as middleware.RequireAuth does.
+	DevModeAuth bool
+	// LocalUserID is who stdio sessions act as. A stdio server is started by
+	// the operator for their own assistant, so there is no token to check.
+	LocalUserID string
+}
+
+type server struct {
+	cfg Config
+}
+
+// New builds an MCP server with one tool per SafeBites capability.
+func New(cfg Config) *mcp.Server {
+	s := &server{cfg: cfg}
+	srv := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: serverVersion}, nil)
+
+	// Results are returned as untyped structured content: the inferred
+	// output schemas would reject the null slices our models marshal to.
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "analyze_product",
+		Description: "Finds a food product's ingredients on the web and scores their safety against the caller's dietary preferences.",
+	}, s.analyzeProduct)
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "score_ingredients",
+		Description: "Scores a list of ingredient names against the caller's dietary preferences, without looking the product up.",
+	}, s.scoreIngredients)
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "recommend_alternatives",
+		Description: "Suggests healthier alternatives to a product, each rescored from its own ingredient list.",
+	}, s.recommendAlternatives)
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "list_dietary_templates",
+		Description: "Lists the dietary templates a user can apply to their preferences: the built-ins (vegan, gluten-free, ...) and, for an authenticated caller, their own.",
+	}, s.listDietaryTemplates)
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "list_scans",
+		Description: "Returns a page of the caller's scan history, newest first unless sorted otherwise, optionally filtered by safety, score, brand, or product. Pass next_cursor back as cursor for the next page. Requires authentication.",
+	}, s.listScans)
+	return srv
+}
+
+type analyzeProductArgs struct {
+	ProductName string `json:"product_name" jsonschema:"Product name, ideally with the brand, e.g. Nutella Hazelnut Spread."`
+}
+
+type scoreIngredientsArgs struct {
+	Ingredients []string `json:"ingredients" jsonschema:"Ingredient names as printed on the label."`
+}
+
+type recommendArgs struct {
+	ProductName  string  `json:"product_name" jsonschema:"Product to find alternatives for."`
+	OverallScore float64 `json:"overall_score" jsonschema:"The product's overall safety score from 0 to 10, as returned by analyze_product."`
+}
+
+type listScansArgs struct {
+	Limit    int    `json:"limit,omitempty" jsonschema:"Maximum number of scans to return, 1 to 100. Defaults to 20."`
+	Cursor   string `json:"cursor,omitempty" jsonschema:"next_cursor from the previous call, to fetch the following page with the same sort."`
+	Sort     string `json:"sort,omitempty" jsonschema:"newest (default), oldest, score_desc, or score_asc."`
+	Safe     *bool  `json:"safe,omitempty" jsonschema:"true for safe scans only, false for risky ones only."`
+	MinScore *int   `json:"min_score,omitempty" jsonschema:"Lowest safety score to include, 0 to 100."`
+	MaxScore *int   `json:"max_score,omitempty" jsonschema:"Highest safety score to include, 0 to 100."`
+	Brand    string `json:"brand,omitempty" jsonschema:"Only scans whose brand contains this text, ignoring case."`
+	Product  string `json:"product,omitempty" jsonschema:"Only scans whose product name contains this text, ignoring case."`
+}
+
+func (s *server) analyzeProduct(ctx context.Context, req *mcp.CallToolRequest, args analyzeProductArgs) (*mcp.CallToolResult, any, error) {
+	if s.cfg.Analyze == nil {
+		return nil, nil, fmt.Errorf("analyze service is not configured")
+	}
+	if strings.TrimSpace(args.ProductName) == "" {
+		return nil, nil, fmt.Errorf("product_name is required")
+	}
+	ctx, prefs, err := s.callerContext(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.cfg.Analyze.AnalyzeProduct(ctx, args.ProductName, prefs)
+	if err != nil {
+		return nil, nil, toolError("analyze_product", "failed to analyze product", err)
+	}
+	return nil, result, nil
+}
+
+func (s *server) scoreIngredients(ctx context.Context, req *mcp.CallToolRequest, args scoreIngredientsArgs) (*mcp.CallToolResult, any, error) {
+	if s.cfg.Analyze == nil {
+		return nil, nil, fmt.Errorf("analyze service is not configured")
+	}
+	if len(args.Ingredients) == 0 {
+		return nil, nil, fmt.Errorf("ingredients are required")
+	}
+	if len(args.Ingredients) > service.MaxScoredIngredients {
+		return nil, nil, fmt.Errorf("at most %d ingredients can be scored", service.MaxScoredIngredients)
+	}
+	ctx, prefs, err := s.callerContext(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.cfg.Analyze.ScoreIngredients(ctx, args.Ingredients, prefs)
+	if err != nil {
+		return nil, nil, toolError("score_ingredients", "failed to score ingredients", err)
+	}
+	return nil, result, nil
+}
+
+func (s *server) recommendAlternatives(ctx context.Context, req *mcp.CallToolRequest, args recommendArgs) (*mcp.CallToolResult, any, error) {
+	if s.cfg.Recommend == nil {
+		return nil, nil, fmt.Errorf("recommend service is not configured")
+	}
+	if strings.TrimSpace(args.ProductName) == "" {
+		return nil, nil, fmt.Errorf("product_name is required")
+	}
+	if args.OverallScore < 0 || args.OverallScore > 10 {
+		return nil, nil, fmt.Errorf("overall_score must be between 0 and 10")
+	}
+	ctx, prefs, err := s.callerContext(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.cfg.Recommend.Recommend(ctx, args.ProductName, args.OverallScore, prefs)
+	if err != nil {
+		return nil, nil, toolError("recommend_alternatives", "failed to fetch recommendations", err)
+	}
+	return nil, result, nil
+}
+
+func (s *server) listDietaryTemplates(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+	if s.cfg.Templates == nil {
+		return nil, nil, fmt.Errorf("template service is not configured")
+	}
+	userID, _ := s.caller(req)
+	templates, err := s.cfg.Templates.List(ctx, userID)
+	if err != nil {
+		return nil, nil, toolError("list_dietary_templates", "failed to fetch templates", err)
+	}
+	return nil, map[string]interface{}{"templates": templates}, nil
+}
+
+func (s *server) listScans(ctx context.Context, req *mcp.CallToolRequest, args listScansArgs) (*mcp.CallToolResult, any, error) {
+	if s.cfg.Scans == nil {
+		return nil, nil, fmt.Errorf("scan service is not configured")
+	}
+	userID, err := s.requireCaller(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit := args.Limit
+	switch {
+	case limit == 0:
+		limit = defaultScanLimit
+	case limit < 0 || limit > maxScanLimit:
+		return nil, nil, fmt.Errorf("limit must be between 1 and %d", maxScanLimit)
+	}
+	sort := model.ScanSort(args.Sort)
+	if sort != "" && !model.ValidScanSort(sort) {
+		return nil, nil, fmt.Errorf("sort must be one of newest, oldest, score_desc, score_asc")
+	}
+	for _, score := range []*int{args.MinScore, args.MaxScore} {
+		if score != nil && (*score < 0 || *score > 100) {
+			return nil, nil, fmt.Errorf("min_score and max_score must be between 0 and 100")
+		}
+	}
+
+	page, err := s.cfg.Scans.ListByUser(ctx, userID, model.ScanFilter{
+		Safe:     args.Safe,
+		MinScore: args.MinScore,
+		MaxScore: args.MaxScore,
+		Brand:    strings.TrimSpace(args.Brand),
+		Product:  strings.TrimSpace(args.Product),
+		Sort:     sort,
+		Limit:    limit,
+		Cursor:   args.Cursor,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, nil, fmt.Errorf("cursor is invalid for this sort")
+		}
+		return nil, nil, toolError("list_scans", "failed to fetch scans", err)
+	}
+	if page.Scans == nil {
+		page.Scans = []model.Scan{}
+	}
+	result := map[string]interface{}{"scans": page.Scans}
+	if page.NextCursor != "" {
+		result["next_cursor"] = page.NextCursor
+	}
+	return nil, result, nil
+}
+
+// caller returns the user a tool call acts for: the subject of the bearer
+// token on HTTP requests, the configured local user on stdio.
+func (s *server) caller(req *mcp.CallToolRequest) (string, bool) {
+	if req != nil && req.Extra != nil && req.Extra.Header != nil {
+		return middleware.UserIDFromAuthorization(req.Extra.Header.Get("Authorization"))
+	}
+	return s.cfg.LocalUserID, s.cfg.LocalUserID != ""
+}
+
+// requireCaller is caller for tools that read a user's own data, falling
+// back to the dev user when Auth0 is not configured.
+func (s *server) requireCaller(req *mcp.CallToolRequest) (string, error) {
+	if userID, ok := s.caller(req); ok {
+		return userID, nil
+	}
+	if s.cfg.DevModeAuth {
+		return middleware.DevUserID, nil
+	}
+	return "", errAuthRequired
+}
+
+// callerContext scopes the agent runs of a tool call to the caller and loads
+// the caller's dietary preferences. Anonymous calls run without preferences.
+func (s *server) callerContext(ctx context.Context, req *mcp.CallToolRequest) (context.Context, *model.UserPreferences, error) {
+	userID, ok := s.caller(req)
+	if !ok {
+		return agent.WithSessionScope(ctx, agent.SessionScope{UserID: agent.AnonymousUserID}), nil, nil
+	}
+	ctx = agent.WithSessionScope(ctx, agent.SessionScope{UserID: userID})
+	if s.cfg.Users == nil {
+		return ctx, nil, nil
+	}
+
+	user, err := s.cfg.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ctx, nil, nil
+		}
+		return ctx, nil, toolError("preferences", "failed to fetch user preferences", err)
+	}
+	return ctx, &model.UserPreferences{
+		Allergies:        user.Allergies,
+		DietGoals:        user.DietGoals,
+		AvoidIngredients: user.AvoidIngredients,
+		HomeRegion:       user.HomeRegion,
+		Version:          user.PreferenceVersion,
+	}, nil
+}
+
+// toolError turns a service error into the message the assistant sees. Like
+// the REST handlers, it names guard rejections and hides internal failures,
+// which are logged instead.
+func toolError(tool, message string, err error) error {
+	var rejected *guard.RejectedError
+	if errors.As(err, &rejected) {
+		if rejected.Source == guard.SourceOCR {
+			return fmt.Errorf("%s: label text was rejected as unsafe input", guard.ErrorCode)
+		}
+		return fmt.Errorf("%s: input was rejected as unsafe", guard.ErrorCode)
+	}
+	log.Printf("mcp tool error tool=%s err=%v", tool, err)
+	return errors.New(message)
+}
@@ -0,0 +1,308 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/safebites/backend-go/internal/agent"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/safebites/backend-go/internal/service"
+)
+
+type mockAnalyzeService struct {
+	analyzeProduct   func(ctx context.Context, productName string, prefs *model.UserPreferences) (*model.AnalysisResult, error)
+	scoreIngredients func(ctx context.Context, names []string, prefs *model.UserPreferences) (*model.ScorerResult, error)
+}
+
+func (m *mockAnalyzeService) Analyze(context.Context, []byte, string, *model.UserPreferences) (*model.AnalysisResult, error) {
+	return nil, errors.New("not used")
+}
+
+func (m *mockAnalyzeService) AnalyzeProduct(ctx context.Context, productName string, prefs *model.UserPreferences) (*model.AnalysisResult, error) {
+	return m.analyzeProduct(ctx, productName, prefs)
+}
+
+func (m *mockAnalyzeService) AnalyzeIngredients(context.Context, string, string, *model.UserPreferences) (*model.AnalysisResult, error) {
+	return nil, errors.New("not used")
+}
+
+func (m *mockAnalyzeService) ScoreIngredients(ctx context.Context, names []string, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+	return m.scoreIngredients(ctx, names, prefs)
+}
+
+func (m *mockAnalyzeService) RescoreIngredients(context.Context, []model.Ingredient, *model.UserPreferences) (*model.ScorerResult, error) {
+	return nil, errors.New("not used")
+}
+
+type mockRecommendService struct {
+	recommend func(ctx context.Context, productName string, score float64, prefs *model.UserPreferences) (*model.RecommenderResult, error)
+}
+
+func (m *mockRecommendService) Recommend(ctx context.Context, productName string, score float64, prefs *model.UserPreferences) (*model.RecommenderResult, error) {
+	return m.recommend(ctx, productName, score, prefs)
+}
+
+type mockScanService struct {
+	listByUser func(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error)
+}
+
+func (m *mockScanService) ListByUser(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
+	return m.listByUser(ctx, userID, filter)
+}
+
+func (m *mockScanService) Create(context.Context, *model.Scan) (*model.Scan, error) {
+	return nil, errors.New("not used")
+}
+
+func (m *mockScanService) GetStats(context.Context, string) (*model.UserStats, error) {
+	return nil, errors.New("not used")
+}
+
+type mockUserService struct {
+	users map[string]*model.User
+}
+
+func (m *mockUserService) GetByID(_ context.Context, userID string) (*model.User, error) {
+	if u, ok := m.users[userID]; ok {
+		return u, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *mockUserService) Upsert(context.Context, *model.User) (*model.User, error) {
+	return nil, errors.New("not used")
+}
+
+func (m *mockUserService) UpdatePreferences(context.Context, string, model.UserPreferences) (*model.User, error) {
+	return nil, errors.New("not used")
+}
+
+func (m *mockUserService) ApplyTemplate(context.Context, string, string) (*model.User, model.DietaryTemplate, error) {
+	return nil, model.DietaryTemplate{}, errors.New("not used")
+}
+
+func (m *mockUserService) RemoveTemplate(context.Context, string, string) (*model.User, error) {
+	return nil, errors.New("not used")
+}
+
+// mockTemplateService lists the built-in templates, plus the caller's own
+// when there is a caller.
+type mockTemplateService struct {
+	service.TemplateService
+	owned map[string][]model.DietaryTemplate
+}
+
+func (m *mockTemplateService) List(_ context.Context, userID string) ([]model.DietaryTemplate, error) {
+	return append(model.SortedDietaryTemplates(), m.owned[userID]...), nil
+}
+
+func connect(t *testing.T, cfg Config) *mcp.ClientSession {
+	t.Helper()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := New(cfg).Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func callTool(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (map[string]any, *mcp.CallToolResult) {
+	t.Helper()
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	require.NoError(t, err)
+	var out map[string]any
+	if !res.IsError {
+		raw, err := json.Marshal(res.StructuredContent)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &out))
+	}
+	return out, res
+}
+
+func errorText(res *mcp.CallToolResult) string {
+	if len(res.Content) == 0 {
+		return ""
+	}
+	if text, ok := res.Content[0].(*mcp.TextContent); ok {
+		return text.Text
+	}
+	return ""
+}
+
+func TestListToolsExposesCapabilities(t *testing.T) {
+	session := connect(t, Config{})
+	res, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	require.ElementsMatch(t, []string{"analyze_product", "score_ingredients", "recommend_alternatives", "list_dietary_templates", "list_scans"}, names)
+}
+
+func TestAnalyzeProductUsesLocalUserPreferences(t *testing.T) {
+	var gotPrefs *model.UserPreferences
+	var gotScope agent.SessionScope
+	session := connect(t, Config{
+		Analyze: &mockAnalyzeService{analyzeProduct: func(ctx context.Context, productName string, prefs *model.UserPreferences) (*model.AnalysisResult, error) {
+			gotPrefs = prefs
+			gotScope, _ = agent.SessionScopeFromContext(ctx)
+			return &model.AnalysisResult{ProductName: productName, IngredientBreakdown: &model.ScorerResult{OverallScore: 4.5}}, nil
+		}},
+		Users:       &mockUserService{users: map[string]*model.User{"user-1": {ID: "user-1", Allergies: model.Allergies("peanuts")}}},
+		LocalUserID: "user-1",
+	})
+
+	out, res := callTool(t, session, "analyze_product", map[string]any{"product_name": "Nutella"})
+	require.False(t, res.IsError, errorText(res))
+	require.Equal(t, "Nutella", out["product_name"])
+	require.Equal(t, model.Allergies("peanuts"), gotPrefs.Allergies)
+	require.Equal(t, "user-1", gotScope.UserID)
+}
+
+func TestScoreIngredientsAnonymous(t *testing.T) {
+	var gotNames []string
+	session := connect(t, Config{
+		Analyze: &mockAnalyzeService{scoreIngredients: func(ctx context.Context, names []string, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+			require.Nil(t, prefs)
+			scope, _ := agent.SessionScopeFromContext(ctx)
+			require.Equal(t, agent.AnonymousUserID, scope.UserID)
+			gotNames = names
+			return &model.ScorerResult{OverallScore: 7}, nil
+		}},
+	})
+
+	out, res := callTool(t, session, "score_ingredients", map[string]any{"ingredients": []string{"Oats", "Salt"}})
+	require.False(t, res.IsError, errorText(res))
+	require.Equal(t, 7.0, out["overall_score"])
+	require.Equal(t, []string{"Oats", "Salt"}, gotNames)
+}
+
+func TestRecommendAlternativesValidatesScore(t *testing.T) {
+	session := connect(t, Config{
+		Recommend: &mockRecommendService{recommend: func(context.Context, string, float64, *model.UserPreferences) (*model.RecommenderResult, error) {
+			return nil, errors.New("upstream exploded with secrets")
+		}},
+	})
+
+	_, res := callTool(t, session, "recommend_alternatives", map[string]any{"product_name": "Cola", "overall_score": 11})
+	require.True(t, res.IsError)
+	require.Contains(t, errorText(res), "overall_score must be between 0 and 10")
+
+	_, res = callTool(t, session, "recommend_alternatives", map[string]any{"product_name": "Cola", "overall_score": 3})
+	require.True(t, res.IsError)
+	require.Equal(t, "failed to fetch recommendations", errorText(res), "internal errors are not leaked")
+}
+
+func TestListDietaryTemplates(t *testing.T) {
+	session := connect(t, Config{Templates: &mockTemplateService{}})
+	out, res := callTool(t, session, "list_dietary_templates", nil)
+	require.False(t, res.IsError, errorText(res))
+	templates, ok := out["templates"].([]any)
+	require.True(t, ok)
+	require.Len(t, templates, len(model.DietaryTemplates))
+}
+
+func TestListScansRequiresCaller(t *testing.T) {
+	scans := &mockScanService{listByUser: func(_ context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
+		require.Equal(t, "user-1", userID)
+		require.Equal(t, defaultScanLimit, filter.Limit)
+		return &model.ScanPage{Scans: []model.Scan{{ID: "scan-1", UserID: userID, ProductName: "Nutella"}}}, nil
+	}}
+
+	_, res := callTool(t, connect(t, Config{Scans: scans}), "list_scans", nil)
+	require.True(t, res.IsError)
+	require.Contains(t, errorText(res), "authentication required")
+
+	out, res := callTool(t, connect(t, Config{Scans: scans, LocalUserID: "user-1"}), "list_scans", nil)
+	require.False(t, res.IsError, errorText(res))
+	require.Len(t, out["scans"], 1)
+
+	_, res = callTool(t, connect(t, Config{Scans: scans, LocalUserID: "user-1"}), "list_scans", map[string]any{"limit": 500})
+	require.True(t, res.IsError)
+}
+
+func TestListScansPagesAndFilters(t *testing.T) {
+	var got model.ScanFilter
+	scans := &mockScanService{listByUser: func(_ context.Context, _ string, filter model.ScanFilter) (*model.ScanPage, error) {
+		got = filter
+		if filter.Cursor == "stale" {
+			return nil, repository.ErrInvalidCursor
+		}
+		return &model.ScanPage{Scans: []model.Scan{{ID: "scan-2"}}, NextCursor: "next"}, nil
+	}}
+	session := connect(t, Config{Scans: scans, LocalUserID: "user-1"})
+
+	out, res := callTool(t, session, "list_scans", map[string]any{"sort": "score_desc", "safe": true, "min_score": 50, "brand": "acme", "cursor": "abc"})
+	require.False(t, res.IsError, errorText(res))
+	require.Equal(t, "next", out["next_cursor"])
+	require.Equal(t, model.ScanSortScoreDesc, got.Sort)
+	require.True(t, *got.Safe)
+	require.Equal(t, 50, *got.MinScore)
+	require.Nil(t, got.MaxScore)
+	require.Equal(t, "acme", got.Brand)
+	require.Equal(t, "abc", got.Cursor)
+
+	for _, args := range []map[string]any{
+		{"sort": "name"},
+		{"max_score": 101},
+		{"cursor": "stale"},
+	} {
+		_, res = callTool(t, session, "list_scans", args)
+		require.True(t, res.IsError, args)
+	}
+}
+
+func TestStreamableHTTPUsesBearerToken(t *testing.T) {
+	scans := &mockScanService{listByUser: func(_ context.Context, userID string, _ model.ScanFilter) (*model.ScanPage, error) {
+		return &model.ScanPage{Scans: []model.Scan{{ID: "scan-1", UserID: userID}}}, nil
+	}}
+	srv := New(Config{Scans: scans, LocalUserID: "ignored-over-http"})
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil))
+	t.Cleanup(ts.Close)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "auth0|abc"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	connectHTTP := func(authorization string) *mcp.ClientSession {
+		client := &http.Client{Transport: headerTransport{authorization: authorization}}
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1"}, nil).
+			Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: ts.URL, HTTPClient: client}, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	out, res := callTool(t, connectHTTP("Bearer "+token), "list_scans", nil)
+	require.False(t, res.IsError, errorText(res))
+	require.Equal(t, "auth0|abc", out["scans"].([]any)[0].(map[string]any)["userId"])
+
+	_, res = callTool(t, connectHTTP(""), "list_scans", nil)
+	require.True(t, res.IsError, "HTTP callers do not fall back to the local user")
+}
+
+type headerTransport struct {
+	authorization string
+}
+
+func (h headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if h.authorization != "" {
+		req.Header.Set("Authorization", h.authorization)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -14,6 +15,15 @@ type contextKey string
 
 const userIDContextKey contextKey = "user_id"
 
+// DevUserID is who unauthenticated requests act as when Auth0 is not
+// configured.
+const DevUserID = "dev-test-user"
+
+var (
+	ErrMissingToken = errors.New("missing authorization token")
+	ErrInvalidToken = errors.New("invalid authorization token")
+)
+
 func UserIDFromContext(ctx context.Context) (string, bool) {
 	v, ok := ctx.Value(userIDContextKey).(string)
 	if !ok || strings.TrimSpace(v) == "" {
@@ -22,6 +32,12 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	return v, true
 }
 
+// WithUserID returns ctx authenticated as userID, for transports that
+// authenticate outside this middleware.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
 func OptionalAuth(cfg *config.Config) func(http.Handler) http.Handler {
 	_ = cfg
 	return func(next http.Handler) http.Handler {
@@ -38,8 +54,7 @@ func OptionalAuth(cfg *config.Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), userIDContextKey, userID)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
 		})
 	}
 }
@@ -47,27 +62,42 @@ func OptionalAuth(cfg *config.Config) func(http.Handler) http.Handler {
 func RequireAuth(cfg *config.Config) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := bearerToken(r.Header.Get("Authorization"))
-			if token == "" {
-				if cfg.DevModeAuth() {
-					ctx := context.WithValue(r.Context(), userIDContextKey, "dev-test-user")
-					next.ServeHTTP(w, r.WithContext(ctx))
-					return
-				}
-				writeAuthError(w, "missing authorization token")
+			userID, err := RequiredUserID(r.Header.Get("Authorization"), cfg.DevModeAuth())
+			if err != nil {
+				writeAuthError(w, err.Error())
 				return
 			}
+			next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
+		})
+	}
+}
 
-			userID, ok := userIDFromToken(token)
-			if !ok {
-				writeAuthError(w, "invalid authorization token")
-				return
-			}
+// RequiredUserID applies RequireAuth's rules to an Authorization header: a
+// valid bearer token's subject, DevUserID when there is no token and
+// devModeAuth is set, and ErrMissingToken or ErrInvalidToken otherwise.
+func RequiredUserID(authorizationHeader string, devModeAuth bool) (string, error) {
+	token := bearerToken(authorizationHeader)
+	if token == "" {
+		if devModeAuth {
+			return DevUserID, nil
+		}
+		return "", ErrMissingToken
+	}
+	userID, ok := userIDFromToken(token)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	return userID, nil
+}
 
-			ctx := context.WithValue(r.Context(), userIDContextKey, userID)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+// UserIDFromAuthorization returns the user a bearer Authorization header
+// authenticates, for transports that read headers outside this middleware.
+func UserIDFromAuthorization(authorizationHeader string) (string, bool) {
+	token := bearerToken(authorizationHeader)
+	if token == "" {
+		return "", false
 	}
+	return userIDFromToken(token)
 }
 
 func bearerToken(authorizationHeader string) string {
@@ -47,3 +47,41 @@ func TestRequireAuthToken(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, rr.Code)
 }
+
+func TestUserIDFromAuthorization(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "auth0|abc"})
+	rawToken, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	userID, ok := UserIDFromAuthorization("Bearer " + rawToken)
+	require.True(t, ok)
+	require.Equal(t, "auth0|abc", userID)
+
+	_, ok = UserIDFromAuthorization("")
+	require.False(t, ok)
+	_, ok = UserIDFromAuthorization("Basic dXNlcjpwYXNz")
+	require.False(t, ok)
+	_, ok = UserIDFromAuthorization("Bearer not-a-jwt")
+	require.False(t, ok)
+}
+
+func TestRequiredUserID(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "auth0|abc"})
+	rawToken, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	userID, err := RequiredUserID("Bearer "+rawToken, false)
+	require.NoError(t, err)
+	require.Equal(t, "auth0|abc", userID)
+
+	userID, err = RequiredUserID("", true)
+	require.NoError(t, err)
+	require.Equal(t, DevUserID, userID)
+
+	_, err = RequiredUserID("", false)
+	require.ErrorIs(t, err, ErrMissingToken)
+
+	// A bad token is rejected even in dev mode, as RequireAuth does.
+	_, err = RequiredUserID("Bearer not-a-jwt", true)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -27,18 +27,47 @@ func (f *FlexibleString) UnmarshalJSON(data []byte) error {
 }
 
 type Ingredient struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	// Name is the canonical English ingredient name used for scoring.
+	Name string `json:"name"`
+	// OriginalName is the ingredient as printed on the label when that differs
+	// from Name, e.g. on non-English labels or for additive codes.
+	OriginalName string `json:"original_name,omitempty"`
+	// AdditiveCode is the E-number of a recognized food additive.
+	AdditiveCode string `json:"additive_code,omitempty"`
+	Description  string `json:"description"`
+	// MayContain marks an allergen named in a "may contain" statement rather
+	// than in the ingredient list. It is only scored for users who asked for
+	// traces to count.
+	MayContain bool `json:"may_contain,omitempty"`
+}
+
+// Source is a web page that grounded an agent's answer.
+type Source struct {
+	Title string `json:"title"`
+	URI   string `json:"uri"`
 }
 
 type WebSearchResult struct {
+	LabelLanguage     string       `json:"label_language,omitempty"`
 	ListOfIngredients []Ingredient `json:"List_of_ingredients"`
+	Sources           []Source     `json:"sources,omitempty"`
+	// SourceAgreement is the mean confidence the grounding reported for the
+	// segments it could attribute to sources, or 0 when none were reported.
+	SourceAgreement float64 `json:"-"`
 }
 
 type IngredientScore struct {
 	IngredientName string         `json:"ingredient_name"`
+	OriginalName   string         `json:"original_name,omitempty"`
+	AdditiveCode   string         `json:"additive_code,omitempty"`
 	SafetyScore    FlexibleString `json:"safety_score"`
 	Reasoning      string         `json:"reasoning"`
+	// Regulatory lists the per-region status of ingredients in the regulatory
+	// dataset; it is empty for everything else.
+	Regulatory []RegionStatus `json:"regulatory,omitempty"`
+	// MayContain marks the score of a "may contain" warning rather than of
+	// an ingredient.
+	MayContain bool `json:"may_contain,omitempty"`
 }
 
 type ScorerResult struct {
@@ -50,8 +79,15 @@ type Recommendation struct {
 	ProductName string         `json:"product_name"`
 	HealthScore FlexibleString `json:"health_score"`
 	Reason      string         `json:"reason"`
+	// IngredientBreakdown is filled in after the alternative has been run
+	// through the same search -> ingredient scoring path as the original.
+	IngredientBreakdown *ScorerResult `json:"ingredient_breakdown,omitempty"`
+	Sources             []Source      `json:"sources,omitempty"`
+	// Profiles holds verdicts for the household profiles the request picked.
+	Profiles []ProfileVerdict `json:"profiles,omitempty"`
 }
 
 type RecommenderResult struct {
 	Recommendations []Recommendation `json:"recommendations"`
+	Sources         []Source         `json:"sources,omitempty"`
 }
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Severity is how strongly a user reacts to an allergen.
+type Severity string
+
+const (
+	SeverityAnaphylactic Severity = "anaphylactic"
+	SeverityAllergy      Severity = "allergy"
+	SeverityIntolerance  Severity = "intolerance"
+	// SeverityPreference is an allergen the user would rather not eat,
+	// without a medical reason.
+	SeverityPreference Severity = "preference"
+)
+
+// Severities lists the valid severities, most severe first.
+var Severities = []Severity{SeverityAnaphylactic, SeverityAllergy, SeverityIntolerance, SeverityPreference}
+
+// ValidSeverity reports whether s is one of Severities.
+func ValidSeverity(s Severity) bool {
+	return s.rank() >= 0
+}
+
+// rank orders severities from least to most severe, or returns -1 for an
+// unknown one.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityPreference:
+		return 0
+	case SeverityIntolerance:
+		return 1
+	case SeverityAllergy:
+		return 2
+	case SeverityAnaphylactic:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// Allergy is one entry of an allergy list.
+type Allergy struct {
+	Name     string   `json:"name"`
+	Severity Severity `json:"severity"`
+	// MayContain makes "may contain" warnings count, not only ingredients.
+	MayContain bool `json:"mayContain"`
+}
+
+// UnmarshalJSON also reads a bare string, the format allergy lists had
+// before severities, as an allergy of SeverityAllergy.
+func (a *Allergy) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		*a = Allergy{Name: name, Severity: SeverityAllergy}
+		return nil
+	}
+
+	type plain Allergy
+	var p plain
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return err
+	}
+	*a = Allergy(p)
+	if a.Severity == "" {
+		a.Severity = SeverityAllergy
+	}
+	return nil
+}
+
+// ScoreLimit is the highest safety score an ingredient containing the
+// allergen can keep: LOW for allergies and anaphylaxis, MEDIUM for
+// intolerances and preferences.
+func (a Allergy) ScoreLimit() string {
+	switch a.Severity {
+	case SeverityIntolerance, SeverityPreference:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+// TraceLimit is the highest safety score a product that only may contain
+// the allergen can keep, or "" when traces do not matter to the user.
+func (a Allergy) TraceLimit() string {
+	if !a.MayContain {
+		return ""
+	}
+	if a.Severity == SeverityAnaphylactic {
+		return "LOW"
+	}
+	return "MEDIUM"
+}
+
+// Allergies wraps names as allergies of SeverityAllergy.
+func Allergies(names ...string) []Allergy {
+	out := make([]Allergy, 0, len(names))
+	for _, name := range names {
+		out = append(out, Allergy{Name: name, Severity: SeverityAllergy})
+	}
+	return out
+}
+
+// AllergyNames returns the names of allergies, in order.
+func AllergyNames(allergies []Allergy) []string {
+	out := make([]string, 0, len(allergies))
+	for _, a := range allergies {
+		out = append(out, a.Name)
+	}
+	return out
+}
+
+// MergeAllergy folds b into a when both name the same allergen, keeping the
+// more severe reaction and trace sensitivity from either.
+func MergeAllergy(a, b Allergy) Allergy {
+	if b.Severity.rank() > a.Severity.rank() {
+		a.Severity = b.Severity
+	}
+	a.MayContain = a.MayContain || b.MayContain
+	return a
+}
+
+// SameAllergen reports whether a and b name the same allergen, ignoring case
+// and surrounding space.
+func SameAllergen(a, b Allergy) bool {
+	return strings.EqualFold(strings.TrimSpace(a.Name), strings.TrimSpace(b.Name))
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// AnalysisResult is the outcome of analyzing a single product: the name that
+// was searched, its scored ingredients, the web sources the ingredient list
+// came from, and how much the pipeline trusts its own answer.
+type AnalysisResult struct {
+	ProductName string `json:"product_name"`
+	// DetectedLanguage is the ISO 639-1 code of the label, empty when unknown.
+	DetectedLanguage string `json:"detected_language"`
+	// Language is the ISO 639-1 code the texts of the result are written in.
+	Language            string        `json:"language"`
+	IngredientBreakdown *ScorerResult `json:"ingredient_breakdown"`
+	// Ingredients is the list the breakdown was scored from, "may contain"
+	// traces included, so the product can be scored again for other
+	// preferences without another search.
+	Ingredients []Ingredient `json:"ingredients,omitempty"`
+	Sources     []Source     `json:"sources"`
+	Confidence  *Confidence  `json:"confidence"`
+}
+
+// Confidence holds per-stage confidence values between 0 and 1 and their
+// weighted combination. NeedsVerification is set when Overall falls below the
+// configured threshold; Notes explains which stages pulled it down.
+type Confidence struct {
+	OCR               float64  `json:"ocr"`
+	Sources           float64  `json:"sources"`
+	Scorer            float64  `json:"scorer"`
+	Overall           float64  `json:"overall"`
+	NeedsVerification bool     `json:"needs_verification"`
+	Notes             []string `json:"notes,omitempty"`
+}
+
+// ImageAnalysis caches what was learned from one uploaded label image, keyed
+// by its perceptual hash so near-duplicate uploads can skip OCR.
+type ImageAnalysis struct {
+	ID    int64
+	PHash uint64
+	Label LabelReading
+	// PreferencesKey identifies the preferences Analysis was scored against;
+	// the full analysis is only reused for a request with the same key.
+	PreferencesKey string
+	Analysis       *AnalysisResult
+	CreatedAt      time.Time
+	// Distance is the Hamming distance to the hash that was looked up. It is
+	// only set on lookup results.
+	Distance int
+}
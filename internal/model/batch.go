@@ -0,0 +1,25 @@
+package model
+
+// MaxBatchProducts caps the product names analyzed in one batch.
+const MaxBatchProducts = 50
+
+// BatchItem is the outcome for one product name of a batch. Exactly one of
+// Result and Error is set.
+type BatchItem struct {
+	ProductName string          `json:"product_name"`
+	Result      *AnalysisResult `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	// Code is a machine-readable error code, set for errors clients are
+	// expected to branch on.
+	Code string `json:"code,omitempty"`
+	// Cached reports that the result was reused from an earlier analysis of
+	// the same name and preferences.
+	Cached bool `json:"cached"`
+}
+
+// BatchResult holds one item per requested name, in request order.
+type BatchResult struct {
+	Items     []BatchItem `json:"items"`
+	Succeeded int         `json:"succeeded"`
+	Failed    int         `json:"failed"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Chat message roles.
+const (
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// ChatMessage is one turn of the follow-up conversation about a scan.
+type ChatMessage struct {
+	Role      string    `json:"role"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
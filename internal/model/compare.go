@@ -0,0 +1,41 @@
+package model
+
+const (
+	MinCompareProducts = 2
+	MaxCompareProducts = 5
+)
+
+// CompareInput identifies one product to compare, either by name or by a label
+// image that still needs OCR.
+type CompareInput struct {
+	ProductName string
+	ImageBytes  []byte
+	MimeType    string
+}
+
+type PreferenceViolation struct {
+	Ingredient string `json:"ingredient"`
+	Preference string `json:"preference"`
+	Kind       string `json:"kind"` // allergy | avoid_ingredient | diet_goal
+}
+
+type ProductComparison struct {
+	ProductName          string                `json:"product_name"`
+	IngredientBreakdown  *ScorerResult         `json:"ingredient_breakdown,omitempty"`
+	Sources              []Source              `json:"sources,omitempty"`
+	UniqueIngredients    []string              `json:"unique_ingredients"`
+	PreferenceViolations []PreferenceViolation `json:"preference_violations"`
+	Rank                 int                   `json:"rank"`
+	Error                string                `json:"error,omitempty"`
+}
+
+type ComparisonVerdict struct {
+	BestProduct string   `json:"best_product"`
+	Ranking     []string `json:"ranking"`
+	Summary     string   `json:"summary"`
+}
+
+type ComparisonResult struct {
+	Products []ProductComparison `json:"products"`
+	Verdict  ComparisonVerdict   `json:"verdict"`
+}
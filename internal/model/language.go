@@ -0,0 +1,13 @@
+package model
+
+// DefaultLanguage is the ISO 639-1 code analyses are produced in before any
+// localization.
+const DefaultLanguage = "en"
+
+// LabelReading is what OCR read from a product label.
+type LabelReading struct {
+	ProductName string
+	// Language is the ISO 639-1 code of the label text, or empty when unknown.
+	Language   string
+	Confidence float64
+}
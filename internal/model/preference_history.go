@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// PreferenceSourceManual is the source of changes the user made directly,
+// rather than by applying or removing a template.
+const PreferenceSourceManual = "manual"
+
+// Changes recorded in a user's preference history.
+const (
+	PreferenceCreated         = "created"
+	PreferenceUpdated         = "updated"
+	PreferenceTemplateApplied = "template_applied"
+	PreferenceTemplateRemoved = "template_removed"
+	// PreferenceTemplateChanged records an edit to, or the deletion of, a
+	// template the user had applied.
+	PreferenceTemplateChanged = "template_changed"
+	// PreferenceBackfilled is the first version of users who existed before
+	// the history did, recorded by migration 020 from what they had then.
+	PreferenceBackfilled = "backfilled"
+)
+
+// MaxPreferenceHistory caps the versions returned by one history request.
+const MaxPreferenceHistory = 100
+
+// PreferenceVersion is a user's effective preferences after one change.
+type PreferenceVersion struct {
+	Version int `json:"version"`
+	// Source is PreferenceSourceManual, or the key of the template the
+	// change came from.
+	Source           string    `json:"source"`
+	Action           string    `json:"action"`
+	Allergies        []Allergy `json:"allergies"`
+	DietGoals        []string  `json:"dietGoals"`
+	AvoidIngredients []string  `json:"avoidIngredients"`
+	HomeRegion       string    `json:"homeRegion,omitempty"`
+	// Templates are the keys of the templates applied at the time, oldest
+	// first.
+	Templates []string  `json:"templates"`
+	CreatedAt time.Time `json:"createdAt"`
+}
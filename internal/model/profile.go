@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// MaxProfiles caps the household profiles of one account.
+const MaxProfiles = 10
+
+// Profile is a household member the account holder shops for, with dietary
+// lists of their own. Profiles share the home region of the account.
+type Profile struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"userId"`
+	Name             string    `json:"name"`
+	Allergies        []Allergy `json:"allergies"`
+	DietGoals        []string  `json:"dietGoals"`
+	AvoidIngredients []string  `json:"avoidIngredients"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Preferences returns the preferences a product is scored against for p.
+func (p Profile) Preferences(homeRegion string) *UserPreferences {
+	return &UserPreferences{
+		Allergies:        p.Allergies,
+		DietGoals:        p.DietGoals,
+		AvoidIngredients: p.AvoidIngredients,
+		HomeRegion:       homeRegion,
+	}
+}
+
+// Verdicts on whether a product suits a household profile.
+const (
+	// VerdictAvoid means an ingredient breaks an allergy or avoided
+	// ingredient of the profile.
+	VerdictAvoid = "avoid"
+	// VerdictCaution means nothing conflicts outright but the product scores
+	// below MinSuitableScore for the profile.
+	VerdictCaution  = "caution"
+	VerdictSuitable = "suitable"
+)
+
+// MinSuitableScore is the overall score from which a product without
+// conflicts suits a profile.
+const MinSuitableScore = 5.0
+
+// Kinds of PreferenceConflict.
+const (
+	ConflictAllergy = "allergy"
+	ConflictAvoid   = "avoid"
+)
+
+// PreferenceConflict is an allergy or avoided ingredient that an ingredient
+// breaks.
+type PreferenceConflict struct {
+	// Ingredient is set when the conflict is reported for a whole product.
+	Ingredient string `json:"ingredient,omitempty"`
+	Kind       string `json:"kind"`
+	Preference string `json:"preference"`
+	// Severity is set for allergy conflicts.
+	Severity Severity `json:"severity,omitempty"`
+	Reason   string   `json:"reason"`
+}
+
+// ProfileVerdict is how a product fares for one household profile. When the
+// evaluation failed only ProfileID, Name, and Error are set.
+type ProfileVerdict struct {
+	ProfileID    string               `json:"profile_id"`
+	Name         string               `json:"name"`
+	Verdict      string               `json:"verdict,omitempty"`
+	OverallScore float64              `json:"overall_score"`
+	Conflicts    []PreferenceConflict `json:"conflicts"`
+	// IngredientBreakdown is the product scored against the profile's
+	// preferences, when it was scored for the profile alone.
+	IngredientBreakdown *ScorerResult `json:"ingredient_breakdown,omitempty"`
+	Error               string        `json:"error,omitempty"`
+}
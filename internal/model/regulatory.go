@@ -0,0 +1,34 @@
+package model
+
+import "strings"
+
+// Regions lists the jurisdictions covered by the regulatory dataset. A user's
+// home region must be one of them.
+var Regions = []string{"EU", "UK", "US", "CA", "AU"}
+
+type RegulatoryStatus string
+
+const (
+	RegulatoryPermitted    RegulatoryStatus = "permitted"
+	RegulatoryWarningLabel RegulatoryStatus = "warning_label"
+	RegulatoryRestricted   RegulatoryStatus = "restricted"
+	RegulatoryBanned       RegulatoryStatus = "banned"
+)
+
+// RegionStatus is how one jurisdiction regulates an ingredient.
+type RegionStatus struct {
+	Region string           `json:"region"`
+	Status RegulatoryStatus `json:"status"`
+	Note   string           `json:"note,omitempty"`
+}
+
+// NormalizeRegion upper-cases region and reports whether it is supported.
+func NormalizeRegion(region string) (string, bool) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	for _, r := range Regions {
+		if r == region {
+			return region, true
+		}
+	}
+	return region, false
+}
@@ -3,13 +3,76 @@ package model
 import "time"
 
 type Scan struct {
-	ID          string                   `json:"id"`
-	UserID      string                   `json:"userId"`
-	ProductName string                   `json:"productName"`
-	Brand       string                   `json:"brand,omitempty"`
-	Image       string                   `json:"image,omitempty"`
+	ID          string `json:"id"`
+	UserID      string `json:"userId"`
+	ProductName string `json:"productName"`
+	Brand       string `json:"brand,omitempty"`
+	Image       string `json:"image,omitempty"`
+	// ImageID references an uploaded image in blob storage. When set, Image
+	// is replaced in responses by a signed, expiring download URL.
+	ImageID     string                   `json:"imageId,omitempty"`
 	SafetyScore int                      `json:"safetyScore"`
 	IsSafe      bool                     `json:"isSafe"`
 	Ingredients []map[string]interface{} `json:"ingredients"`
-	Timestamp   time.Time                `json:"timestamp"`
+	Sources     []Source                 `json:"sources"`
+	Confidence  *Confidence              `json:"confidence,omitempty"`
+	// PreferenceVersion is the version of the user's preference history the
+	// scan was scored against, or 0 when unknown.
+	PreferenceVersion int       `json:"preferenceVersion,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// ScanSort orders a scan list. Scans with equal keys are ordered by
+// timestamp and then ID, in the same direction, so pages never overlap.
+type ScanSort string
+
+const (
+	ScanSortNewest    ScanSort = "newest"
+	ScanSortOldest    ScanSort = "oldest"
+	ScanSortScoreDesc ScanSort = "score_desc"
+	ScanSortScoreAsc  ScanSort = "score_asc"
+)
+
+// ScanSorts lists the valid orders; the first is the default.
+var ScanSorts = []ScanSort{ScanSortNewest, ScanSortOldest, ScanSortScoreDesc, ScanSortScoreAsc}
+
+// ValidScanSort reports whether s is one of ScanSorts.
+func ValidScanSort(s ScanSort) bool {
+	for _, sort := range ScanSorts {
+		if s == sort {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxScanPage is the most scans one page of a scan list holds.
+const MaxScanPage = 100
+
+// ScanFilter selects one page of a user's scans. Zero fields select
+// everything.
+type ScanFilter struct {
+	// From and To bound the scan timestamp; From is inclusive, To exclusive.
+	From time.Time
+	To   time.Time
+	// Safe keeps only safe scans when true and only risky ones when false.
+	Safe     *bool
+	MinScore *int
+	MaxScore *int
+	// Brand and Product match case-insensitive substrings.
+	Brand   string
+	Product string
+	Sort    ScanSort
+	Limit   int
+	// Cursor is the NextCursor of the previous page, or empty for the first.
+	// It only continues a list in the order it was created for.
+	Cursor string
+}
+
+// ScanPage is one page of a scan list.
+type ScanPage struct {
+	Scans []Scan `json:"scans"`
+	// NextCursor fetches the page after this one. It is empty on the last
+	// page.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
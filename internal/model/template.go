@@ -1,20 +1,44 @@
 package model
 
+import (
+	"sort"
+	"time"
+)
+
+// MaxUserTemplates caps the dietary templates one user can own.
+const MaxUserTemplates = 20
+
+// DietaryTemplate is a set of dietary lists a user can apply to their
+// preferences. Built-in templates have no owner; the others belong to the
+// user who created or forked them.
 type DietaryTemplate struct {
-	Key              string   `json:"key"`
-	Name             string   `json:"name"`
-	Description      string   `json:"description"`
-	Allergies        []string `json:"allergies"`
-	DietGoals        []string `json:"dietGoals"`
-	AvoidIngredients []string `json:"avoidIngredients"`
+	Key              string    `json:"key"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Allergies        []Allergy `json:"allergies"`
+	DietGoals        []string  `json:"dietGoals"`
+	AvoidIngredients []string  `json:"avoidIngredients"`
+
+	BuiltIn bool   `json:"builtIn"`
+	OwnerID string `json:"ownerId,omitempty"`
+	// ShareCode is set while the template is published. Anyone with the code
+	// can view, apply, or fork the template.
+	ShareCode string `json:"shareCode,omitempty"`
+	// ForkedFrom is the key of the template this one was copied from.
+	ForkedFrom string    `json:"forkedFrom,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
+// DietaryTemplates are the built-in templates. They are written to the
+// dietary_templates table at startup, and read from there like any other
+// template.
 var DietaryTemplates = map[string]DietaryTemplate{
 	"vegan": {
 		Key:              "vegan",
 		Name:             "Vegan",
 		Description:      "No animal products",
-		Allergies:        []string{},
+		Allergies:        []Allergy{},
 		DietGoals:        []string{"vegan"},
 		AvoidIngredients: []string{"meat", "fish", "dairy", "eggs", "honey", "gelatin", "whey", "casein", "lactose", "shellac"},
 	},
@@ -22,7 +46,7 @@ var DietaryTemplates = map[string]DietaryTemplate{
 		Key:              "vegetarian",
 		Name:             "Vegetarian",
 		Description:      "No meat or fish",
-		Allergies:        []string{},
+		Allergies:        []Allergy{},
 		DietGoals:        []string{"vegetarian"},
 		AvoidIngredients: []string{"meat", "fish", "gelatin", "shellac"},
 	},
@@ -30,7 +54,7 @@ var DietaryTemplates = map[string]DietaryTemplate{
 		Key:              "gluten_free",
 		Name:             "Gluten-Free",
 		Description:      "No gluten-containing grains",
-		Allergies:        []string{"gluten"},
+		Allergies:        Allergies("gluten"),
 		DietGoals:        []string{"gluten-free"},
 		AvoidIngredients: []string{"wheat", "barley", "rye", "malt", "brewer's yeast"},
 	},
@@ -38,7 +62,7 @@ var DietaryTemplates = map[string]DietaryTemplate{
 		Key:              "keto",
 		Name:             "Keto",
 		Description:      "Low carb, high fat diet",
-		Allergies:        []string{},
+		Allergies:        []Allergy{},
 		DietGoals:        []string{"keto", "low-carb"},
 		AvoidIngredients: []string{"sugar", "corn syrup", "high fructose corn syrup", "maltodextrin", "dextrose", "sucrose"},
 	},
@@ -46,7 +70,7 @@ var DietaryTemplates = map[string]DietaryTemplate{
 		Key:              "dairy_free",
 		Name:             "Dairy-Free",
 		Description:      "No dairy products",
-		Allergies:        []string{"dairy"},
+		Allergies:        Allergies("dairy"),
 		DietGoals:        []string{"dairy-free"},
 		AvoidIngredients: []string{"milk", "cream", "butter", "cheese", "yogurt", "whey", "casein", "lactose"},
 	},
@@ -54,7 +78,7 @@ var DietaryTemplates = map[string]DietaryTemplate{
 		Key:              "nut_free",
 		Name:             "Nut-Free",
 		Description:      "No tree nuts or peanuts",
-		Allergies:        []string{"tree nuts", "peanuts"},
+		Allergies:        Allergies("tree nuts", "peanuts"),
 		DietGoals:        []string{"nut-free"},
 		AvoidIngredients: []string{"almonds", "cashews", "walnuts", "pecans", "pistachios", "hazelnuts", "macadamia", "brazil nuts", "peanuts"},
 	},
@@ -62,8 +86,20 @@ var DietaryTemplates = map[string]DietaryTemplate{
 		Key:              "paleo",
 		Name:             "Paleo",
 		Description:      "Whole foods, no processed ingredients",
-		Allergies:        []string{},
+		Allergies:        []Allergy{},
 		DietGoals:        []string{"paleo"},
 		AvoidIngredients: []string{"sugar", "corn syrup", "soy", "legumes", "dairy", "grains", "processed oils", "artificial sweeteners"},
 	},
 }
+
+// SortedDietaryTemplates returns the built-in templates ordered by key.
+func SortedDietaryTemplates() []DietaryTemplate {
+	templates := make([]DietaryTemplate, 0, len(DietaryTemplates))
+	for _, tpl := range DietaryTemplates {
+		templates = append(templates, tpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Key < templates[j].Key
+	})
+	return templates
+}
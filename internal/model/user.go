@@ -1,23 +1,138 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
+// MaxTemplateLayers caps the templates applied to one user at a time.
+const MaxTemplateLayers = 10
+
+// CustomSource names the user's own entries in PreferenceSources.
+const CustomSource = "custom"
+
+// User holds the effective dietary lists, merged from the applied templates
+// and the user's own entries (see MergeLayers), alongside the layers
+// they came from.
 type User struct {
 	ID               string    `json:"id"`
 	Email            string    `json:"email"`
 	Name             string    `json:"name,omitempty"`
 	Picture          string    `json:"picture,omitempty"`
-	Allergies        []string  `json:"allergies"`
+	Allergies        []Allergy `json:"allergies"`
 	DietGoals        []string  `json:"dietGoals"`
 	AvoidIngredients []string  `json:"avoidIngredients"`
+	HomeRegion       string    `json:"homeRegion,omitempty"`
 	CreatedAt        time.Time `json:"createdAt"`
 	UpdatedAt        time.Time `json:"updatedAt"`
+	// PreferenceVersion is the latest entry in the user's preference
+	// history, or 0 before the first one.
+	PreferenceVersion int `json:"preferenceVersion"`
+
+	// Templates are the applied templates, oldest first.
+	Templates []TemplateLayer `json:"templates"`
+	// Custom holds the entries the user set themselves.
+	Custom PreferenceLists `json:"custom"`
+	// Sources maps every effective entry to the layers that contributed it.
+	Sources PreferenceSources `json:"sources"`
 }
 
 type UserPreferences struct {
-	Allergies        []string `json:"allergies"`
-	DietGoals        []string `json:"dietGoals"`
-	AvoidIngredients []string `json:"avoidIngredients"`
+	Allergies        []Allergy `json:"allergies"`
+	DietGoals        []string  `json:"dietGoals"`
+	AvoidIngredients []string  `json:"avoidIngredients"`
+	// HomeRegion is one of Regions, or empty when the user has not set one.
+	HomeRegion string `json:"homeRegion,omitempty"`
+	// Version is the preference version these were read at, or 0 when they
+	// are not a user's stored preferences. It is left out of JSON so that
+	// scoring payloads and cache keys do not depend on it.
+	Version int `json:"-"`
+}
+
+// PreferenceLists are the dietary lists of one preference layer.
+type PreferenceLists struct {
+	Allergies        []Allergy `json:"allergies"`
+	DietGoals        []string  `json:"dietGoals"`
+	AvoidIngredients []string  `json:"avoidIngredients"`
+}
+
+// TemplateLayer is a template applied to a user. A layer of a built-in or
+// own template follows later edits to it and goes away with it. A layer of a
+// template another user shared is a Snapshot: it keeps the name and lists the
+// template had when applied, even after the template changes or is deleted.
+type TemplateLayer struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	PreferenceLists
+	Snapshot  bool      `json:"snapshot"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// PreferenceSources maps each effective entry to the keys of the templates
+// that contributed it, plus CustomSource when the user added it themselves.
+type PreferenceSources struct {
+	Allergies        map[string][]string `json:"allergies"`
+	DietGoals        map[string][]string `json:"dietGoals"`
+	AvoidIngredients map[string][]string `json:"avoidIngredients"`
+}
+
+// MergeLayers fills the effective lists and Sources of user from
+// user.Custom and user.Templates. The lists are the union of all layers,
+// the user's own entries first and then the templates in the order they were
+// applied. Entries differing only in case or surrounding space count once,
+// spelled as they first appeared. An allergy listed by several layers takes
+// the most severe reaction any of them records.
+func MergeLayers(user *User) {
+	sources := make([]string, 0, len(user.Templates)+1)
+	layers := make([]PreferenceLists, 0, len(user.Templates)+1)
+	sources = append(sources, CustomSource)
+	layers = append(layers, user.Custom)
+	for _, tpl := range user.Templates {
+		sources = append(sources, tpl.Key)
+		layers = append(layers, tpl.PreferenceLists)
+	}
+
+	pick := func(lists func(PreferenceLists) []string) ([]string, map[string][]string) {
+		merged := []string{}
+		attributed := map[string][]string{}
+		seen := map[string]string{}
+		for i, layer := range layers {
+			for _, entry := range lists(layer) {
+				entry = strings.TrimSpace(entry)
+				if entry == "" {
+					continue
+				}
+				norm := strings.ToLower(entry)
+				spelled, ok := seen[norm]
+				if !ok {
+					spelled = entry
+					seen[norm] = entry
+					merged = append(merged, entry)
+				}
+				if n := len(attributed[spelled]); n == 0 || attributed[spelled][n-1] != sources[i] {
+					attributed[spelled] = append(attributed[spelled], sources[i])
+				}
+			}
+		}
+		return merged, attributed
+	}
+	var allergies []string
+	allergies, user.Sources.Allergies = pick(func(l PreferenceLists) []string { return AllergyNames(l.Allergies) })
+	user.Allergies = make([]Allergy, len(allergies))
+	index := map[string]int{}
+	for i, name := range allergies {
+		user.Allergies[i] = Allergy{Name: name}
+		index[strings.ToLower(name)] = i
+	}
+	for _, layer := range layers {
+		for _, a := range layer.Allergies {
+			if i, ok := index[strings.ToLower(strings.TrimSpace(a.Name))]; ok {
+				user.Allergies[i] = MergeAllergy(user.Allergies[i], a)
+			}
+		}
+	}
+	user.DietGoals, user.Sources.DietGoals = pick(func(l PreferenceLists) []string { return l.DietGoals })
+	user.AvoidIngredients, user.Sources.AvoidIngredients = pick(func(l PreferenceLists) []string { return l.AvoidIngredients })
 }
 
 type UserStats struct {
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types partner apps can subscribe to.
+const (
+	EventAnalysisCompleted = "analysis.completed"
+	EventScanCreated       = "scan.created"
+)
+
+// WebhookEventTypes lists every event type a subscription may name.
+var WebhookEventTypes = []string{EventAnalysisCompleted, EventScanCreated}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookSubscription is a partner app's endpoint for one user's events.
+type WebhookSubscription struct {
+	ID      string `json:"id"`
+	UserID  string `json:"userId"`
+	AppName string `json:"appName"`
+	URL     string `json:"url"`
+	// Secret signs every payload sent to URL. It is only returned when the
+	// subscription is created.
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookEvent is the envelope posted to subscribers. Retries and replays of
+// a delivery carry the same event ID so receivers can deduplicate.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	UserID    string      `json:"userId"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// AnalysisCompleted is the data of an analysis.completed event. Scan
+// events carry the saved Scan itself.
+type AnalysisCompleted struct {
+	ScanID              string        `json:"scanId"`
+	ProductName         string        `json:"productName"`
+	Language            string        `json:"language"`
+	IngredientBreakdown *ScorerResult `json:"ingredientBreakdown"`
+	Confidence          *Confidence   `json:"confidence,omitempty"`
+	ImageID             string        `json:"imageId,omitempty"`
+}
+
+// NewAnalysisCompleted summarizes an analysis performed under scanID.
+func NewAnalysisCompleted(scanID string, result *AnalysisResult, imageID string) AnalysisCompleted {
+	return AnalysisCompleted{
+		ScanID:              scanID,
+		ProductName:         result.ProductName,
+		Language:            result.Language,
+		IngredientBreakdown: result.IngredientBreakdown,
+		Confidence:          result.Confidence,
+		ImageID:             imageID,
+	}
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	// ReplayOf is the delivery this one replays, if any.
+	ReplayOf    *int64     `json:"replayOf,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// WebhookAttempt is a due delivery together with where it goes.
+type WebhookAttempt struct {
+	Delivery     WebhookDelivery
+	Subscription WebhookSubscription
+}
@@ -0,0 +1,71 @@
+package observability
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// Image dedup outcomes, counted in the image_dedup metric and recorded on the
+// ImageDedup span.
+const (
+	DedupAnalysisHit = "analysis_hit"
+	DedupNameHit     = "name_hit"
+	DedupMiss        = "miss"
+	// DedupSkipped means no lookup ran, e.g. the image could not be hashed.
+	DedupSkipped = "skipped"
+	DedupError   = "error"
+)
+
+// imageDedup is deliberately not published in the global expvar registry, so
+// mounting expvar.Handler anywhere cannot leak it together with the cmdline
+// and memstats. MetricsHandler serves it instead.
+var (
+	imageDedup   = new(expvar.Map)
+	dedupHits    atomic.Int64
+	dedupLookups atomic.Int64
+)
+
+func init() {
+	imageDedup.Set("hit_rate", expvar.Func(func() any { return ImageDedupHitRate() }))
+}
+
+// RecordImageDedup counts one near-duplicate lookup outcome.
+func RecordImageDedup(outcome string) {
+	imageDedup.Add(outcome, 1)
+	switch outcome {
+	case DedupAnalysisHit, DedupNameHit:
+		dedupHits.Add(1)
+		dedupLookups.Add(1)
+	case DedupMiss:
+		dedupLookups.Add(1)
+	}
+}
+
+// ImageDedupCount returns how many lookups ended with outcome.
+func ImageDedupCount(outcome string) int64 {
+	if v, ok := imageDedup.Get(outcome).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// ImageDedupHitRate is the share of completed lookups that reused a cached
+// product name or analysis, or 0 before the first lookup.
+func ImageDedupHitRate() float64 {
+	lookups := dedupLookups.Load()
+	if lookups == 0 {
+		return 0
+	}
+	return float64(dedupHits.Load()) / float64(lookups)
+}
+
+// MetricsHandler serves the image_dedup counters in expvar's JSON format. The
+// server mounts it on the admin listener only.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, "{\n%q: %s\n}\n", "image_dedup", imageDedup.String())
+	})
+}
@@ -0,0 +1,24 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandlerServesOnlyDedupCounters(t *testing.T) {
+	RecordImageDedup(DedupMiss)
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body map[string]map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body, 1, "cmdline and memstats must not be exposed")
+	require.Contains(t, body["image_dedup"], DedupMiss)
+	require.Contains(t, body["image_dedup"], "hit_rate")
+}
@@ -20,10 +20,31 @@ const (
 	AttrGenAIInputTokens  = "gen_ai.usage.input_tokens"
 	AttrGenAIOutputTokens = "gen_ai.usage.output_tokens"
 
+	AttrGenAIOperation = "gen_ai.operation.name"
+	AttrGenAIToolName  = "gen_ai.tool.name"
+
 	// Langfuse-specific OTel attributes.
 	AttrLangfuseObservationName = "langfuse.observation.name"
 	AttrLangfuseTraceName       = "langfuse.trace.name"
 	AttrLangfuseUserID          = "langfuse.user.id"
+	AttrLangfuseSessionID       = "langfuse.session.id"
+
+	// SafeBites-specific attributes.
+	AttrGroundingSources = "safebites.grounding.sources"
+	AttrConfidence       = "safebites.confidence"
+	AttrGuardSource      = "safebites.guard.source"
+	AttrGuardVerdict     = "safebites.guard.verdict"
+	AttrGuardReasons     = "safebites.guard.reasons"
+
+	AttrImageOriginalBytes   = "safebites.image.original_bytes"
+	AttrImageProcessedBytes  = "safebites.image.processed_bytes"
+	AttrImageOriginalWidth   = "safebites.image.original_width"
+	AttrImageOriginalHeight  = "safebites.image.original_height"
+	AttrImageProcessedWidth  = "safebites.image.processed_width"
+	AttrImageProcessedHeight = "safebites.image.processed_height"
+	AttrImagePHash           = "safebites.image.phash"
+	AttrDedupOutcome         = "safebites.dedup.outcome"
+	AttrDedupDistance        = "safebites.dedup.distance"
 )
 
 // AgentSpan wraps trace.Span with typed setters that enforce attribute names.
@@ -48,6 +69,65 @@ func (s AgentSpan) SetTokens(input, output int64) {
 	)
 }
 
+// SetSources records the URIs of the web pages that grounded the output.
+func (s AgentSpan) SetSources(uris []string) {
+	s.SetAttributes(attribute.StringSlice(AttrGroundingSources, uris))
+}
+
+// SetConfidence records how confident the stage is in its output, from 0 to 1.
+func (s AgentSpan) SetConfidence(v float64) {
+	s.SetAttributes(attribute.Float64(AttrConfidence, v))
+}
+
+// SetGuard records the input guard's verdict on untrusted text and why it was
+// sanitized or rejected.
+func (s AgentSpan) SetGuard(source, verdict string, reasons []string) {
+	s.SetAttributes(
+		attribute.String(AttrGuardSource, source),
+		attribute.String(AttrGuardVerdict, verdict),
+		attribute.StringSlice(AttrGuardReasons, reasons),
+	)
+}
+
+// SetImageBytes records the size of an uploaded image before and after
+// preprocessing.
+func (s AgentSpan) SetImageBytes(original, processed int) {
+	s.SetAttributes(
+		attribute.Int(AttrImageOriginalBytes, original),
+		attribute.Int(AttrImageProcessedBytes, processed),
+	)
+}
+
+// SetImageDimensions records the pixel dimensions of an uploaded image before
+// and after preprocessing.
+func (s AgentSpan) SetImageDimensions(originalWidth, originalHeight, width, height int) {
+	s.SetAttributes(
+		attribute.Int(AttrImageOriginalWidth, originalWidth),
+		attribute.Int(AttrImageOriginalHeight, originalHeight),
+		attribute.Int(AttrImageProcessedWidth, width),
+		attribute.Int(AttrImageProcessedHeight, height),
+	)
+}
+
+// SetDedup records the perceptual hash of an upload and the outcome of the
+// near-duplicate lookup. distance is negative when nothing matched.
+func (s AgentSpan) SetDedup(phash, outcome string, distance int) {
+	s.SetAttributes(
+		attribute.String(AttrImagePHash, phash),
+		attribute.String(AttrDedupOutcome, outcome),
+		attribute.Int(AttrDedupDistance, distance),
+	)
+}
+
+// SetSession records which user and ADK session an agent run was stored
+// under, so a trace links to its persisted event history.
+func (s AgentSpan) SetSession(userID, sessionID string) {
+	s.SetAttributes(
+		attribute.String(AttrLangfuseUserID, userID),
+		attribute.String(AttrLangfuseSessionID, sessionID),
+	)
+}
+
 func tracer() trace.Tracer {
 	providerMu.RLock()
 	tp := currentProvider
@@ -66,6 +146,20 @@ func StartAgentSpan(ctx context.Context, agentName string) (context.Context, Age
 	return ctx, AgentSpan{sp}
 }
 
+// StartToolSpan opens a child span for one function tool call, named
+// "tool:<name>" so tool calls stand out from the agent spans around them.
+func StartToolSpan(ctx context.Context, toolName string) (context.Context, AgentSpan) {
+	name := "tool:" + toolName
+	ctx, sp := tracer().Start(ctx, name,
+		trace.WithAttributes(
+			attribute.String(AttrLangfuseObservationName, name),
+			attribute.String(AttrGenAIOperation, "execute_tool"),
+			attribute.String(AttrGenAIToolName, toolName),
+		),
+	)
+	return ctx, AgentSpan{sp}
+}
+
 // StartPipelineSpan opens the root span for an end-to-end request.
 // Child agent spans nest under it.
 func StartPipelineSpan(ctx context.Context, pipelineName string) (context.Context, AgentSpan) {
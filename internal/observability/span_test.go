@@ -103,3 +103,37 @@ func TestStartAgentSpan_NoopsWhenConfiguredProviderCleared(t *testing.T) {
 	_, span := observability.StartAgentSpan(context.Background(), "SearchAgent")
 	span.End()
 }
+
+func TestStartToolSpan_NamesToolCall(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	observability.SetTracerProvider(tp)
+	t.Cleanup(func() { observability.SetTracerProvider(nil) })
+
+	ctx, parent := observability.StartAgentSpan(context.Background(), "ScorerAgent")
+	_, span := observability.StartToolSpan(ctx, "lookup_allergens")
+	span.End()
+	parent.End()
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("want 2 spans, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "tool:lookup_allergens" {
+		t.Errorf("span name = %q, want tool:lookup_allergens", s.Name())
+	}
+	if s.Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("tool span is not a child of the agent span")
+	}
+	attrs := map[string]string{}
+	for _, kv := range s.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+	}
+	if attrs["gen_ai.tool.name"] != "lookup_allergens" {
+		t.Errorf("gen_ai.tool.name = %q, want lookup_allergens", attrs["gen_ai.tool.name"])
+	}
+	if attrs["gen_ai.operation.name"] != "execute_tool" {
+		t.Errorf("gen_ai.operation.name = %q, want execute_tool", attrs["gen_ai.operation.name"])
+	}
+}
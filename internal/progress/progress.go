@@ -0,0 +1,42 @@
+// Package progress carries a listener for the stages of an analysis through
+// its context, so transports that can stream, like the gRPC API, report them
+// while the others run the same code without one.
+package progress
+
+import "context"
+
+type Stage string
+
+const (
+	StageReadingLabel Stage = "reading_label"
+	StageLabelRead    Stage = "label_read"
+	StageSearching    Stage = "searching"
+	StageScoring      Stage = "scoring"
+	StageLocalizing   Stage = "localizing"
+)
+
+// Event is the stage an analysis has reached. ProductName is set once the
+// product is known and IngredientCount once its ingredients are.
+type Event struct {
+	Stage           Stage
+	ProductName     string
+	IngredientCount int
+}
+
+type listenerKey struct{}
+
+// WithListener makes Report under ctx call fn. Stages are reported in order
+// from the goroutine running the analysis.
+func WithListener(ctx context.Context, fn func(Event)) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, listenerKey{}, fn)
+}
+
+// Report passes e to the listener of ctx, if any.
+func Report(ctx context.Context, e Event) {
+	if fn, ok := ctx.Value(listenerKey{}).(func(Event)); ok {
+		fn(e)
+	}
+}
@@ -0,0 +1,212 @@
+// Package regulatory is an embedded dataset of how jurisdictions regulate
+// individual ingredients, keyed by canonical ingredient name and E-number.
+package regulatory
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/safebites/backend-go/internal/model"
+)
+
+//go:embed regulatory.json
+var embeddedEntries []byte
+
+type Entry struct {
+	// Ingredient is the canonical name, matching the additive reference where
+	// the ingredient is an additive.
+	Ingredient   string               `json:"ingredient"`
+	AdditiveCode string               `json:"additive_code,omitempty"`
+	Aliases      []string             `json:"aliases,omitempty"`
+	Regions      []model.RegionStatus `json:"regions"`
+}
+
+type Catalog struct {
+	entries []Entry
+	byName  map[string]int
+	byCode  map[string]int
+}
+
+// Load parses a catalog in the format of the embedded regulatory.json.
+func Load(r io.Reader) (*Catalog, error) {
+	var doc struct {
+		Entries []Entry `json:"entries"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode regulatory entries: %w", err)
+	}
+
+	c := &Catalog{byName: map[string]int{}, byCode: map[string]int{}}
+	for i, e := range doc.Entries {
+		if normalizeName(e.Ingredient) == "" {
+			return nil, fmt.Errorf("regulatory entry %d has no ingredient", i)
+		}
+		for _, rs := range e.Regions {
+			if _, ok := model.NormalizeRegion(rs.Region); !ok {
+				return nil, fmt.Errorf("%s: unsupported region %q", e.Ingredient, rs.Region)
+			}
+			if severity(rs.Status) < 0 {
+				return nil, fmt.Errorf("%s: unknown status %q", e.Ingredient, rs.Status)
+			}
+		}
+		if e.AdditiveCode != "" {
+			c.byCode[strings.ToUpper(e.AdditiveCode)] = i
+		}
+		for _, name := range append([]string{e.Ingredient}, e.Aliases...) {
+			key := normalizeName(name)
+			if _, dup := c.byName[key]; dup {
+				return nil, fmt.Errorf("duplicate regulatory name %q", name)
+			}
+			c.byName[key] = i
+		}
+	}
+	c.entries = doc.Entries
+	return c, nil
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the catalog built from the embedded dataset.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		c, err := Load(strings.NewReader(string(embeddedEntries)))
+		if err != nil {
+			panic(fmt.Sprintf("embedded regulatory catalog: %v", err))
+		}
+		defaultCatalog = c
+	})
+	return defaultCatalog
+}
+
+// Lookup returns the per-region statuses of an ingredient, matching the
+// additive code first and then the name.
+func (c *Catalog) Lookup(name, additiveCode string) ([]model.RegionStatus, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if i, ok := c.byCode[strings.ToUpper(strings.TrimSpace(additiveCode))]; ok {
+		return c.statuses(i), true
+	}
+	if i, ok := c.byName[normalizeName(name)]; ok {
+		return c.statuses(i), true
+	}
+	return nil, false
+}
+
+// Apply attaches regulatory statuses to every known ingredient of result. When
+// homeRegion bans an ingredient its score drops to LOW, and when it restricts
+// one the score is capped at MEDIUM; the overall score falls by the same share
+// so a single downgraded ingredient cannot hide behind an otherwise good list.
+func (c *Catalog) Apply(result *model.ScorerResult, homeRegion string) {
+	if c == nil || result == nil || len(result.IngredientScores) == 0 {
+		return
+	}
+	homeRegion, _ = model.NormalizeRegion(homeRegion)
+
+	var penalty float64
+	for i := range result.IngredientScores {
+		is := &result.IngredientScores[i]
+		statuses, ok := c.Lookup(is.IngredientName, is.AdditiveCode)
+		if !ok {
+			statuses, ok = c.Lookup(is.OriginalName, "")
+		}
+		if !ok {
+			continue
+		}
+		is.Regulatory = statuses
+
+		home, ok := statusIn(statuses, homeRegion)
+		if !ok {
+			continue
+		}
+		limit := ""
+		switch home.Status {
+		case model.RegulatoryBanned:
+			limit = "LOW"
+		case model.RegulatoryRestricted:
+			limit = "MEDIUM"
+		default:
+			continue
+		}
+		before := levelValue(string(is.SafetyScore))
+		if after := levelValue(limit); after < before {
+			is.SafetyScore = model.FlexibleString(limit)
+			penalty += before - after
+		}
+		is.Reasoning = strings.TrimSpace(is.Reasoning + " " + homeNote(home))
+	}
+
+	if penalty > 0 {
+		overall := result.OverallScore - penalty/float64(len(result.IngredientScores))
+		result.OverallScore = math.Round(math.Max(overall, 0)*10) / 10
+	}
+}
+
+func (c *Catalog) statuses(i int) []model.RegionStatus {
+	return append([]model.RegionStatus(nil), c.entries[i].Regions...)
+}
+
+func statusIn(statuses []model.RegionStatus, region string) (model.RegionStatus, bool) {
+	if region == "" {
+		return model.RegionStatus{}, false
+	}
+	for _, rs := range statuses {
+		if strings.EqualFold(rs.Region, region) {
+			return rs, true
+		}
+	}
+	return model.RegionStatus{}, false
+}
+
+func homeNote(rs model.RegionStatus) string {
+	verb := "Banned"
+	if rs.Status == model.RegulatoryRestricted {
+		verb = "Restricted"
+	}
+	note := fmt.Sprintf("%s in your home region (%s).", verb, rs.Region)
+	if rs.Note != "" {
+		note += " " + rs.Note
+	}
+	return note
+}
+
+// severity orders statuses from least to most restrictive, or returns -1 for
+// an unknown status.
+func severity(status model.RegulatoryStatus) int {
+	switch status {
+	case model.RegulatoryPermitted:
+		return 0
+	case model.RegulatoryWarningLabel:
+		return 1
+	case model.RegulatoryRestricted:
+		return 2
+	case model.RegulatoryBanned:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// levelValue maps a safety level onto the 0-10 overall scale.
+func levelValue(level string) float64 {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "HIGH":
+		return 10
+	case "MEDIUM":
+		return 5
+	default:
+		return 0
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
@@ -0,0 +1,101 @@
+package regulatory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/additive"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCatalogLoads(t *testing.T) {
+	c := Default()
+	require.NotEmpty(t, c.entries)
+	for _, e := range c.entries {
+		require.NotEmpty(t, e.Regions, e.Ingredient)
+	}
+}
+
+func TestDefaultCatalogMatchesAdditiveNames(t *testing.T) {
+	additives := additive.Default()
+	for _, e := range Default().entries {
+		if e.AdditiveCode == "" {
+			continue
+		}
+		a, ok := additives.Lookup(e.AdditiveCode)
+		require.True(t, ok, e.AdditiveCode)
+		require.Equal(t, a.Name, e.Ingredient, e.AdditiveCode)
+	}
+}
+
+func TestCatalogLookup(t *testing.T) {
+	c := Default()
+
+	statuses, ok := c.Lookup("anything", "e171")
+	require.True(t, ok)
+	eu, ok := statusIn(statuses, "EU")
+	require.True(t, ok)
+	require.Equal(t, model.RegulatoryBanned, eu.Status)
+
+	_, ok = c.Lookup("  partially hydrogenated   SOYBEAN oil", "")
+	require.True(t, ok)
+
+	_, ok = c.Lookup("Sugar", "")
+	require.False(t, ok)
+}
+
+func TestLoadRejectsUnknownRegionAndStatus(t *testing.T) {
+	_, err := Load(strings.NewReader(`{"entries":[{"ingredient":"X","regions":[{"region":"MARS","status":"banned"}]}]}`))
+	require.ErrorContains(t, err, "unsupported region")
+
+	_, err = Load(strings.NewReader(`{"entries":[{"ingredient":"X","regions":[{"region":"EU","status":"frowned_upon"}]}]}`))
+	require.ErrorContains(t, err, "unknown status")
+}
+
+func TestApplyAttachesStatusesWithoutHomeRegion(t *testing.T) {
+	result := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{
+			{IngredientName: "Titanium Dioxide", AdditiveCode: "E171", SafetyScore: "HIGH"},
+			{IngredientName: "Water", SafetyScore: "HIGH"},
+		},
+		OverallScore: 9,
+	}
+
+	Default().Apply(result, "")
+	require.NotEmpty(t, result.IngredientScores[0].Regulatory)
+	require.Empty(t, result.IngredientScores[1].Regulatory)
+	require.Equal(t, model.FlexibleString("HIGH"), result.IngredientScores[0].SafetyScore)
+	require.Equal(t, 9.0, result.OverallScore)
+}
+
+func TestApplyDowngradesForHomeRegion(t *testing.T) {
+	result := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{
+			{IngredientName: "Titanium Dioxide", AdditiveCode: "E171", SafetyScore: "HIGH", Reasoning: "Inert whitener."},
+			{IngredientName: "Carrageenan", SafetyScore: "HIGH"},
+			{IngredientName: "Water", SafetyScore: "HIGH"},
+			{IngredientName: "Salt", SafetyScore: "MEDIUM"},
+		},
+		OverallScore: 8.5,
+	}
+
+	Default().Apply(result, "eu")
+	require.Equal(t, model.FlexibleString("LOW"), result.IngredientScores[0].SafetyScore)
+	require.Contains(t, result.IngredientScores[0].Reasoning, "Banned in your home region (EU).")
+	require.Equal(t, model.FlexibleString("MEDIUM"), result.IngredientScores[1].SafetyScore)
+	require.Contains(t, result.IngredientScores[1].Reasoning, "Restricted in your home region (EU).")
+	// (10 + 5) points lost across four ingredients.
+	require.Equal(t, 4.8, result.OverallScore)
+}
+
+func TestApplyLeavesPermittedIngredientsAlone(t *testing.T) {
+	result := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{{IngredientName: "Titanium Dioxide", SafetyScore: "MEDIUM"}},
+		OverallScore:     6,
+	}
+
+	Default().Apply(result, "US")
+	require.Equal(t, model.FlexibleString("MEDIUM"), result.IngredientScores[0].SafetyScore)
+	require.Equal(t, 6.0, result.OverallScore)
+}
@@ -0,0 +1,517 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/adk/session"
+)
+
+type agentSessionQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// agentSessionRepo implements the ADK session.Service on Postgres. Sessions
+// keep their own state; "app:" and "user:" keys are shared through the
+// agent_app_states and agent_user_states tables, and "temp:" keys never
+// leave the invocation, matching the in-memory service.
+type agentSessionRepo struct {
+	q   agentSessionQuerier
+	now func() time.Time
+}
+
+func NewAgentSessionService(db *DB) AgentSessionRepository {
+	return &agentSessionRepo{q: db.Pool, now: time.Now}
+}
+
+func (r *agentSessionRepo) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+	appDelta, userDelta, sessionState := splitStateDelta(req.State)
+
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create agent session: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const insert = `
+		INSERT INTO agent_sessions (app_name, user_id, id, state, updated_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5)
+		ON CONFLICT DO NOTHING
+		RETURNING updated_at`
+
+	stateJSON, err := marshalState(sessionState)
+	if err != nil {
+		return nil, err
+	}
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, insert, req.AppName, req.UserID, sessionID, stateJSON, r.now().UTC()).Scan(&updatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("session %s already exists", sessionID)
+		}
+		return nil, fmt.Errorf("create agent session: %w", err)
+	}
+	if err := upsertSharedState(ctx, tx, req.AppName, req.UserID, appDelta, userDelta); err != nil {
+		return nil, err
+	}
+
+	appState, userState, err := loadSharedState(ctx, tx, req.AppName, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit agent session: %w", err)
+	}
+
+	return &session.CreateResponse{Session: &agentSession{
+		appName:   req.AppName,
+		userID:    req.UserID,
+		id:        sessionID,
+		state:     mergeState(appState, userState, sessionState),
+		updatedAt: updatedAt,
+	}}, nil
+}
+
+func (r *agentSessionRepo) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+
+	const query = `
+		SELECT s.state, s.updated_at, COALESCE(a.state, '{}'), COALESCE(u.state, '{}')
+		FROM agent_sessions s
+		LEFT JOIN agent_app_states a ON a.app_name = s.app_name
+		LEFT JOIN agent_user_states u ON u.app_name = s.app_name AND u.user_id = s.user_id
+		WHERE s.app_name = $1 AND s.user_id = $2 AND s.id = $3`
+
+	var sessionJSON, appJSON, userJSON []byte
+	sess := &agentSession{appName: req.AppName, userID: req.UserID, id: req.SessionID}
+	err := r.q.QueryRow(ctx, query, req.AppName, req.UserID, req.SessionID).Scan(&sessionJSON, &sess.updatedAt, &appJSON, &userJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("session %s: %w", req.SessionID, ErrNotFound)
+		}
+		return nil, fmt.Errorf("get agent session: %w", err)
+	}
+	if sess.state, err = decodeStates(appJSON, userJSON, sessionJSON); err != nil {
+		return nil, err
+	}
+
+	// The newest NumRecentEvents events are picked first and then filtered
+	// by After, the same order the in-memory service applies them in.
+	const eventsQuery = `
+		SELECT event FROM (
+			SELECT seq, event, timestamp
+			FROM agent_session_events
+			WHERE app_name = $1 AND user_id = $2 AND session_id = $3
+			ORDER BY seq DESC
+			LIMIT $4
+		) recent
+		WHERE timestamp >= $5
+		ORDER BY seq`
+
+	var limit *int
+	if req.NumRecentEvents > 0 {
+		limit = &req.NumRecentEvents
+	}
+	rows, err := r.q.Query(ctx, eventsQuery, req.AppName, req.UserID, req.SessionID, limit, req.After)
+	if err != nil {
+		return nil, fmt.Errorf("list agent session events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan agent session event: %w", err)
+		}
+		var event session.Event
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, fmt.Errorf("decode agent session event: %w", err)
+		}
+		sess.events = append(sess.events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate agent session events: %w", err)
+	}
+
+	return &session.GetResponse{Session: sess}, nil
+}
+
+// List returns the sessions of an app without their events, newest first.
+// An empty UserID lists the sessions of every user.
+func (r *agentSessionRepo) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+
+	const query = `
+		SELECT s.user_id, s.id, s.state, s.updated_at, COALESCE(a.state, '{}'), COALESCE(u.state, '{}')
+		FROM agent_sessions s
+		LEFT JOIN agent_app_states a ON a.app_name = s.app_name
+		LEFT JOIN agent_user_states u ON u.app_name = s.app_name AND u.user_id = s.user_id
+		WHERE s.app_name = $1 AND ($2 = '' OR s.user_id = $2)
+		ORDER BY s.updated_at DESC`
+
+	rows, err := r.q.Query(ctx, query, req.AppName, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("list agent sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]session.Session, 0)
+	for rows.Next() {
+		var sessionJSON, appJSON, userJSON []byte
+		sess := &agentSession{appName: req.AppName}
+		if err := rows.Scan(&sess.userID, &sess.id, &sessionJSON, &sess.updatedAt, &appJSON, &userJSON); err != nil {
+			return nil, fmt.Errorf("scan agent session: %w", err)
+		}
+		if sess.state, err = decodeStates(appJSON, userJSON, sessionJSON); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate agent sessions: %w", err)
+	}
+
+	return &session.ListResponse{Sessions: sessions}, nil
+}
+
+// Delete removes a session; its events go with it through ON DELETE CASCADE.
+func (r *agentSessionRepo) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+
+	const query = `DELETE FROM agent_sessions WHERE app_name = $1 AND user_id = $2 AND id = $3`
+	if _, err := r.q.Exec(ctx, query, req.AppName, req.UserID, req.SessionID); err != nil {
+		return fmt.Errorf("delete agent session: %w", err)
+	}
+	return nil
+}
+
+// DeleteIdle removes the sessions not updated since before, with their
+// events.
+func (r *agentSessionRepo) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	const query = `DELETE FROM agent_sessions WHERE updated_at < $1`
+	tag, err := r.q.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete idle agent sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// AppendEvent applies the event to the caller's session and then persists
+// the event and its state delta in one transaction. Partial (streaming)
+// events are skipped; the runner appends the final event once complete.
+func (r *agentSessionRepo) AppendEvent(ctx context.Context, cur session.Session, event *session.Event) error {
+	if cur == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	if event.Partial {
+		return nil
+	}
+	sess, ok := cur.(*agentSession)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", cur)
+	}
+
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = r.now().UTC()
+	}
+	sess.append(event)
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal agent session event: %w", err)
+	}
+	appDelta, userDelta, sessionDelta := splitStateDelta(event.Actions.StateDelta)
+	deltaJSON, err := marshalState(sessionDelta)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin append agent session event: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const update = `
+		UPDATE agent_sessions
+		SET state = state || $4::jsonb, updated_at = $5
+		WHERE app_name = $1 AND user_id = $2 AND id = $3`
+
+	tag, err := tx.Exec(ctx, update, sess.appName, sess.userID, sess.id, deltaJSON, event.Timestamp)
+	if err != nil {
+		return fmt.Errorf("update agent session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("session %s: %w", sess.id, ErrNotFound)
+	}
+
+	const insert = `
+		INSERT INTO agent_session_events (id, app_name, user_id, session_id, invocation_id, author, timestamp, event)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb)`
+
+	if _, err := tx.Exec(ctx, insert, event.ID, sess.appName, sess.userID, sess.id, event.InvocationID, event.Author, event.Timestamp, eventJSON); err != nil {
+		return fmt.Errorf("insert agent session event: %w", err)
+	}
+	if err := upsertSharedState(ctx, tx, sess.appName, sess.userID, appDelta, userDelta); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit agent session event: %w", err)
+	}
+	return nil
+}
+
+func upsertSharedState(ctx context.Context, tx pgx.Tx, appName, userID string, appDelta, userDelta map[string]any) error {
+	if len(appDelta) > 0 {
+		deltaJSON, err := marshalState(appDelta)
+		if err != nil {
+			return err
+		}
+		const query = `
+			INSERT INTO agent_app_states (app_name, state) VALUES ($1, $2::jsonb)
+			ON CONFLICT (app_name) DO UPDATE SET state = agent_app_states.state || EXCLUDED.state`
+		if _, err := tx.Exec(ctx, query, appName, deltaJSON); err != nil {
+			return fmt.Errorf("update agent app state: %w", err)
+		}
+	}
+	if len(userDelta) > 0 {
+		deltaJSON, err := marshalState(userDelta)
+		if err != nil {
+			return err
+		}
+		const query = `
+			INSERT INTO agent_user_states (app_name, user_id, state) VALUES ($1, $2, $3::jsonb)
+			ON CONFLICT (app_name, user_id) DO UPDATE SET state = agent_user_states.state || EXCLUDED.state`
+		if _, err := tx.Exec(ctx, query, appName, userID, deltaJSON); err != nil {
+			return fmt.Errorf("update agent user state: %w", err)
+		}
+	}
+	return nil
+}
+
+func loadSharedState(ctx context.Context, tx pgx.Tx, appName, userID string) (appState, userState map[string]any, err error) {
+	const query = `
+		SELECT
+			COALESCE((SELECT state FROM agent_app_states WHERE app_name = $1), '{}'),
+			COALESCE((SELECT state FROM agent_user_states WHERE app_name = $1 AND user_id = $2), '{}')`
+
+	var appJSON, userJSON []byte
+	if err := tx.QueryRow(ctx, query, appName, userID).Scan(&appJSON, &userJSON); err != nil {
+		return nil, nil, fmt.Errorf("load agent shared state: %w", err)
+	}
+	if appState, err = unmarshalState(appJSON); err != nil {
+		return nil, nil, err
+	}
+	if userState, err = unmarshalState(userJSON); err != nil {
+		return nil, nil, err
+	}
+	return appState, userState, nil
+}
+
+// splitStateDelta sorts a state delta by key prefix, stripping "app:" and
+// "user:" and dropping "temp:" keys.
+func splitStateDelta(delta map[string]any) (appDelta, userDelta, sessionDelta map[string]any) {
+	appDelta, userDelta, sessionDelta = map[string]any{}, map[string]any{}, map[string]any{}
+	for key, value := range delta {
+		switch {
+		case strings.HasPrefix(key, session.KeyPrefixApp):
+			appDelta[strings.TrimPrefix(key, session.KeyPrefixApp)] = value
+		case strings.HasPrefix(key, session.KeyPrefixUser):
+			userDelta[strings.TrimPrefix(key, session.KeyPrefixUser)] = value
+		case strings.HasPrefix(key, session.KeyPrefixTemp):
+		default:
+			sessionDelta[key] = value
+		}
+	}
+	return appDelta, userDelta, sessionDelta
+}
+
+// mergeState is the inverse of splitStateDelta: the state a session sees.
+func mergeState(appState, userState, sessionState map[string]any) map[string]any {
+	merged := maps.Clone(sessionState)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	for key, value := range appState {
+		merged[session.KeyPrefixApp+key] = value
+	}
+	for key, value := range userState {
+		merged[session.KeyPrefixUser+key] = value
+	}
+	return merged
+}
+
+func decodeStates(appJSON, userJSON, sessionJSON []byte) (map[string]any, error) {
+	appState, err := unmarshalState(appJSON)
+	if err != nil {
+		return nil, err
+	}
+	userState, err := unmarshalState(userJSON)
+	if err != nil {
+		return nil, err
+	}
+	sessionState, err := unmarshalState(sessionJSON)
+	if err != nil {
+		return nil, err
+	}
+	return mergeState(appState, userState, sessionState), nil
+}
+
+func marshalState(state map[string]any) ([]byte, error) {
+	if state == nil {
+		state = map[string]any{}
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("marshal agent state: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalState(data []byte) (map[string]any, error) {
+	state := map[string]any{}
+	if len(data) == 0 {
+		return state, nil
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode agent state: %w", err)
+	}
+	return state, nil
+}
+
+// agentSession is the session handed to the ADK runner. The runner mutates
+// it as events arrive, so state and events are guarded by a mutex.
+type agentSession struct {
+	appName string
+	userID  string
+	id      string
+
+	mu        sync.RWMutex
+	state     map[string]any
+	events    []*session.Event
+	updatedAt time.Time
+}
+
+func (s *agentSession) ID() string      { return s.id }
+func (s *agentSession) AppName() string { return s.appName }
+func (s *agentSession) UserID() string  { return s.userID }
+
+func (s *agentSession) State() session.State   { return agentSessionState{s} }
+func (s *agentSession) Events() session.Events { return agentSessionEvents{s} }
+
+func (s *agentSession) LastUpdateTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+// append mirrors the in-memory service: the full delta, temp keys included,
+// lands in the live session, but the stored event keeps only durable keys.
+func (s *agentSession) append(event *session.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(event.Actions.StateDelta) > 0 {
+		if s.state == nil {
+			s.state = map[string]any{}
+		}
+		maps.Copy(s.state, event.Actions.StateDelta)
+
+		durable := make(map[string]any, len(event.Actions.StateDelta))
+		for key, value := range event.Actions.StateDelta {
+			if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+				durable[key] = value
+			}
+		}
+		event.Actions.StateDelta = durable
+	}
+	s.events = append(s.events, event)
+	s.updatedAt = event.Timestamp
+}
+
+type agentSessionState struct{ s *agentSession }
+
+func (st agentSessionState) Get(key string) (any, error) {
+	st.s.mu.RLock()
+	defer st.s.mu.RUnlock()
+	value, ok := st.s.state[key]
+	if !ok {
+		return nil, session.ErrStateKeyNotExist
+	}
+	return value, nil
+}
+
+func (st agentSessionState) Set(key string, value any) error {
+	st.s.mu.Lock()
+	defer st.s.mu.Unlock()
+	if st.s.state == nil {
+		st.s.state = map[string]any{}
+	}
+	st.s.state[key] = value
+	return nil
+}
+
+func (st agentSessionState) All() iter.Seq2[string, any] {
+	st.s.mu.RLock()
+	snapshot := maps.Clone(st.s.state)
+	st.s.mu.RUnlock()
+	return maps.All(snapshot)
+}
+
+type agentSessionEvents struct{ s *agentSession }
+
+func (ev agentSessionEvents) All() iter.Seq[*session.Event] {
+	ev.s.mu.RLock()
+	snapshot := append([]*session.Event(nil), ev.s.events...)
+	ev.s.mu.RUnlock()
+	return func(yield func(*session.Event) bool) {
+		for _, event := range snapshot {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+func (ev agentSessionEvents) Len() int {
+	ev.s.mu.RLock()
+	defer ev.s.mu.RUnlock()
+	return len(ev.s.events)
+}
+
+func (ev agentSessionEvents) At(i int) *session.Event {
+	ev.s.mu.RLock()
+	defer ev.s.mu.RUnlock()
+	return ev.s.events[i]
+}
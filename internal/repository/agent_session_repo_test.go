@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func newAgentSessionRepo(t *testing.T) (*agentSessionRepo, pgxmock.PgxPoolIface, time.Time) {
+	t.Helper()
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(mock.Close)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &agentSessionRepo{q: mock, now: func() time.Time { return now }}, mock, now
+}
+
+func TestAgentSessionRepoCreateSplitsState(t *testing.T) {
+	repo, mock, now := newAgentSessionRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO agent_sessions").
+		WithArgs("ScorerAgent", "user-1", "scan-1/ScorerAgent/1", []byte(`{"step":"score"}`), now).
+		WillReturnRows(pgxmock.NewRows([]string{"updated_at"}).AddRow(now))
+	mock.ExpectExec("INSERT INTO agent_user_states").
+		WithArgs("ScorerAgent", "user-1", []byte(`{"region":"EU"}`)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("SELECT").
+		WithArgs("ScorerAgent", "user-1").
+		WillReturnRows(pgxmock.NewRows([]string{"app", "user"}).AddRow([]byte(`{"version":2}`), []byte(`{"region":"EU"}`)))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	resp, err := repo.Create(context.Background(), &session.CreateRequest{
+		AppName:   "ScorerAgent",
+		UserID:    "user-1",
+		SessionID: "scan-1/ScorerAgent/1",
+		State:     map[string]any{"step": "score", "user:region": "EU", "temp:scratch": true},
+	})
+	require.NoError(t, err)
+	sess := resp.Session
+	require.Equal(t, "scan-1/ScorerAgent/1", sess.ID())
+	require.Equal(t, now, sess.LastUpdateTime())
+
+	region, err := sess.State().Get("user:region")
+	require.NoError(t, err)
+	require.Equal(t, "EU", region)
+	version, err := sess.State().Get("app:version")
+	require.NoError(t, err)
+	require.Equal(t, float64(2), version)
+	_, err = sess.State().Get("temp:scratch")
+	require.ErrorIs(t, err, session.ErrStateKeyNotExist)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentSessionRepoCreateExisting(t *testing.T) {
+	repo, mock, now := newAgentSessionRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO agent_sessions").
+		WithArgs("ScorerAgent", "user-1", "s-1", []byte(`{}`), now).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.Create(context.Background(), &session.CreateRequest{AppName: "ScorerAgent", UserID: "user-1", SessionID: "s-1"})
+	require.ErrorContains(t, err, "already exists")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentSessionRepoGetLoadsEvents(t *testing.T) {
+	repo, mock, now := newAgentSessionRepo(t)
+
+	event := session.NewEvent("inv-1")
+	event.Author = "ScorerAgent"
+	event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("score: 7", genai.RoleModel)}
+	eventJSON, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT s.state, s.updated_at").
+		WithArgs("ScorerAgent", "user-1", "s-1").
+		WillReturnRows(pgxmock.NewRows([]string{"state", "updated_at", "app", "user"}).
+			AddRow([]byte(`{"step":"score"}`), now, []byte(`{}`), []byte(`{}`)))
+	limit := 5
+	mock.ExpectQuery("SELECT event FROM").
+		WithArgs("ScorerAgent", "user-1", "s-1", &limit, time.Time{}).
+		WillReturnRows(pgxmock.NewRows([]string{"event"}).AddRow(eventJSON))
+
+	resp, err := repo.Get(context.Background(), &session.GetRequest{AppName: "ScorerAgent", UserID: "user-1", SessionID: "s-1", NumRecentEvents: 5})
+	require.NoError(t, err)
+	events := resp.Session.Events()
+	require.Equal(t, 1, events.Len())
+	require.Equal(t, event.ID, events.At(0).ID)
+	require.Equal(t, "inv-1", events.At(0).InvocationID)
+	require.Equal(t, "score: 7", events.At(0).Content.Parts[0].Text)
+	step, err := resp.Session.State().Get("step")
+	require.NoError(t, err)
+	require.Equal(t, "score", step)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentSessionRepoGetNotFound(t *testing.T) {
+	repo, mock, _ := newAgentSessionRepo(t)
+
+	mock.ExpectQuery("SELECT s.state, s.updated_at").
+		WithArgs("ScorerAgent", "user-1", "missing").
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err := repo.Get(context.Background(), &session.GetRequest{AppName: "ScorerAgent", UserID: "user-1", SessionID: "missing"})
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentSessionRepoAppendEventPersistsDelta(t *testing.T) {
+	repo, mock, now := newAgentSessionRepo(t)
+	sess := &agentSession{appName: "ScorerAgent", userID: "user-1", id: "s-1", state: map[string]any{}}
+
+	event := session.NewEvent("inv-1")
+	event.Timestamp = now
+	event.Author = "ScorerAgent"
+	event.Actions.StateDelta = map[string]any{"score": 7, "app:model": "flash", "temp:draft": "x"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE agent_sessions").
+		WithArgs("ScorerAgent", "user-1", "s-1", []byte(`{"score":7}`), now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("INSERT INTO agent_session_events").
+		WithArgs(event.ID, "ScorerAgent", "user-1", "s-1", "inv-1", "ScorerAgent", now, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO agent_app_states").
+		WithArgs("ScorerAgent", []byte(`{"model":"flash"}`)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	require.NoError(t, repo.AppendEvent(context.Background(), sess, event))
+
+	draft, err := sess.State().Get("temp:draft")
+	require.NoError(t, err)
+	require.Equal(t, "x", draft)
+	require.NotContains(t, event.Actions.StateDelta, "temp:draft")
+	require.Equal(t, 1, sess.Events().Len())
+	require.Equal(t, now, sess.LastUpdateTime())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentSessionRepoAppendEventSkipsPartial(t *testing.T) {
+	repo, mock, _ := newAgentSessionRepo(t)
+	sess := &agentSession{appName: "ScorerAgent", userID: "user-1", id: "s-1"}
+
+	event := session.NewEvent("inv-1")
+	event.Partial = true
+
+	require.NoError(t, repo.AppendEvent(context.Background(), sess, event))
+	require.Equal(t, 0, sess.Events().Len())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentSessionRepoAppendEventMissingSession(t *testing.T) {
+	repo, mock, now := newAgentSessionRepo(t)
+	sess := &agentSession{appName: "ScorerAgent", userID: "user-1", id: "gone"}
+
+	event := session.NewEvent("inv-1")
+	event.Timestamp = now
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE agent_sessions").
+		WithArgs("ScorerAgent", "user-1", "gone", []byte(`{}`), now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+
+	err := repo.AppendEvent(context.Background(), sess, event)
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentSessionRepoDeleteIdle(t *testing.T) {
+	repo, mock, now := newAgentSessionRepo(t)
+	before := now.Add(-720 * time.Hour)
+
+	mock.ExpectExec("DELETE FROM agent_sessions WHERE updated_at < \\$1").
+		WithArgs(before).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	n, err := repo.DeleteIdle(context.Background(), before)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
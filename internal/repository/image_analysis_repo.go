@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+type imageAnalysisQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+type imageAnalysisRepo struct {
+	q imageAnalysisQuerier
+}
+
+func NewImageAnalysisRepository(db *DB) ImageAnalysisRepository {
+	return &imageAnalysisRepo{q: db.Pool}
+}
+
+// MaxPHashDistance is the largest distance FindNearest searches; larger
+// values are clamped. Lookups go through the phash_band indexes, and each
+// extra bit of tolerance every four bits multiplies the keys probed per band:
+// 1, 17, and then 137 at this limit.
+const MaxPHashDistance = 11
+
+// phashBands is how many 16-bit bands the hash is indexed as.
+const phashBands = 4
+
+// Hashes are stored as BIGINT, so the unsigned hash is reinterpreted as a
+// signed integer on the way in and out; XOR and bit_count are unaffected.
+//
+// Candidates are narrowed through the band indexes before any distance is
+// computed: two hashes at most maxDistance bits apart must have a band that
+// differs in at most maxDistance/4 of its bits.
+func (r *imageAnalysisRepo) FindNearest(ctx context.Context, phash uint64, maxDistance int, preferencesKey string, since time.Time) (*model.ImageAnalysis, error) {
+	const query = `
+		SELECT id, phash, product_name, label_language, ocr_confidence, preferences_key, analysis, created_at, distance
+		FROM (
+			SELECT *, bit_count((phash # $1)::bit(64))::int AS distance
+			FROM image_analyses
+			WHERE created_at >= $3
+			  AND (phash_band0 = ANY($5) OR phash_band1 = ANY($6) OR phash_band2 = ANY($7) OR phash_band3 = ANY($8))
+		) candidates
+		WHERE distance <= $2
+		ORDER BY (preferences_key = $4 AND analysis IS NOT NULL) DESC, distance, created_at DESC
+		LIMIT 1`
+
+	maxDistance = min(maxDistance, MaxPHashDistance)
+	radius := maxDistance / phashBands
+	args := []interface{}{int64(phash), maxDistance, since, preferencesKey}
+	for band := range phashBands {
+		args = append(args, bandNeighbours(phashBand(phash, band), radius))
+	}
+
+	var entry model.ImageAnalysis
+	var hash int64
+	var analysisBytes []byte
+	err := r.q.QueryRow(ctx, query, args...).Scan(
+		&entry.ID,
+		&hash,
+		&entry.Label.ProductName,
+		&entry.Label.Language,
+		&entry.Label.Confidence,
+		&entry.PreferencesKey,
+		&analysisBytes,
+		&entry.CreatedAt,
+		&entry.Distance,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("find nearest image analysis: %w", err)
+	}
+	entry.PHash = uint64(hash)
+
+	if len(analysisBytes) > 0 && string(analysisBytes) != "null" {
+		var analysis model.AnalysisResult
+		if err := json.Unmarshal(analysisBytes, &analysis); err != nil {
+			return nil, fmt.Errorf("decode analysis: %w", err)
+		}
+		entry.Analysis = &analysis
+	}
+
+	return &entry, nil
+}
+
+// phashBand returns band 0-3 of the hash, counted from the most significant
+// bits, matching the phash_band columns.
+func phashBand(phash uint64, band int) uint16 {
+	return uint16(phash >> (48 - 16*band))
+}
+
+// bandNeighbours lists every 16-bit value within radius bits of band.
+func bandNeighbours(band uint16, radius int) []int32 {
+	out := []int32{int32(band)}
+	var flip func(v uint16, from, left int)
+	flip = func(v uint16, from, left int) {
+		for bit := from; bit < 16; bit++ {
+			w := v ^ 1<<bit
+			out = append(out, int32(w))
+			if left > 1 {
+				flip(w, bit+1, left-1)
+			}
+		}
+	}
+	if radius > 0 {
+		flip(band, 0, radius)
+	}
+	return out
+}
+
+func (r *imageAnalysisRepo) Create(ctx context.Context, entry *model.ImageAnalysis) error {
+	var analysisJSON []byte
+	if entry.Analysis != nil {
+		var err error
+		if analysisJSON, err = json.Marshal(entry.Analysis); err != nil {
+			return fmt.Errorf("marshal analysis: %w", err)
+		}
+	}
+
+	const query = `
+		INSERT INTO image_analyses (phash, product_name, label_language, ocr_confidence, preferences_key, analysis)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+		RETURNING id, created_at`
+
+	err := r.q.QueryRow(
+		ctx,
+		query,
+		int64(entry.PHash),
+		entry.Label.ProductName,
+		entry.Label.Language,
+		entry.Label.Confidence,
+		entry.PreferencesKey,
+		analysisJSON,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create image analysis: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageAnalysisRepoFindNearestSuccess(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	since := now.Add(-time.Hour)
+	hash := uint64(0xF0F0F0F0F0F0F0F0)
+	rows := pgxmock.NewRows([]string{"id", "phash", "product_name", "label_language", "ocr_confidence", "preferences_key", "analysis", "created_at", "distance"}).
+		AddRow(int64(7), int64(-1085102592571150096), "Oatly Oat Milk", "sv", 0.92, "", []byte(`{"product_name":"Oatly Oat Milk","ingredient_breakdown":{"overall_score":8.5}}`), now, 3)
+
+	mock.ExpectQuery("SELECT id, phash").WithArgs(phashArgs(hash, 6, since, "")...).WillReturnRows(rows)
+
+	repo := &imageAnalysisRepo{q: mock}
+	entry, err := repo.FindNearest(context.Background(), hash, 6, "", since)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), entry.ID)
+	require.Equal(t, hash, entry.PHash)
+	require.Equal(t, "Oatly Oat Milk", entry.Label.ProductName)
+	require.Equal(t, "sv", entry.Label.Language)
+	require.Equal(t, 3, entry.Distance)
+	require.NotNil(t, entry.Analysis)
+	require.Equal(t, 8.5, entry.Analysis.IngredientBreakdown.OverallScore)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImageAnalysisRepoFindNearestNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	since := time.Now().UTC()
+	mock.ExpectQuery("SELECT id, phash").WithArgs(phashArgs(1, 6, since, "")...).WillReturnError(pgx.ErrNoRows)
+
+	repo := &imageAnalysisRepo{q: mock}
+	_, err = repo.FindNearest(context.Background(), 1, 6, "", since)
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// phashArgs are the query arguments FindNearest sends for a lookup.
+func phashArgs(hash uint64, maxDistance int, since time.Time, preferencesKey string) []interface{} {
+	args := []interface{}{int64(hash), maxDistance, since, preferencesKey}
+	for band := range phashBands {
+		args = append(args, bandNeighbours(phashBand(hash, band), maxDistance/phashBands))
+	}
+	return args
+}
+
+func TestImageAnalysisRepoFindNearestProbesBandIndexes(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	since := time.Now().UTC()
+	mock.ExpectQuery(`WHERE created_at >= \$3\s+AND \(phash_band0 = ANY\(\$5\) OR phash_band1 = ANY\(\$6\) OR phash_band2 = ANY\(\$7\) OR phash_band3 = ANY\(\$8\)\)`).
+		WithArgs(phashArgs(1, MaxPHashDistance, since, "")...).
+		WillReturnError(pgx.ErrNoRows)
+
+	repo := &imageAnalysisRepo{q: mock}
+	_, err = repo.FindNearest(context.Background(), 1, 64, "", since)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// Every band the lookup filters on leads an index, so candidates come
+	// from index probes rather than a scan of the TTL window.
+	migration, err := os.ReadFile("../../migrations/017_add_image_analysis_phash_bands.up.sql")
+	require.NoError(t, err)
+	for band := range phashBands {
+		require.Regexp(t, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS \w+ ON image_analyses\(phash_band%d,`, band), string(migration))
+	}
+}
+
+func TestBandNeighboursFindEveryHashWithinMaxDistance(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 2000; i++ {
+		hash := rng.Uint64()
+		distance := rng.IntN(MaxPHashDistance + 1)
+		other := hash
+		for _, bit := range rng.Perm(64)[:distance] {
+			other ^= 1 << bit
+		}
+
+		found := false
+		for band := range phashBands {
+			if slices.Contains(bandNeighbours(phashBand(hash, band), distance/phashBands), int32(phashBand(other, band))) {
+				found = true
+			}
+		}
+		require.True(t, found, "%016x and %016x are %d bits apart", hash, other, distance)
+	}
+}
+
+func TestBandNeighboursCount(t *testing.T) {
+	require.Len(t, bandNeighbours(0xBEEF, 0), 1)
+	require.Len(t, bandNeighbours(0xBEEF, 1), 17)
+	neighbours := bandNeighbours(0xBEEF, 2)
+	require.Len(t, neighbours, 137)
+	slices.Sort(neighbours)
+	require.Len(t, slices.Compact(neighbours), 137, "no value is listed twice")
+}
+
+func TestImageAnalysisRepoCreateWithoutAnalysis(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectQuery("INSERT INTO image_analyses").
+		WithArgs(int64(42), "Granola Bar", "en", 0.8, "", []byte(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(1), now))
+
+	repo := &imageAnalysisRepo{q: mock}
+	entry := &model.ImageAnalysis{PHash: 42, Label: model.LabelReading{ProductName: "Granola Bar", Language: "en", Confidence: 0.8}}
+	require.NoError(t, repo.Create(context.Background(), entry))
+	require.Equal(t, int64(1), entry.ID)
+	require.Equal(t, now, entry.CreatedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
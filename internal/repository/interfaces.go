@@ -3,20 +3,77 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/safebites/backend-go/internal/model"
+	"google.golang.org/adk/session"
 )
 
 var ErrNotFound = errors.New("not found")
 
+// UserRepository stores users. The dietary lists written by Upsert and
+// UpdatePreferences are the user's own entries; users are read back with
+// their applied templates merged in (see model.MergeLayers). Every change to
+// the merged preferences is recorded as a new version of the user's
+// preference history.
 type UserRepository interface {
 	GetByID(ctx context.Context, userID string) (*model.User, error)
 	Upsert(ctx context.Context, user *model.User) (*model.User, error)
 	UpdatePreferences(ctx context.Context, userID string, preferences model.UserPreferences) (*model.User, error)
+	// AddTemplateLayer applies layer.Key. A Snapshot layer stores its own
+	// name and lists; any other layer follows the template. It returns
+	// ErrNotFound when the user, or the template a layer would follow, does
+	// not exist.
+	AddTemplateLayer(ctx context.Context, userID string, layer model.TemplateLayer) error
+	// RemoveTemplateLayer returns ErrNotFound when the template is not
+	// applied to the user.
+	RemoveTemplateLayer(ctx context.Context, userID, templateKey string) error
+	// ListPreferenceHistory returns the user's preference versions, newest
+	// first.
+	ListPreferenceHistory(ctx context.Context, userID string, limit int) ([]model.PreferenceVersion, error)
+}
+
+// ProfileRepository stores the household profiles of an account. Get,
+// Update, and Delete return ErrNotFound when the profile does not exist or
+// belongs to another user.
+type ProfileRepository interface {
+	ListByUser(ctx context.Context, userID string) ([]model.Profile, error)
+	Get(ctx context.Context, userID, profileID string) (*model.Profile, error)
+	Create(ctx context.Context, profile *model.Profile) (*model.Profile, error)
+	Update(ctx context.Context, profile *model.Profile) (*model.Profile, error)
+	Delete(ctx context.Context, userID, profileID string) error
+}
+
+// TemplateRepository stores dietary templates. Update, SetShareCode, and
+// Delete only match templates the user owns and return ErrNotFound
+// otherwise, so built-in templates cannot be changed through them.
+type TemplateRepository interface {
+	// UpsertBuiltIns writes the built-in templates that differ from the
+	// stored versions, recording a preference version for each user whose
+	// layer follows one that changed.
+	UpsertBuiltIns(ctx context.Context, templates []model.DietaryTemplate) error
+	// List returns the built-in templates ordered by key, followed by the
+	// templates userID owns, oldest first.
+	List(ctx context.Context, userID string) ([]model.DietaryTemplate, error)
+	// Resolve finds ref as the key of a built-in template or of one userID
+	// owns, or as the share code of a published template.
+	Resolve(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error)
+	Create(ctx context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error)
+	Update(ctx context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error)
+	// SetShareCode publishes the template under shareCode, or unpublishes it
+	// when shareCode is empty.
+	SetShareCode(ctx context.Context, userID, key, shareCode string) (*model.DietaryTemplate, error)
+	Delete(ctx context.Context, userID, key string) error
 }
 
 type ScanRepository interface {
-	ListByUser(ctx context.Context, userID string, limit int) ([]model.Scan, error)
+	// ListByUser returns one page of the user's scans matching filter. It
+	// returns ErrInvalidCursor when filter.Cursor was not issued for
+	// filter.Sort.
+	ListByUser(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error)
+	// GetByID returns ErrNotFound when the scan does not exist or belongs
+	// to another user.
+	GetByID(ctx context.Context, userID, scanID string) (*model.Scan, error)
 	Create(ctx context.Context, scan *model.Scan) (*model.Scan, error)
 	GetStats(ctx context.Context, userID string) (*model.UserStats, error)
 }
@@ -27,3 +84,45 @@ type FavoriteRepository interface {
 	Delete(ctx context.Context, userID string, favoriteID int) error
 	Exists(ctx context.Context, userID, productName string) (bool, error)
 }
+
+type ImageAnalysisRepository interface {
+	// FindNearest returns the cached analysis created at or after since whose
+	// perceptual hash is closest to phash and at most maxDistance bits away,
+	// preferring entries scored against preferencesKey. maxDistance is capped
+	// at MaxPHashDistance. It returns ErrNotFound when nothing is close enough.
+	FindNearest(ctx context.Context, phash uint64, maxDistance int, preferencesKey string, since time.Time) (*model.ImageAnalysis, error)
+	Create(ctx context.Context, entry *model.ImageAnalysis) error
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	// GetSubscription returns ErrNotFound when the subscription does not exist
+	// or belongs to another user.
+	GetSubscription(ctx context.Context, userID, subscriptionID string) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, userID string) ([]model.WebhookSubscription, error)
+	// ListSubscribers returns the user's subscriptions to eventType.
+	ListSubscribers(ctx context.Context, userID, eventType string) ([]model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, userID, subscriptionID string) error
+
+	CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error)
+	// GetDelivery returns ErrNotFound when the delivery does not exist or
+	// belongs to another user's subscription.
+	GetDelivery(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]model.WebhookDelivery, error)
+	// ClaimDue returns up to limit pending deliveries due at now and moves
+	// their next attempt to leaseUntil, so a worker that dies mid-send leaves
+	// them to be retried rather than lost.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.WebhookAttempt, error)
+	// RecordAttempt stores the status, attempt count, and schedule of a
+	// delivery after an attempt.
+	RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+// AgentSessionRepository is the ADK session.Service on Postgres. Sessions
+// belong to a user and are deleted with them.
+type AgentSessionRepository interface {
+	session.Service
+	// DeleteIdle removes the sessions not updated since before, with their
+	// events, and returns how many it removed.
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
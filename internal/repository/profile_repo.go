@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+type profileQuerier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+type profileRepo struct {
+	q profileQuerier
+}
+
+func NewProfileRepository(db *DB) ProfileRepository {
+	return &profileRepo{q: db.Pool}
+}
+
+const profileColumns = `id, user_id, name, allergies, diet_goals, avoid_ingredients, created_at, updated_at`
+
+func (r *profileRepo) ListByUser(ctx context.Context, userID string) ([]model.Profile, error) {
+	const query = `
+		SELECT ` + profileColumns + `
+		FROM profiles
+		WHERE user_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.q.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []model.Profile{}
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+func (r *profileRepo) Get(ctx context.Context, userID, profileID string) (*model.Profile, error) {
+	const query = `SELECT ` + profileColumns + ` FROM profiles WHERE user_id = $1 AND id = $2`
+
+	profile, err := scanProfile(r.q.QueryRow(ctx, query, userID, profileID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return profile, err
+}
+
+func (r *profileRepo) Create(ctx context.Context, profile *model.Profile) (*model.Profile, error) {
+	lists, err := marshalPreferenceLists(profile.Allergies, profile.DietGoals, profile.AvoidIngredients)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		INSERT INTO profiles (id, user_id, name, allergies, diet_goals, avoid_ingredients)
+		VALUES ($1, $2, $3, $4::jsonb, $5::jsonb, $6::jsonb)
+		RETURNING ` + profileColumns
+
+	args := append([]interface{}{profile.ID, profile.UserID, profile.Name}, lists...)
+	created, err := scanProfile(r.q.QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("create profile: %w", err)
+	}
+	return created, nil
+}
+
+func (r *profileRepo) Update(ctx context.Context, profile *model.Profile) (*model.Profile, error) {
+	lists, err := marshalPreferenceLists(profile.Allergies, profile.DietGoals, profile.AvoidIngredients)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		UPDATE profiles
+		SET name = $3,
+			allergies = $4::jsonb,
+			diet_goals = $5::jsonb,
+			avoid_ingredients = $6::jsonb,
+			updated_at = NOW()
+		WHERE user_id = $1 AND id = $2
+		RETURNING ` + profileColumns
+
+	args := append([]interface{}{profile.UserID, profile.ID, profile.Name}, lists...)
+	updated, err := scanProfile(r.q.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("update profile: %w", err)
+	}
+	return updated, nil
+}
+
+func (r *profileRepo) Delete(ctx context.Context, userID, profileID string) error {
+	const query = `DELETE FROM profiles WHERE user_id = $1 AND id = $2`
+
+	cmdTag, err := r.q.Exec(ctx, query, userID, profileID)
+	if err != nil {
+		return fmt.Errorf("delete profile: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// marshalPreferenceLists encodes the three dietary lists for JSONB columns.
+func marshalPreferenceLists(allergies []model.Allergy, dietGoals, avoidIngredients []string) ([]interface{}, error) {
+	allergiesJSON, err := json.Marshal(allergies)
+	if err != nil {
+		return nil, fmt.Errorf("marshal allergies: %w", err)
+	}
+	dietGoalsJSON, err := json.Marshal(dietGoals)
+	if err != nil {
+		return nil, fmt.Errorf("marshal diet goals: %w", err)
+	}
+	avoidIngredientsJSON, err := json.Marshal(avoidIngredients)
+	if err != nil {
+		return nil, fmt.Errorf("marshal avoid ingredients: %w", err)
+	}
+	return []interface{}{allergiesJSON, dietGoalsJSON, avoidIngredientsJSON}, nil
+}
+
+// scanProfile reads one row of profileColumns. pgx.ErrNoRows is returned
+// unwrapped so callers can map it to ErrNotFound.
+func scanProfile(row pgx.Row) (*model.Profile, error) {
+	var profile model.Profile
+	var allergiesBytes []byte
+	var dietGoalsBytes []byte
+	var avoidIngredientsBytes []byte
+
+	err := row.Scan(
+		&profile.ID,
+		&profile.UserID,
+		&profile.Name,
+		&allergiesBytes,
+		&dietGoalsBytes,
+		&avoidIngredientsBytes,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan profile: %w", err)
+	}
+
+	if err := unmarshalAllergies(allergiesBytes, &profile.Allergies); err != nil {
+		return nil, fmt.Errorf("decode allergies: %w", err)
+	}
+	if err := unmarshalStringSlice(dietGoalsBytes, &profile.DietGoals); err != nil {
+		return nil, fmt.Errorf("decode diet goals: %w", err)
+	}
+	if err := unmarshalStringSlice(avoidIngredientsBytes, &profile.AvoidIngredients); err != nil {
+		return nil, fmt.Errorf("decode avoid ingredients: %w", err)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+var profileRowColumns = []string{"id", "user_id", "name", "allergies", "diet_goals", "avoid_ingredients", "created_at", "updated_at"}
+
+func TestProfileRepoCreate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectQuery("INSERT INTO profiles").
+		WithArgs("prof-1", "user-1", "Mia", []byte(`[{"name":"peanuts","severity":"allergy","mayContain":false}]`), []byte(`[]`), []byte(`["sesame"]`)).
+		WillReturnRows(pgxmock.NewRows(profileRowColumns).
+			AddRow("prof-1", "user-1", "Mia", []byte(`["peanuts"]`), []byte(`[]`), []byte(`["sesame"]`), now, now))
+
+	repo := &profileRepo{q: mock}
+	created, err := repo.Create(context.Background(), &model.Profile{
+		ID:               "prof-1",
+		UserID:           "user-1",
+		Name:             "Mia",
+		Allergies:        model.Allergies("peanuts"),
+		DietGoals:        []string{},
+		AvoidIngredients: []string{"sesame"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Mia", created.Name)
+	require.Equal(t, model.Allergies("peanuts"), created.Allergies)
+	require.Equal(t, []string{}, created.DietGoals)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProfileRepoListByUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectQuery("FROM profiles").WithArgs("user-1").
+		WillReturnRows(pgxmock.NewRows(profileRowColumns).
+			AddRow("prof-1", "user-1", "Mia", []byte(`["peanuts"]`), []byte(`[]`), []byte(`[]`), now, now).
+			AddRow("prof-2", "user-1", "Leo", []byte(`[]`), []byte(`["vegan"]`), []byte(`[]`), now, now))
+
+	repo := &profileRepo{q: mock}
+	profiles, err := repo.ListByUser(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	require.Equal(t, []string{"vegan"}, profiles[1].DietGoals)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProfileRepoNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery("FROM profiles").WithArgs("user-1", "prof-9").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery("UPDATE profiles").
+		WithArgs("user-1", "prof-9", "Mia", []byte(`null`), []byte(`null`), []byte(`null`)).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectExec("DELETE FROM profiles").WithArgs("user-1", "prof-9").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	repo := &profileRepo{q: mock}
+	_, err = repo.Get(context.Background(), "user-1", "prof-9")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.Update(context.Background(), &model.Profile{ID: "prof-9", UserID: "user-1", Name: "Mia"})
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, repo.Delete(context.Background(), "user-1", "prof-9"), ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,13 +2,47 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/safebites/backend-go/internal/model"
 )
 
+// ErrUnknownPreferenceVersion is returned by Create when the scan names a
+// preference version the user never had.
+var ErrUnknownPreferenceVersion = errors.New("unknown preference version")
+
+// ErrInvalidCursor is returned by ListByUser for a cursor it did not issue,
+// or one issued for a different sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// scanOrder is how ListByUser sorts for one model.ScanSort. after compares
+// the sort key of a row with the cursor's to find the rows that follow it.
+type scanOrder struct {
+	by      string
+	after   string
+	byScore bool
+}
+
+// scanOrders are backed by idx_scans_user_timestamp and idx_scans_user_score,
+// read forwards or backwards.
+var scanOrders = map[model.ScanSort]scanOrder{
+	model.ScanSortNewest:    {by: "timestamp DESC, id DESC", after: "<"},
+	model.ScanSortOldest:    {by: "timestamp ASC, id ASC", after: ">"},
+	model.ScanSortScoreDesc: {by: "safety_score DESC, timestamp DESC, id DESC", after: "<", byScore: true},
+	model.ScanSortScoreAsc:  {by: "safety_score ASC, timestamp ASC, id ASC", after: ">", byScore: true},
+}
+
+// scanColumns are the columns scanRow reads, in order.
+const scanColumns = `id, user_id, product_name, COALESCE(brand, ''), COALESCE(image, ''), image_id, safety_score, is_safe, ingredients, sources, confidence, COALESCE(preference_version, 0), timestamp`
+
 type scanQuerier interface {
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
@@ -22,19 +56,68 @@ func NewScanRepository(db *DB) ScanRepository {
 	return &scanRepo{q: db.Pool}
 }
 
-func (r *scanRepo) ListByUser(ctx context.Context, userID string, limit int) ([]model.Scan, error) {
+func (r *scanRepo) ListByUser(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
+	limit := filter.Limit
 	if limit <= 0 {
 		limit = 20
 	}
+	sort := filter.Sort
+	if sort == "" {
+		sort = model.ScanSortNewest
+	}
+	order, ok := scanOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown scan sort %q", sort)
+	}
 
-	const query = `
-		SELECT id, user_id, product_name, COALESCE(brand, ''), COALESCE(image, ''), safety_score, is_safe, ingredients, timestamp
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	where := []string{"user_id = $1"}
+	if !filter.From.IsZero() {
+		where = append(where, "timestamp >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "timestamp < "+arg(filter.To))
+	}
+	if filter.Safe != nil {
+		where = append(where, "is_safe = "+arg(*filter.Safe))
+	}
+	if filter.MinScore != nil {
+		where = append(where, "safety_score >= "+arg(*filter.MinScore))
+	}
+	if filter.MaxScore != nil {
+		where = append(where, "safety_score <= "+arg(*filter.MaxScore))
+	}
+	if filter.Brand != "" {
+		where = append(where, "brand ILIKE "+arg(containsPattern(filter.Brand)))
+	}
+	if filter.Product != "" {
+		where = append(where, "product_name ILIKE "+arg(containsPattern(filter.Product)))
+	}
+	if filter.Cursor != "" {
+		after, err := decodeScanCursor(filter.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		if order.byScore {
+			where = append(where, fmt.Sprintf("(safety_score, timestamp, id) %s (%s, %s, %s)", order.after, arg(after.Score), arg(after.Timestamp), arg(after.ID)))
+		} else {
+			where = append(where, fmt.Sprintf("(timestamp, id) %s (%s, %s)", order.after, arg(after.Timestamp), arg(after.ID)))
+		}
+	}
+
+	// One extra row tells whether another page follows.
+	query := `
+		SELECT ` + scanColumns + `
 		FROM scans
-		WHERE user_id = $1
-		ORDER BY timestamp DESC
-		LIMIT $2`
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + order.by + `
+		LIMIT ` + arg(limit+1)
 
-	rows, err := r.q.Query(ctx, query, userID, limit)
+	rows, err := r.q.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list scans by user: %w", err)
 	}
@@ -42,35 +125,116 @@ func (r *scanRepo) ListByUser(ctx context.Context, userID string, limit int) ([]
 
 	scans := make([]model.Scan, 0)
 	for rows.Next() {
-		var scan model.Scan
-		var ingredientsBytes []byte
-
-		if err := rows.Scan(
-			&scan.ID,
-			&scan.UserID,
-			&scan.ProductName,
-			&scan.Brand,
-			&scan.Image,
-			&scan.SafetyScore,
-			&scan.IsSafe,
-			&ingredientsBytes,
-			&scan.Timestamp,
-		); err != nil {
-			return nil, fmt.Errorf("scan row: %w", err)
+		scan, err := scanRow(rows)
+		if err != nil {
+			return nil, err
 		}
-
-		if err := unmarshalIngredients(ingredientsBytes, &scan.Ingredients); err != nil {
-			return nil, fmt.Errorf("decode ingredients: %w", err)
-		}
-
-		scans = append(scans, scan)
+		scans = append(scans, *scan)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate scans: %w", err)
 	}
 
-	return scans, nil
+	page := &model.ScanPage{Scans: scans}
+	if len(scans) > limit {
+		page.Scans = scans[:limit]
+		last := page.Scans[limit-1]
+		page.NextCursor = encodeScanCursor(scanCursor{
+			Sort:      sort,
+			Score:     last.SafetyScore,
+			Timestamp: last.Timestamp,
+			ID:        last.ID,
+		})
+	}
+	return page, nil
+}
+
+// scanCursor is the sort key of the last scan on a page. Cursors are handed
+// out base64-encoded, so clients treat them as opaque.
+type scanCursor struct {
+	Sort      model.ScanSort `json:"s"`
+	Score     int            `json:"v,omitempty"`
+	Timestamp time.Time      `json:"t"`
+	ID        string         `json:"id"`
+}
+
+func encodeScanCursor(c scanCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeScanCursor(cursor string, sort model.ScanSort) (scanCursor, error) {
+	var c scanCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sort || c.ID == "" || c.Timestamp.IsZero() {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// containsPattern is an ILIKE pattern matching s anywhere, with the
+// wildcards in s itself escaped.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *scanRepo) GetByID(ctx context.Context, userID, scanID string) (*model.Scan, error) {
+	const query = `
+		SELECT ` + scanColumns + `
+		FROM scans
+		WHERE user_id = $1 AND id = $2`
+
+	scan, err := scanRow(r.q.QueryRow(ctx, query, userID, scanID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return scan, nil
+}
+
+// scanRow reads one row in the column order the scan queries select.
+func scanRow(row pgx.Row) (*model.Scan, error) {
+	var scan model.Scan
+	var ingredientsBytes []byte
+	var sourcesBytes []byte
+	var confidenceBytes []byte
+
+	if err := row.Scan(
+		&scan.ID,
+		&scan.UserID,
+		&scan.ProductName,
+		&scan.Brand,
+		&scan.Image,
+		&scan.ImageID,
+		&scan.SafetyScore,
+		&scan.IsSafe,
+		&ingredientsBytes,
+		&sourcesBytes,
+		&confidenceBytes,
+		&scan.PreferenceVersion,
+		&scan.Timestamp,
+	); err != nil {
+		return nil, fmt.Errorf("scan row: %w", err)
+	}
+
+	if err := unmarshalIngredients(ingredientsBytes, &scan.Ingredients); err != nil {
+		return nil, fmt.Errorf("decode ingredients: %w", err)
+	}
+	if err := unmarshalSources(sourcesBytes, &scan.Sources); err != nil {
+		return nil, fmt.Errorf("decode sources: %w", err)
+	}
+	if err := unmarshalConfidence(confidenceBytes, &scan.Confidence); err != nil {
+		return nil, fmt.Errorf("decode confidence: %w", err)
+	}
+	return &scan, nil
 }
 
 func (r *scanRepo) Create(ctx context.Context, scan *model.Scan) (*model.Scan, error) {
@@ -78,16 +242,31 @@ func (r *scanRepo) Create(ctx context.Context, scan *model.Scan) (*model.Scan, e
 	if err != nil {
 		return nil, fmt.Errorf("marshal ingredients: %w", err)
 	}
+	sources := scan.Sources
+	if sources == nil {
+		sources = []model.Source{}
+	}
+	sourcesJSON, err := json.Marshal(sources)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sources: %w", err)
+	}
+	// A scan without a confidence assessment stores SQL NULL, not JSON null.
+	var confidenceJSON []byte
+	if scan.Confidence != nil {
+		if confidenceJSON, err = json.Marshal(scan.Confidence); err != nil {
+			return nil, fmt.Errorf("marshal confidence: %w", err)
+		}
+	}
 
+	// Without a version the scan is taken to be scored against the user's
+	// current preferences.
 	const query = `
-		INSERT INTO scans (id, user_id, product_name, brand, image, safety_score, is_safe, ingredients)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb)
-		RETURNING id, user_id, product_name, COALESCE(brand, ''), COALESCE(image, ''), safety_score, is_safe, ingredients, timestamp`
+		INSERT INTO scans (id, user_id, product_name, brand, image, image_id, safety_score, is_safe, ingredients, sources, confidence, preference_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::jsonb, $10::jsonb, $11::jsonb,
+			COALESCE(NULLIF($12, 0), (SELECT NULLIF(preference_version, 0) FROM users WHERE id = $2)))
+		RETURNING ` + scanColumns
 
-	var created model.Scan
-	var ingredientsBytes []byte
-
-	err = r.q.QueryRow(
+	created, err := scanRow(r.q.QueryRow(
 		ctx,
 		query,
 		scan.ID,
@@ -95,29 +274,22 @@ func (r *scanRepo) Create(ctx context.Context, scan *model.Scan) (*model.Scan, e
 		scan.ProductName,
 		scan.Brand,
 		scan.Image,
+		scan.ImageID,
 		scan.SafetyScore,
 		scan.IsSafe,
 		ingredientsJSON,
-	).Scan(
-		&created.ID,
-		&created.UserID,
-		&created.ProductName,
-		&created.Brand,
-		&created.Image,
-		&created.SafetyScore,
-		&created.IsSafe,
-		&ingredientsBytes,
-		&created.Timestamp,
-	)
+		sourcesJSON,
+		confidenceJSON,
+		scan.PreferenceVersion,
+	))
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "scans_preference_version_fkey" {
+			return nil, ErrUnknownPreferenceVersion
+		}
 		return nil, fmt.Errorf("create scan: %w", err)
 	}
-
-	if err := unmarshalIngredients(ingredientsBytes, &created.Ingredients); err != nil {
-		return nil, fmt.Errorf("decode ingredients: %w", err)
-	}
-
-	return &created, nil
+	return created, nil
 }
 
 func (r *scanRepo) GetStats(ctx context.Context, userID string) (*model.UserStats, error) {
@@ -159,3 +331,30 @@ func unmarshalIngredients(in []byte, out *[]map[string]interface{}) error {
 	}
 	return nil
 }
+
+func unmarshalSources(in []byte, out *[]model.Source) error {
+	if len(in) == 0 {
+		*out = []model.Source{}
+		return nil
+	}
+	if err := json.Unmarshal(in, out); err != nil {
+		return err
+	}
+	if *out == nil {
+		*out = []model.Source{}
+	}
+	return nil
+}
+
+func unmarshalConfidence(in []byte, out **model.Confidence) error {
+	if len(in) == 0 || string(in) == "null" {
+		*out = nil
+		return nil
+	}
+	var c model.Confidence
+	if err := json.Unmarshal(in, &c); err != nil {
+		return err
+	}
+	*out = &c
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/safebites/backend-go/internal/model"
 	"github.com/stretchr/testify/require"
@@ -17,16 +19,21 @@ func TestScanRepoListByUserSuccess(t *testing.T) {
 	defer mock.Close()
 
 	now := time.Now().UTC()
-	rows := pgxmock.NewRows([]string{"id", "user_id", "product_name", "brand", "image", "safety_score", "is_safe", "ingredients", "timestamp"}).
-		AddRow("scan-1", "user-1", "Granola Bar", "Brand A", "", 78, true, []byte(`[{"name":"oats"}]`), now)
+	rows := pgxmock.NewRows([]string{"id", "user_id", "product_name", "brand", "image", "image_id", "safety_score", "is_safe", "ingredients", "sources", "confidence", "preference_version", "timestamp"}).
+		AddRow("scan-1", "user-1", "Granola Bar", "Brand A", "", "", 78, true, []byte(`[{"name":"oats"}]`), []byte(`[{"title":"Label","uri":"https://example.com/granola"}]`), []byte(`{"ocr":0.9,"sources":0.8,"scorer":0.7,"overall":0.78,"needs_verification":false}`), 0, now)
 
-	mock.ExpectQuery("SELECT id, user_id").WithArgs("user-1", 10).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT id, user_id").WithArgs("user-1", 11).WillReturnRows(rows)
 
 	repo := &scanRepo{q: mock}
-	scans, err := repo.ListByUser(context.Background(), "user-1", 10)
+	page, err := repo.ListByUser(context.Background(), "user-1", model.ScanFilter{Limit: 10})
 	require.NoError(t, err)
-	require.Len(t, scans, 1)
+	require.Len(t, page.Scans, 1)
+	require.Empty(t, page.NextCursor)
+	scans := page.Scans
 	require.Equal(t, "scan-1", scans[0].ID)
+	require.Equal(t, "https://example.com/granola", scans[0].Sources[0].URI)
+	require.NotNil(t, scans[0].Confidence)
+	require.Equal(t, 0.78, scans[0].Confidence.Overall)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -36,8 +43,8 @@ func TestScanRepoCreateSuccess(t *testing.T) {
 	defer mock.Close()
 
 	now := time.Now().UTC()
-	rows := pgxmock.NewRows([]string{"id", "user_id", "product_name", "brand", "image", "safety_score", "is_safe", "ingredients", "timestamp"}).
-		AddRow("scan-1", "user-1", "Granola Bar", "Brand A", "", 78, true, []byte(`[{"name":"oats"}]`), now)
+	rows := pgxmock.NewRows([]string{"id", "user_id", "product_name", "brand", "image", "image_id", "safety_score", "is_safe", "ingredients", "sources", "confidence", "preference_version", "timestamp"}).
+		AddRow("scan-1", "user-1", "Granola Bar", "Brand A", "", "img_0123456789abcdef0123456789abcdef", 78, true, []byte(`[{"name":"oats"}]`), []byte(`[{"title":"Label","uri":"https://example.com/granola"}]`), []byte(`{"ocr":0.9,"sources":0.8,"scorer":0.7,"overall":0.78,"needs_verification":false}`), 3, now)
 
 	mock.ExpectQuery("INSERT INTO scans").WithArgs(
 		"scan-1",
@@ -45,24 +52,65 @@ func TestScanRepoCreateSuccess(t *testing.T) {
 		"Granola Bar",
 		"Brand A",
 		"",
+		"img_0123456789abcdef0123456789abcdef",
 		78,
 		true,
 		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		3,
 	).WillReturnRows(rows)
 
 	repo := &scanRepo{q: mock}
 	created, err := repo.Create(context.Background(), &model.Scan{
-		ID:          "scan-1",
-		UserID:      "user-1",
-		ProductName: "Granola Bar",
-		Brand:       "Brand A",
-		Image:       "",
-		SafetyScore: 78,
-		IsSafe:      true,
-		Ingredients: []map[string]interface{}{{"name": "oats"}},
+		ID:                "scan-1",
+		UserID:            "user-1",
+		ProductName:       "Granola Bar",
+		Brand:             "Brand A",
+		Image:             "",
+		ImageID:           "img_0123456789abcdef0123456789abcdef",
+		SafetyScore:       78,
+		IsSafe:            true,
+		Ingredients:       []map[string]interface{}{{"name": "oats"}},
+		PreferenceVersion: 3,
 	})
 	require.NoError(t, err)
 	require.Equal(t, "scan-1", created.ID)
+	require.Equal(t, "img_0123456789abcdef0123456789abcdef", created.ImageID)
+	require.Equal(t, 3, created.PreferenceVersion)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScanRepoCreateUnknownPreferenceVersion(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery("INSERT INTO scans").WithArgs(
+		"scan-1",
+		"user-1",
+		"Granola Bar",
+		"",
+		"",
+		"",
+		78,
+		true,
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		9,
+	).WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "scans_preference_version_fkey"})
+
+	repo := &scanRepo{q: mock}
+	_, err = repo.Create(context.Background(), &model.Scan{
+		ID:                "scan-1",
+		UserID:            "user-1",
+		ProductName:       "Granola Bar",
+		SafetyScore:       78,
+		IsSafe:            true,
+		PreferenceVersion: 9,
+	})
+	require.ErrorIs(t, err, ErrUnknownPreferenceVersion)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -90,15 +138,84 @@ func TestScanRepoListByUserDefaultLimit(t *testing.T) {
 	defer mock.Close()
 
 	now := time.Now().UTC()
-	rows := pgxmock.NewRows([]string{"id", "user_id", "product_name", "brand", "image", "safety_score", "is_safe", "ingredients", "timestamp"}).
-		AddRow("scan-1", "user-1", "Granola Bar", "Brand A", "", 78, true, []byte(`[{"name":"oats"}]`), now)
+	rows := pgxmock.NewRows([]string{"id", "user_id", "product_name", "brand", "image", "image_id", "safety_score", "is_safe", "ingredients", "sources", "confidence", "preference_version", "timestamp"}).
+		AddRow("scan-1", "user-1", "Granola Bar", "Brand A", "", "", 78, true, []byte(`[{"name":"oats"}]`), []byte(`[{"title":"Label","uri":"https://example.com/granola"}]`), []byte(`{"ocr":0.9,"sources":0.8,"scorer":0.7,"overall":0.78,"needs_verification":false}`), 0, now)
 
-	mock.ExpectQuery("SELECT id, user_id").WithArgs("user-1", 20).WillReturnRows(rows)
+	mock.ExpectQuery("ORDER BY timestamp DESC, id DESC").WithArgs("user-1", 21).WillReturnRows(rows)
 
 	repo := &scanRepo{q: mock}
-	scans, err := repo.ListByUser(context.Background(), "user-1", 0)
+	page, err := repo.ListByUser(context.Background(), "user-1", model.ScanFilter{})
 	require.NoError(t, err)
-	require.Len(t, scans, 1)
+	require.Len(t, page.Scans, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScanRepoListByUserFiltersAndPages(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	columns := []string{"id", "user_id", "product_name", "brand", "image", "image_id", "safety_score", "is_safe", "ingredients", "sources", "confidence", "preference_version", "timestamp"}
+	older := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	safe := false
+	minScore, maxScore := 10, 60
+
+	mock.ExpectQuery(`WHERE user_id = \$1 AND timestamp >= \$2 AND timestamp < \$3 AND is_safe = \$4 AND safety_score >= \$5 AND safety_score <= \$6 AND brand ILIKE \$7 AND product_name ILIKE \$8\s+ORDER BY safety_score DESC, timestamp DESC, id DESC\s+LIMIT \$9`).
+		WithArgs("user-1", from, to, false, 10, 60, `%50\% Cocoa%`, "%bar%", 3).
+		WillReturnRows(pgxmock.NewRows(columns).
+			AddRow("scan-3", "user-1", "Dark Bar", "50% Cocoa", "", "", 55, false, []byte(`[]`), []byte(`[]`), []byte(nil), 0, newer).
+			AddRow("scan-2", "user-1", "Milk Bar", "50% Cocoa", "", "", 40, false, []byte(`[]`), []byte(`[]`), []byte(nil), 0, older).
+			AddRow("scan-1", "user-1", "Nut Bar", "50% Cocoa", "", "", 20, false, []byte(`[]`), []byte(`[]`), []byte(nil), 0, older))
+
+	filter := model.ScanFilter{
+		From:     from,
+		To:       to,
+		Safe:     &safe,
+		MinScore: &minScore,
+		MaxScore: &maxScore,
+		Brand:    "50% Cocoa",
+		Product:  "bar",
+		Sort:     model.ScanSortScoreDesc,
+		Limit:    2,
+	}
+	repo := &scanRepo{q: mock}
+	page, err := repo.ListByUser(context.Background(), "user-1", filter)
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 2)
+	require.Equal(t, "scan-2", page.Scans[1].ID)
+	require.NotEmpty(t, page.NextCursor)
+
+	// The cursor continues after the last scan of the page.
+	mock.ExpectQuery(`AND \(safety_score, timestamp, id\) < \(\$9, \$10, \$11\)\s+ORDER BY safety_score DESC`).
+		WithArgs("user-1", from, to, false, 10, 60, `%50\% Cocoa%`, "%bar%", 40, older, "scan-2", 3).
+		WillReturnRows(pgxmock.NewRows(columns).
+			AddRow("scan-1", "user-1", "Nut Bar", "50% Cocoa", "", "", 20, false, []byte(`[]`), []byte(`[]`), []byte(nil), 0, older))
+
+	filter.Cursor = page.NextCursor
+	page, err = repo.ListByUser(context.Background(), "user-1", filter)
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 1)
+	require.Empty(t, page.NextCursor)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScanRepoListByUserRejectsForeignCursor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := &scanRepo{q: mock}
+	cursor := encodeScanCursor(scanCursor{Sort: model.ScanSortNewest, Timestamp: time.Now(), ID: "scan-1"})
+	for _, filter := range []model.ScanFilter{
+		{Cursor: "not a cursor"},
+		{Cursor: cursor, Sort: model.ScanSortOldest},
+	} {
+		_, err := repo.ListByUser(context.Background(), "user-1", filter)
+		require.ErrorIs(t, err, ErrInvalidCursor)
+	}
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -115,3 +232,36 @@ func TestScanRepoGetStatsError(t *testing.T) {
 	require.ErrorContains(t, err, "get user scan stats")
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestScanRepoGetByIDSuccess(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	rows := pgxmock.NewRows([]string{"id", "user_id", "product_name", "brand", "image", "image_id", "safety_score", "is_safe", "ingredients", "sources", "confidence", "preference_version", "timestamp"}).
+		AddRow("scan-1", "user-1", "Granola Bar", "", "", "", 78, true, []byte(`[{"name":"oats"}]`), []byte(`[]`), []byte(nil), 0, now)
+
+	mock.ExpectQuery("SELECT id, user_id").WithArgs("user-1", "scan-1").WillReturnRows(rows)
+
+	repo := &scanRepo{q: mock}
+	scan, err := repo.GetByID(context.Background(), "user-1", "scan-1")
+	require.NoError(t, err)
+	require.Equal(t, "Granola Bar", scan.ProductName)
+	require.Equal(t, "oats", scan.Ingredients[0]["name"])
+	require.Nil(t, scan.Confidence)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScanRepoGetByIDNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery("SELECT id, user_id").WithArgs("user-1", "missing").WillReturnError(pgx.ErrNoRows)
+
+	repo := &scanRepo{q: mock}
+	_, err = repo.GetByID(context.Background(), "user-1", "missing")
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+// ErrShareCodeTaken is returned by SetShareCode when another template is
+// already published under the code.
+var ErrShareCodeTaken = errors.New("share code is taken")
+
+type templateQuerier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+type templateRepo struct {
+	q templateQuerier
+}
+
+func NewTemplateRepository(db *DB) TemplateRepository {
+	return &templateRepo{q: db.Pool}
+}
+
+const templateColumns = `key, COALESCE(owner_id, ''), name, description, allergies, diet_goals, avoid_ingredients,
+	COALESCE(share_code, ''), COALESCE(forked_from, ''), created_at, updated_at`
+
+func (r *templateRepo) UpsertBuiltIns(ctx context.Context, templates []model.DietaryTemplate) error {
+	// A user template never takes a built-in key, since those are UUIDs; the
+	// owner check only keeps the upsert from ever rewriting one. An unchanged
+	// built-in is left alone, so startup does not touch updated_at.
+	const query = `
+		INSERT INTO dietary_templates (key, name, description, allergies, diet_goals, avoid_ingredients)
+		VALUES ($1, $2, $3, $4::jsonb, $5::jsonb, $6::jsonb)
+		ON CONFLICT (key) DO UPDATE
+		SET name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			allergies = EXCLUDED.allergies,
+			diet_goals = EXCLUDED.diet_goals,
+			avoid_ingredients = EXCLUDED.avoid_ingredients,
+			updated_at = NOW()
+		WHERE dietary_templates.owner_id IS NULL
+			AND (dietary_templates.name, dietary_templates.description, dietary_templates.allergies,
+				dietary_templates.diet_goals, dietary_templates.avoid_ingredients)
+			IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.description, EXCLUDED.allergies,
+				EXCLUDED.diet_goals, EXCLUDED.avoid_ingredients)`
+
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin upsert templates: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, tpl := range templates {
+		lists, err := marshalPreferenceLists(tpl.Allergies, tpl.DietGoals, tpl.AvoidIngredients)
+		if err != nil {
+			return err
+		}
+		args := append([]interface{}{tpl.Key, tpl.Name, tpl.Description}, lists...)
+		cmdTag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("upsert template %s: %w", tpl.Key, err)
+		}
+		if cmdTag.RowsAffected() == 0 {
+			continue
+		}
+		// The layers following a rewritten built-in now merge its new lists,
+		// just as when an owner edits their template.
+		users, err := appliedBy(ctx, tx, tpl.Key)
+		if err != nil {
+			return err
+		}
+		if err := recordTemplateChange(ctx, tx, users, tpl.Key, model.PreferenceTemplateChanged); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit template upsert: %w", err)
+	}
+	return nil
+}
+
+func (r *templateRepo) List(ctx context.Context, userID string) ([]model.DietaryTemplate, error) {
+	const query = `
+		SELECT ` + templateColumns + `
+		FROM dietary_templates
+		WHERE owner_id IS NULL OR owner_id = $1
+		ORDER BY owner_id IS NOT NULL, CASE WHEN owner_id IS NULL THEN key END, created_at ASC`
+
+	rows, err := r.q.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []model.DietaryTemplate{}
+	for rows.Next() {
+		tpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *tpl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *templateRepo) Resolve(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error) {
+	const query = `
+		SELECT ` + templateColumns + `
+		FROM dietary_templates
+		WHERE (key = $2 AND (owner_id IS NULL OR owner_id = $1)) OR share_code = $2
+		ORDER BY key = $2 DESC
+		LIMIT 1`
+
+	tpl, err := scanTemplate(r.q.QueryRow(ctx, query, userID, ref))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return tpl, err
+}
+
+func (r *templateRepo) Create(ctx context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error) {
+	lists, err := marshalPreferenceLists(tpl.Allergies, tpl.DietGoals, tpl.AvoidIngredients)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		INSERT INTO dietary_templates (key, owner_id, name, description, allergies, diet_goals, avoid_ingredients, forked_from)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7::jsonb, NULLIF($8, ''))
+		RETURNING ` + templateColumns
+
+	args := []interface{}{tpl.Key, tpl.OwnerID, tpl.Name, tpl.Description}
+	args = append(args, lists...)
+	args = append(args, tpl.ForkedFrom)
+	created, err := scanTemplate(r.q.QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("create template: %w", err)
+	}
+	return created, nil
+}
+
+func (r *templateRepo) Update(ctx context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error) {
+	lists, err := marshalPreferenceLists(tpl.Allergies, tpl.DietGoals, tpl.AvoidIngredients)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		UPDATE dietary_templates
+		SET name = $3,
+			description = $4,
+			allergies = $5::jsonb,
+			diet_goals = $6::jsonb,
+			avoid_ingredients = $7::jsonb,
+			updated_at = NOW()
+		WHERE owner_id = $1 AND key = $2
+		RETURNING ` + templateColumns
+
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin update template: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	args := append([]interface{}{tpl.OwnerID, tpl.Key, tpl.Name, tpl.Description}, lists...)
+	updated, err := scanTemplate(tx.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("update template: %w", err)
+	}
+	users, err := appliedBy(ctx, tx, tpl.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordTemplateChange(ctx, tx, users, tpl.Key, model.PreferenceTemplateChanged); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit template update: %w", err)
+	}
+	return updated, nil
+}
+
+func (r *templateRepo) SetShareCode(ctx context.Context, userID, key, shareCode string) (*model.DietaryTemplate, error) {
+	const query = `
+		UPDATE dietary_templates
+		SET share_code = NULLIF($3, ''), updated_at = NOW()
+		WHERE owner_id = $1 AND key = $2
+		RETURNING ` + templateColumns
+
+	tpl, err := scanTemplate(r.q.QueryRow(ctx, query, userID, key, shareCode))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrNotFound
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			return nil, ErrShareCodeTaken
+		}
+		return nil, fmt.Errorf("set template share code: %w", err)
+	}
+	return tpl, nil
+}
+
+func (r *templateRepo) Delete(ctx context.Context, userID, key string) error {
+	const query = `DELETE FROM dietary_templates WHERE owner_id = $1 AND key = $2`
+
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin delete template: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The layers that follow the template go with it, so find who had it
+	// applied first. Snapshot layers stay.
+	users, err := appliedBy(ctx, tx, key)
+	if err != nil {
+		return err
+	}
+	cmdTag, err := tx.Exec(ctx, query, userID, key)
+	if err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_template_layers WHERE template_key = $1 AND allergies IS NULL`, key); err != nil {
+		return fmt.Errorf("delete template layers: %w", err)
+	}
+	if err := recordTemplateChange(ctx, tx, users, key, model.PreferenceTemplateRemoved); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit template deletion: %w", err)
+	}
+	return nil
+}
+
+// appliedBy returns the users whose layer follows the template. Users with a
+// snapshot of it are unaffected by its changes.
+func appliedBy(ctx context.Context, tx pgx.Tx, key string) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT user_id FROM user_template_layers WHERE template_key = $1 AND allergies IS NULL ORDER BY user_id`, key)
+	if err != nil {
+		return nil, fmt.Errorf("list template users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan template user: %w", err)
+		}
+		users = append(users, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate template users: %w", err)
+	}
+	return users, nil
+}
+
+// recordTemplateChange records a new preference version for every user in
+// users, since editing or deleting a template they applied changes their
+// effective preferences too.
+func recordTemplateChange(ctx context.Context, tx pgx.Tx, users []string, key, action string) error {
+	for _, userID := range users {
+		if _, err := recordPreferenceVersion(ctx, tx, userID, key, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanTemplate reads one row of templateColumns. pgx.ErrNoRows is returned
+// unwrapped so callers can map it to ErrNotFound.
+func scanTemplate(row pgx.Row) (*model.DietaryTemplate, error) {
+	var tpl model.DietaryTemplate
+	var allergiesBytes []byte
+	var dietGoalsBytes []byte
+	var avoidIngredientsBytes []byte
+
+	err := row.Scan(
+		&tpl.Key,
+		&tpl.OwnerID,
+		&tpl.Name,
+		&tpl.Description,
+		&allergiesBytes,
+		&dietGoalsBytes,
+		&avoidIngredientsBytes,
+		&tpl.ShareCode,
+		&tpl.ForkedFrom,
+		&tpl.CreatedAt,
+		&tpl.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan template: %w", err)
+	}
+	tpl.BuiltIn = tpl.OwnerID == ""
+
+	if err := unmarshalAllergies(allergiesBytes, &tpl.Allergies); err != nil {
+		return nil, fmt.Errorf("decode allergies: %w", err)
+	}
+	if err := unmarshalStringSlice(dietGoalsBytes, &tpl.DietGoals); err != nil {
+		return nil, fmt.Errorf("decode diet goals: %w", err)
+	}
+	if err := unmarshalStringSlice(avoidIngredientsBytes, &tpl.AvoidIngredients); err != nil {
+		return nil, fmt.Errorf("decode avoid ingredients: %w", err)
+	}
+	return &tpl, nil
+}
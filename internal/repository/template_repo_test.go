@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+var templateRowColumns = []string{"key", "owner_id", "name", "description", "allergies", "diet_goals", "avoid_ingredients", "share_code", "forked_from", "created_at", "updated_at"}
+
+func TestTemplateRepoList(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectQuery("FROM dietary_templates").WithArgs("user-1").
+		WillReturnRows(pgxmock.NewRows(templateRowColumns).
+			AddRow("vegan", "", "Vegan", "No animal products", []byte(`[]`), []byte(`["vegan"]`), []byte(`["honey"]`), "", "", now, now).
+			AddRow("tpl-1", "user-1", "Low FODMAP", "", []byte(`[]`), []byte(`[]`), []byte(`["onion"]`), "K7QX2M9P", "vegan", now, now))
+
+	repo := &templateRepo{q: mock}
+	templates, err := repo.List(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	require.True(t, templates[0].BuiltIn)
+	require.False(t, templates[1].BuiltIn)
+	require.Equal(t, "K7QX2M9P", templates[1].ShareCode)
+	require.Equal(t, "vegan", templates[1].ForkedFrom)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTemplateRepoResolveNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery("FROM dietary_templates").WithArgs("user-1", "carnivore").WillReturnError(pgx.ErrNoRows)
+
+	repo := &templateRepo{q: mock}
+	_, err = repo.Resolve(context.Background(), "user-1", "carnivore")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTemplateRepoCreateFork(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectQuery("INSERT INTO dietary_templates").
+		WithArgs("tpl-2", "user-1", "Vegan", "No animal products", []byte(`[]`), []byte(`["vegan"]`), []byte(`["honey"]`), "vegan").
+		WillReturnRows(pgxmock.NewRows(templateRowColumns).
+			AddRow("tpl-2", "user-1", "Vegan", "No animal products", []byte(`[]`), []byte(`["vegan"]`), []byte(`["honey"]`), "", "vegan", now, now))
+
+	repo := &templateRepo{q: mock}
+	created, err := repo.Create(context.Background(), &model.DietaryTemplate{
+		Key:              "tpl-2",
+		OwnerID:          "user-1",
+		Name:             "Vegan",
+		Description:      "No animal products",
+		Allergies:        model.Allergies(),
+		DietGoals:        []string{"vegan"},
+		AvoidIngredients: []string{"honey"},
+		ForkedFrom:       "vegan",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "vegan", created.ForkedFrom)
+	require.False(t, created.BuiltIn)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTemplateRepoSetShareCodeTaken(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery("UPDATE dietary_templates").WithArgs("user-1", "tpl-1", "K7QX2M9P").
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	repo := &templateRepo{q: mock}
+	_, err = repo.SetShareCode(context.Background(), "user-1", "tpl-1", "K7QX2M9P")
+	require.ErrorIs(t, err, ErrShareCodeTaken)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTemplateRepoUpsertBuiltIns(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	templates := model.SortedDietaryTemplates()
+	mock.ExpectBegin()
+	for i, tpl := range templates {
+		exec := mock.ExpectExec("ON CONFLICT \\(key\\) DO UPDATE(.|\n)*IS DISTINCT FROM").
+			WithArgs(tpl.Key, tpl.Name, tpl.Description, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg())
+		if i > 0 {
+			// Unchanged built-ins are not rewritten.
+			exec.WillReturnResult(pgxmock.NewResult("INSERT", 0))
+			continue
+		}
+		exec.WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectQuery("FROM user_template_layers").WithArgs(tpl.Key).
+			WillReturnRows(pgxmock.NewRows([]string{"user_id"}).AddRow("user-2"))
+		mock.ExpectQuery("SET preference_version = preference_version \\+ 1").WithArgs("user-2").
+			WillReturnRows(pgxmock.NewRows(userRowColumns).
+				AddRow("user-2", "two@example.com", "", "", []byte(`[]`), []byte(`[]`), []byte(`[]`), "", now, now, 2, []byte(`[]`)))
+		mock.ExpectExec("INSERT INTO preference_history").
+			WithArgs("user-2", 2, tpl.Key, model.PreferenceTemplateChanged, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "", pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	repo := &templateRepo{q: mock}
+	require.NoError(t, repo.UpsertBuiltIns(context.Background(), templates))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTemplateRepoUpdateRecordsVersionForAppliedUsers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE dietary_templates").
+		WithArgs("user-1", "tpl-1", "Low FODMAP", "", []byte(`[]`), []byte(`[]`), []byte(`["onion","garlic"]`)).
+		WillReturnRows(pgxmock.NewRows(templateRowColumns).
+			AddRow("tpl-1", "user-1", "Low FODMAP", "", []byte(`[]`), []byte(`[]`), []byte(`["onion","garlic"]`), "", "", now, now))
+	mock.ExpectQuery("FROM user_template_layers").WithArgs("tpl-1").
+		WillReturnRows(pgxmock.NewRows([]string{"user_id"}).AddRow("user-2"))
+	mock.ExpectQuery("SET preference_version = preference_version \\+ 1").WithArgs("user-2").
+		WillReturnRows(pgxmock.NewRows(userRowColumns).
+			AddRow("user-2", "two@example.com", "", "", []byte(`[]`), []byte(`[]`), []byte(`[]`), "", now, now, 4, []byte(`[]`)))
+	mock.ExpectExec("INSERT INTO preference_history").
+		WithArgs("user-2", 4, "tpl-1", model.PreferenceTemplateChanged, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	repo := &templateRepo{q: mock}
+	_, err = repo.Update(context.Background(), &model.DietaryTemplate{
+		Key:              "tpl-1",
+		OwnerID:          "user-1",
+		Name:             "Low FODMAP",
+		Allergies:        []model.Allergy{},
+		DietGoals:        []string{},
+		AvoidIngredients: []string{"onion", "garlic"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTemplateRepoDeleteKeepsSnapshotLayers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_template_layers WHERE template_key = \\$1 AND allergies IS NULL").WithArgs("tpl-1").
+		WillReturnRows(pgxmock.NewRows([]string{"user_id"}).AddRow("user-1"))
+	mock.ExpectExec("DELETE FROM dietary_templates").WithArgs("user-1", "tpl-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("DELETE FROM user_template_layers WHERE template_key = \\$1 AND allergies IS NULL").WithArgs("tpl-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectQuery("SET preference_version = preference_version \\+ 1").WithArgs("user-1").
+		WillReturnRows(pgxmock.NewRows(userRowColumns).
+			AddRow("user-1", "one@example.com", "", "", []byte(`[]`), []byte(`[]`), []byte(`[]`), "", now, now, 7, []byte(`[]`)))
+	mock.ExpectExec("INSERT INTO preference_history").
+		WithArgs("user-1", 7, "tpl-1", model.PreferenceTemplateRemoved, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	repo := &templateRepo{q: mock}
+	require.NoError(t, repo.Delete(context.Background(), "user-1", "tpl-1"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
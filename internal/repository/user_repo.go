@@ -7,13 +7,38 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/safebites/backend-go/internal/model"
 )
 
 type userQuerier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 }
 
+// userLayersColumn selects the templates applied to a user as a JSON array
+// of model.TemplateLayer, oldest first. Snapshot layers carry their own name
+// and lists; the others read them from the template.
+const userLayersColumn = `COALESCE((
+			SELECT json_agg(json_build_object(
+				'key', l.template_key,
+				'name', COALESCE(l.name, t.name),
+				'allergies', COALESCE(l.allergies, t.allergies),
+				'dietGoals', COALESCE(l.diet_goals, t.diet_goals),
+				'avoidIngredients', COALESCE(l.avoid_ingredients, t.avoid_ingredients),
+				'snapshot', l.allergies IS NOT NULL,
+				'appliedAt', l.applied_at
+			) ORDER BY l.applied_at, l.template_key)
+			FROM user_template_layers l
+			LEFT JOIN dietary_templates t ON t.key = l.template_key
+			WHERE l.user_id = users.id AND (l.allergies IS NOT NULL OR t.key IS NOT NULL)
+		), '[]'::json)`
+
+// userColumns are the columns scanUser reads, in order.
+const userColumns = `id, email, COALESCE(name, ''), COALESCE(picture, ''), allergies, diet_goals, avoid_ingredients, home_region, created_at, updated_at, preference_version, ` + userLayersColumn
+
 type userRepo struct {
 	q userQuerier
 }
@@ -24,143 +49,280 @@ func NewUserRepository(db *DB) UserRepository {
 
 func (r *userRepo) GetByID(ctx context.Context, userID string) (*model.User, error) {
 	const query = `
-		SELECT id, email, COALESCE(name, ''), COALESCE(picture, ''), allergies, diet_goals, avoid_ingredients, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1`
 
-	var user model.User
-	var allergiesBytes []byte
-	var dietGoalsBytes []byte
-	var avoidIngredientsBytes []byte
-
-	err := r.q.QueryRow(ctx, query, userID).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.Picture,
-		&allergiesBytes,
-		&dietGoalsBytes,
-		&avoidIngredientsBytes,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.q.QueryRow(ctx, query, userID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("query user by id: %w", err)
 	}
-
-	if err := unmarshalStringSlice(allergiesBytes, &user.Allergies); err != nil {
-		return nil, fmt.Errorf("decode allergies: %w", err)
-	}
-	if err := unmarshalStringSlice(dietGoalsBytes, &user.DietGoals); err != nil {
-		return nil, fmt.Errorf("decode diet goals: %w", err)
-	}
-	if err := unmarshalStringSlice(avoidIngredientsBytes, &user.AvoidIngredients); err != nil {
-		return nil, fmt.Errorf("decode avoid ingredients: %w", err)
-	}
-
-	return &user, nil
+	return user, nil
 }
 
+// Upsert creates the user with the given preferences, or updates the
+// profile fields of an existing one. A user's first upsert records the
+// first version of their preferences.
 func (r *userRepo) Upsert(ctx context.Context, user *model.User) (*model.User, error) {
-	allergiesJSON, err := json.Marshal(user.Allergies)
+	lists, err := marshalPreferenceLists(user.Allergies, user.DietGoals, user.AvoidIngredients)
 	if err != nil {
-		return nil, fmt.Errorf("marshal allergies: %w", err)
-	}
-	dietGoalsJSON, err := json.Marshal(user.DietGoals)
-	if err != nil {
-		return nil, fmt.Errorf("marshal diet goals: %w", err)
-	}
-	avoidIngredientsJSON, err := json.Marshal(user.AvoidIngredients)
-	if err != nil {
-		return nil, fmt.Errorf("marshal avoid ingredients: %w", err)
+		return nil, err
 	}
 
 	const query = `
-		INSERT INTO users (id, email, name, picture, allergies, diet_goals, avoid_ingredients)
-		VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7::jsonb)
+		INSERT INTO users (id, email, name, picture, allergies, diet_goals, avoid_ingredients, home_region)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7::jsonb, $8)
 		ON CONFLICT (id)
 		DO UPDATE SET
 			email = EXCLUDED.email,
 			name = EXCLUDED.name,
 			picture = EXCLUDED.picture,
 			updated_at = NOW()
-		RETURNING id, email, COALESCE(name, ''), COALESCE(picture, ''), allergies, diet_goals, avoid_ingredients, created_at, updated_at`
+		RETURNING ` + userColumns
 
-	var created model.User
-	var allergiesBytes []byte
-	var dietGoalsBytes []byte
-	var avoidIngredientsBytes []byte
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin upsert user: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	err = r.q.QueryRow(
-		ctx,
-		query,
-		user.ID,
-		user.Email,
-		user.Name,
-		user.Picture,
-		allergiesJSON,
-		dietGoalsJSON,
-		avoidIngredientsJSON,
-	).Scan(
-		&created.ID,
-		&created.Email,
-		&created.Name,
-		&created.Picture,
-		&allergiesBytes,
-		&dietGoalsBytes,
-		&avoidIngredientsBytes,
-		&created.CreatedAt,
-		&created.UpdatedAt,
-	)
+	args := append([]interface{}{user.ID, user.Email, user.Name, user.Picture}, lists...)
+	created, err := scanUser(tx.QueryRow(ctx, query, append(args, user.HomeRegion)...))
 	if err != nil {
 		return nil, fmt.Errorf("upsert user: %w", err)
 	}
-
-	if err := unmarshalStringSlice(allergiesBytes, &created.Allergies); err != nil {
-		return nil, fmt.Errorf("decode allergies: %w", err)
+	if created.PreferenceVersion == 0 {
+		if created, err = recordPreferenceVersion(ctx, tx, created.ID, model.PreferenceSourceManual, model.PreferenceCreated); err != nil {
+			return nil, err
+		}
 	}
-	if err := unmarshalStringSlice(dietGoalsBytes, &created.DietGoals); err != nil {
-		return nil, fmt.Errorf("decode diet goals: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit upsert user: %w", err)
 	}
-	if err := unmarshalStringSlice(avoidIngredientsBytes, &created.AvoidIngredients); err != nil {
-		return nil, fmt.Errorf("decode avoid ingredients: %w", err)
+	return created, nil
+}
+
+func (r *userRepo) UpdatePreferences(ctx context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
+	lists, err := marshalPreferenceLists(preferences.Allergies, preferences.DietGoals, preferences.AvoidIngredients)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		UPDATE users
+		SET allergies = $2::jsonb,
+			diet_goals = $3::jsonb,
+			avoid_ingredients = $4::jsonb,
+			home_region = $5,
+			updated_at = NOW()
+		WHERE id = $1`
+
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin update user preferences: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	return &created, nil
+	args := append([]interface{}{userID}, lists...)
+	tag, err := tx.Exec(ctx, query, append(args, preferences.HomeRegion)...)
+	if err != nil {
+		return nil, fmt.Errorf("update user preferences: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+	user, err := recordPreferenceVersion(ctx, tx, userID, model.PreferenceSourceManual, model.PreferenceUpdated)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit user preferences: %w", err)
+	}
+	return user, nil
 }
 
-func (r *userRepo) UpdatePreferences(ctx context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
-	allergiesJSON, err := json.Marshal(preferences.Allergies)
+// AddTemplateLayer applies a template on top of the user's preferences.
+// Applying a template twice keeps its first position, and its first snapshot,
+// and records no new version.
+func (r *userRepo) AddTemplateLayer(ctx context.Context, userID string, layer model.TemplateLayer) error {
+	// A layer that follows its template needs the template to exist; a
+	// snapshot does not.
+	const query = `
+		INSERT INTO user_template_layers (user_id, template_key, name, allergies, diet_goals, avoid_ingredients)
+		SELECT $1, $2, $3, $4::jsonb, $5::jsonb, $6::jsonb
+		WHERE $4::jsonb IS NOT NULL OR EXISTS (SELECT 1 FROM dietary_templates WHERE key = $2)
+		ON CONFLICT (user_id, template_key) DO NOTHING`
+
+	args := []interface{}{userID, layer.Key, nil, nil, nil, nil}
+	if layer.Snapshot {
+		lists, err := marshalPreferenceLists(layer.Allergies, layer.DietGoals, layer.AvoidIngredients)
+		if err != nil {
+			return err
+		}
+		args = append([]interface{}{userID, layer.Key, layer.Name}, lists...)
+	}
+
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin add template layer: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("marshal allergies: %w", err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("add template layer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Either the template is applied already, or the template the layer
+		// would follow is gone.
+		var applied bool
+		const check = `SELECT EXISTS (SELECT 1 FROM user_template_layers WHERE user_id = $1 AND template_key = $2)`
+		if err := tx.QueryRow(ctx, check, userID, layer.Key).Scan(&applied); err != nil {
+			return fmt.Errorf("check template layer: %w", err)
+		}
+		if !applied {
+			return ErrNotFound
+		}
+		return nil
+	}
+	if _, err := recordPreferenceVersion(ctx, tx, userID, layer.Key, model.PreferenceTemplateApplied); err != nil {
+		return err
 	}
-	dietGoalsJSON, err := json.Marshal(preferences.DietGoals)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit template layer: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepo) RemoveTemplateLayer(ctx context.Context, userID, templateKey string) error {
+	const query = `DELETE FROM user_template_layers WHERE user_id = $1 AND template_key = $2`
+
+	tx, err := r.q.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("marshal diet goals: %w", err)
+		return fmt.Errorf("begin remove template layer: %w", err)
 	}
-	avoidIngredientsJSON, err := json.Marshal(preferences.AvoidIngredients)
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, userID, templateKey)
 	if err != nil {
-		return nil, fmt.Errorf("marshal avoid ingredients: %w", err)
+		return fmt.Errorf("remove template layer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if _, err := recordPreferenceVersion(ctx, tx, userID, templateKey, model.PreferenceTemplateRemoved); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit template layer removal: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepo) ListPreferenceHistory(ctx context.Context, userID string, limit int) ([]model.PreferenceVersion, error) {
+	if limit <= 0 {
+		limit = 20
 	}
 
 	const query = `
+		SELECT version, source, action, allergies, diet_goals, avoid_ingredients, home_region, templates, created_at
+		FROM preference_history
+		WHERE user_id = $1
+		ORDER BY version DESC
+		LIMIT $2`
+
+	rows, err := r.q.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list preference history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]model.PreferenceVersion, 0)
+	for rows.Next() {
+		var v model.PreferenceVersion
+		var allergiesBytes, dietGoalsBytes, avoidIngredientsBytes, templatesBytes []byte
+		if err := rows.Scan(&v.Version, &v.Source, &v.Action, &allergiesBytes, &dietGoalsBytes, &avoidIngredientsBytes, &v.HomeRegion, &templatesBytes, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan preference version: %w", err)
+		}
+		if err := unmarshalAllergies(allergiesBytes, &v.Allergies); err != nil {
+			return nil, fmt.Errorf("decode allergies: %w", err)
+		}
+		if err := unmarshalStringSlice(dietGoalsBytes, &v.DietGoals); err != nil {
+			return nil, fmt.Errorf("decode diet goals: %w", err)
+		}
+		if err := unmarshalStringSlice(avoidIngredientsBytes, &v.AvoidIngredients); err != nil {
+			return nil, fmt.Errorf("decode avoid ingredients: %w", err)
+		}
+		if err := unmarshalStringSlice(templatesBytes, &v.Templates); err != nil {
+			return nil, fmt.Errorf("decode templates: %w", err)
+		}
+		history = append(history, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate preference history: %w", err)
+	}
+	return history, nil
+}
+
+// recordPreferenceVersion stores the user's effective preferences, as they
+// stand within tx, as the next version of their history and returns the
+// user at that version. It returns ErrNotFound when the user does not exist.
+func recordPreferenceVersion(ctx context.Context, tx pgx.Tx, userID, source, action string) (*model.User, error) {
+	const bump = `
 		UPDATE users
-		SET allergies = $2::jsonb,
-			diet_goals = $3::jsonb,
-			avoid_ingredients = $4::jsonb,
-			updated_at = NOW()
+		SET preference_version = preference_version + 1
 		WHERE id = $1
-		RETURNING id, email, COALESCE(name, ''), COALESCE(picture, ''), allergies, diet_goals, avoid_ingredients, created_at, updated_at`
+		RETURNING ` + userColumns
 
+	user, err := scanUser(tx.QueryRow(ctx, bump, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("bump preference version: %w", err)
+	}
+
+	lists, err := marshalPreferenceLists(user.Allergies, user.DietGoals, user.AvoidIngredients)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(user.Templates))
+	for _, layer := range user.Templates {
+		keys = append(keys, layer.Key)
+	}
+	templatesJSON, err := json.Marshal(keys)
+	if err != nil {
+		return nil, fmt.Errorf("marshal templates: %w", err)
+	}
+
+	const insert = `
+		INSERT INTO preference_history (user_id, version, source, action, allergies, diet_goals, avoid_ingredients, home_region, templates)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7::jsonb, $8, $9::jsonb)`
+
+	args := append([]interface{}{user.ID, user.PreferenceVersion, source, action}, lists...)
+	if _, err := tx.Exec(ctx, insert, append(args, user.HomeRegion, templatesJSON)...); err != nil {
+		return nil, fmt.Errorf("record preference version: %w", err)
+	}
+	return user, nil
+}
+
+// scanUser reads one row of userColumns. pgx.ErrNoRows is returned
+// unwrapped so callers can map it to ErrNotFound.
+func scanUser(row pgx.Row) (*model.User, error) {
 	var user model.User
 	var allergiesBytes []byte
 	var dietGoalsBytes []byte
 	var avoidIngredientsBytes []byte
+	var layersBytes []byte
 
-	err = r.q.QueryRow(ctx, query, userID, allergiesJSON, dietGoalsJSON, avoidIngredientsJSON).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.Name,
@@ -168,27 +330,42 @@ func (r *userRepo) UpdatePreferences(ctx context.Context, userID string, prefere
 		&allergiesBytes,
 		&dietGoalsBytes,
 		&avoidIngredientsBytes,
+		&user.HomeRegion,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.PreferenceVersion,
+		&layersBytes,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrNotFound
-		}
-		return nil, fmt.Errorf("update user preferences: %w", err)
+		return nil, err
+	}
+	if err := decodeUser(&user, allergiesBytes, dietGoalsBytes, avoidIngredientsBytes, layersBytes); err != nil {
+		return nil, err
 	}
+	return &user, nil
+}
 
-	if err := unmarshalStringSlice(allergiesBytes, &user.Allergies); err != nil {
-		return nil, fmt.Errorf("decode allergies: %w", err)
+// decodeUser decodes the JSONB columns of a user row. The list columns hold
+// the user's own entries; the effective lists are merged from them and the
+// applied templates.
+func decodeUser(user *model.User, allergies, dietGoals, avoidIngredients, layers []byte) error {
+	if err := unmarshalAllergies(allergies, &user.Custom.Allergies); err != nil {
+		return fmt.Errorf("decode allergies: %w", err)
 	}
-	if err := unmarshalStringSlice(dietGoalsBytes, &user.DietGoals); err != nil {
-		return nil, fmt.Errorf("decode diet goals: %w", err)
+	if err := unmarshalStringSlice(dietGoals, &user.Custom.DietGoals); err != nil {
+		return fmt.Errorf("decode diet goals: %w", err)
 	}
-	if err := unmarshalStringSlice(avoidIngredientsBytes, &user.AvoidIngredients); err != nil {
-		return nil, fmt.Errorf("decode avoid ingredients: %w", err)
+	if err := unmarshalStringSlice(avoidIngredients, &user.Custom.AvoidIngredients); err != nil {
+		return fmt.Errorf("decode avoid ingredients: %w", err)
 	}
-
-	return &user, nil
+	user.Templates = []model.TemplateLayer{}
+	if len(layers) > 0 {
+		if err := json.Unmarshal(layers, &user.Templates); err != nil {
+			return fmt.Errorf("decode template layers: %w", err)
+		}
+	}
+	model.MergeLayers(user)
+	return nil
 }
 
 func unmarshalStringSlice(in []byte, out *[]string) error {
@@ -204,3 +381,19 @@ func unmarshalStringSlice(in []byte, out *[]string) error {
 	}
 	return nil
 }
+
+// unmarshalAllergies decodes an allergy list. Entries may still be bare
+// names from before severities were stored; see model.Allergy.
+func unmarshalAllergies(in []byte, out *[]model.Allergy) error {
+	if len(in) == 0 {
+		*out = []model.Allergy{}
+		return nil
+	}
+	if err := json.Unmarshal(in, out); err != nil {
+		return err
+	}
+	if *out == nil {
+		*out = []model.Allergy{}
+	}
+	return nil
+}
@@ -7,19 +7,22 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/safebites/backend-go/internal/model"
 	"github.com/stretchr/testify/require"
 )
 
+var userRowColumns = []string{"id", "email", "name", "picture", "allergies", "diet_goals", "avoid_ingredients", "home_region", "created_at", "updated_at", "preference_version", "templates"}
+
 func TestUserRepoGetByIDSuccess(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer mock.Close()
 
 	now := time.Now().UTC()
-	rows := pgxmock.NewRows([]string{"id", "email", "name", "picture", "allergies", "diet_goals", "avoid_ingredients", "created_at", "updated_at"}).
-		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`["vegan"]`), []byte(`["gelatin"]`), now, now)
+	rows := pgxmock.NewRows(userRowColumns).
+		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`["vegan"]`), []byte(`["gelatin"]`), "", now, now, 1, []byte(`[]`))
 
 	mock.ExpectQuery("SELECT id, email").WithArgs("user-1").WillReturnRows(rows)
 
@@ -27,7 +30,36 @@ func TestUserRepoGetByIDSuccess(t *testing.T) {
 	user, err := repo.GetByID(context.Background(), "user-1")
 	require.NoError(t, err)
 	require.Equal(t, "user-1", user.ID)
-	require.Equal(t, []string{"peanut"}, user.Allergies)
+	require.Equal(t, model.Allergies("peanut"), user.Allergies)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoGetByIDMergesTemplateLayers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	layers := `[
+		{"key": "vegan", "name": "Vegan", "allergies": [], "dietGoals": ["vegan"], "avoidIngredients": ["honey", "gelatin"], "appliedAt": "2026-10-01T09:00:00+00:00"},
+		{"key": "nut_free", "name": "Nut-Free", "allergies": ["tree nuts", "Peanuts"], "dietGoals": ["nut-free"], "avoidIngredients": [], "appliedAt": "2026-10-02T09:00:00+00:00"}
+	]`
+	rows := pgxmock.NewRows(userRowColumns).
+		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanuts"]`), []byte(`[]`), []byte(`["gelatin"]`), "", now, now, 3, []byte(layers))
+	mock.ExpectQuery("FROM user_template_layers").WithArgs("user-1").WillReturnRows(rows)
+
+	repo := &userRepo{q: mock}
+	user, err := repo.GetByID(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Equal(t, model.Allergies("peanuts"), user.Custom.Allergies)
+	require.Equal(t, model.Allergies("peanuts", "tree nuts"), user.Allergies)
+	require.Equal(t, []string{"vegan", "nut-free"}, user.DietGoals)
+	require.Equal(t, []string{"gelatin", "honey"}, user.AvoidIngredients)
+	require.Equal(t, []string{"custom", "nut_free"}, user.Sources.Allergies["peanuts"])
+	require.Equal(t, []string{"custom", "vegan"}, user.Sources.AvoidIngredients["gelatin"])
+	require.Equal(t, []string{"vegan"}, user.Sources.DietGoals["vegan"])
+	require.Len(t, user.Templates, 2)
+	require.Equal(t, "nut_free", user.Templates[1].Key)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -51,9 +83,12 @@ func TestUserRepoUpsertSuccess(t *testing.T) {
 	defer mock.Close()
 
 	now := time.Now().UTC()
-	rows := pgxmock.NewRows([]string{"id", "email", "name", "picture", "allergies", "diet_goals", "avoid_ingredients", "created_at", "updated_at"}).
-		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`["vegan"]`), []byte(`["gelatin"]`), now, now)
+	rows := pgxmock.NewRows(userRowColumns).
+		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`["vegan"]`), []byte(`["gelatin"]`), "", now, now, 0, []byte(`[]`))
+	bumped := pgxmock.NewRows(userRowColumns).
+		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`["vegan"]`), []byte(`["gelatin"]`), "", now, now, 1, []byte(`[]`))
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO users").WithArgs(
 		"user-1",
 		"user@example.com",
@@ -62,46 +97,111 @@ func TestUserRepoUpsertSuccess(t *testing.T) {
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
+		"",
 	).WillReturnRows(rows)
+	mock.ExpectQuery("SET preference_version = preference_version \\+ 1").WithArgs("user-1").WillReturnRows(bumped)
+	mock.ExpectExec("INSERT INTO preference_history").WithArgs(
+		"user-1",
+		1,
+		model.PreferenceSourceManual,
+		model.PreferenceCreated,
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		"",
+		[]byte(`[]`),
+	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
 
 	repo := &userRepo{q: mock}
 	created, err := repo.Upsert(context.Background(), &model.User{
 		ID:               "user-1",
 		Email:            "user@example.com",
 		Name:             "Test User",
-		Allergies:        []string{"peanut"},
+		Allergies:        model.Allergies("peanut"),
 		DietGoals:        []string{"vegan"},
 		AvoidIngredients: []string{"gelatin"},
 	})
 	require.NoError(t, err)
 	require.Equal(t, "user-1", created.ID)
+	require.Equal(t, 1, created.PreferenceVersion)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUserRepoUpdatePreferencesSuccess(t *testing.T) {
+func TestUserRepoUpsertExistingUserKeepsVersion(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer mock.Close()
 
 	now := time.Now().UTC()
-	rows := pgxmock.NewRows([]string{"id", "email", "name", "picture", "allergies", "diet_goals", "avoid_ingredients", "created_at", "updated_at"}).
-		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`["keto"]`), []byte(`["sugar"]`), now, now)
+	rows := pgxmock.NewRows(userRowColumns).
+		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`[]`), []byte(`[]`), "", now, now, 4, []byte(`[]`))
 
-	mock.ExpectQuery("UPDATE users").WithArgs(
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO users").WithArgs(
 		"user-1",
+		"user@example.com",
+		"Test User",
+		"",
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
+		"",
 	).WillReturnRows(rows)
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	repo := &userRepo{q: mock}
+	user, err := repo.Upsert(context.Background(), &model.User{ID: "user-1", Email: "user@example.com", Name: "Test User"})
+	require.NoError(t, err)
+	require.Equal(t, 4, user.PreferenceVersion)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoUpdatePreferencesSuccess(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	rows := pgxmock.NewRows(userRowColumns).
+		AddRow("user-1", "user@example.com", "Test User", "", []byte(`["peanut"]`), []byte(`["keto"]`), []byte(`["sugar"]`), "EU", now, now, 2, []byte(`[]`))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WithArgs(
+		"user-1",
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		"EU",
+	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery("SET preference_version = preference_version \\+ 1").WithArgs("user-1").WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO preference_history").WithArgs(
+		"user-1",
+		2,
+		model.PreferenceSourceManual,
+		model.PreferenceUpdated,
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		"EU",
+		[]byte(`[]`),
+	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
 
 	repo := &userRepo{q: mock}
 	updated, err := repo.UpdatePreferences(context.Background(), "user-1", model.UserPreferences{
-		Allergies:        []string{"peanut"},
+		Allergies:        model.Allergies("peanut"),
 		DietGoals:        []string{"keto"},
 		AvoidIngredients: []string{"sugar"},
+		HomeRegion:       "EU",
 	})
 	require.NoError(t, err)
 	require.Equal(t, []string{"keto"}, updated.DietGoals)
+	require.Equal(t, "EU", updated.HomeRegion)
+	require.Equal(t, 2, updated.PreferenceVersion)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -110,12 +210,15 @@ func TestUserRepoUpdatePreferencesNotFound(t *testing.T) {
 	require.NoError(t, err)
 	defer mock.Close()
 
-	mock.ExpectQuery("UPDATE users").WithArgs(
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WithArgs(
 		"missing",
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
-	).WillReturnError(pgx.ErrNoRows)
+		"",
+	).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
 
 	repo := &userRepo{q: mock}
 	_, err = repo.UpdatePreferences(context.Background(), "missing", model.UserPreferences{})
@@ -129,6 +232,7 @@ func TestUserRepoUpsertDBError(t *testing.T) {
 	require.NoError(t, err)
 	defer mock.Close()
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO users").WithArgs(
 		"user-1",
 		"user@example.com",
@@ -137,7 +241,9 @@ func TestUserRepoUpsertDBError(t *testing.T) {
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
 		pgxmock.AnyArg(),
+		"",
 	).WillReturnError(errors.New("db failure"))
+	mock.ExpectRollback()
 
 	repo := &userRepo{q: mock}
 	_, err = repo.Upsert(context.Background(), &model.User{ID: "user-1", Email: "user@example.com"})
@@ -145,3 +251,158 @@ func TestUserRepoUpsertDBError(t *testing.T) {
 	require.ErrorContains(t, err, "upsert user")
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepoAddTemplateLayerUnknownUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO user_template_layers").WithArgs("missing", "vegan", nil, nil, nil, nil).
+		WillReturnError(&pgconn.PgError{Code: "23503"})
+	mock.ExpectRollback()
+
+	repo := &userRepo{q: mock}
+	require.ErrorIs(t, repo.AddTemplateLayer(context.Background(), "missing", model.TemplateLayer{Key: "vegan"}), ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoRemoveTemplateLayerNotApplied(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM user_template_layers").WithArgs("user-1", "keto").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectRollback()
+
+	repo := &userRepo{q: mock}
+	require.ErrorIs(t, repo.RemoveTemplateLayer(context.Background(), "user-1", "keto"), ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoAddTemplateLayerRecordsVersion(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	layers := `[{"key": "vegan", "name": "Vegan", "allergies": [], "dietGoals": ["vegan"], "avoidIngredients": ["honey"], "appliedAt": "2026-10-01T09:00:00+00:00"}]`
+	rows := pgxmock.NewRows(userRowColumns).
+		AddRow("user-1", "user@example.com", "Test User", "", []byte(`[]`), []byte(`[]`), []byte(`[]`), "", now, now, 5, []byte(layers))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO user_template_layers").WithArgs("user-1", "vegan", nil, nil, nil, nil).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("SET preference_version = preference_version \\+ 1").WithArgs("user-1").WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO preference_history").WithArgs(
+		"user-1",
+		5,
+		"vegan",
+		model.PreferenceTemplateApplied,
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		pgxmock.AnyArg(),
+		"",
+		[]byte(`["vegan"]`),
+	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	repo := &userRepo{q: mock}
+	require.NoError(t, repo.AddTemplateLayer(context.Background(), "user-1", model.TemplateLayer{Key: "vegan"}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoAddTemplateLayerStoresSnapshot(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO user_template_layers").
+		WithArgs("user-1", "tpl-shared", "Low FODMAP", []byte(`[{"name":"gluten","severity":"allergy","mayContain":false}]`), []byte(`[]`), []byte(`["onion"]`)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("SET preference_version = preference_version \\+ 1").WithArgs("user-1").
+		WillReturnRows(pgxmock.NewRows(userRowColumns).
+			AddRow("user-1", "user@example.com", "", "", []byte(`[]`), []byte(`[]`), []byte(`[]`), "", now, now, 2, []byte(`[]`)))
+	mock.ExpectExec("INSERT INTO preference_history").
+		WithArgs("user-1", 2, "tpl-shared", model.PreferenceTemplateApplied, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	repo := &userRepo{q: mock}
+	require.NoError(t, repo.AddTemplateLayer(context.Background(), "user-1", model.TemplateLayer{
+		Key:  "tpl-shared",
+		Name: "Low FODMAP",
+		PreferenceLists: model.PreferenceLists{
+			Allergies:        model.Allergies("gluten"),
+			DietGoals:        []string{},
+			AvoidIngredients: []string{"onion"},
+		},
+		Snapshot: true,
+	}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoAddTemplateLayerAlreadyAppliedRecordsNothing(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO user_template_layers").WithArgs("user-1", "vegan", nil, nil, nil, nil).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("user-1", "vegan").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	repo := &userRepo{q: mock}
+	require.NoError(t, repo.AddTemplateLayer(context.Background(), "user-1", model.TemplateLayer{Key: "vegan"}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoAddTemplateLayerDeletedTemplate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO user_template_layers").WithArgs("user-1", "tpl-gone", nil, nil, nil, nil).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("user-1", "tpl-gone").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	repo := &userRepo{q: mock}
+	require.ErrorIs(t, repo.AddTemplateLayer(context.Background(), "user-1", model.TemplateLayer{Key: "tpl-gone"}), ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepoListPreferenceHistory(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	rows := pgxmock.NewRows([]string{"version", "source", "action", "allergies", "diet_goals", "avoid_ingredients", "home_region", "templates", "created_at"}).
+		AddRow(2, "vegan", "template_applied", []byte(`[{"name": "peanut", "severity": "anaphylactic"}]`), []byte(`["vegan"]`), []byte(`["honey"]`), "EU", []byte(`["vegan"]`), now).
+		AddRow(1, "manual", "created", []byte(`["peanut"]`), []byte(`[]`), []byte(`[]`), "EU", []byte(`[]`), now)
+
+	mock.ExpectQuery("FROM preference_history").WithArgs("user-1", 20).WillReturnRows(rows)
+
+	repo := &userRepo{q: mock}
+	history, err := repo.ListPreferenceHistory(context.Background(), "user-1", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, 2, history[0].Version)
+	require.Equal(t, "vegan", history[0].Source)
+	require.Equal(t, []string{"vegan"}, history[0].Templates)
+	require.Equal(t, model.SeverityAnaphylactic, history[0].Allergies[0].Severity)
+	require.Equal(t, model.Allergies("peanut"), history[1].Allergies)
+	require.Empty(t, history[1].Templates)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+type webhookQuerier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+type webhookRepo struct {
+	q webhookQuerier
+}
+
+func NewWebhookRepository(db *DB) WebhookRepository {
+	return &webhookRepo{q: db.Pool}
+}
+
+const subscriptionColumns = `s.id, s.user_id, s.app_name, s.url, s.secret, s.events, s.created_at`
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.response_status, d.last_error, d.next_attempt_at, d.replay_of, d.created_at, d.delivered_at`
+
+func subscriptionFields(s *model.WebhookSubscription) []interface{} {
+	return []interface{}{&s.ID, &s.UserID, &s.AppName, &s.URL, &s.Secret, &s.Events, &s.CreatedAt}
+}
+
+func deliveryFields(d *model.WebhookDelivery) []interface{} {
+	return []interface{}{
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.ReplayOf, &d.CreatedAt, &d.DeliveredAt,
+	}
+}
+
+func (r *webhookRepo) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	const query = `
+		INSERT INTO webhook_subscriptions AS s (id, user_id, app_name, url, secret, events)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + subscriptionColumns
+
+	var created model.WebhookSubscription
+	err := r.q.QueryRow(ctx, query, sub.ID, sub.UserID, sub.AppName, sub.URL, sub.Secret, sub.Events).
+		Scan(subscriptionFields(&created)...)
+	if err != nil {
+		return nil, fmt.Errorf("create webhook subscription: %w", err)
+	}
+	return &created, nil
+}
+
+func (r *webhookRepo) GetSubscription(ctx context.Context, userID, subscriptionID string) (*model.WebhookSubscription, error) {
+	const query = `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions s WHERE s.user_id = $1 AND s.id = $2`
+
+	var sub model.WebhookSubscription
+	if err := r.q.QueryRow(ctx, query, userID, subscriptionID).Scan(subscriptionFields(&sub)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+func (r *webhookRepo) ListSubscriptions(ctx context.Context, userID string) ([]model.WebhookSubscription, error) {
+	const query = `
+		SELECT ` + subscriptionColumns + `
+		FROM webhook_subscriptions s
+		WHERE s.user_id = $1
+		ORDER BY s.created_at DESC`
+
+	return r.listSubscriptions(ctx, "list webhook subscriptions", query, userID)
+}
+
+func (r *webhookRepo) ListSubscribers(ctx context.Context, userID, eventType string) ([]model.WebhookSubscription, error) {
+	const query = `
+		SELECT ` + subscriptionColumns + `
+		FROM webhook_subscriptions s
+		WHERE s.user_id = $1 AND $2 = ANY(s.events)`
+
+	return r.listSubscriptions(ctx, "list webhook subscribers", query, userID, eventType)
+}
+
+func (r *webhookRepo) listSubscriptions(ctx context.Context, op, query string, args ...interface{}) ([]model.WebhookSubscription, error) {
+	rows, err := r.q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	subs := make([]model.WebhookSubscription, 0)
+	for rows.Next() {
+		var sub model.WebhookSubscription
+		if err := rows.Scan(subscriptionFields(&sub)...); err != nil {
+			return nil, fmt.Errorf("scan webhook subscription row: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+func (r *webhookRepo) DeleteSubscription(ctx context.Context, userID, subscriptionID string) error {
+	const query = `DELETE FROM webhook_subscriptions WHERE user_id = $1 AND id = $2`
+
+	cmdTag, err := r.q.Exec(ctx, query, userID, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	const query = `
+		INSERT INTO webhook_deliveries AS d (subscription_id, event_id, event_type, payload, status, next_attempt_at, replay_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + deliveryColumns
+
+	var created model.WebhookDelivery
+	err := r.q.QueryRow(ctx, query,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Payload,
+		delivery.Status,
+		delivery.NextAttemptAt,
+		delivery.ReplayOf,
+	).Scan(deliveryFields(&created)...)
+	if err != nil {
+		return nil, fmt.Errorf("create webhook delivery: %w", err)
+	}
+	return &created, nil
+}
+
+func (r *webhookRepo) GetDelivery(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error) {
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE s.user_id = $1 AND d.id = $2`
+
+	var delivery model.WebhookDelivery
+	if err := r.q.QueryRow(ctx, query, userID, deliveryID).Scan(deliveryFields(&delivery)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $2`
+
+	rows, err := r.q.Query(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery model.WebhookDelivery
+		if err := rows.Scan(deliveryFields(&delivery)...); err != nil {
+			return nil, fmt.Errorf("scan webhook delivery row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ClaimDue uses SKIP LOCKED so several server instances can drain the queue
+// without sending the same delivery twice.
+func (r *webhookRepo) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.WebhookAttempt, error) {
+	const query = `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns + `, ` + subscriptionColumns
+
+	rows, err := r.q.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]model.WebhookAttempt, 0)
+	for rows.Next() {
+		var attempt model.WebhookAttempt
+		fields := append(deliveryFields(&attempt.Delivery), subscriptionFields(&attempt.Subscription)...)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, fmt.Errorf("scan due webhook delivery row: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate due webhook deliveries: %w", err)
+	}
+	return attempts, nil
+}
+
+func (r *webhookRepo) RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_status = $4, last_error = $5, next_attempt_at = $6, delivered_at = $7
+		WHERE id = $1`
+
+	cmdTag, err := r.q.Exec(ctx, query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("record webhook attempt: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
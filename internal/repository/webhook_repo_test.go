@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	subscriptionRowColumns = []string{"id", "user_id", "app_name", "url", "secret", "events", "created_at"}
+	deliveryRowColumns     = []string{
+		"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempts",
+		"response_status", "last_error", "next_attempt_at", "replay_of", "created_at", "delivered_at",
+	}
+)
+
+func TestWebhookRepoCreateSubscription(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	events := []string{model.EventScanCreated}
+	mock.ExpectQuery("INSERT INTO webhook_subscriptions").
+		WithArgs("sub-1", "user-1", "Pantry", "https://pantry.example.com/hook", "whsec_x", events).
+		WillReturnRows(pgxmock.NewRows(subscriptionRowColumns).
+			AddRow("sub-1", "user-1", "Pantry", "https://pantry.example.com/hook", "whsec_x", events, now))
+
+	repo := &webhookRepo{q: mock}
+	created, err := repo.CreateSubscription(context.Background(), &model.WebhookSubscription{
+		ID:      "sub-1",
+		UserID:  "user-1",
+		AppName: "Pantry",
+		URL:     "https://pantry.example.com/hook",
+		Secret:  "whsec_x",
+		Events:  events,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "whsec_x", created.Secret)
+	require.Equal(t, events, created.Events)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepoDeleteSubscriptionNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectExec("DELETE FROM webhook_subscriptions").WithArgs("user-1", "sub-9").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	repo := &webhookRepo{q: mock}
+	err = repo.DeleteSubscription(context.Background(), "user-1", "sub-9")
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepoGetDeliveryScopedToUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery("JOIN webhook_subscriptions s").WithArgs("user-2", int64(7)).WillReturnError(pgx.ErrNoRows)
+
+	repo := &webhookRepo{q: mock}
+	_, err = repo.GetDelivery(context.Background(), "user-2", 7)
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepoClaimDue(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC()
+	lease := now.Add(20 * time.Second)
+	payload := json.RawMessage(`{"type":"scan.created"}`)
+	columns := append(append([]string{}, deliveryRowColumns...), subscriptionRowColumns...)
+	mock.ExpectQuery("FOR UPDATE SKIP LOCKED").WithArgs(now, lease, 20).
+		WillReturnRows(pgxmock.NewRows(columns).AddRow(
+			int64(3), "sub-1", "evt-1", model.EventScanCreated, payload, model.DeliveryPending, 1,
+			nil, "endpoint answered 500", &lease, nil, now, nil,
+			"sub-1", "user-1", "Pantry", "https://pantry.example.com/hook", "whsec_x", []string{model.EventScanCreated}, now,
+		))
+
+	repo := &webhookRepo{q: mock}
+	attempts, err := repo.ClaimDue(context.Background(), now, lease, 20)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	require.Equal(t, int64(3), attempts[0].Delivery.ID)
+	require.JSONEq(t, string(payload), string(attempts[0].Delivery.Payload))
+	require.Nil(t, attempts[0].Delivery.ResponseStatus)
+	require.Equal(t, "https://pantry.example.com/hook", attempts[0].Subscription.URL)
+	require.Equal(t, "whsec_x", attempts[0].Subscription.Secret)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
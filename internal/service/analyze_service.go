@@ -2,61 +2,367 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/safebites/backend-go/internal/guard"
+	"github.com/safebites/backend-go/internal/imageprep"
 	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/observability"
+	"github.com/safebites/backend-go/internal/progress"
+	"github.com/safebites/backend-go/internal/repository"
 )
 
-type productNameExtractor interface {
-	ExtractProductName(ctx context.Context, imageBytes []byte, mimeType string) (string, error)
+const (
+	// DefaultDedupMaxDistance is how many of the 64 perceptual-hash bits may
+	// differ for an upload to count as a near-duplicate.
+	DefaultDedupMaxDistance = 6
+	// DefaultDedupTTL bounds how long a cached image analysis is reused.
+	DefaultDedupTTL = 30 * 24 * time.Hour
+	// MaxScoredIngredients caps the ingredient lists clients submit for
+	// scoring.
+	MaxScoredIngredients = 100
+)
+
+// Errors for client-supplied ingredient lists and text that cannot be scored.
+var (
+	ErrNoIngredients      = errors.New("at least one ingredient is required")
+	ErrTooManyIngredients = fmt.Errorf("at most %d ingredients can be scored", MaxScoredIngredients)
+)
+
+type labelReader interface {
+	ReadLabel(ctx context.Context, imageBytes []byte, mimeType string) (*model.LabelReading, error)
 }
 
 type analyzeWorkflow interface {
 	AnalyzeOnly(ctx context.Context, productName string, prefs *model.UserPreferences) (*model.WebSearchResult, *model.ScorerResult, error)
+	ScoreIngredients(ctx context.Context, ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error)
+}
+
+type AnalyzeConfig struct {
+	// ConfidenceThreshold is the overall confidence below which a result is
+	// flagged as needing verification. Zero turns flagging off; negative
+	// values fall back to DefaultConfidenceThreshold.
+	ConfidenceThreshold float64
+	// DedupMaxDistance is the largest Hamming distance between perceptual
+	// hashes at which an upload reuses a cached product name. Negative values
+	// turn deduplication off.
+	DedupMaxDistance int
+	// DedupReuseAnalysis also reuses the cached full analysis when it was
+	// scored against the same preferences, skipping search and scoring.
+	DedupReuseAnalysis bool
+	DedupTTL           time.Duration
 }
 
 type analyzeService struct {
-	vision       productNameExtractor
+	vision       labelReader
 	orchestrator analyzeWorkflow
+	images       repository.ImageAnalysisRepository
+	cfg          AnalyzeConfig
 }
 
-func NewAnalyzeService(vision productNameExtractor, orchestrator analyzeWorkflow) AnalyzeService {
+// NewAnalyzeService builds the image analysis service. images caches results
+// by perceptual hash; nil disables deduplication of repeated uploads.
+func NewAnalyzeService(vision labelReader, orchestrator analyzeWorkflow, images repository.ImageAnalysisRepository, cfg AnalyzeConfig) AnalyzeService {
+	if cfg.ConfidenceThreshold < 0 {
+		cfg.ConfidenceThreshold = DefaultConfidenceThreshold
+	}
+	if cfg.DedupTTL <= 0 {
+		cfg.DedupTTL = DefaultDedupTTL
+	}
 	return &analyzeService{
 		vision:       vision,
 		orchestrator: orchestrator,
+		images:       images,
+		cfg:          cfg,
 	}
 }
 
-func (s *analyzeService) Analyze(ctx context.Context, imageBytes []byte, mimeType string, prefs *model.UserPreferences) (string, *model.ScorerResult, error) {
+func (s *analyzeService) Analyze(ctx context.Context, imageBytes []byte, mimeType string, prefs *model.UserPreferences) (*model.AnalysisResult, error) {
 	if s.vision == nil {
-		return "", nil, fmt.Errorf("vision dependency is required")
+		return nil, fmt.Errorf("vision dependency is required")
 	}
 	if s.orchestrator == nil {
-		return "", nil, fmt.Errorf("orchestrator dependency is required")
+		return nil, fmt.Errorf("orchestrator dependency is required")
 	}
 	if len(imageBytes) == 0 {
-		return "", nil, fmt.Errorf("image bytes are required")
+		return nil, fmt.Errorf("image bytes are required")
 	}
 	if strings.TrimSpace(mimeType) == "" {
 		mimeType = "image/jpeg"
 	}
 
-	productName, err := s.vision.ExtractProductName(ctx, imageBytes, mimeType)
+	prefsKey := preferencesKey(prefs)
+	dup := s.findDuplicate(ctx, imageBytes, prefsKey)
+	if dup.reuseAnalysis {
+		return dup.entry.Analysis, nil
+	}
+
+	reading := dup.label()
+	if reading == nil {
+		progress.Report(ctx, progress.Event{Stage: progress.StageReadingLabel})
+		var err error
+		if reading, err = s.vision.ReadLabel(ctx, imageBytes, mimeType); err != nil {
+			return nil, fmt.Errorf("extract product name: %w", err)
+		}
+	}
+	if reading == nil || strings.TrimSpace(reading.ProductName) == "" {
+		return nil, fmt.Errorf("product name extraction returned empty value")
+	}
+	productName := strings.TrimSpace(reading.ProductName)
+	progress.Report(ctx, progress.Event{Stage: progress.StageLabelRead, ProductName: productName})
+
+	search, score, err := s.orchestrator.AnalyzeOnly(ctx, productName, prefs)
 	if err != nil {
-		return "", nil, fmt.Errorf("extract product name: %w", err)
+		return nil, fmt.Errorf("run analyze workflow: %w", err)
+	}
+	if score == nil {
+		return nil, fmt.Errorf("analyze workflow returned empty result")
+	}
+
+	result := &model.AnalysisResult{
+		ProductName:         productName,
+		DetectedLanguage:    reading.Language,
+		Language:            model.DefaultLanguage,
+		IngredientBreakdown: score,
+		Sources:             []model.Source{},
+		Confidence:          assessConfidence(reading.Confidence, search, score, s.cfg.ConfidenceThreshold),
+	}
+	if search != nil {
+		result.Ingredients = search.ListOfIngredients
+		if search.Sources != nil {
+			result.Sources = search.Sources
+		}
+	}
+	if result.DetectedLanguage == "" && search != nil {
+		result.DetectedLanguage = search.LabelLanguage
+	}
+	s.remember(ctx, dup, *reading, prefsKey, result)
+	return result, nil
+}
+
+// AnalyzeProduct analyzes a product by name, without a label image.
+func (s *analyzeService) AnalyzeProduct(ctx context.Context, productName string, prefs *model.UserPreferences) (*model.AnalysisResult, error) {
+	if s.orchestrator == nil {
+		return nil, fmt.Errorf("orchestrator dependency is required")
 	}
 	if strings.TrimSpace(productName) == "" {
-		return "", nil, fmt.Errorf("product name extraction returned empty value")
+		return nil, fmt.Errorf("product name is required")
+	}
+	guarded, err := guard.ProductName(ctx, guard.SourceParam, productName)
+	if err != nil {
+		return nil, err
 	}
 
-	_, score, err := s.orchestrator.AnalyzeOnly(ctx, strings.TrimSpace(productName), prefs)
+	search, score, err := s.orchestrator.AnalyzeOnly(ctx, guarded.Value, prefs)
 	if err != nil {
-		return "", nil, fmt.Errorf("run analyze workflow: %w", err)
+		return nil, fmt.Errorf("run analyze workflow: %w", err)
 	}
 	if score == nil {
-		return "", nil, fmt.Errorf("analyze workflow returned empty result")
+		return nil, fmt.Errorf("analyze workflow returned empty result")
+	}
+
+	// The name was given rather than read off a label, so OCR cannot have
+	// gotten it wrong.
+	result := &model.AnalysisResult{
+		ProductName:         guarded.Value,
+		Language:            model.DefaultLanguage,
+		IngredientBreakdown: score,
+		Sources:             []model.Source{},
+		Confidence:          assessConfidence(1, search, score, s.cfg.ConfidenceThreshold),
+	}
+	if search != nil {
+		result.DetectedLanguage = search.LabelLanguage
+		result.Ingredients = search.ListOfIngredients
+		if search.Sources != nil {
+			result.Sources = search.Sources
+		}
+	}
+	return result, nil
+}
+
+// AnalyzeIngredients scores ingredient text pasted from a label, skipping OCR
+// and the product search. productName is optional and only labels the result.
+func (s *analyzeService) AnalyzeIngredients(ctx context.Context, productName, ingredientText string, prefs *model.UserPreferences) (*model.AnalysisResult, error) {
+	if s.orchestrator == nil {
+		return nil, fmt.Errorf("orchestrator dependency is required")
+	}
+	if len(ingredientText) > MaxIngredientTextLength {
+		return nil, fmt.Errorf("ingredient text must be at most %d bytes", MaxIngredientTextLength)
+	}
+	if strings.TrimSpace(productName) != "" {
+		guarded, err := guard.ProductName(ctx, guard.SourceParam, productName)
+		if err != nil {
+			return nil, err
+		}
+		productName = guarded.Value
+	}
+
+	ingredients := parseIngredientText(ingredientText)
+	score, err := s.scoreIngredients(ctx, ingredients, prefs)
+	if err != nil {
+		return nil, err
 	}
+	return &model.AnalysisResult{
+		ProductName:         productName,
+		Language:            model.DefaultLanguage,
+		IngredientBreakdown: score,
+		Ingredients:         ingredients,
+		Sources:             []model.Source{},
+		Confidence:          assessListConfidence(countListed(ingredients), score, s.cfg.ConfidenceThreshold),
+	}, nil
+}
 
-	return strings.TrimSpace(productName), score, nil
+// ScoreIngredients scores a list of ingredient names directly, skipping the
+// product search.
+func (s *analyzeService) ScoreIngredients(ctx context.Context, names []string, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+	if s.orchestrator == nil {
+		return nil, fmt.Errorf("orchestrator dependency is required")
+	}
+	ingredients := make([]model.Ingredient, 0, len(names))
+	for _, name := range names {
+		ingredients = append(ingredients, model.Ingredient{Name: name})
+	}
+	return s.scoreIngredients(ctx, ingredients, prefs)
+}
+
+// RescoreIngredients scores ingredients an analysis already found, keeping
+// their "may contain" flags so traces are only counted for allergies that
+// ask for them.
+func (s *analyzeService) RescoreIngredients(ctx context.Context, ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+	if s.orchestrator == nil {
+		return nil, fmt.Errorf("orchestrator dependency is required")
+	}
+	return s.scoreIngredients(ctx, ingredients, prefs)
+}
+
+// scoreIngredients scores client-supplied ingredients. Every name passes the
+// input guard like a product name; blank names are skipped.
+func (s *analyzeService) scoreIngredients(ctx context.Context, ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+	guarded := make([]model.Ingredient, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		if strings.TrimSpace(ingredient.Name) == "" {
+			continue
+		}
+		name, err := guard.ProductName(ctx, guard.SourceParam, ingredient.Name)
+		if err != nil {
+			return nil, err
+		}
+		ingredient.Name = name.Value
+		guarded = append(guarded, ingredient)
+	}
+	// "May contain" traces alone are not an ingredient list.
+	if countListed(guarded) == 0 {
+		return nil, ErrNoIngredients
+	}
+	if len(guarded) > MaxScoredIngredients {
+		return nil, ErrTooManyIngredients
+	}
+
+	result, err := s.orchestrator.ScoreIngredients(ctx, guarded, prefs)
+	if err != nil {
+		return nil, fmt.Errorf("score ingredients: %w", err)
+	}
+	if result == nil {
+		return nil, fmt.Errorf("scorer returned empty result")
+	}
+	return result, nil
+}
+
+// duplicate is the outcome of looking an upload up by its perceptual hash.
+type duplicate struct {
+	hashed bool
+	phash  uint64
+	// entry is the closest cached analysis, or nil on a miss.
+	entry         *model.ImageAnalysis
+	reuseAnalysis bool
+}
+
+func (d duplicate) label() *model.LabelReading {
+	if d.entry == nil {
+		return nil
+	}
+	reading := d.entry.Label
+	return &reading
+}
+
+// findDuplicate hashes the upload and looks for a near-duplicate that was
+// analyzed before. Lookup failures only cost the cache hit, never the request.
+func (s *analyzeService) findDuplicate(ctx context.Context, imageBytes []byte, prefsKey string) duplicate {
+	if s.images == nil || s.cfg.DedupMaxDistance < 0 {
+		return duplicate{}
+	}
+	ctx, span := observability.StartAgentSpan(ctx, "ImageDedup")
+	defer span.End()
+
+	phash, err := imageprep.PerceptualHash(imageBytes)
+	if err != nil {
+		observability.RecordImageDedup(observability.DedupSkipped)
+		span.SetDedup("", observability.DedupSkipped, -1)
+		return duplicate{}
+	}
+	dup := duplicate{hashed: true, phash: phash}
+	hexHash := strconv.FormatUint(phash, 16)
+
+	entry, err := s.images.FindNearest(ctx, phash, s.cfg.DedupMaxDistance, prefsKey, time.Now().Add(-s.cfg.DedupTTL))
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		observability.RecordImageDedup(observability.DedupMiss)
+		span.SetDedup(hexHash, observability.DedupMiss, -1)
+		return dup
+	case err != nil:
+		log.Printf("image dedup lookup failed phash=%s err=%v", hexHash, err)
+		span.RecordError(err)
+		observability.RecordImageDedup(observability.DedupError)
+		span.SetDedup(hexHash, observability.DedupError, -1)
+		return dup
+	}
+
+	dup.entry = entry
+	dup.reuseAnalysis = s.cfg.DedupReuseAnalysis && entry.Analysis != nil && entry.PreferencesKey == prefsKey
+	outcome := observability.DedupNameHit
+	if dup.reuseAnalysis {
+		outcome = observability.DedupAnalysisHit
+	}
+	observability.RecordImageDedup(outcome)
+	span.SetDedup(hexHash, outcome, entry.Distance)
+	return dup
+}
+
+// remember stores a fresh analysis under the upload's hash so later
+// near-duplicates can reuse it. A name hit is stored again only when its
+// analysis becomes reusable for preferences the cache has not seen.
+func (s *analyzeService) remember(ctx context.Context, dup duplicate, reading model.LabelReading, prefsKey string, result *model.AnalysisResult) {
+	if !dup.hashed {
+		return
+	}
+	if dup.entry != nil && (!s.cfg.DedupReuseAnalysis || dup.entry.PreferencesKey == prefsKey) {
+		return
+	}
+	reading.ProductName = result.ProductName
+	entry := &model.ImageAnalysis{PHash: dup.phash, Label: reading, PreferencesKey: prefsKey, Analysis: result}
+	if err := s.images.Create(ctx, entry); err != nil {
+		log.Printf("image dedup store failed product=%q err=%v", result.ProductName, err)
+	}
+}
+
+// preferencesKey fingerprints the preferences an analysis is scored against.
+// Requests without preferences share the empty key.
+func preferencesKey(prefs *model.UserPreferences) string {
+	if prefs == nil {
+		return ""
+	}
+	raw, err := json.Marshal(prefs)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16])
 }
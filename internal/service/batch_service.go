@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/safebites/backend-go/internal/guard"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+const (
+	// DefaultBatchConcurrency is how many batch products are analyzed at
+	// once, across all batches.
+	DefaultBatchConcurrency = 4
+	// DefaultBatchCacheTTL bounds how long a product analysis is reused by
+	// later batches.
+	DefaultBatchCacheTTL = time.Hour
+	// DefaultBatchCacheSize caps the analyses kept for reuse.
+	DefaultBatchCacheSize = 1000
+	// DefaultBatchAnalysisTimeout bounds one shared product analysis,
+	// including the wait for a concurrency slot.
+	DefaultBatchAnalysisTimeout = 2 * time.Minute
+)
+
+type BatchConfig struct {
+	// Concurrency bounds the analyses running at once for all batches
+	// together, so a client cannot raise it by sending more requests.
+	Concurrency int
+	CacheTTL    time.Duration
+	CacheSize   int
+	// AnalysisTimeout bounds each analysis. Analyses are shared between
+	// requests, so they do not stop when the request that started them is
+	// cancelled.
+	AnalysisTimeout time.Duration
+}
+
+type batchService struct {
+	analyze AnalyzeService
+	cfg     BatchConfig
+	now     func() time.Time
+	// sem holds a slot for each running analysis.
+	sem chan struct{}
+
+	mu    sync.Mutex
+	cache map[string]*batchEntry
+}
+
+// batchEntry is one analysis, shared by every item that asks for the same
+// product under the same preferences while it runs and until it expires.
+type batchEntry struct {
+	done    chan struct{}
+	result  *model.AnalysisResult
+	err     error
+	expires time.Time
+}
+
+// NewBatchService analyzes lists of product names with analyze, sharing
+// analyses across items and batches.
+func NewBatchService(analyze AnalyzeService, cfg BatchConfig) BatchService {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultBatchConcurrency
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = DefaultBatchCacheTTL
+	}
+	if cfg.CacheSize <= 0 {
+		cfg.CacheSize = DefaultBatchCacheSize
+	}
+	if cfg.AnalysisTimeout <= 0 {
+		cfg.AnalysisTimeout = DefaultBatchAnalysisTimeout
+	}
+	return &batchService{
+		analyze: analyze,
+		cfg:     cfg,
+		now:     time.Now,
+		sem:     make(chan struct{}, cfg.Concurrency),
+		cache:   map[string]*batchEntry{},
+	}
+}
+
+func (s *batchService) AnalyzeBatch(ctx context.Context, productNames []string, prefs *model.UserPreferences) (*model.BatchResult, error) {
+	if s.analyze == nil {
+		return nil, fmt.Errorf("analyze dependency is required")
+	}
+	if len(productNames) == 0 || len(productNames) > model.MaxBatchProducts {
+		return nil, fmt.Errorf("between 1 and %d product names are required", model.MaxBatchProducts)
+	}
+
+	prefsKey := preferencesKey(prefs)
+	items := make([]model.BatchItem, len(productNames))
+	var wg sync.WaitGroup
+	for i, name := range productNames {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			items[i] = s.analyzeOne(ctx, name, prefs, prefsKey)
+		}(i, name)
+	}
+	wg.Wait()
+
+	result := &model.BatchResult{Items: items}
+	for _, item := range items {
+		if item.Error == "" {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// analyzeOne turns the analysis of one name into a batch item, so a failure
+// only costs that item.
+func (s *batchService) analyzeOne(ctx context.Context, productName string, prefs *model.UserPreferences, prefsKey string) model.BatchItem {
+	item := model.BatchItem{ProductName: strings.TrimSpace(productName)}
+	if item.ProductName == "" {
+		item.Error = "product name is required"
+		return item
+	}
+
+	result, cached, err := s.shared(ctx, item.ProductName, prefs, prefsKey)
+	var rejected *guard.RejectedError
+	switch {
+	case errors.As(err, &rejected):
+		item.Error = "product name was rejected as unsafe input"
+		item.Code = guard.ErrorCode
+	case err != nil:
+		log.Printf("batch analysis failed product=%q err=%v", item.ProductName, err)
+		item.Error = "failed to analyze product"
+	default:
+		item.Result = result
+		item.Cached = cached
+	}
+	return item
+}
+
+// shared returns the cached or in-flight analysis of productName under
+// prefsKey, or starts it. Every caller, the one that started it included,
+// only stops waiting when its own ctx is done.
+func (s *batchService) shared(ctx context.Context, productName string, prefs *model.UserPreferences, prefsKey string) (*model.AnalysisResult, bool, error) {
+	key := prefsKey + "\x00" + strings.ToLower(strings.Join(strings.Fields(productName), " "))
+
+	s.mu.Lock()
+	entry, cached := s.cache[key]
+	if !cached || s.expired(entry) {
+		entry, cached = &batchEntry{done: make(chan struct{})}, false
+		s.store(key, entry)
+		go s.run(context.WithoutCancel(ctx), key, entry, productName, prefs)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		return entry.result, cached, entry.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// run analyzes productName into entry while holding a slot of s.sem, under its
+// own timeout rather than the cancellation of the request that started it.
+// Failures are handed to the items waiting on them but never cached.
+func (s *batchService) run(ctx context.Context, key string, entry *batchEntry, productName string, prefs *model.UserPreferences) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.AnalysisTimeout)
+	defer cancel()
+
+	select {
+	case s.sem <- struct{}{}:
+		entry.result, entry.err = s.analyze.AnalyzeProduct(ctx, productName, prefs)
+		<-s.sem
+	case <-ctx.Done():
+		entry.err = ctx.Err()
+	}
+
+	s.mu.Lock()
+	entry.expires = s.now().Add(s.cfg.CacheTTL)
+	if entry.err != nil && s.cache[key] == entry {
+		delete(s.cache, key)
+	}
+	s.mu.Unlock()
+	close(entry.done)
+}
+
+// expired reports whether a finished entry has outlived the TTL. Running
+// entries never expire. s.mu must be held.
+func (s *batchService) expired(entry *batchEntry) bool {
+	select {
+	case <-entry.done:
+		return s.now().After(entry.expires)
+	default:
+		return false
+	}
+}
+
+// store adds entry, first dropping expired entries when the cache is full.
+// When everything is still fresh the entry runs uncached rather than evicting
+// work others may be waiting on. s.mu must be held.
+func (s *batchService) store(key string, entry *batchEntry) {
+	if len(s.cache) >= s.cfg.CacheSize {
+		for k, e := range s.cache {
+			if s.expired(e) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= s.cfg.CacheSize {
+			return
+		}
+	}
+	s.cache[key] = entry
+}
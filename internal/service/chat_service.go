@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/safebites/backend-go/internal/guard"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+type chatRunner interface {
+	Ask(ctx context.Context, userID string, scan *model.Scan, prefs *model.UserPreferences, question string, onDelta func(string) error) (string, error)
+	History(ctx context.Context, userID, scanID string) ([]model.ChatMessage, error)
+}
+
+type chatService struct {
+	scans repository.ScanRepository
+	chat  chatRunner
+	now   func() time.Time
+}
+
+func NewChatService(scans repository.ScanRepository, chat chatRunner) ChatService {
+	return &chatService{scans: scans, chat: chat, now: time.Now}
+}
+
+func (s *chatService) Ask(ctx context.Context, userID, scanID, question string, prefs *model.UserPreferences, onDelta func(string) error) (*model.ChatMessage, error) {
+	if s.chat == nil || s.scans == nil {
+		return nil, fmt.Errorf("chat dependencies are required")
+	}
+	if strings.TrimSpace(userID) == "" || strings.TrimSpace(scanID) == "" {
+		return nil, fmt.Errorf("user id and scan id are required")
+	}
+
+	guarded, err := guard.Question(ctx, question)
+	if err != nil {
+		return nil, err
+	}
+
+	scan, err := s.scans.GetByID(ctx, userID, scanID)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := s.chat.Ask(ctx, userID, scan, prefs, guarded.Value, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ChatMessage{Role: model.ChatRoleAssistant, Text: answer, Timestamp: s.now().UTC()}, nil
+}
+
+func (s *chatService) History(ctx context.Context, userID, scanID string) ([]model.ChatMessage, error) {
+	if s.chat == nil || s.scans == nil {
+		return nil, fmt.Errorf("chat dependencies are required")
+	}
+	if strings.TrimSpace(userID) == "" || strings.TrimSpace(scanID) == "" {
+		return nil, fmt.Errorf("user id and scan id are required")
+	}
+
+	// Checking the scan first keeps another user's conversation hidden
+	// behind the same not-found answer as a scan that does not exist.
+	if _, err := s.scans.GetByID(ctx, userID, scanID); err != nil {
+		return nil, err
+	}
+	return s.chat.History(ctx, userID, scanID)
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/safebites/backend-go/internal/guard"
+	"github.com/safebites/backend-go/internal/model"
+)
+
+type compareService struct {
+	vision       labelReader
+	orchestrator analyzeWorkflow
+}
+
+func NewCompareService(vision labelReader, orchestrator analyzeWorkflow) CompareService {
+	return &compareService{
+		vision:       vision,
+		orchestrator: orchestrator,
+	}
+}
+
+type compareAnalysis struct {
+	productName string
+	search      *model.WebSearchResult
+	score       *model.ScorerResult
+	err         error
+}
+
+func (s *compareService) Compare(ctx context.Context, inputs []model.CompareInput, prefs *model.UserPreferences) (*model.ComparisonResult, error) {
+	if s.orchestrator == nil {
+		return nil, fmt.Errorf("orchestrator dependency is required")
+	}
+	if len(inputs) < model.MinCompareProducts || len(inputs) > model.MaxCompareProducts {
+		return nil, fmt.Errorf("between %d and %d products are required", model.MinCompareProducts, model.MaxCompareProducts)
+	}
+	for i, in := range inputs {
+		if strings.TrimSpace(in.ProductName) == "" && len(in.ImageBytes) == 0 {
+			return nil, fmt.Errorf("product %d requires a name or an image", i+1)
+		}
+		if strings.TrimSpace(in.ProductName) == "" && s.vision == nil {
+			return nil, fmt.Errorf("vision dependency is required")
+		}
+	}
+
+	analyses := make([]compareAnalysis, len(inputs))
+	var wg sync.WaitGroup
+	for i := range inputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			analyses[i] = s.analyzeOne(ctx, inputs[i], prefs)
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, a := range analyses {
+		if a.err != nil {
+			failed++
+		}
+	}
+	if failed == len(analyses) {
+		return nil, fmt.Errorf("compare products: %w", analyses[0].err)
+	}
+
+	return buildComparison(analyses, prefs), nil
+}
+
+func (s *compareService) analyzeOne(ctx context.Context, in model.CompareInput, prefs *model.UserPreferences) compareAnalysis {
+	productName := strings.TrimSpace(in.ProductName)
+	if productName == "" {
+		mimeType := in.MimeType
+		if strings.TrimSpace(mimeType) == "" {
+			mimeType = "image/jpeg"
+		}
+		reading, err := s.vision.ReadLabel(ctx, in.ImageBytes, mimeType)
+		if err != nil {
+			return compareAnalysis{err: fmt.Errorf("extract product name: %w", err)}
+		}
+		if reading != nil {
+			productName = strings.TrimSpace(reading.ProductName)
+		}
+		if productName == "" {
+			return compareAnalysis{err: fmt.Errorf("product name extraction returned empty value")}
+		}
+	} else {
+		guarded, err := guard.ProductName(ctx, guard.SourceParam, productName)
+		if err != nil {
+			return compareAnalysis{productName: productName, err: err}
+		}
+		productName = guarded.Value
+	}
+
+	search, score, err := s.orchestrator.AnalyzeOnly(ctx, productName, prefs)
+	if err != nil {
+		log.Printf("compare product failed product=%q err=%v", productName, err)
+		return compareAnalysis{productName: productName, err: fmt.Errorf("run analyze workflow: %w", err)}
+	}
+	if score == nil {
+		return compareAnalysis{productName: productName, err: fmt.Errorf("analyze workflow returned empty result")}
+	}
+	return compareAnalysis{productName: productName, search: search, score: score}
+}
+
+func buildComparison(analyses []compareAnalysis, prefs *model.UserPreferences) *model.ComparisonResult {
+	// Count in how many products each ingredient appears so we can report the
+	// ones that only a single product contains.
+	seenIn := map[string]int{}
+	for _, a := range analyses {
+		for name := range ingredientSet(a) {
+			seenIn[name]++
+		}
+	}
+
+	products := make([]model.ProductComparison, len(analyses))
+	for i, a := range analyses {
+		pc := model.ProductComparison{
+			ProductName:          a.productName,
+			UniqueIngredients:    []string{},
+			PreferenceViolations: []model.PreferenceViolation{},
+		}
+		if a.err != nil {
+			pc.Error = a.err.Error()
+			products[i] = pc
+			continue
+		}
+		pc.IngredientBreakdown = a.score
+		if a.search != nil {
+			pc.Sources = a.search.Sources
+		}
+		for _, name := range ingredientNames(a) {
+			if seenIn[normalizeIngredient(name)] == 1 {
+				pc.UniqueIngredients = append(pc.UniqueIngredients, name)
+			}
+		}
+		pc.PreferenceViolations = findPreferenceViolations(ingredientNames(a), a.score, prefs)
+		products[i] = pc
+	}
+
+	order := make([]int, len(products))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rankLess(products[order[i]], products[order[j]])
+	})
+
+	verdict := model.ComparisonVerdict{Ranking: make([]string, 0, len(products))}
+	for rank, idx := range order {
+		products[idx].Rank = rank + 1
+		verdict.Ranking = append(verdict.Ranking, products[idx].ProductName)
+	}
+
+	best := products[order[0]]
+	verdict.BestProduct = best.ProductName
+	verdict.Summary = verdictSummary(best, prefs)
+
+	return &model.ComparisonResult{Products: products, Verdict: verdict}
+}
+
+// rankLess orders products for the requesting user: failed analyses last, then
+// fewest allergy matches, fewest violations overall, and highest score.
+func rankLess(a, b model.ProductComparison) bool {
+	if (a.Error == "") != (b.Error == "") {
+		return a.Error == ""
+	}
+	if a.Error != "" {
+		return false
+	}
+	if av, bv := countViolations(a, "allergy"), countViolations(b, "allergy"); av != bv {
+		return av < bv
+	}
+	if len(a.PreferenceViolations) != len(b.PreferenceViolations) {
+		return len(a.PreferenceViolations) < len(b.PreferenceViolations)
+	}
+	return a.IngredientBreakdown.OverallScore > b.IngredientBreakdown.OverallScore
+}
+
+func verdictSummary(best model.ProductComparison, prefs *model.UserPreferences) string {
+	if best.Error != "" {
+		return "None of the products could be analyzed."
+	}
+	if len(best.PreferenceViolations) > 0 {
+		return fmt.Sprintf("%s ranks best (%.1f/10) but still conflicts with %d of your preferences.", best.ProductName, best.IngredientBreakdown.OverallScore, len(best.PreferenceViolations))
+	}
+	if prefs == nil {
+		return fmt.Sprintf("%s has the highest safety score (%.1f/10).", best.ProductName, best.IngredientBreakdown.OverallScore)
+	}
+	return fmt.Sprintf("%s is the best fit for your preferences (%.1f/10).", best.ProductName, best.IngredientBreakdown.OverallScore)
+}
+
+func countViolations(pc model.ProductComparison, kind string) int {
+	n := 0
+	for _, v := range pc.PreferenceViolations {
+		if v.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// findPreferenceViolations matches allergies and avoided ingredients against
+// ingredient names, and diet goals against the scorer's LOW-rated reasoning.
+func findPreferenceViolations(ingredients []string, score *model.ScorerResult, prefs *model.UserPreferences) []model.PreferenceViolation {
+	violations := []model.PreferenceViolation{}
+	if prefs == nil {
+		return violations
+	}
+
+	for _, ingredient := range ingredients {
+		normalized := normalizeIngredient(ingredient)
+		for _, allergy := range prefs.Allergies {
+			if matchesPreference(normalized, allergy.Name) {
+				violations = append(violations, model.PreferenceViolation{Ingredient: ingredient, Preference: allergy.Name, Kind: "allergy"})
+			}
+		}
+		for _, avoid := range prefs.AvoidIngredients {
+			if matchesPreference(normalized, avoid) {
+				violations = append(violations, model.PreferenceViolation{Ingredient: ingredient, Preference: avoid, Kind: "avoid_ingredient"})
+			}
+		}
+	}
+
+	if score != nil {
+		for _, is := range score.IngredientScores {
+			if !strings.EqualFold(string(is.SafetyScore), "LOW") {
+				continue
+			}
+			reasoning := strings.ToLower(is.Reasoning)
+			for _, goal := range prefs.DietGoals {
+				if g := normalizeIngredient(goal); g != "" && strings.Contains(reasoning, g) {
+					violations = append(violations, model.PreferenceViolation{Ingredient: is.IngredientName, Preference: goal, Kind: "diet_goal"})
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+func matchesPreference(normalizedIngredient, preference string) bool {
+	p := normalizeIngredient(preference)
+	return p != "" && strings.Contains(normalizedIngredient, p)
+}
+
+func ingredientNames(a compareAnalysis) []string {
+	if a.search != nil && len(a.search.ListOfIngredients) > 0 {
+		names := make([]string, 0, len(a.search.ListOfIngredients))
+		for _, ing := range a.search.ListOfIngredients {
+			names = append(names, ing.Name)
+		}
+		return names
+	}
+	if a.score == nil {
+		return nil
+	}
+	names := make([]string, 0, len(a.score.IngredientScores))
+	for _, is := range a.score.IngredientScores {
+		names = append(names, is.IngredientName)
+	}
+	return names
+}
+
+func ingredientSet(a compareAnalysis) map[string]struct{} {
+	set := map[string]struct{}{}
+	if a.err != nil {
+		return set
+	}
+	for _, name := range ingredientNames(a) {
+		set[normalizeIngredient(name)] = struct{}{}
+	}
+	return set
+}
+
+func normalizeIngredient(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func compareWorkflow(results map[string]*model.ScorerResult, ingredients map[string][]string) *mockAnalyzeWorkflow {
+	return &mockAnalyzeWorkflow{
+		analyzeOnly: func(_ context.Context, productName string, _ *model.UserPreferences) (*model.WebSearchResult, *model.ScorerResult, error) {
+			score, ok := results[productName]
+			if !ok {
+				return nil, nil, errors.New("product not found")
+			}
+			search := &model.WebSearchResult{}
+			for _, name := range ingredients[productName] {
+				search.ListOfIngredients = append(search.ListOfIngredients, model.Ingredient{Name: name})
+			}
+			return search, score, nil
+		},
+	}
+}
+
+func TestCompareServiceCompareRanksByPreferencesThenScore(t *testing.T) {
+	svc := NewCompareService(nil, compareWorkflow(
+		map[string]*model.ScorerResult{
+			"Peanut Bar": {OverallScore: 9.0},
+			"Oat Bar":    {OverallScore: 7.5},
+		},
+		map[string][]string{
+			"Peanut Bar": {"Peanuts", "Sugar"},
+			"Oat Bar":    {"Oats", "Sugar"},
+		},
+	))
+
+	res, err := svc.Compare(context.Background(), []model.CompareInput{
+		{ProductName: "Peanut Bar"},
+		{ProductName: "Oat Bar"},
+	}, &model.UserPreferences{Allergies: model.Allergies("peanut")})
+	require.NoError(t, err)
+
+	require.Equal(t, "Oat Bar", res.Verdict.BestProduct)
+	require.Equal(t, []string{"Oat Bar", "Peanut Bar"}, res.Verdict.Ranking)
+	require.Equal(t, 2, res.Products[0].Rank)
+	require.Equal(t, []string{"Peanuts"}, res.Products[0].UniqueIngredients)
+	require.Equal(t, []string{"Oats"}, res.Products[1].UniqueIngredients)
+	require.Len(t, res.Products[0].PreferenceViolations, 1)
+	require.Equal(t, "allergy", res.Products[0].PreferenceViolations[0].Kind)
+	require.Empty(t, res.Products[1].PreferenceViolations)
+}
+
+func TestCompareServiceCompareUsesVisionForImages(t *testing.T) {
+	svc := NewCompareService(
+		&mockVisionExtractor{
+			extractProductName: func(_ context.Context, _ []byte, mimeType string) (string, error) {
+				require.Equal(t, "image/jpeg", mimeType)
+				return "Oat Bar", nil
+			},
+		},
+		compareWorkflow(
+			map[string]*model.ScorerResult{"Oat Bar": {OverallScore: 7.5}, "Candy Bar": {OverallScore: 3.0}},
+			nil,
+		),
+	)
+
+	res, err := svc.Compare(context.Background(), []model.CompareInput{
+		{ImageBytes: []byte("img")},
+		{ProductName: "Candy Bar"},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Oat Bar", res.Verdict.BestProduct)
+}
+
+func TestCompareServiceCompareKeepsPartialFailures(t *testing.T) {
+	svc := NewCompareService(nil, compareWorkflow(map[string]*model.ScorerResult{"Oat Bar": {OverallScore: 5.0}}, nil))
+
+	res, err := svc.Compare(context.Background(), []model.CompareInput{
+		{ProductName: "Mystery Bar"},
+		{ProductName: "Oat Bar"},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Oat Bar", res.Verdict.BestProduct)
+	require.NotEmpty(t, res.Products[0].Error)
+	require.Equal(t, 2, res.Products[0].Rank)
+}
+
+func TestCompareServiceCompareValidation(t *testing.T) {
+	svc := NewCompareService(nil, compareWorkflow(nil, nil))
+
+	_, err := svc.Compare(context.Background(), []model.CompareInput{{ProductName: "Only One"}}, nil)
+	require.ErrorContains(t, err, "between 2 and 5 products are required")
+
+	_, err = svc.Compare(context.Background(), []model.CompareInput{{ProductName: "A"}, {}}, nil)
+	require.ErrorContains(t, err, "product 2 requires a name or an image")
+
+	_, err = svc.Compare(context.Background(), []model.CompareInput{{ProductName: "A"}, {ProductName: "B"}}, nil)
+	require.ErrorContains(t, err, "compare products")
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"math"
+	"strings"
+
+	"github.com/safebites/backend-go/internal/model"
+)
+
+// DefaultConfidenceThreshold is the overall confidence below which an
+// analysis is flagged as needing verification.
+const DefaultConfidenceThreshold = 0.6
+
+// Stage weights for the overall confidence. Sources and scoring dominate: a
+// perfectly read label is still a guess if nothing backs its ingredient list.
+const (
+	ocrConfidenceWeight     = 0.2
+	sourceConfidenceWeight  = 0.4
+	scorerConfidenceWeight  = 0.4
+	ungroundedConfidence    = 0.2
+	fullySourcedSourceCount = 3
+)
+
+// assessConfidence combines the OCR confidence with confidence values derived
+// from the search and scoring results.
+func assessConfidence(ocr float64, search *model.WebSearchResult, score *model.ScorerResult, threshold float64) *model.Confidence {
+	c := &model.Confidence{
+		OCR:     round2(clamp01(ocr)),
+		Sources: round2(sourceConfidence(search)),
+		Scorer:  round2(scorerConfidence(search, score)),
+	}
+	return weighConfidence(c, threshold)
+}
+
+// assessListConfidence rates an analysis of an ingredient list the client
+// supplied. Nothing was read or searched, so only the scoring is in doubt,
+// and it counts against how many of the submitted ingredients were scored.
+func assessListConfidence(submitted int, score *model.ScorerResult, threshold float64) *model.Confidence {
+	scorer := scorerConfidence(nil, score)
+	if submitted > 0 && score != nil {
+		scorer *= math.Min(1, float64(len(score.IngredientScores))/float64(submitted))
+	}
+	return weighConfidence(&model.Confidence{OCR: 1, Sources: 1, Scorer: round2(scorer)}, threshold)
+}
+
+// weighConfidence fills in the overall value of c and notes the stages that
+// fall below threshold.
+func weighConfidence(c *model.Confidence, threshold float64) *model.Confidence {
+	c.Overall = round2(ocrConfidenceWeight*c.OCR + sourceConfidenceWeight*c.Sources + scorerConfidenceWeight*c.Scorer)
+	c.NeedsVerification = c.Overall < threshold
+
+	if c.OCR < threshold {
+		c.Notes = append(c.Notes, "product name could not be read reliably from the image")
+	}
+	if c.Sources < threshold {
+		c.Notes = append(c.Notes, "few or disagreeing web sources back the ingredient list")
+	}
+	if c.Scorer < threshold {
+		c.Notes = append(c.Notes, "ingredient scores are incomplete or inconsistent with the overall score")
+	}
+	return c
+}
+
+// sourceConfidence grows with the number of distinct grounding sources and is
+// averaged with their agreement when the model reported it.
+func sourceConfidence(search *model.WebSearchResult) float64 {
+	if search == nil || len(search.ListOfIngredients) == 0 {
+		return 0
+	}
+	if len(search.Sources) == 0 {
+		return ungroundedConfidence
+	}
+	count := math.Min(float64(len(search.Sources)), fullySourcedSourceCount) / fullySourcedSourceCount
+	coverage := ungroundedConfidence + (1-ungroundedConfidence)*count
+	if search.SourceAgreement <= 0 {
+		return coverage
+	}
+	return (coverage + clamp01(search.SourceAgreement)) / 2
+}
+
+// scorerConfidence multiplies how many searched ingredients were scored by how
+// well the reported overall score matches the per-ingredient levels.
+func scorerConfidence(search *model.WebSearchResult, score *model.ScorerResult) float64 {
+	if score == nil || len(score.IngredientScores) == 0 {
+		return 0
+	}
+
+	coverage := 1.0
+	if search != nil && len(search.ListOfIngredients) > 0 {
+		scored := map[string]bool{}
+		for _, is := range score.IngredientScores {
+			scored[normalizeIngredient(is.IngredientName)] = true
+		}
+		matched := 0
+		for _, ing := range search.ListOfIngredients {
+			if scored[normalizeIngredient(ing.Name)] {
+				matched++
+			}
+		}
+		coverage = float64(matched) / float64(len(search.ListOfIngredients))
+	}
+
+	var sum float64
+	valid := 0
+	for _, is := range score.IngredientScores {
+		if v, ok := safetyLevelValue(string(is.SafetyScore)); ok {
+			sum += v
+			valid++
+		}
+	}
+	if valid == 0 {
+		return 0
+	}
+	levelMean := sum / float64(valid)
+	consistency := 1 - math.Abs(score.OverallScore-levelMean)/10
+	validity := float64(valid) / float64(len(score.IngredientScores))
+
+	return clamp01(coverage * consistency * validity)
+}
+
+func safetyLevelValue(level string) (float64, bool) {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "HIGH":
+		return 10, true
+	case "MEDIUM":
+		return 5, true
+	case "LOW":
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
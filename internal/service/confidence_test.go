@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssessConfidenceWellSourcedAnalysis(t *testing.T) {
+	search := &model.WebSearchResult{
+		ListOfIngredients: []model.Ingredient{{Name: "Oats"}, {Name: "Honey"}},
+		Sources: []model.Source{
+			{URI: "https://brand.example"},
+			{URI: "https://shop.example"},
+			{URI: "https://db.example"},
+		},
+		SourceAgreement: 0.9,
+	}
+	score := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{
+			{IngredientName: "oats", SafetyScore: "HIGH"},
+			{IngredientName: "Honey", SafetyScore: "MEDIUM"},
+		},
+		OverallScore: 7.5,
+	}
+
+	c := assessConfidence(0.95, search, score, DefaultConfidenceThreshold)
+	require.Equal(t, 0.95, c.OCR)
+	require.Equal(t, 0.95, c.Sources)
+	require.Equal(t, 1.0, c.Scorer)
+	require.Equal(t, 0.97, c.Overall)
+	require.False(t, c.NeedsVerification)
+	require.Empty(t, c.Notes)
+}
+
+func TestAssessConfidenceUngroundedGuessNeedsVerification(t *testing.T) {
+	search := &model.WebSearchResult{
+		ListOfIngredients: []model.Ingredient{{Name: "Flour"}, {Name: "Sugar"}, {Name: "Palm Oil"}, {Name: "Salt"}},
+	}
+	score := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{
+			{IngredientName: "Flour", SafetyScore: "MEDIUM"},
+			{IngredientName: "Sugar", SafetyScore: "LOW"},
+		},
+		OverallScore: 8,
+	}
+
+	c := assessConfidence(0.9, search, score, DefaultConfidenceThreshold)
+	require.Equal(t, 0.2, c.Sources)
+	require.Less(t, c.Scorer, 0.5)
+	require.True(t, c.NeedsVerification)
+	require.Len(t, c.Notes, 2)
+}
+
+func TestAssessConfidenceRespectsThreshold(t *testing.T) {
+	search := &model.WebSearchResult{
+		ListOfIngredients: []model.Ingredient{{Name: "Water"}},
+		Sources:           []model.Source{{URI: "https://brand.example"}},
+	}
+	score := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{{IngredientName: "Water", SafetyScore: "HIGH"}},
+		OverallScore:     10,
+	}
+
+	require.False(t, assessConfidence(1, search, score, 0.5).NeedsVerification)
+	require.True(t, assessConfidence(1, search, score, 0.9).NeedsVerification)
+}
+
+func TestAssessConfidenceIgnoresUnknownSafetyLevels(t *testing.T) {
+	score := &model.ScorerResult{
+		IngredientScores: []model.IngredientScore{{IngredientName: "Water", SafetyScore: "7"}},
+		OverallScore:     7,
+	}
+
+	c := assessConfidence(1, nil, score, DefaultConfidenceThreshold)
+	require.Zero(t, c.Sources)
+	require.Zero(t, c.Scorer)
+}
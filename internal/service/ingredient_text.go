@@ -0,0 +1,211 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/safebites/backend-go/internal/model"
+)
+
+// MaxIngredientTextLength caps the pasted ingredient text analyzed in one
+// request, in bytes.
+const MaxIngredientTextLength = 4000
+
+var (
+	ingredientPercent = regexp.MustCompile(`(?:<\s*)?\d+(?:[.,]\d+)?\s*%`)
+	ingredientMarks   = strings.NewReplacer("*", " ", "†", " ", "‡", " ", "¹", " ", "²", " ")
+	// ingredientAdvisory matches precautionary statements such as "May contain
+	// traces of nuts.", which list what is not an ingredient.
+	ingredientAdvisory = regexp.MustCompile(`(?i)\bmay (?:also )?contain\b[^.]*(?:\.|$)`)
+	// traceLeadIn is the start of an advisory statement, up to the first
+	// allergen it names.
+	traceLeadIn    = regexp.MustCompile(`(?i)^\s*may (?:also )?contain\s*:?\s*(?:(?:small )?(?:traces|amounts) of\s*:?)?`)
+	traceSeparator = regexp.MustCompile(`(?i)\s*(?:[,;/]|\band\b|\bor\b)\s*`)
+)
+
+// parseIngredientText splits an ingredient statement as printed on a label,
+// e.g. "Ingredients: wheat flour, chocolate 12% (sugar, cocoa butter), salt.",
+// into ingredients. Compound ingredients contribute their own name and their
+// bracketed parts. Lead-ins such as "Ingredients:" or "contains 2% or less
+// of:" and footnotes are dropped. Names are returned in label order, without
+// duplicates, followed by the allergens of "may contain" statements marked
+// as MayContain.
+func parseIngredientText(text string) []model.Ingredient {
+	var names, traces []string
+	text = ingredientAdvisory.ReplaceAllStringFunc(text, func(statement string) string {
+		traces = append(traces, splitTraces(statement)...)
+		return ". "
+	})
+	collectIngredients(text, &names)
+
+	seen := map[string]bool{}
+	ingredients := make([]model.Ingredient, 0, len(names)+len(traces))
+	for i, name := range append(names, traces...) {
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ingredients = append(ingredients, model.Ingredient{Name: name, MayContain: i >= len(names)})
+	}
+	return ingredients
+}
+
+// splitTraces returns the allergens named in a "may contain" statement.
+func splitTraces(statement string) []string {
+	var traces []string
+	for _, part := range traceSeparator.Split(traceLeadIn.ReplaceAllString(statement, ""), -1) {
+		if cleaned := cleanIngredientName(part); cleaned != "" {
+			traces = append(traces, cleaned)
+		}
+	}
+	return traces
+}
+
+func collectIngredients(text string, names *[]string) {
+	for _, segment := range splitIngredientText(text) {
+		// "*Organic." explains a mark rather than naming an ingredient.
+		if trimmed := strings.TrimSpace(segment); strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "†") {
+			continue
+		}
+		segment = cutLeadIn(segment)
+
+		var name strings.Builder
+		var parts []string
+		rest := segment
+		for {
+			open := strings.IndexAny(rest, "([{")
+			if open < 0 {
+				name.WriteString(rest)
+				break
+			}
+			name.WriteString(rest[:open] + " ")
+			inner := rest[open+1:]
+			end := matchingBracket(inner)
+			if end < 0 {
+				parts = append(parts, inner)
+				break
+			}
+			parts = append(parts, inner[:end])
+			rest = inner[end+1:]
+		}
+		if cleaned := cleanIngredientName(name.String()); cleaned != "" {
+			*names = append(*names, cleaned)
+		}
+		for _, part := range parts {
+			collectIngredients(part, names)
+		}
+	}
+}
+
+// splitIngredientText splits text at commas, semicolons, and sentence ends
+// outside brackets. Line breaks only separate ingredients when the text has
+// no other separators, since labels wrap long names across lines.
+func splitIngredientText(text string) []string {
+	separated := strings.ContainsAny(text, ",;")
+	var (
+		segments []string
+		current  strings.Builder
+		depth    int
+	)
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case (r == ')' || r == ']' || r == '}') && depth > 0:
+			depth--
+		case depth == 0 && isIngredientSeparator(runes, i, separated):
+			segments = append(segments, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(segments, current.String())
+}
+
+func isIngredientSeparator(runes []rune, i int, separated bool) bool {
+	switch runes[i] {
+	case ',', ';':
+		// A comma between digits is a decimal comma, as in "1,5%".
+		return !(runes[i] == ',' && i > 0 && i+1 < len(runes) && isDigit(runes[i-1]) && isDigit(runes[i+1]))
+	case '.':
+		return i+1 == len(runes) || runes[i+1] == ' ' || runes[i+1] == '\n'
+	case '\n', '\r':
+		return !separated
+	}
+	return false
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// cutLeadIn drops "label:" up to the last colon outside brackets.
+func cutLeadIn(segment string) string {
+	depth := 0
+	colon := -1
+	for i, r := range segment {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+			}
+		case ':':
+			if depth == 0 {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return segment
+	}
+	return segment[colon+1:]
+}
+
+// matchingBracket returns the index in s of the bracket closing one that was
+// opened just before s, or -1 when it is never closed.
+func matchingBracket(s string) int {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+func cleanIngredientName(name string) string {
+	name = ingredientMarks.Replace(name)
+	name = ingredientPercent.ReplaceAllString(name, " ")
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.Trim(name, " .:-–&")
+	lower := strings.ToLower(name)
+	for _, prefix := range []string{"and ", "or "} {
+		if strings.HasPrefix(lower, prefix) {
+			name = strings.TrimSpace(name[len(prefix):])
+			break
+		}
+	}
+	return name
+}
+
+// countListed counts the ingredients that are not "may contain" traces.
+func countListed(ingredients []model.Ingredient) int {
+	n := 0
+	for _, ingredient := range ingredients {
+		if !ingredient.MayContain {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func parsedNames(ingredients []model.Ingredient) []string {
+	names := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		if !ingredient.MayContain {
+			names = append(names, ingredient.Name)
+		}
+	}
+	return names
+}
+
+func parsedTraces(ingredients []model.Ingredient) []string {
+	var traces []string
+	for _, ingredient := range ingredients {
+		if ingredient.MayContain {
+			traces = append(traces, ingredient.Name)
+		}
+	}
+	return traces
+}
+
+func TestParseIngredientText(t *testing.T) {
+	cases := map[string]struct {
+		text   string
+		want   []string
+		traces []string
+	}{
+		"simple list": {
+			text: "Ingredients: Wheat Flour, Sugar, Salt.",
+			want: []string{"Wheat Flour", "Sugar", "Salt"},
+		},
+		"compound ingredients": {
+			text: "Milk chocolate 25% (sugar, cocoa butter, emulsifier [soy lecithin]), hazelnuts (12%)",
+			want: []string{"Milk chocolate", "sugar", "cocoa butter", "emulsifier", "soy lecithin", "hazelnuts"},
+		},
+		"lead-ins and advisory statements": {
+			text:   "INGREDIENTS: oats; contains 2% or less of: salt, natural flavor*. *Organic. May contain: peanuts, tree nuts.",
+			want:   []string{"oats", "salt", "natural flavor"},
+			traces: []string{"peanuts", "tree nuts"},
+		},
+		"advisory without colon": {
+			text:   "Sugar, cocoa mass. May also contain traces of milk and nuts",
+			want:   []string{"Sugar", "cocoa mass"},
+			traces: []string{"milk", "nuts"},
+		},
+		"decimal comma percentages": {
+			text: "Tomatoes 80,5%, olive oil 1,5 %, and basil",
+			want: []string{"Tomatoes", "olive oil", "basil"},
+		},
+		"line per ingredient": {
+			text: "Water\nSugar\nCitric Acid\nE330",
+			want: []string{"Water", "Sugar", "Citric Acid", "E330"},
+		},
+		"wrapped line": {
+			text: "enriched wheat\nflour, niacin",
+			want: []string{"enriched wheat flour", "niacin"},
+		},
+		"duplicates and unclosed bracket": {
+			text: "Sugar, sugar, Vitamin C (ascorbic acid",
+			want: []string{"Sugar", "Vitamin C", "ascorbic acid"},
+		},
+		"empty": {
+			text: " Ingredients: . ",
+			want: []string{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ingredients := parseIngredientText(tc.text)
+			require.Equal(t, tc.want, parsedNames(ingredients))
+			require.Equal(t, tc.traces, parsedTraces(ingredients))
+		})
+	}
+}
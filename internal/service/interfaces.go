@@ -7,22 +7,131 @@ import (
 )
 
 type AnalyzeService interface {
-	Analyze(ctx context.Context, imageBytes []byte, mimeType string, prefs *model.UserPreferences) (string, *model.ScorerResult, error)
+	Analyze(ctx context.Context, imageBytes []byte, mimeType string, prefs *model.UserPreferences) (*model.AnalysisResult, error)
+	AnalyzeProduct(ctx context.Context, productName string, prefs *model.UserPreferences) (*model.AnalysisResult, error)
+	AnalyzeIngredients(ctx context.Context, productName, ingredientText string, prefs *model.UserPreferences) (*model.AnalysisResult, error)
+	ScoreIngredients(ctx context.Context, names []string, prefs *model.UserPreferences) (*model.ScorerResult, error)
+	RescoreIngredients(ctx context.Context, ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error)
+}
+
+// BatchService analyzes many products by name at once. A failed item is
+// reported in its place in the result rather than failing the batch.
+type BatchService interface {
+	AnalyzeBatch(ctx context.Context, productNames []string, prefs *model.UserPreferences) (*model.BatchResult, error)
+}
+
+type CompareService interface {
+	Compare(ctx context.Context, inputs []model.CompareInput, prefs *model.UserPreferences) (*model.ComparisonResult, error)
 }
 
 type RecommendService interface {
-	Recommend(ctx context.Context, productName string, score float64) (*model.RecommenderResult, error)
+	Recommend(ctx context.Context, productName string, score float64, prefs *model.UserPreferences) (*model.RecommenderResult, error)
+}
+
+// LocalizeService translates the free-text parts of results into a response
+// language. Ingredient names stay canonical English.
+type LocalizeService interface {
+	LocalizeAnalysis(ctx context.Context, result *model.AnalysisResult, lang string) (*model.AnalysisResult, error)
+	LocalizeRecommendations(ctx context.Context, result *model.RecommenderResult, lang string) (*model.RecommenderResult, error)
+	LocalizeComparison(ctx context.Context, result *model.ComparisonResult, lang string) (*model.ComparisonResult, error)
+}
+
+// ChatService answers follow-up questions about a saved scan and keeps the
+// conversation per scan.
+type ChatService interface {
+	// Ask answers question, passing streamed text to onDelta when it is set.
+	// It returns repository.ErrNotFound when the user has no such scan.
+	Ask(ctx context.Context, userID, scanID, question string, prefs *model.UserPreferences, onDelta func(string) error) (*model.ChatMessage, error)
+	History(ctx context.Context, userID, scanID string) ([]model.ChatMessage, error)
 }
 
 type UserService interface {
 	GetByID(ctx context.Context, userID string) (*model.User, error)
 	Upsert(ctx context.Context, user *model.User) (*model.User, error)
+	// UpdatePreferences replaces the user's own entries, leaving applied
+	// templates in place.
 	UpdatePreferences(ctx context.Context, userID string, preferences model.UserPreferences) (*model.User, error)
-	ApplyTemplate(ctx context.Context, userID string, templateKey string) (*model.User, model.DietaryTemplate, error)
+	// ApplyTemplate layers the template templateRef resolves to (see
+	// TemplateService.Get) on top of the user's preferences. It returns
+	// ErrTemplateNotFound for an unknown template, ErrTooManyLayers when
+	// model.MaxTemplateLayers are applied already, and repository.ErrNotFound
+	// for an unknown user.
+	ApplyTemplate(ctx context.Context, userID string, templateRef string) (*model.User, model.DietaryTemplate, error)
+	// RemoveTemplate removes one applied template, keeping the other layers.
+	// It returns ErrTemplateNotApplied when the template is not applied.
+	RemoveTemplate(ctx context.Context, userID, templateKey string) (*model.User, error)
+}
+
+// TemplateService manages dietary templates: the built-ins, the templates
+// users create or fork, and the share codes they publish them under. Methods
+// return ErrTemplateNotFound for templates the user cannot see or change.
+type TemplateService interface {
+	// SeedBuiltIns writes the model.DietaryTemplates that are missing from
+	// the database or differ from the stored versions.
+	SeedBuiltIns(ctx context.Context) error
+	// List returns the built-in templates followed by the user's own. userID
+	// may be empty.
+	List(ctx context.Context, userID string) ([]model.DietaryTemplate, error)
+	// Get resolves ref as the key of a built-in template or of one the user
+	// owns, or as the share code of a published template.
+	Get(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error)
+	Create(ctx context.Context, userID string, tpl model.DietaryTemplate) (*model.DietaryTemplate, error)
+	Update(ctx context.Context, userID string, tpl model.DietaryTemplate) (*model.DietaryTemplate, error)
+	Delete(ctx context.Context, userID, key string) error
+	// Publish gives one of the user's templates a share code, keeping the
+	// one it already has.
+	Publish(ctx context.Context, userID, key string) (*model.DietaryTemplate, error)
+	// Unpublish revokes the share code of one of the user's templates.
+	Unpublish(ctx context.Context, userID, key string) (*model.DietaryTemplate, error)
+	// Fork copies the template ref resolves to into the user's templates.
+	Fork(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error)
+}
+
+// ProfileService manages the household profiles of an account and judges
+// products for them. Update and Delete return repository.ErrNotFound when the
+// user has no such profile.
+type ProfileService interface {
+	List(ctx context.Context, userID string) ([]model.Profile, error)
+	Create(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error)
+	Update(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error)
+	Delete(ctx context.Context, userID, profileID string) error
+	// Select returns the user's profiles with the given IDs, or all of them
+	// when none are given. It returns repository.ErrNotFound for an ID the
+	// user does not own.
+	Select(ctx context.Context, userID string, profileIDs []string) ([]model.Profile, error)
+	// Evaluate scores the ingredients of an analyzed product against each
+	// profile's own preferences. A profile that cannot be scored gets an
+	// error in its verdict instead of failing the others.
+	Evaluate(ctx context.Context, profiles []model.Profile, homeRegion string, ingredients []model.Ingredient, breakdown *model.ScorerResult) []model.ProfileVerdict
+	// Judge gives verdicts from breakdown as it is, without scoring again.
+	Judge(profiles []model.Profile, homeRegion string, breakdown *model.ScorerResult) []model.ProfileVerdict
 }
 
 type ScanService interface {
-	ListByUser(ctx context.Context, userID string, limit int) ([]model.Scan, error)
+	// ListByUser returns one page of the user's scans; see
+	// repository.ScanRepository.
+	ListByUser(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error)
 	Create(ctx context.Context, scan *model.Scan) (*model.Scan, error)
 	GetStats(ctx context.Context, userID string) (*model.UserStats, error)
 }
+
+// EventPublisher notifies a user's webhook subscribers of an event. Delivery
+// happens in the background, so publishing never fails the caller.
+type EventPublisher interface {
+	Publish(ctx context.Context, userID, eventType string, data interface{})
+}
+
+// WebhookService manages the webhook subscriptions of a user and their
+// delivery log.
+type WebhookService interface {
+	Subscribe(ctx context.Context, userID, appName, url string, events []string) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, userID string) ([]model.WebhookSubscription, error)
+	Unsubscribe(ctx context.Context, userID, subscriptionID string) error
+	// ListDeliveries returns repository.ErrNotFound when the user has no such
+	// subscription.
+	ListDeliveries(ctx context.Context, userID, subscriptionID string, limit int) ([]model.WebhookDelivery, error)
+	// Replay sends a logged delivery again as a new delivery carrying the
+	// same event. It returns repository.ErrNotFound when the user has no
+	// such delivery.
+	Replay(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/safebites/backend-go/internal/model"
+)
+
+// supportedLanguages lists the response languages, default first so that the
+// matcher falls back to it.
+var supportedLanguages = []language.Tag{
+	language.English,
+	language.Spanish,
+	language.French,
+	language.German,
+	language.Japanese,
+}
+
+var languageMatcher = language.NewMatcher(supportedLanguages)
+
+// ResponseLanguage picks the best supported response language for an
+// Accept-Language style list such as "es-MX,es;q=0.9", defaulting to
+// English.
+func ResponseLanguage(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return model.DefaultLanguage
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return model.DefaultLanguage
+	}
+	_, idx, confidence := languageMatcher.Match(tags...)
+	if confidence == language.No {
+		return model.DefaultLanguage
+	}
+	base, _ := supportedLanguages[idx].Base()
+	return base.String()
+}
+
+type textTranslator interface {
+	Translate(ctx context.Context, texts []string, targetLanguage string) ([]string, error)
+}
+
+type localizeService struct {
+	translator textTranslator
+}
+
+func NewLocalizeService(translator textTranslator) LocalizeService {
+	return &localizeService{translator: translator}
+}
+
+// LocalizeAnalysis returns a copy of result with ingredient reasoning and
+// confidence notes translated into lang. The input is left untouched.
+func (s *localizeService) LocalizeAnalysis(ctx context.Context, result *model.AnalysisResult, lang string) (*model.AnalysisResult, error) {
+	if result == nil {
+		return nil, fmt.Errorf("analysis result is required")
+	}
+	out := *result
+	if isDefaultLanguage(lang) {
+		out.Language = model.DefaultLanguage
+		return &out, nil
+	}
+
+	var texts textBatch
+	out.IngredientBreakdown = texts.addScores(result.IngredientBreakdown)
+	if result.Confidence != nil {
+		confidence := *result.Confidence
+		confidence.Notes = append([]string(nil), result.Confidence.Notes...)
+		for i := range confidence.Notes {
+			texts.add(&confidence.Notes[i])
+		}
+		out.Confidence = &confidence
+	}
+
+	if err := s.translate(ctx, texts, lang); err != nil {
+		return nil, err
+	}
+	out.Language = normalizeLanguage(lang)
+	return &out, nil
+}
+
+// LocalizeRecommendations returns a copy of result with recommendation
+// reasons and their ingredient reasoning translated into lang.
+func (s *localizeService) LocalizeRecommendations(ctx context.Context, result *model.RecommenderResult, lang string) (*model.RecommenderResult, error) {
+	if result == nil {
+		return nil, fmt.Errorf("recommender result is required")
+	}
+	out := *result
+	if isDefaultLanguage(lang) {
+		return &out, nil
+	}
+
+	var texts textBatch
+	out.Recommendations = make([]model.Recommendation, len(result.Recommendations))
+	for i, rec := range result.Recommendations {
+		out.Recommendations[i] = rec
+		texts.add(&out.Recommendations[i].Reason)
+		out.Recommendations[i].IngredientBreakdown = texts.addScores(rec.IngredientBreakdown)
+	}
+
+	if err := s.translate(ctx, texts, lang); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LocalizeComparison returns a copy of result with the verdict summary and
+// each product's ingredient reasoning translated into lang.
+func (s *localizeService) LocalizeComparison(ctx context.Context, result *model.ComparisonResult, lang string) (*model.ComparisonResult, error) {
+	if result == nil {
+		return nil, fmt.Errorf("comparison result is required")
+	}
+	out := *result
+	if isDefaultLanguage(lang) {
+		return &out, nil
+	}
+
+	var texts textBatch
+	texts.add(&out.Verdict.Summary)
+	out.Products = make([]model.ProductComparison, len(result.Products))
+	for i, product := range result.Products {
+		out.Products[i] = product
+		out.Products[i].IngredientBreakdown = texts.addScores(product.IngredientBreakdown)
+	}
+
+	if err := s.translate(ctx, texts, lang); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *localizeService) translate(ctx context.Context, texts textBatch, lang string) error {
+	if len(texts) == 0 {
+		return nil
+	}
+	if s.translator == nil {
+		return fmt.Errorf("translator dependency is required")
+	}
+
+	values := make([]string, len(texts))
+	for i, ptr := range texts {
+		values[i] = *ptr
+	}
+	translated, err := s.translator.Translate(ctx, values, normalizeLanguage(lang))
+	if err != nil {
+		return fmt.Errorf("translate to %s: %w", lang, err)
+	}
+	if len(translated) != len(texts) {
+		return fmt.Errorf("translate to %s: got %d texts, want %d", lang, len(translated), len(texts))
+	}
+	for i, ptr := range texts {
+		*ptr = translated[i]
+	}
+	return nil
+}
+
+// textBatch collects pointers to the strings of copied results so they can be
+// translated in a single call and written back in place.
+type textBatch []*string
+
+func (b *textBatch) add(text *string) {
+	*b = append(*b, text)
+}
+
+func (b *textBatch) addScores(score *model.ScorerResult) *model.ScorerResult {
+	if score == nil {
+		return nil
+	}
+	copied := *score
+	copied.IngredientScores = append([]model.IngredientScore(nil), score.IngredientScores...)
+	for i := range copied.IngredientScores {
+		b.add(&copied.IngredientScores[i].Reasoning)
+	}
+	return &copied
+}
+
+func normalizeLanguage(lang string) string {
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+func isDefaultLanguage(lang string) bool {
+	l := normalizeLanguage(lang)
+	return l == "" || l == model.DefaultLanguage
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTranslator struct {
+	translate func(ctx context.Context, texts []string, targetLanguage string) ([]string, error)
+}
+
+func (m *mockTranslator) Translate(ctx context.Context, texts []string, targetLanguage string) ([]string, error) {
+	return m.translate(ctx, texts, targetLanguage)
+}
+
+func upperTranslator(t *testing.T, wantLang string) *mockTranslator {
+	return &mockTranslator{
+		translate: func(_ context.Context, texts []string, lang string) ([]string, error) {
+			require.Equal(t, wantLang, lang)
+			out := make([]string, len(texts))
+			for i, text := range texts {
+				out[i] = strings.ToUpper(text)
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestLocalizeServiceLocalizeAnalysis(t *testing.T) {
+	original := &model.AnalysisResult{
+		ProductName: "Galletas María",
+		Language:    model.DefaultLanguage,
+		IngredientBreakdown: &model.ScorerResult{
+			IngredientScores: []model.IngredientScore{{IngredientName: "Sugar", OriginalName: "Azúcar", SafetyScore: "LOW", Reasoning: "added sugar"}},
+			OverallScore:     4,
+		},
+		Confidence: &model.Confidence{Overall: 0.5, NeedsVerification: true, Notes: []string{"few sources"}},
+	}
+
+	out, err := NewLocalizeService(upperTranslator(t, "es")).LocalizeAnalysis(context.Background(), original, "ES")
+	require.NoError(t, err)
+	require.Equal(t, "es", out.Language)
+	require.Equal(t, "ADDED SUGAR", out.IngredientBreakdown.IngredientScores[0].Reasoning)
+	require.Equal(t, "Sugar", out.IngredientBreakdown.IngredientScores[0].IngredientName)
+	require.Equal(t, []string{"FEW SOURCES"}, out.Confidence.Notes)
+
+	require.Equal(t, "added sugar", original.IngredientBreakdown.IngredientScores[0].Reasoning)
+	require.Equal(t, []string{"few sources"}, original.Confidence.Notes)
+}
+
+func TestLocalizeServiceSkipsDefaultLanguage(t *testing.T) {
+	svc := NewLocalizeService(&mockTranslator{
+		translate: func(context.Context, []string, string) ([]string, error) {
+			t.Fatal("translator should not be called")
+			return nil, nil
+		},
+	})
+
+	out, err := svc.LocalizeAnalysis(context.Background(), &model.AnalysisResult{ProductName: "Oats"}, "en")
+	require.NoError(t, err)
+	require.Equal(t, model.DefaultLanguage, out.Language)
+}
+
+func TestLocalizeServiceLocalizeRecommendations(t *testing.T) {
+	original := &model.RecommenderResult{Recommendations: []model.Recommendation{{
+		ProductName: "Plain Oats",
+		Reason:      "no added sugar",
+		IngredientBreakdown: &model.ScorerResult{
+			IngredientScores: []model.IngredientScore{{IngredientName: "Oats", SafetyScore: "HIGH", Reasoning: "whole grain"}},
+		},
+	}}}
+
+	out, err := NewLocalizeService(upperTranslator(t, "de")).LocalizeRecommendations(context.Background(), original, "de")
+	require.NoError(t, err)
+	require.Equal(t, "NO ADDED SUGAR", out.Recommendations[0].Reason)
+	require.Equal(t, "WHOLE GRAIN", out.Recommendations[0].IngredientBreakdown.IngredientScores[0].Reasoning)
+	require.Equal(t, "no added sugar", original.Recommendations[0].Reason)
+}
+
+func TestLocalizeServiceLocalizeComparison(t *testing.T) {
+	original := &model.ComparisonResult{
+		Products: []model.ProductComparison{
+			{ProductName: "Plain Oats", IngredientBreakdown: &model.ScorerResult{
+				IngredientScores: []model.IngredientScore{{IngredientName: "Oats", SafetyScore: "HIGH", Reasoning: "whole grain"}},
+			}},
+			{ProductName: "Unknown Bar", Error: "product not found"},
+		},
+		Verdict: model.ComparisonVerdict{BestProduct: "Plain Oats", Summary: "plain oats are safer"},
+	}
+
+	out, err := NewLocalizeService(upperTranslator(t, "fr")).LocalizeComparison(context.Background(), original, "fr")
+	require.NoError(t, err)
+	require.Equal(t, "PLAIN OATS ARE SAFER", out.Verdict.Summary)
+	require.Equal(t, "WHOLE GRAIN", out.Products[0].IngredientBreakdown.IngredientScores[0].Reasoning)
+	require.Equal(t, "Plain Oats", out.Verdict.BestProduct)
+	require.Nil(t, out.Products[1].IngredientBreakdown)
+	require.Equal(t, "plain oats are safer", original.Verdict.Summary)
+	require.Equal(t, "whole grain", original.Products[0].IngredientBreakdown.IngredientScores[0].Reasoning)
+}
+
+func TestLocalizeServiceTranslatorError(t *testing.T) {
+	svc := NewLocalizeService(&mockTranslator{
+		translate: func(context.Context, []string, string) ([]string, error) {
+			return nil, errors.New("model unavailable")
+		},
+	})
+
+	_, err := svc.LocalizeRecommendations(context.Background(), &model.RecommenderResult{Recommendations: []model.Recommendation{{Reason: "less salt"}}}, "fr")
+	require.ErrorContains(t, err, "model unavailable")
+}
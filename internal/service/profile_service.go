@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/safebites/backend-go/internal/allergen"
+	"github.com/safebites/backend-go/internal/guard"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+var (
+	// ErrProfileLimit is returned when an account already has
+	// model.MaxProfiles profiles.
+	ErrProfileLimit = fmt.Errorf("an account can have at most %d profiles", model.MaxProfiles)
+	// ErrDuplicateProfile is returned when another profile of the account
+	// has the same name, ignoring case.
+	ErrDuplicateProfile = errors.New("a profile with this name already exists")
+)
+
+type profileService struct {
+	profiles repository.ProfileRepository
+	analyze  AnalyzeService
+}
+
+// NewProfileService manages household profiles. analyze scores products for
+// each profile in Evaluate.
+func NewProfileService(profiles repository.ProfileRepository, analyze AnalyzeService) ProfileService {
+	return &profileService{profiles: profiles, analyze: analyze}
+}
+
+func (s *profileService) List(ctx context.Context, userID string) ([]model.Profile, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	return s.profiles.ListByUser(ctx, userID)
+}
+
+func (s *profileService) Create(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	existing, err := s.profiles.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= model.MaxProfiles {
+		return nil, ErrProfileLimit
+	}
+	if nameTaken(existing, profile.Name, "") {
+		return nil, ErrDuplicateProfile
+	}
+
+	profile.ID = uuid.NewString()
+	profile.UserID = userID
+	return s.profiles.Create(ctx, &profile)
+}
+
+func (s *profileService) Update(ctx context.Context, userID string, profile model.Profile) (*model.Profile, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	existing, err := s.profiles.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if nameTaken(existing, profile.Name, profile.ID) {
+		return nil, ErrDuplicateProfile
+	}
+
+	profile.UserID = userID
+	return s.profiles.Update(ctx, &profile)
+}
+
+func (s *profileService) Delete(ctx context.Context, userID, profileID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return fmt.Errorf("user id is required")
+	}
+	return s.profiles.Delete(ctx, userID, profileID)
+}
+
+func (s *profileService) Select(ctx context.Context, userID string, profileIDs []string) ([]model.Profile, error) {
+	all, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(profileIDs) == 0 {
+		return all, nil
+	}
+
+	byID := make(map[string]model.Profile, len(all))
+	for _, p := range all {
+		byID[p.ID] = p
+	}
+	selected := make([]model.Profile, 0, len(profileIDs))
+	seen := map[string]bool{}
+	for _, id := range profileIDs {
+		p, ok := byID[id]
+		if !ok {
+			return nil, repository.ErrNotFound
+		}
+		if !seen[id] {
+			seen[id] = true
+			selected = append(selected, p)
+		}
+	}
+	return selected, nil
+}
+
+// Evaluate rescores ingredients for every profile at once. Only scoring is
+// repeated: the ingredient list comes from the analysis, so no profile
+// triggers another search. Traces keep their "may contain" flag, so each
+// profile's own allergies decide whether they count. Analyses cached before
+// the list was kept fall back to the names in breakdown, which only hold the
+// traces that mattered to the caller.
+func (s *profileService) Evaluate(ctx context.Context, profiles []model.Profile, homeRegion string, ingredients []model.Ingredient, breakdown *model.ScorerResult) []model.ProfileVerdict {
+	if len(ingredients) == 0 && breakdown != nil {
+		for _, is := range breakdown.IngredientScores {
+			ingredients = append(ingredients, model.Ingredient{Name: is.IngredientName, OriginalName: is.OriginalName, MayContain: is.MayContain})
+		}
+	}
+	if s.analyze == nil || breakdown == nil || len(ingredients) == 0 {
+		return s.Judge(profiles, homeRegion, breakdown)
+	}
+
+	verdicts := make([]model.ProfileVerdict, len(profiles))
+	var wg sync.WaitGroup
+	for i, profile := range profiles {
+		wg.Add(1)
+		go func(i int, profile model.Profile) {
+			defer wg.Done()
+			scored, err := s.analyze.RescoreIngredients(ctx, ingredients, profile.Preferences(homeRegion))
+			if err != nil {
+				log.Printf("profile evaluation failed profile=%s err=%v", profile.ID, err)
+				verdicts[i] = model.ProfileVerdict{ProfileID: profile.ID, Name: profile.Name, Error: profileEvaluationError(err)}
+				return
+			}
+			verdicts[i] = judgeProfile(profile, homeRegion, scored)
+			verdicts[i].IngredientBreakdown = scored
+		}(i, profile)
+	}
+	wg.Wait()
+	return verdicts
+}
+
+func (s *profileService) Judge(profiles []model.Profile, homeRegion string, breakdown *model.ScorerResult) []model.ProfileVerdict {
+	verdicts := make([]model.ProfileVerdict, 0, len(profiles))
+	for _, profile := range profiles {
+		verdicts = append(verdicts, judgeProfile(profile, homeRegion, breakdown))
+	}
+	return verdicts
+}
+
+// judgeProfile gives the verdict for profile on a product scored as
+// breakdown. Allergy and avoid conflicts are found with the allergen catalog
+// rather than read from the scorer, so a model that misses one cannot turn
+// "avoid" into "caution". Conflicts that only cap a score at MEDIUM, such as
+// an intolerance, make the verdict "caution" rather than "avoid".
+func judgeProfile(profile model.Profile, homeRegion string, breakdown *model.ScorerResult) model.ProfileVerdict {
+	verdict := model.ProfileVerdict{
+		ProfileID: profile.ID,
+		Name:      profile.Name,
+		Conflicts: []model.PreferenceConflict{},
+	}
+	if breakdown == nil {
+		verdict.Verdict = model.VerdictCaution
+		return verdict
+	}
+
+	prefs := profile.Preferences(homeRegion)
+	avoid := false
+	for _, is := range breakdown.IngredientScores {
+		conflicts := allergen.Default().Conflicts
+		if is.MayContain {
+			conflicts = allergen.Default().TraceConflicts
+		}
+		for _, c := range conflicts(is.IngredientName, prefs) {
+			c.Ingredient = is.IngredientName
+			verdict.Conflicts = append(verdict.Conflicts, c)
+			// Avoided ingredients carry no severity and are held to LOW.
+			allergy := model.Allergy{Severity: c.Severity, MayContain: is.MayContain}
+			limit := allergy.ScoreLimit()
+			if is.MayContain {
+				limit = allergy.TraceLimit()
+			}
+			if limit == "LOW" {
+				avoid = true
+			}
+		}
+	}
+	verdict.OverallScore = breakdown.OverallScore
+	switch {
+	case avoid:
+		verdict.Verdict = model.VerdictAvoid
+	case len(verdict.Conflicts) > 0:
+		verdict.Verdict = model.VerdictCaution
+	case breakdown.OverallScore < model.MinSuitableScore:
+		verdict.Verdict = model.VerdictCaution
+	default:
+		verdict.Verdict = model.VerdictSuitable
+	}
+	return verdict
+}
+
+func profileEvaluationError(err error) string {
+	var rejected *guard.RejectedError
+	if errors.As(err, &rejected) {
+		return "ingredients were rejected as unsafe input"
+	}
+	return "failed to score product for profile"
+}
+
+func nameTaken(profiles []model.Profile, name, exceptID string) bool {
+	for _, p := range profiles {
+		if p.ID != exceptID && strings.EqualFold(strings.TrimSpace(p.Name), strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// MergePreferences combines prefs with the dietary lists of profiles, for
+// results that have to suit the whole household at once. Duplicate entries
+// are dropped, ignoring case; an allergy several people share keeps the most
+// severe reaction among them.
+func MergePreferences(prefs *model.UserPreferences, profiles []model.Profile) *model.UserPreferences {
+	merged := &model.UserPreferences{}
+	if prefs != nil {
+		merged.HomeRegion = prefs.HomeRegion
+		merged.Allergies = appendUniqueAllergies(merged.Allergies, prefs.Allergies)
+		merged.DietGoals = appendUnique(merged.DietGoals, prefs.DietGoals)
+		merged.AvoidIngredients = appendUnique(merged.AvoidIngredients, prefs.AvoidIngredients)
+	}
+	for _, p := range profiles {
+		merged.Allergies = appendUniqueAllergies(merged.Allergies, p.Allergies)
+		merged.DietGoals = appendUnique(merged.DietGoals, p.DietGoals)
+		merged.AvoidIngredients = appendUnique(merged.AvoidIngredients, p.AvoidIngredients)
+	}
+	return merged
+}
+
+func appendUnique(dst, src []string) []string {
+	for _, v := range src {
+		dup := false
+		for _, d := range dst {
+			if strings.EqualFold(d, v) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+func appendUniqueAllergies(dst, src []model.Allergy) []model.Allergy {
+	for _, a := range src {
+		dup := false
+		for i, d := range dst {
+			if model.SameAllergen(d, a) {
+				dst[i] = model.MergeAllergy(d, a)
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, a)
+		}
+	}
+	return dst
+}
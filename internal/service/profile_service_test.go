@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/allergen"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type memProfileRepo struct {
+	profiles []model.Profile
+}
+
+func (m *memProfileRepo) ListByUser(_ context.Context, userID string) ([]model.Profile, error) {
+	out := []model.Profile{}
+	for _, p := range m.profiles {
+		if p.UserID == userID {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memProfileRepo) Get(_ context.Context, userID, profileID string) (*model.Profile, error) {
+	for _, p := range m.profiles {
+		if p.UserID == userID && p.ID == profileID {
+			return &p, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memProfileRepo) Create(_ context.Context, profile *model.Profile) (*model.Profile, error) {
+	m.profiles = append(m.profiles, *profile)
+	return profile, nil
+}
+
+func (m *memProfileRepo) Update(_ context.Context, profile *model.Profile) (*model.Profile, error) {
+	for i, p := range m.profiles {
+		if p.UserID == profile.UserID && p.ID == profile.ID {
+			m.profiles[i] = *profile
+			return profile, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memProfileRepo) Delete(_ context.Context, userID, profileID string) error {
+	return nil
+}
+
+func TestProfileServiceCreateEnforcesLimitAndUniqueNames(t *testing.T) {
+	repo := &memProfileRepo{}
+	svc := NewProfileService(repo, nil)
+
+	created, err := svc.Create(context.Background(), "user-1", model.Profile{Name: "Mia", Allergies: model.Allergies("peanuts")})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ID)
+	require.Equal(t, "user-1", created.UserID)
+
+	_, err = svc.Create(context.Background(), "user-1", model.Profile{Name: " mia "})
+	require.ErrorIs(t, err, ErrDuplicateProfile)
+	// Names only need to be unique within an account.
+	_, err = svc.Create(context.Background(), "user-2", model.Profile{Name: "Mia"})
+	require.NoError(t, err)
+
+	for i := 1; i < model.MaxProfiles; i++ {
+		_, err = svc.Create(context.Background(), "user-1", model.Profile{Name: fmt.Sprintf("Kid %d", i)})
+		require.NoError(t, err)
+	}
+	_, err = svc.Create(context.Background(), "user-1", model.Profile{Name: "One too many"})
+	require.ErrorIs(t, err, ErrProfileLimit)
+
+	// Renaming a profile to its own name is fine, to another's is not.
+	_, err = svc.Update(context.Background(), "user-1", model.Profile{ID: created.ID, Name: "MIA"})
+	require.NoError(t, err)
+	_, err = svc.Update(context.Background(), "user-1", model.Profile{ID: created.ID, Name: "Kid 1"})
+	require.ErrorIs(t, err, ErrDuplicateProfile)
+}
+
+func TestProfileServiceSelect(t *testing.T) {
+	repo := &memProfileRepo{profiles: []model.Profile{
+		{ID: "p1", UserID: "user-1", Name: "Mia"},
+		{ID: "p2", UserID: "user-1", Name: "Leo"},
+		{ID: "p3", UserID: "user-2", Name: "Ada"},
+	}}
+	svc := NewProfileService(repo, nil)
+
+	all, err := svc.Select(context.Background(), "user-1", nil)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	picked, err := svc.Select(context.Background(), "user-1", []string{"p2", "p2"})
+	require.NoError(t, err)
+	require.Equal(t, []model.Profile{repo.profiles[1]}, picked)
+
+	_, err = svc.Select(context.Background(), "user-1", []string{"p3"})
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestProfileServiceEvaluateScoresEachProfile(t *testing.T) {
+	mia := model.Profile{ID: "p1", Name: "Mia", Allergies: model.Allergies("dairy")}
+	leo := model.Profile{ID: "p2", Name: "Leo", DietGoals: []string{"low sugar"}}
+	ada := model.Profile{ID: "p3", Name: "Ada"}
+
+	var mu sync.Mutex
+	seen := map[string]*model.UserPreferences{}
+	analyze := &productAnalyzer{}
+	svc := NewProfileService(nil, &scoringAnalyzer{productAnalyzer: analyze, score: func(ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+		require.Equal(t, []model.Ingredient{{Name: "Sugar"}, {Name: "Skimmed Milk Powder"}}, ingredients)
+		require.Equal(t, "EU", prefs.HomeRegion)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case len(prefs.DietGoals) > 0:
+			seen["leo"] = prefs
+			return &model.ScorerResult{OverallScore: 3, IngredientScores: []model.IngredientScore{{IngredientName: "Sugar", SafetyScore: "LOW"}, {IngredientName: "Skimmed Milk Powder", SafetyScore: "HIGH"}}}, nil
+		case len(prefs.Allergies) > 0:
+			seen["mia"] = prefs
+			return &model.ScorerResult{OverallScore: 6, IngredientScores: []model.IngredientScore{{IngredientName: "Sugar", SafetyScore: "MEDIUM"}, {IngredientName: "Skimmed Milk Powder", SafetyScore: "MEDIUM"}}}, nil
+		}
+		return nil, errors.New("scorer unavailable")
+	}})
+
+	breakdown := &model.ScorerResult{OverallScore: 7, IngredientScores: []model.IngredientScore{{IngredientName: "Sugar"}, {IngredientName: "Skimmed Milk Powder"}}}
+	verdicts := svc.Evaluate(context.Background(), []model.Profile{mia, leo, ada}, "EU", nil, breakdown)
+	require.Len(t, verdicts, 3)
+
+	// The allergen catalog catches milk powder for a dairy allergy even
+	// though the scorer rated it MEDIUM.
+	require.Equal(t, "p1", verdicts[0].ProfileID)
+	require.Equal(t, model.VerdictAvoid, verdicts[0].Verdict)
+	require.Len(t, verdicts[0].Conflicts, 1)
+	require.Equal(t, "Skimmed Milk Powder", verdicts[0].Conflicts[0].Ingredient)
+	require.Equal(t, model.ConflictAllergy, verdicts[0].Conflicts[0].Kind)
+	require.NotNil(t, verdicts[0].IngredientBreakdown)
+
+	require.Equal(t, model.VerdictCaution, verdicts[1].Verdict)
+	require.Equal(t, 3.0, verdicts[1].OverallScore)
+	require.Empty(t, verdicts[1].Conflicts)
+
+	require.Empty(t, verdicts[2].Verdict)
+	require.Equal(t, "failed to score product for profile", verdicts[2].Error)
+	require.Len(t, seen, 2)
+}
+
+func TestProfileServiceEvaluateKeepsTraces(t *testing.T) {
+	// The scorer stand-in rates every listed ingredient HIGH and applies the
+	// allergen catalog, as the scorer agent does.
+	svc := NewProfileService(nil, &scoringAnalyzer{productAnalyzer: &productAnalyzer{}, score: func(ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+		result := &model.ScorerResult{OverallScore: 8}
+		var traces []string
+		for _, ing := range ingredients {
+			if ing.MayContain {
+				traces = append(traces, ing.Name)
+				continue
+			}
+			result.IngredientScores = append(result.IngredientScores, model.IngredientScore{IngredientName: ing.Name, SafetyScore: "HIGH"})
+		}
+		allergen.Default().Apply(result, traces, prefs)
+		return result, nil
+	}})
+	profiles := []model.Profile{
+		{ID: "p1", Name: "Ada", Allergies: model.Allergies("peanuts")},
+		{ID: "p2", Name: "Leo", Allergies: []model.Allergy{{Name: "peanuts", Severity: model.SeverityAnaphylactic, MayContain: true}}},
+	}
+	ingredients := []model.Ingredient{{Name: "Oats"}, {Name: "peanuts", MayContain: true}}
+
+	// The account owner has no peanut allergy, so their breakdown dropped
+	// the trace.
+	owner := &model.ScorerResult{OverallScore: 8, IngredientScores: []model.IngredientScore{{IngredientName: "Oats", SafetyScore: "HIGH"}}}
+	verdicts := svc.Evaluate(context.Background(), profiles, "", ingredients, owner)
+	require.Equal(t, model.VerdictSuitable, verdicts[0].Verdict, "a trace is not a listed ingredient")
+	require.Empty(t, verdicts[0].Conflicts)
+	require.Equal(t, model.VerdictAvoid, verdicts[1].Verdict, "traces count for an allergy that asks for them")
+	require.Len(t, verdicts[1].Conflicts, 1)
+	require.Equal(t, "peanuts", verdicts[1].Conflicts[0].Ingredient)
+
+	// An analysis cached without its ingredient list still keeps the flag of
+	// the traces in its breakdown.
+	owner.IngredientScores = append(owner.IngredientScores, model.IngredientScore{IngredientName: "peanuts", SafetyScore: "LOW", MayContain: true})
+	verdicts = svc.Evaluate(context.Background(), profiles, "", nil, owner)
+	require.Equal(t, model.VerdictSuitable, verdicts[0].Verdict)
+	require.Equal(t, model.VerdictAvoid, verdicts[1].Verdict)
+}
+
+func TestProfileServiceJudgeUsesGivenBreakdown(t *testing.T) {
+	svc := NewProfileService(nil, nil)
+	breakdown := &model.ScorerResult{OverallScore: 8, IngredientScores: []model.IngredientScore{{IngredientName: "Oats"}, {IngredientName: "Sesame Seeds"}}}
+
+	verdicts := svc.Judge([]model.Profile{
+		{ID: "p1", Name: "Mia", AvoidIngredients: []string{"sesame"}},
+		{ID: "p2", Name: "Leo"},
+	}, "", breakdown)
+	require.Equal(t, model.VerdictAvoid, verdicts[0].Verdict)
+	require.Equal(t, model.ConflictAvoid, verdicts[0].Conflicts[0].Kind)
+	require.Equal(t, model.VerdictSuitable, verdicts[1].Verdict)
+	require.Nil(t, verdicts[1].IngredientBreakdown)
+}
+
+func TestProfileServiceJudgeWeighsSeverity(t *testing.T) {
+	svc := NewProfileService(nil, nil)
+	breakdown := &model.ScorerResult{OverallScore: 8, IngredientScores: []model.IngredientScore{
+		{IngredientName: "Whole Milk"},
+		{IngredientName: "peanuts", MayContain: true},
+	}}
+
+	verdicts := svc.Judge([]model.Profile{
+		{ID: "p1", Name: "Mia", Allergies: []model.Allergy{{Name: "dairy", Severity: model.SeverityIntolerance}}},
+		{ID: "p2", Name: "Leo", Allergies: []model.Allergy{{Name: "peanuts", Severity: model.SeverityAnaphylactic, MayContain: true}}},
+		{ID: "p3", Name: "Ada", Allergies: model.Allergies("peanuts")},
+	}, "", breakdown)
+	require.Equal(t, model.VerdictCaution, verdicts[0].Verdict, "an intolerance is a caution")
+	require.Equal(t, model.SeverityIntolerance, verdicts[0].Conflicts[0].Severity)
+	require.Equal(t, model.VerdictAvoid, verdicts[1].Verdict, "traces matter for an anaphylactic allergy")
+	require.Equal(t, model.VerdictSuitable, verdicts[2].Verdict, "traces are ignored unless asked for")
+	require.Empty(t, verdicts[2].Conflicts)
+}
+
+func TestMergePreferences(t *testing.T) {
+	merged := MergePreferences(
+		&model.UserPreferences{Allergies: model.Allergies("Peanuts"), HomeRegion: "UK"},
+		[]model.Profile{
+			{Allergies: model.Allergies("peanuts", "dairy"), DietGoals: []string{"vegan"}},
+			{AvoidIngredients: []string{"palm oil"}, DietGoals: []string{"Vegan"}},
+		},
+	)
+	require.Equal(t, model.Allergies("Peanuts", "dairy"), merged.Allergies)
+	require.Equal(t, []string{"vegan"}, merged.DietGoals)
+	require.Equal(t, []string{"palm oil"}, merged.AvoidIngredients)
+	require.Equal(t, "UK", merged.HomeRegion)
+}
+
+// scoringAnalyzer is an AnalyzeService that only scores ingredient lists.
+type scoringAnalyzer struct {
+	*productAnalyzer
+	score func(ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error)
+}
+
+func (s *scoringAnalyzer) RescoreIngredients(_ context.Context, ingredients []model.Ingredient, prefs *model.UserPreferences) (*model.ScorerResult, error) {
+	return s.score(ingredients, prefs)
+}
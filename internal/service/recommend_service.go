@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/safebites/backend-go/internal/guard"
 	"github.com/safebites/backend-go/internal/model"
 )
 
 type recommendationRunner interface {
-	Recommend(ctx context.Context, productName string, score float64) (*model.RecommenderResult, error)
+	RecommendAlternatives(ctx context.Context, productName string, score float64, prefs *model.UserPreferences) (*model.RecommenderResult, error)
 }
 
 type recommendService struct {
@@ -20,7 +21,7 @@ func NewRecommendService(recommender recommendationRunner) RecommendService {
 	return &recommendService{recommender: recommender}
 }
 
-func (s *recommendService) Recommend(ctx context.Context, productName string, score float64) (*model.RecommenderResult, error) {
+func (s *recommendService) Recommend(ctx context.Context, productName string, score float64, prefs *model.UserPreferences) (*model.RecommenderResult, error) {
 	if s.recommender == nil {
 		return nil, fmt.Errorf("recommender dependency is required")
 	}
@@ -31,7 +32,12 @@ func (s *recommendService) Recommend(ctx context.Context, productName string, sc
 		return nil, fmt.Errorf("score must be non-negative")
 	}
 
-	result, err := s.recommender.Recommend(ctx, strings.TrimSpace(productName), score)
+	guarded, err := guard.ProductName(ctx, guard.SourceParam, productName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.recommender.RecommendAlternatives(ctx, guarded.Value, score, prefs)
 	if err != nil {
 		return nil, fmt.Errorf("run recommender workflow: %w", err)
 	}
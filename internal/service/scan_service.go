@@ -17,11 +17,11 @@ func NewScanService(scans repository.ScanRepository) ScanService {
 	return &scanService{scans: scans}
 }
 
-func (s *scanService) ListByUser(ctx context.Context, userID string, limit int) ([]model.Scan, error) {
+func (s *scanService) ListByUser(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
 	if strings.TrimSpace(userID) == "" {
 		return nil, fmt.Errorf("user id is required")
 	}
-	return s.scans.ListByUser(ctx, userID, limit)
+	return s.scans.ListByUser(ctx, userID, filter)
 }
 
 func (s *scanService) Create(ctx context.Context, scan *model.Scan) (*model.Scan, error) {
@@ -9,13 +9,18 @@ import (
 )
 
 type mockServiceScanRepo struct {
-	listByUser func(ctx context.Context, userID string, limit int) ([]model.Scan, error)
+	listByUser func(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error)
+	getByID    func(ctx context.Context, userID, scanID string) (*model.Scan, error)
 	create     func(ctx context.Context, scan *model.Scan) (*model.Scan, error)
 	getStats   func(ctx context.Context, userID string) (*model.UserStats, error)
 }
 
-func (m *mockServiceScanRepo) ListByUser(ctx context.Context, userID string, limit int) ([]model.Scan, error) {
-	return m.listByUser(ctx, userID, limit)
+func (m *mockServiceScanRepo) ListByUser(ctx context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
+	return m.listByUser(ctx, userID, filter)
+}
+
+func (m *mockServiceScanRepo) GetByID(ctx context.Context, userID, scanID string) (*model.Scan, error) {
+	return m.getByID(ctx, userID, scanID)
 }
 
 func (m *mockServiceScanRepo) Create(ctx context.Context, scan *model.Scan) (*model.Scan, error) {
@@ -28,22 +33,23 @@ func (m *mockServiceScanRepo) GetStats(ctx context.Context, userID string) (*mod
 
 func TestScanServiceListByUser(t *testing.T) {
 	svc := NewScanService(&mockServiceScanRepo{
-		listByUser: func(_ context.Context, userID string, limit int) ([]model.Scan, error) {
-			return []model.Scan{{ID: "scan-1", UserID: userID, ProductName: "Granola"}}, nil
+		listByUser: func(_ context.Context, userID string, filter model.ScanFilter) (*model.ScanPage, error) {
+			require.Equal(t, 10, filter.Limit)
+			return &model.ScanPage{Scans: []model.Scan{{ID: "scan-1", UserID: userID, ProductName: "Granola"}}}, nil
 		},
 		create:   nil,
 		getStats: nil,
 	})
 
-	scans, err := svc.ListByUser(context.Background(), "user-1", 10)
+	page, err := svc.ListByUser(context.Background(), "user-1", model.ScanFilter{Limit: 10})
 	require.NoError(t, err)
-	require.Len(t, scans, 1)
-	require.Equal(t, "user-1", scans[0].UserID)
+	require.Len(t, page.Scans, 1)
+	require.Equal(t, "user-1", page.Scans[0].UserID)
 }
 
 func TestScanServiceListByUserValidation(t *testing.T) {
 	svc := NewScanService(&mockServiceScanRepo{
-		listByUser: func(_ context.Context, _ string, _ int) ([]model.Scan, error) {
+		listByUser: func(_ context.Context, _ string, _ model.ScanFilter) (*model.ScanPage, error) {
 			t.Fatal("repo should not be called")
 			return nil, nil
 		},
@@ -51,7 +57,7 @@ func TestScanServiceListByUserValidation(t *testing.T) {
 		getStats: nil,
 	})
 
-	_, err := svc.ListByUser(context.Background(), "   ", 10)
+	_, err := svc.ListByUser(context.Background(), "   ", model.ScanFilter{Limit: 10})
 	require.Error(t, err)
 	require.ErrorContains(t, err, "user id is required")
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// DefaultSessionRetention is how long an agent session is kept after its
+	// last update.
+	DefaultSessionRetention = 30 * 24 * time.Hour
+	// DefaultSessionRetentionInterval is how often expired sessions are
+	// deleted.
+	DefaultSessionRetentionInterval = time.Hour
+)
+
+type idleSessionDeleter interface {
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SessionRetention deletes agent sessions, chat transcripts included, once
+// they have gone Retention without an update.
+type SessionRetention struct {
+	sessions  idleSessionDeleter
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewSessionRetention keeps sessions for retention after their last update,
+// DefaultSessionRetention when it is not positive.
+func NewSessionRetention(sessions idleSessionDeleter, retention time.Duration) *SessionRetention {
+	if retention <= 0 {
+		retention = DefaultSessionRetention
+	}
+	return &SessionRetention{
+		sessions:  sessions,
+		retention: retention,
+		interval:  DefaultSessionRetentionInterval,
+		now:       time.Now,
+	}
+}
+
+// Run deletes expired sessions right away and then every interval until ctx
+// is canceled.
+func (s *SessionRetention) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.prune(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SessionRetention) prune(ctx context.Context) {
+	n, err := s.sessions.DeleteIdle(ctx, s.now().Add(-s.retention))
+	switch {
+	case err != nil && ctx.Err() == nil:
+		log.Printf("agent session retention failed err=%v", err)
+	case n > 0:
+		log.Printf("agent session retention deleted=%d", n)
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type idleSessions struct {
+	before chan time.Time
+}
+
+func (s *idleSessions) DeleteIdle(_ context.Context, before time.Time) (int64, error) {
+	s.before <- before
+	return 1, nil
+}
+
+func TestSessionRetentionDeletesSessionsPastRetention(t *testing.T) {
+	sessions := &idleSessions{before: make(chan time.Time, 1)}
+	retention := NewSessionRetention(sessions, 48*time.Hour)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	retention.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		retention.Run(ctx)
+		close(done)
+	}()
+
+	require.Equal(t, now.Add(-48*time.Hour), <-sessions.before, "the first pass runs at start")
+	cancel()
+	<-done
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+var (
+	// ErrTemplateNotFound is returned for a template that does not exist or
+	// is not visible to the user. It matches repository.ErrNotFound.
+	ErrTemplateNotFound = fmt.Errorf("template %w", repository.ErrNotFound)
+	// ErrTemplateLimit is returned when a user already owns
+	// model.MaxUserTemplates templates.
+	ErrTemplateLimit = fmt.Errorf("a user can have at most %d templates", model.MaxUserTemplates)
+)
+
+// Share codes are read aloud and typed by hand, so the alphabet leaves out
+// 0/O and 1/I.
+const (
+	shareCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	shareCodeLength   = 8
+	shareCodeAttempts = 3
+)
+
+type templateService struct {
+	templates repository.TemplateRepository
+}
+
+func NewTemplateService(templates repository.TemplateRepository) TemplateService {
+	return &templateService{templates: templates}
+}
+
+func (s *templateService) SeedBuiltIns(ctx context.Context) error {
+	return s.templates.UpsertBuiltIns(ctx, model.SortedDietaryTemplates())
+}
+
+func (s *templateService) List(ctx context.Context, userID string) ([]model.DietaryTemplate, error) {
+	return s.templates.List(ctx, userID)
+}
+
+func (s *templateService) Get(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error) {
+	return resolveTemplate(ctx, s.templates, userID, ref)
+}
+
+func (s *templateService) Create(ctx context.Context, userID string, tpl model.DietaryTemplate) (*model.DietaryTemplate, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	if err := s.checkLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	tpl.Key = uuid.NewString()
+	tpl.OwnerID = userID
+	tpl.ForkedFrom = ""
+	return s.templates.Create(ctx, &tpl)
+}
+
+func (s *templateService) Update(ctx context.Context, userID string, tpl model.DietaryTemplate) (*model.DietaryTemplate, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	tpl.OwnerID = userID
+	updated, err := s.templates.Update(ctx, &tpl)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	return updated, err
+}
+
+func (s *templateService) Delete(ctx context.Context, userID, key string) error {
+	if strings.TrimSpace(userID) == "" {
+		return fmt.Errorf("user id is required")
+	}
+	err := s.templates.Delete(ctx, userID, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTemplateNotFound
+	}
+	return err
+}
+
+func (s *templateService) Publish(ctx context.Context, userID, key string) (*model.DietaryTemplate, error) {
+	tpl, err := s.owned(ctx, userID, key)
+	if err != nil {
+		return nil, err
+	}
+	if tpl.ShareCode != "" {
+		return tpl, nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		code, err := newShareCode()
+		if err != nil {
+			return nil, err
+		}
+		published, err := s.templates.SetShareCode(ctx, userID, key, code)
+		if errors.Is(err, repository.ErrShareCodeTaken) && attempt+1 < shareCodeAttempts {
+			continue
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return published, err
+	}
+}
+
+func (s *templateService) Unpublish(ctx context.Context, userID, key string) (*model.DietaryTemplate, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	tpl, err := s.templates.SetShareCode(ctx, userID, key, "")
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	return tpl, err
+}
+
+func (s *templateService) Fork(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	source, err := resolveTemplate(ctx, s.templates, userID, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.templates.Create(ctx, &model.DietaryTemplate{
+		Key:              uuid.NewString(),
+		OwnerID:          userID,
+		Name:             source.Name,
+		Description:      source.Description,
+		Allergies:        source.Allergies,
+		DietGoals:        source.DietGoals,
+		AvoidIngredients: source.AvoidIngredients,
+		ForkedFrom:       source.Key,
+	})
+}
+
+// owned returns the user's template with key. Built-in templates and
+// templates shared by others are not the user's to change.
+func (s *templateService) owned(ctx context.Context, userID, key string) (*model.DietaryTemplate, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	tpl, err := resolveTemplate(ctx, s.templates, userID, key)
+	if err != nil {
+		return nil, err
+	}
+	if tpl.OwnerID != userID || tpl.Key != key {
+		return nil, ErrTemplateNotFound
+	}
+	return tpl, nil
+}
+
+func (s *templateService) checkLimit(ctx context.Context, userID string) error {
+	templates, err := s.templates.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	owned := 0
+	for _, tpl := range templates {
+		if tpl.OwnerID == userID {
+			owned++
+		}
+	}
+	if owned >= model.MaxUserTemplates {
+		return ErrTemplateLimit
+	}
+	return nil
+}
+
+func resolveTemplate(ctx context.Context, templates repository.TemplateRepository, userID, ref string) (*model.DietaryTemplate, error) {
+	if strings.TrimSpace(ref) == "" {
+		return nil, ErrTemplateNotFound
+	}
+	tpl, err := templates.Resolve(ctx, userID, ref)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	return tpl, err
+}
+
+func newShareCode() (string, error) {
+	buf := make([]byte, shareCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate share code: %w", err)
+	}
+	// 256 is a multiple of the alphabet size, so every letter is equally
+	// likely.
+	for i, b := range buf {
+		buf[i] = shareCodeAlphabet[int(b)%len(shareCodeAlphabet)]
+	}
+	return string(buf), nil
+}
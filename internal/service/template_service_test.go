@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTemplateRepo struct {
+	list          func(ctx context.Context, userID string) ([]model.DietaryTemplate, error)
+	resolve       func(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error)
+	create        func(ctx context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error)
+	setShareCode  func(ctx context.Context, userID, key, shareCode string) (*model.DietaryTemplate, error)
+	upsertBuiltIn func(ctx context.Context, templates []model.DietaryTemplate) error
+}
+
+func (m *mockTemplateRepo) UpsertBuiltIns(ctx context.Context, templates []model.DietaryTemplate) error {
+	return m.upsertBuiltIn(ctx, templates)
+}
+
+func (m *mockTemplateRepo) List(ctx context.Context, userID string) ([]model.DietaryTemplate, error) {
+	return m.list(ctx, userID)
+}
+
+func (m *mockTemplateRepo) Resolve(ctx context.Context, userID, ref string) (*model.DietaryTemplate, error) {
+	return m.resolve(ctx, userID, ref)
+}
+
+func (m *mockTemplateRepo) Create(ctx context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error) {
+	return m.create(ctx, tpl)
+}
+
+func (m *mockTemplateRepo) Update(context.Context, *model.DietaryTemplate) (*model.DietaryTemplate, error) {
+	return nil, repository.ErrNotFound
+}
+
+func (m *mockTemplateRepo) SetShareCode(ctx context.Context, userID, key, shareCode string) (*model.DietaryTemplate, error) {
+	return m.setShareCode(ctx, userID, key, shareCode)
+}
+
+func (m *mockTemplateRepo) Delete(context.Context, string, string) error {
+	return repository.ErrNotFound
+}
+
+func TestTemplateServiceSeedsBuiltIns(t *testing.T) {
+	var seeded []model.DietaryTemplate
+	svc := NewTemplateService(&mockTemplateRepo{upsertBuiltIn: func(_ context.Context, templates []model.DietaryTemplate) error {
+		seeded = templates
+		return nil
+	}})
+
+	require.NoError(t, svc.SeedBuiltIns(context.Background()))
+	require.Len(t, seeded, len(model.DietaryTemplates))
+	require.Equal(t, "dairy_free", seeded[0].Key)
+}
+
+func TestTemplateServiceCreateAssignsKeyAndOwner(t *testing.T) {
+	svc := NewTemplateService(&mockTemplateRepo{
+		list: func(context.Context, string) ([]model.DietaryTemplate, error) {
+			return model.SortedDietaryTemplates(), nil
+		},
+		create: func(_ context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error) {
+			return tpl, nil
+		},
+	})
+
+	created, err := svc.Create(context.Background(), "user-1", model.DietaryTemplate{Key: "vegan", Name: "Low FODMAP", ForkedFrom: "keto"})
+	require.NoError(t, err)
+	require.NotEqual(t, "vegan", created.Key, "callers cannot pick keys")
+	require.Equal(t, "user-1", created.OwnerID)
+	require.Empty(t, created.ForkedFrom)
+}
+
+func TestTemplateServiceEnforcesLimit(t *testing.T) {
+	owned := make([]model.DietaryTemplate, model.MaxUserTemplates)
+	for i := range owned {
+		owned[i].OwnerID = "user-1"
+	}
+	svc := NewTemplateService(&mockTemplateRepo{
+		list: func(context.Context, string) ([]model.DietaryTemplate, error) {
+			return append(model.SortedDietaryTemplates(), owned...), nil
+		},
+		resolve: func(_ context.Context, _ string, ref string) (*model.DietaryTemplate, error) {
+			tpl := model.DietaryTemplates[ref]
+			return &tpl, nil
+		},
+	})
+
+	_, err := svc.Create(context.Background(), "user-1", model.DietaryTemplate{Name: "One too many"})
+	require.ErrorIs(t, err, ErrTemplateLimit)
+	_, err = svc.Fork(context.Background(), "user-1", "vegan")
+	require.ErrorIs(t, err, ErrTemplateLimit)
+}
+
+func TestTemplateServiceForkCopiesSharedTemplate(t *testing.T) {
+	shared := &model.DietaryTemplate{
+		Key:              "tpl-9",
+		OwnerID:          "user-2",
+		Name:             "Low FODMAP",
+		AvoidIngredients: []string{"onion", "garlic"},
+		ShareCode:        "K7QX2M9P",
+	}
+	svc := NewTemplateService(&mockTemplateRepo{
+		list: func(context.Context, string) ([]model.DietaryTemplate, error) { return nil, nil },
+		resolve: func(_ context.Context, userID, ref string) (*model.DietaryTemplate, error) {
+			require.Equal(t, "user-1", userID)
+			require.Equal(t, "K7QX2M9P", ref)
+			return shared, nil
+		},
+		create: func(_ context.Context, tpl *model.DietaryTemplate) (*model.DietaryTemplate, error) {
+			return tpl, nil
+		},
+	})
+
+	fork, err := svc.Fork(context.Background(), "user-1", "K7QX2M9P")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", fork.OwnerID)
+	require.Equal(t, "tpl-9", fork.ForkedFrom)
+	require.Equal(t, shared.AvoidIngredients, fork.AvoidIngredients)
+	require.Empty(t, fork.ShareCode, "a fork starts unpublished")
+	require.NotEqual(t, shared.Key, fork.Key)
+}
+
+func TestTemplateServicePublish(t *testing.T) {
+	own := &model.DietaryTemplate{Key: "tpl-1", OwnerID: "user-1"}
+	var attempts []string
+	svc := NewTemplateService(&mockTemplateRepo{
+		resolve: func(_ context.Context, _ string, ref string) (*model.DietaryTemplate, error) {
+			if ref == "tpl-1" {
+				return own, nil
+			}
+			tpl := model.DietaryTemplates[ref]
+			return &tpl, nil
+		},
+		setShareCode: func(_ context.Context, _ string, key, code string) (*model.DietaryTemplate, error) {
+			attempts = append(attempts, code)
+			if len(attempts) == 1 {
+				return nil, repository.ErrShareCodeTaken
+			}
+			return &model.DietaryTemplate{Key: key, OwnerID: "user-1", ShareCode: code}, nil
+		},
+	})
+
+	published, err := svc.Publish(context.Background(), "user-1", "tpl-1")
+	require.NoError(t, err)
+	require.Len(t, attempts, 2, "a taken code is retried")
+	require.Equal(t, attempts[1], published.ShareCode)
+	require.Len(t, published.ShareCode, shareCodeLength)
+	require.Empty(t, strings.Trim(published.ShareCode, shareCodeAlphabet))
+
+	// Publishing again keeps the code.
+	own.ShareCode = published.ShareCode
+	again, err := svc.Publish(context.Background(), "user-1", "tpl-1")
+	require.NoError(t, err)
+	require.Equal(t, published.ShareCode, again.ShareCode)
+	require.Len(t, attempts, 2)
+
+	// Built-in templates are public already and belong to no one.
+	_, err = svc.Publish(context.Background(), "user-1", "vegan")
+	require.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestTemplateServiceMapsNotFound(t *testing.T) {
+	svc := NewTemplateService(&mockTemplateRepo{resolve: func(context.Context, string, string) (*model.DietaryTemplate, error) {
+		return nil, repository.ErrNotFound
+	}})
+
+	_, err := svc.Get(context.Background(), "", "carnivore")
+	require.ErrorIs(t, err, ErrTemplateNotFound)
+	_, err = svc.Update(context.Background(), "user-1", model.DietaryTemplate{Key: "vegan"})
+	require.ErrorIs(t, err, ErrTemplateNotFound)
+	require.True(t, errors.Is(svc.Delete(context.Background(), "user-1", "vegan"), ErrTemplateNotFound))
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,12 +10,24 @@ import (
 	"github.com/safebites/backend-go/internal/repository"
 )
 
+var (
+	// ErrTooManyLayers is returned when applying another template would
+	// exceed model.MaxTemplateLayers.
+	ErrTooManyLayers = fmt.Errorf("at most %d templates can be applied at once", model.MaxTemplateLayers)
+	// ErrTemplateNotApplied is returned when removing a template the user
+	// has not applied. It matches repository.ErrNotFound.
+	ErrTemplateNotApplied = fmt.Errorf("template not applied: %w", repository.ErrNotFound)
+)
+
 type userService struct {
-	users repository.UserRepository
+	users     repository.UserRepository
+	templates repository.TemplateRepository
 }
 
-func NewUserService(users repository.UserRepository) UserService {
-	return &userService{users: users}
+// NewUserService manages users. templates resolves the templates
+// ApplyTemplate applies.
+func NewUserService(users repository.UserRepository, templates repository.TemplateRepository) UserService {
+	return &userService{users: users, templates: templates}
 }
 
 func (s *userService) GetByID(ctx context.Context, userID string) (*model.User, error) {
@@ -44,24 +57,65 @@ func (s *userService) UpdatePreferences(ctx context.Context, userID string, pref
 	return s.users.UpdatePreferences(ctx, userID, preferences)
 }
 
-func (s *userService) ApplyTemplate(ctx context.Context, userID string, templateKey string) (*model.User, model.DietaryTemplate, error) {
+func (s *userService) ApplyTemplate(ctx context.Context, userID string, templateRef string) (*model.User, model.DietaryTemplate, error) {
 	if strings.TrimSpace(userID) == "" {
 		return nil, model.DietaryTemplate{}, fmt.Errorf("user id is required")
 	}
 
-	template, ok := model.DietaryTemplates[templateKey]
-	if !ok {
-		return nil, model.DietaryTemplate{}, repository.ErrNotFound
+	template, err := resolveTemplate(ctx, s.templates, userID, templateRef)
+	if err != nil {
+		return nil, model.DietaryTemplate{}, err
 	}
 
-	updated, err := s.users.UpdatePreferences(ctx, userID, model.UserPreferences{
-		Allergies:        template.Allergies,
-		DietGoals:        template.DietGoals,
-		AvoidIngredients: template.AvoidIngredients,
-	})
+	current, err := s.users.GetByID(ctx, userID)
 	if err != nil {
 		return nil, model.DietaryTemplate{}, err
 	}
+	applied := false
+	for _, layer := range current.Templates {
+		if layer.Key == template.Key {
+			applied = true
+			break
+		}
+	}
+	if !applied && len(current.Templates) >= model.MaxTemplateLayers {
+		return nil, model.DietaryTemplate{}, ErrTooManyLayers
+	}
 
-	return updated, template, nil
+	// A template shared by another user is copied into the layer, so its
+	// owner's later edits or deletion never change this user's preferences.
+	layer := model.TemplateLayer{Key: template.Key}
+	if template.OwnerID != "" && template.OwnerID != userID {
+		layer.Name = template.Name
+		layer.PreferenceLists = model.PreferenceLists{
+			Allergies:        append([]model.Allergy{}, template.Allergies...),
+			DietGoals:        append([]string{}, template.DietGoals...),
+			AvoidIngredients: append([]string{}, template.AvoidIngredients...),
+		}
+		layer.Snapshot = true
+	}
+	if err := s.users.AddTemplateLayer(ctx, userID, layer); err != nil {
+		return nil, model.DietaryTemplate{}, err
+	}
+	updated, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, model.DietaryTemplate{}, err
+	}
+
+	return updated, *template, nil
+}
+
+func (s *userService) RemoveTemplate(ctx context.Context, userID, templateKey string) (*model.User, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+
+	err := s.users.RemoveTemplateLayer(ctx, userID, templateKey)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTemplateNotApplied
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.users.GetByID(ctx, userID)
 }
@@ -14,6 +14,8 @@ type mockServiceUserRepo struct {
 	getByID           func(ctx context.Context, userID string) (*model.User, error)
 	upsert            func(ctx context.Context, user *model.User) (*model.User, error)
 	updatePreferences func(ctx context.Context, userID string, preferences model.UserPreferences) (*model.User, error)
+	addLayer          func(ctx context.Context, userID string, layer model.TemplateLayer) error
+	removeLayer       func(ctx context.Context, userID, templateKey string) error
 }
 
 func (m *mockServiceUserRepo) GetByID(ctx context.Context, userID string) (*model.User, error) {
@@ -28,6 +30,18 @@ func (m *mockServiceUserRepo) UpdatePreferences(ctx context.Context, userID stri
 	return m.updatePreferences(ctx, userID, preferences)
 }
 
+func (m *mockServiceUserRepo) AddTemplateLayer(ctx context.Context, userID string, layer model.TemplateLayer) error {
+	return m.addLayer(ctx, userID, layer)
+}
+
+func (m *mockServiceUserRepo) RemoveTemplateLayer(ctx context.Context, userID, templateKey string) error {
+	return m.removeLayer(ctx, userID, templateKey)
+}
+
+func (m *mockServiceUserRepo) ListPreferenceHistory(context.Context, string, int) ([]model.PreferenceVersion, error) {
+	return nil, nil
+}
+
 func TestUserServiceGetByID(t *testing.T) {
 	now := time.Now()
 	svc := NewUserService(&mockServiceUserRepo{
@@ -36,7 +50,7 @@ func TestUserServiceGetByID(t *testing.T) {
 		},
 		upsert:            nil,
 		updatePreferences: nil,
-	})
+	}, nil)
 
 	user, err := svc.GetByID(context.Background(), "user-1")
 	require.NoError(t, err)
@@ -51,7 +65,7 @@ func TestUserServiceGetByIDEmptyUserID(t *testing.T) {
 		},
 		upsert:            nil,
 		updatePreferences: nil,
-	})
+	}, nil)
 
 	_, err := svc.GetByID(context.Background(), "   ")
 	require.Error(t, err)
@@ -65,7 +79,7 @@ func TestUserServiceUpsert(t *testing.T) {
 			return user, nil
 		},
 		updatePreferences: nil,
-	})
+	}, nil)
 
 	user, err := svc.Upsert(context.Background(), &model.User{ID: "user-1", Email: "user@example.com"})
 	require.NoError(t, err)
@@ -80,7 +94,7 @@ func TestUserServiceUpsertValidation(t *testing.T) {
 			return nil, nil
 		},
 		updatePreferences: nil,
-	})
+	}, nil)
 
 	_, err := svc.Upsert(context.Background(), nil)
 	require.Error(t, err)
@@ -102,7 +116,7 @@ func TestUserServiceUpdatePreferences(t *testing.T) {
 		updatePreferences: func(_ context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
 			return &model.User{ID: userID, DietGoals: preferences.DietGoals}, nil
 		},
-	})
+	}, nil)
 
 	updated, err := svc.UpdatePreferences(context.Background(), "user-1", model.UserPreferences{DietGoals: []string{"keto"}})
 	require.NoError(t, err)
@@ -110,36 +124,141 @@ func TestUserServiceUpdatePreferences(t *testing.T) {
 }
 
 func TestUserServiceApplyTemplate(t *testing.T) {
+	// The user is vegan already and has a peanut allergy of their own.
+	user := &model.User{ID: "user-1", HomeRegion: "EU", Custom: model.PreferenceLists{Allergies: model.Allergies("peanuts")}}
+	user.Templates = []model.TemplateLayer{{Key: "vegan", PreferenceLists: model.PreferenceLists{DietGoals: []string{"vegan"}}}}
 	svc := NewUserService(&mockServiceUserRepo{
-		getByID: nil,
-		upsert:  nil,
-		updatePreferences: func(_ context.Context, userID string, preferences model.UserPreferences) (*model.User, error) {
-			return &model.User{
-				ID:               userID,
-				Allergies:        preferences.Allergies,
-				DietGoals:        preferences.DietGoals,
-				AvoidIngredients: preferences.AvoidIngredients,
-			}, nil
+		getByID: func(_ context.Context, userID string) (*model.User, error) {
+			merged := *user
+			model.MergeLayers(&merged)
+			return &merged, nil
 		},
-	})
+		addLayer: func(_ context.Context, userID string, layer model.TemplateLayer) error {
+			require.False(t, layer.Snapshot, "built-in templates are followed, not copied")
+			tpl := model.DietaryTemplates[layer.Key]
+			user.Templates = append(user.Templates, model.TemplateLayer{Key: tpl.Key, Name: tpl.Name, PreferenceLists: model.PreferenceLists{
+				Allergies:        tpl.Allergies,
+				DietGoals:        tpl.DietGoals,
+				AvoidIngredients: tpl.AvoidIngredients,
+			}})
+			return nil
+		},
+	}, &mockTemplateRepo{resolve: func(_ context.Context, userID, ref string) (*model.DietaryTemplate, error) {
+		require.Equal(t, "user-1", userID)
+		tpl := model.DietaryTemplates[ref]
+		return &tpl, nil
+	}})
 
-	updated, template, err := svc.ApplyTemplate(context.Background(), "user-1", "vegan")
+	updated, template, err := svc.ApplyTemplate(context.Background(), "user-1", "nut_free")
 	require.NoError(t, err)
-	require.Equal(t, "vegan", template.Key)
-	require.Equal(t, template.DietGoals, updated.DietGoals)
+	require.Equal(t, "nut_free", template.Key)
+	require.Len(t, updated.Templates, 2)
+	require.Equal(t, model.Allergies("peanuts", "tree nuts"), updated.Allergies, "the layers merge without duplicates")
+	require.Equal(t, []string{"custom", "nut_free"}, updated.Sources.Allergies["peanuts"])
+	require.Equal(t, []string{"vegan", "nut-free"}, updated.DietGoals)
+	require.Equal(t, "EU", updated.HomeRegion)
+}
+
+func TestUserServiceApplyTemplateLayerLimit(t *testing.T) {
+	user := &model.User{ID: "user-1", Templates: make([]model.TemplateLayer, model.MaxTemplateLayers)}
+	svc := NewUserService(&mockServiceUserRepo{
+		getByID: func(context.Context, string) (*model.User, error) { return user, nil },
+		addLayer: func(context.Context, string, model.TemplateLayer) error {
+			t.Fatal("repo should not be called")
+			return nil
+		},
+	}, &mockTemplateRepo{resolve: func(_ context.Context, _ string, ref string) (*model.DietaryTemplate, error) {
+		tpl := model.DietaryTemplates[ref]
+		return &tpl, nil
+	}})
+
+	_, _, err := svc.ApplyTemplate(context.Background(), "user-1", "vegan")
+	require.ErrorIs(t, err, ErrTooManyLayers)
+}
+
+func TestUserServiceRemoveTemplate(t *testing.T) {
+	svc := NewUserService(&mockServiceUserRepo{
+		getByID: func(_ context.Context, userID string) (*model.User, error) {
+			return &model.User{ID: userID}, nil
+		},
+		removeLayer: func(_ context.Context, _ string, templateKey string) error {
+			if templateKey != "vegan" {
+				return repository.ErrNotFound
+			}
+			return nil
+		},
+	}, nil)
+
+	updated, err := svc.RemoveTemplate(context.Background(), "user-1", "vegan")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", updated.ID)
+
+	_, err = svc.RemoveTemplate(context.Background(), "user-1", "keto")
+	require.ErrorIs(t, err, ErrTemplateNotApplied)
 }
 
 func TestUserServiceApplyTemplateNotFound(t *testing.T) {
 	svc := NewUserService(&mockServiceUserRepo{
 		getByID: nil,
 		upsert:  nil,
-		updatePreferences: func(_ context.Context, _ string, _ model.UserPreferences) (*model.User, error) {
+		addLayer: func(context.Context, string, model.TemplateLayer) error {
 			t.Fatal("repo should not be called")
-			return nil, nil
+			return nil
 		},
-	})
+	}, &mockTemplateRepo{resolve: func(context.Context, string, string) (*model.DietaryTemplate, error) {
+		return nil, repository.ErrNotFound
+	}})
 
 	_, _, err := svc.ApplyTemplate(context.Background(), "user-1", "does-not-exist")
 	require.Error(t, err)
 	require.ErrorIs(t, err, repository.ErrNotFound)
+	require.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestUserServiceApplyTemplateSnapshotsOtherUsersTemplates(t *testing.T) {
+	shared := model.DietaryTemplate{
+		Key:              "tpl-shared",
+		OwnerID:          "user-2",
+		Name:             "Low FODMAP",
+		Allergies:        model.Allergies("gluten"),
+		DietGoals:        []string{"low-fodmap"},
+		AvoidIngredients: []string{"onion", "garlic"},
+		ShareCode:        "ABCD2345",
+	}
+	own := model.DietaryTemplate{Key: "tpl-own", OwnerID: "user-1", Name: "Mine", Allergies: []model.Allergy{}, DietGoals: []string{}, AvoidIngredients: []string{"kiwi"}}
+
+	var added []model.TemplateLayer
+	svc := NewUserService(&mockServiceUserRepo{
+		getByID: func(context.Context, string) (*model.User, error) { return &model.User{ID: "user-1"}, nil },
+		addLayer: func(_ context.Context, _ string, layer model.TemplateLayer) error {
+			added = append(added, layer)
+			return nil
+		},
+	}, &mockTemplateRepo{resolve: func(_ context.Context, _ string, ref string) (*model.DietaryTemplate, error) {
+		if ref == shared.ShareCode {
+			tpl := shared
+			return &tpl, nil
+		}
+		tpl := own
+		return &tpl, nil
+	}})
+
+	_, _, err := svc.ApplyTemplate(context.Background(), "user-1", shared.ShareCode)
+	require.NoError(t, err)
+	_, _, err = svc.ApplyTemplate(context.Background(), "user-1", own.Key)
+	require.NoError(t, err)
+
+	require.Equal(t, []model.TemplateLayer{
+		{
+			Key:  "tpl-shared",
+			Name: "Low FODMAP",
+			PreferenceLists: model.PreferenceLists{
+				Allergies:        model.Allergies("gluten"),
+				DietGoals:        []string{"low-fodmap"},
+				AvoidIngredients: []string{"onion", "garlic"},
+			},
+			Snapshot: true,
+		},
+		{Key: "tpl-own"},
+	}, added, "another user's template is copied; the user's own is followed")
 }
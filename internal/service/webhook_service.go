@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+// DeliveryQueue schedules a webhook delivery for sending.
+type DeliveryQueue interface {
+	Enqueue(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	webhooks repository.WebhookRepository
+	queue    DeliveryQueue
+}
+
+func NewWebhookService(webhooks repository.WebhookRepository, queue DeliveryQueue) WebhookService {
+	return &webhookService{webhooks: webhooks, queue: queue}
+}
+
+func (s *webhookService) Subscribe(ctx context.Context, userID, appName, url string, events []string) (*model.WebhookSubscription, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.webhooks.CreateSubscription(ctx, &model.WebhookSubscription{
+		ID:      uuid.NewString(),
+		UserID:  userID,
+		AppName: appName,
+		URL:     url,
+		Secret:  secret,
+		Events:  events,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context, userID string) ([]model.WebhookSubscription, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	subs, err := s.webhooks.ListSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// The secret is shown once, at creation.
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *webhookService) Unsubscribe(ctx context.Context, userID, subscriptionID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return fmt.Errorf("user id is required")
+	}
+	return s.webhooks.DeleteSubscription(ctx, userID, subscriptionID)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, userID, subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	if _, err := s.webhooks.GetSubscription(ctx, userID, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.webhooks.ListDeliveries(ctx, subscriptionID, limit)
+}
+
+func (s *webhookService) Replay(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	original, err := s.webhooks.GetDelivery(ctx, userID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.queue.Enqueue(ctx, &model.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		ReplayOf:       &original.ID,
+	})
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+// mockWebhookRepo implements the methods the tests set; the embedded nil
+// interface panics on anything else.
+type mockWebhookRepo struct {
+	repository.WebhookRepository
+	createSubscription func(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	getSubscription    func(ctx context.Context, userID, subscriptionID string) (*model.WebhookSubscription, error)
+	listSubscriptions  func(ctx context.Context, userID string) ([]model.WebhookSubscription, error)
+	listDeliveries     func(ctx context.Context, subscriptionID string, limit int) ([]model.WebhookDelivery, error)
+	getDelivery        func(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error)
+}
+
+func (m *mockWebhookRepo) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	return m.createSubscription(ctx, sub)
+}
+
+func (m *mockWebhookRepo) GetSubscription(ctx context.Context, userID, subscriptionID string) (*model.WebhookSubscription, error) {
+	return m.getSubscription(ctx, userID, subscriptionID)
+}
+
+func (m *mockWebhookRepo) ListSubscriptions(ctx context.Context, userID string) ([]model.WebhookSubscription, error) {
+	return m.listSubscriptions(ctx, userID)
+}
+
+func (m *mockWebhookRepo) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	return m.listDeliveries(ctx, subscriptionID, limit)
+}
+
+func (m *mockWebhookRepo) GetDelivery(ctx context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error) {
+	return m.getDelivery(ctx, userID, deliveryID)
+}
+
+type mockDeliveryQueue struct {
+	enqueued []model.WebhookDelivery
+}
+
+func (m *mockDeliveryQueue) Enqueue(_ context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	m.enqueued = append(m.enqueued, *delivery)
+	queued := *delivery
+	queued.ID = 99
+	queued.Status = model.DeliveryPending
+	return &queued, nil
+}
+
+func TestWebhookServiceSubscribeGeneratesSecret(t *testing.T) {
+	svc := NewWebhookService(&mockWebhookRepo{
+		createSubscription: func(_ context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+			return sub, nil
+		},
+	}, &mockDeliveryQueue{})
+
+	first, err := svc.Subscribe(context.Background(), "user-1", "Pantry", "https://pantry.example.com/hook", []string{model.EventScanCreated})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.ID)
+	require.True(t, strings.HasPrefix(first.Secret, "whsec_"))
+
+	second, err := svc.Subscribe(context.Background(), "user-1", "Pantry", "https://pantry.example.com/hook", []string{model.EventScanCreated})
+	require.NoError(t, err)
+	require.NotEqual(t, first.Secret, second.Secret)
+}
+
+func TestWebhookServiceListSubscriptionsHidesSecrets(t *testing.T) {
+	svc := NewWebhookService(&mockWebhookRepo{
+		listSubscriptions: func(_ context.Context, userID string) ([]model.WebhookSubscription, error) {
+			return []model.WebhookSubscription{{ID: "sub-1", UserID: userID, Secret: "whsec_x"}}, nil
+		},
+	}, &mockDeliveryQueue{})
+
+	subs, err := svc.ListSubscriptions(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Empty(t, subs[0].Secret)
+}
+
+func TestWebhookServiceListDeliveriesChecksOwnership(t *testing.T) {
+	svc := NewWebhookService(&mockWebhookRepo{
+		getSubscription: func(context.Context, string, string) (*model.WebhookSubscription, error) {
+			return nil, repository.ErrNotFound
+		},
+		listDeliveries: func(context.Context, string, int) ([]model.WebhookDelivery, error) {
+			t.Fatal("deliveries of another user's subscription must not be listed")
+			return nil, nil
+		},
+	}, &mockDeliveryQueue{})
+
+	_, err := svc.ListDeliveries(context.Background(), "user-2", "sub-1", 50)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestWebhookServiceReplayQueuesSameEvent(t *testing.T) {
+	queue := &mockDeliveryQueue{}
+	payload := json.RawMessage(`{"id":"evt-1"}`)
+	svc := NewWebhookService(&mockWebhookRepo{
+		getDelivery: func(_ context.Context, userID string, deliveryID int64) (*model.WebhookDelivery, error) {
+			require.Equal(t, "user-1", userID)
+			return &model.WebhookDelivery{
+				ID:             deliveryID,
+				SubscriptionID: "sub-1",
+				EventID:        "evt-1",
+				EventType:      model.EventAnalysisCompleted,
+				Payload:        payload,
+				Status:         model.DeliveryFailed,
+				Attempts:       8,
+			}, nil
+		},
+	}, queue)
+
+	replay, err := svc.Replay(context.Background(), "user-1", 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(99), replay.ID)
+	require.Len(t, queue.enqueued, 1)
+	queued := queue.enqueued[0]
+	require.Equal(t, "sub-1", queued.SubscriptionID)
+	require.Equal(t, "evt-1", queued.EventID)
+	require.Equal(t, payload, queued.Payload)
+	require.Zero(t, queued.Attempts)
+	require.Equal(t, int64(5), *queued.ReplayOf)
+}
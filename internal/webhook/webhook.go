@@ -0,0 +1,358 @@
+// Package webhook delivers scan and analysis events to the endpoints partner
+// apps subscribe for a user.
+//
+// Events are queued as delivery rows first and sent by a worker, so a slow or
+// failing endpoint never holds up the request that raised the event, and
+// retries survive a restart. Each POST carries the event envelope as JSON
+// and a signature header:
+//
+//	X-SafeBites-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256>
+//
+// where the HMAC is keyed with the subscription secret over "<t>.<body>".
+// Receivers should recompute it and reject stale timestamps.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderEvent     = "X-SafeBites-Event"
+	HeaderDelivery  = "X-SafeBites-Delivery"
+	HeaderSignature = "X-SafeBites-Signature"
+)
+
+// maxErrorLength bounds the error text kept in the delivery log.
+const maxErrorLength = 500
+
+// Config tunes delivery. Zero fields take the defaults below.
+type Config struct {
+	// MaxAttempts is how many times a delivery is tried before it is marked
+	// failed. Default 8.
+	MaxAttempts int
+	// BaseDelay is the wait after the first failed attempt; it doubles with
+	// every further failure up to MaxDelay. Defaults 30s and 1h.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Timeout bounds one POST. Default 10s.
+	Timeout time.Duration
+	// PollInterval is how often the worker looks for due retries. Default 5s.
+	PollInterval time.Duration
+	// BatchSize is how many deliveries the worker sends at once. Default 20.
+	BatchSize int
+	// AllowPrivateNetworks lets the default client connect to loopback,
+	// private, and link-local addresses. Only for local development.
+	AllowPrivateNetworks bool
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 8
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = 30 * time.Second
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = time.Hour
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 5 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 20
+	}
+	return c
+}
+
+// Dispatcher queues events for a user's subscribers and sends them.
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	cfg    Config
+	now    func() time.Time
+	wake   chan struct{}
+}
+
+// New returns a dispatcher over repo. A nil client gets one that does not
+// follow redirects, so an endpoint cannot bounce deliveries elsewhere, and
+// that refuses to connect to non-public addresses unless
+// cfg.AllowPrivateNetworks is set.
+func New(repo repository.WebhookRepository, cfg Config, client *http.Client) *Dispatcher {
+	if client == nil {
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+		if !cfg.AllowPrivateNetworks {
+			dialer.Control = refusePrivateAddress
+		}
+		client = &http.Client{
+			// No proxy: the dialer must see the endpoint's own address.
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 10 * time.Second,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	return &Dispatcher{
+		repo:   repo,
+		client: client,
+		cfg:    cfg.withDefaults(),
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// ErrPrivateAddress is returned when a webhook endpoint resolves to an
+// address inside our network.
+var ErrPrivateAddress = errors.New("webhook endpoint address is not public")
+
+// PublicIP reports whether ip may receive deliveries: anything but
+// loopback, private, link-local (which includes cloud metadata at
+// 169.254.169.254), multicast, and unspecified addresses.
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// refusePrivateAddress is a net.Dialer Control hook. It runs on the
+// resolved address of every connection, so a hostname that is re-pointed
+// after the subscription was created (DNS rebinding) is still caught.
+func refusePrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the wait before the attempt after the given number of
+// failed attempts.
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseDelay
+	for i := 1; i < attempts && delay < d.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxDelay {
+		delay = d.cfg.MaxDelay
+	}
+	return delay
+}
+
+// Publish queues an event for every subscriber of userID to eventType.
+// Failures are logged rather than returned: a lost notification must not
+// fail the scan or analysis that raised it.
+func (d *Dispatcher) Publish(ctx context.Context, userID, eventType string, data interface{}) {
+	if userID == "" {
+		return
+	}
+	subs, err := d.repo.ListSubscribers(ctx, userID, eventType)
+	if err != nil {
+		log.Printf("webhook list subscribers failed user=%s event=%s err=%v", userID, eventType, err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	event := model.WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		UserID:    userID,
+		CreatedAt: d.now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("webhook encode event failed event=%s err=%v", eventType, err)
+		return
+	}
+
+	for _, sub := range subs {
+		_, err := d.Enqueue(ctx, &model.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        payload,
+		})
+		if err != nil {
+			log.Printf("webhook enqueue failed subscription=%s event=%s err=%v", sub.ID, event.ID, err)
+		}
+	}
+}
+
+// Enqueue stores delivery as due now and wakes the worker to send it.
+func (d *Dispatcher) Enqueue(ctx context.Context, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	now := d.now()
+	queued := *delivery
+	queued.Status = model.DeliveryPending
+	queued.NextAttemptAt = &now
+
+	created, err := d.repo.CreateDelivery(ctx, &queued)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return created, nil
+}
+
+// Run sends due deliveries until ctx is canceled, waking on every Enqueue
+// and every PollInterval for retries.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// drain sends batches of due deliveries until none are left.
+func (d *Dispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := d.now()
+		// The lease outlasts one attempt, so a claimed delivery is only picked
+		// up again if this worker died before recording the outcome.
+		attempts, err := d.repo.ClaimDue(ctx, now, now.Add(2*d.cfg.Timeout), d.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("webhook claim due deliveries failed err=%v", err)
+			}
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range attempts {
+			wg.Add(1)
+			go func(a *model.WebhookAttempt) {
+				defer wg.Done()
+				d.attempt(ctx, a)
+			}(&attempts[i])
+		}
+		wg.Wait()
+
+		if len(attempts) < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome, scheduling a retry
+// with exponential backoff until MaxAttempts is reached.
+func (d *Dispatcher) attempt(ctx context.Context, a *model.WebhookAttempt) {
+	delivery := a.Delivery
+	status, err := d.send(ctx, a)
+	if ctx.Err() != nil {
+		// Shutting down; the lease expires and another run retries it.
+		return
+	}
+
+	now := d.now()
+	delivery.Attempts++
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = model.DeliveryFailed
+		delivery.LastError = truncate(err.Error(), maxErrorLength)
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(d.Backoff(delivery.Attempts))
+		delivery.Status = model.DeliveryPending
+		delivery.LastError = truncate(err.Error(), maxErrorLength)
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := d.repo.RecordAttempt(ctx, &delivery); err != nil {
+		log.Printf("webhook record attempt failed delivery=%d err=%v", delivery.ID, err)
+	}
+}
+
+// send posts the delivery and returns the response status, with an error
+// for anything but a 2xx answer.
+func (d *Dispatcher) send(ctx context.Context, a *model.WebhookAttempt) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	body := []byte(a.Delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SafeBites-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, a.Delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(a.Delivery.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(a.Subscription.Secret, d.now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
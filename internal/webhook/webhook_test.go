@@ -0,0 +1,296 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/safebites/backend-go/internal/model"
+	"github.com/safebites/backend-go/internal/repository"
+)
+
+// memRepo keeps subscriptions and deliveries in memory, claiming due
+// deliveries the way the Postgres repository does.
+type memRepo struct {
+	mu         sync.Mutex
+	subs       []model.WebhookSubscription
+	deliveries []model.WebhookDelivery
+}
+
+func (m *memRepo) CreateSubscription(_ context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subs = append(m.subs, *sub)
+	return sub, nil
+}
+
+func (m *memRepo) GetSubscription(_ context.Context, userID, id string) (*model.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.subs {
+		if m.subs[i].UserID == userID && m.subs[i].ID == id {
+			sub := m.subs[i]
+			return &sub, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memRepo) ListSubscriptions(_ context.Context, userID string) ([]model.WebhookSubscription, error) {
+	return m.ListSubscribers(context.Background(), userID, "")
+}
+
+func (m *memRepo) ListSubscribers(_ context.Context, userID, eventType string) ([]model.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []model.WebhookSubscription
+	for _, sub := range m.subs {
+		if sub.UserID != userID {
+			continue
+		}
+		for _, e := range sub.Events {
+			if eventType == "" || e == eventType {
+				out = append(out, sub)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) DeleteSubscription(context.Context, string, string) error {
+	return repository.ErrNotFound
+}
+
+func (m *memRepo) CreateDelivery(_ context.Context, d *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	created := *d
+	created.ID = int64(len(m.deliveries) + 1)
+	m.deliveries = append(m.deliveries, created)
+	return &created, nil
+}
+
+func (m *memRepo) GetDelivery(_ context.Context, _ string, id int64) (*model.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || int(id) > len(m.deliveries) {
+		return nil, repository.ErrNotFound
+	}
+	d := m.deliveries[id-1]
+	return &d, nil
+}
+
+func (m *memRepo) ListDeliveries(context.Context, string, int) ([]model.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (m *memRepo) ClaimDue(_ context.Context, now, leaseUntil time.Time, limit int) ([]model.WebhookAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []model.WebhookAttempt
+	for i := range m.deliveries {
+		d := &m.deliveries[i]
+		if len(out) == limit || d.Status != model.DeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		lease := leaseUntil
+		d.NextAttemptAt = &lease
+		for _, sub := range m.subs {
+			if sub.ID == d.SubscriptionID {
+				out = append(out, model.WebhookAttempt{Delivery: *d, Subscription: sub})
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) RecordAttempt(_ context.Context, d *model.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries[d.ID-1] = *d
+	return nil
+}
+
+func (m *memRepo) delivery(id int64) model.WebhookDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deliveries[id-1]
+}
+
+func TestSignIsHMACOfTimestampAndBody(t *testing.T) {
+	body := []byte(`{"type":"scan.created"}`)
+	at := time.Unix(1700000000, 0)
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	require.Equal(t, want, Sign("whsec_test", at, body))
+	require.NotEqual(t, want, Sign("other", at, body))
+}
+
+func TestBackoffDoublesUpToMaxDelay(t *testing.T) {
+	d := New(&memRepo{}, Config{BaseDelay: time.Second, MaxDelay: 10 * time.Second}, nil)
+
+	require.Equal(t, time.Second, d.Backoff(1))
+	require.Equal(t, 2*time.Second, d.Backoff(2))
+	require.Equal(t, 8*time.Second, d.Backoff(4))
+	require.Equal(t, 10*time.Second, d.Backoff(5))
+	require.Equal(t, 10*time.Second, d.Backoff(40))
+}
+
+func TestPublishDeliversSignedEvent(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	repo := &memRepo{subs: []model.WebhookSubscription{
+		{ID: "sub-1", UserID: "user-1", URL: srv.URL, Secret: "whsec_test", Events: []string{model.EventScanCreated}},
+		{ID: "sub-2", UserID: "user-1", URL: srv.URL, Secret: "whsec_test", Events: []string{model.EventAnalysisCompleted}},
+	}}
+	now := time.Unix(1700000000, 0)
+	d := New(repo, Config{}, srv.Client())
+	d.now = func() time.Time { return now }
+
+	d.Publish(context.Background(), "user-1", model.EventScanCreated, model.Scan{ID: "scan-1", ProductName: "Oat Milk"})
+	require.Len(t, repo.deliveries, 1)
+	d.drain(context.Background())
+
+	require.NotNil(t, got)
+	require.Equal(t, model.EventScanCreated, got.Header.Get(HeaderEvent))
+	require.Equal(t, "1", got.Header.Get(HeaderDelivery))
+	require.Equal(t, Sign("whsec_test", now, gotBody), got.Header.Get(HeaderSignature))
+
+	var event model.WebhookEvent
+	require.NoError(t, json.Unmarshal(gotBody, &event))
+	require.Equal(t, model.EventScanCreated, event.Type)
+	require.Equal(t, "user-1", event.UserID)
+	require.Equal(t, "scan-1", event.Data.(map[string]interface{})["id"])
+
+	delivery := repo.delivery(1)
+	require.Equal(t, model.DeliverySucceeded, delivery.Status)
+	require.Equal(t, 1, delivery.Attempts)
+	require.Equal(t, http.StatusNoContent, *delivery.ResponseStatus)
+	require.NotNil(t, delivery.DeliveredAt)
+	require.Nil(t, delivery.NextAttemptAt)
+}
+
+func TestPublishSkipsAnonymousCallers(t *testing.T) {
+	repo := &memRepo{subs: []model.WebhookSubscription{
+		{ID: "sub-1", UserID: "", Events: []string{model.EventScanCreated}},
+	}}
+	New(repo, Config{}, nil).Publish(context.Background(), "", model.EventScanCreated, nil)
+	require.Empty(t, repo.deliveries)
+}
+
+func TestFailedDeliveryRetriesWithBackoffThenFails(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	repo := &memRepo{subs: []model.WebhookSubscription{
+		{ID: "sub-1", UserID: "user-1", URL: srv.URL, Events: []string{model.EventAnalysisCompleted}},
+	}}
+	now := time.Unix(1700000000, 0)
+	d := New(repo, Config{MaxAttempts: 3, BaseDelay: time.Minute}, srv.Client())
+	d.now = func() time.Time { return now }
+
+	d.Publish(context.Background(), "user-1", model.EventAnalysisCompleted, map[string]string{"scanId": "scan-1"})
+	d.drain(context.Background())
+
+	delivery := repo.delivery(1)
+	require.Equal(t, model.DeliveryPending, delivery.Status)
+	require.Equal(t, 1, delivery.Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, *delivery.ResponseStatus)
+	require.Contains(t, delivery.LastError, "503")
+	require.Equal(t, now.Add(time.Minute), *delivery.NextAttemptAt)
+
+	// Not due yet.
+	d.drain(context.Background())
+	require.Equal(t, 1, calls)
+
+	now = now.Add(time.Minute)
+	d.drain(context.Background())
+	require.Equal(t, now.Add(2*time.Minute), *repo.delivery(1).NextAttemptAt)
+
+	now = now.Add(2 * time.Minute)
+	d.drain(context.Background())
+	delivery = repo.delivery(1)
+	require.Equal(t, 3, calls)
+	require.Equal(t, model.DeliveryFailed, delivery.Status)
+	require.Equal(t, 3, delivery.Attempts)
+	require.Nil(t, delivery.NextAttemptAt)
+}
+
+func TestRedirectsCountAsFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	repo := &memRepo{subs: []model.WebhookSubscription{
+		{ID: "sub-1", UserID: "user-1", URL: srv.URL, Events: []string{model.EventScanCreated}},
+	}}
+	// The test server listens on loopback.
+	d := New(repo, Config{AllowPrivateNetworks: true}, nil)
+	d.Publish(context.Background(), "user-1", model.EventScanCreated, nil)
+	d.drain(context.Background())
+
+	delivery := repo.delivery(1)
+	require.Equal(t, model.DeliveryPending, delivery.Status)
+	require.Equal(t, http.StatusFound, *delivery.ResponseStatus)
+}
+
+func TestDefaultClientRefusesLoopbackEndpoints(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// localhost resolves at connect time, as a rebound DNS name would.
+	endpoint := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	repo := &memRepo{subs: []model.WebhookSubscription{
+		{ID: "sub-1", UserID: "user-1", URL: endpoint, Events: []string{model.EventScanCreated}},
+	}}
+	d := New(repo, Config{}, nil)
+	d.Publish(context.Background(), "user-1", model.EventScanCreated, nil)
+	d.drain(context.Background())
+
+	require.False(t, called)
+	delivery := repo.delivery(1)
+	require.Equal(t, model.DeliveryPending, delivery.Status)
+	require.Nil(t, delivery.ResponseStatus)
+	require.Contains(t, delivery.LastError, ErrPrivateAddress.Error())
+}
+
+func TestPublicIP(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0", "::ffff:127.0.0.1"} {
+		require.False(t, PublicIP(net.ParseIP(addr)), addr)
+	}
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		require.True(t, PublicIP(net.ParseIP(addr)), addr)
+	}
+}
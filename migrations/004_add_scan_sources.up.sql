@@ -0,0 +1 @@
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS sources JSONB NOT NULL DEFAULT '[]'::jsonb;
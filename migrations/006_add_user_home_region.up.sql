@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS home_region TEXT NOT NULL DEFAULT '';
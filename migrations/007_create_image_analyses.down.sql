@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_image_analyses_created_at;
+DROP TABLE IF EXISTS image_analyses;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS image_analyses (
+    id               BIGSERIAL        PRIMARY KEY,
+    phash            BIGINT           NOT NULL,
+    product_name     TEXT             NOT NULL,
+    label_language   TEXT             NOT NULL DEFAULT '',
+    ocr_confidence   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    preferences_key  TEXT             NOT NULL DEFAULT '',
+    analysis         JSONB,
+    created_at       TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_image_analyses_created_at ON image_analyses(created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS image_id TEXT NOT NULL DEFAULT '';
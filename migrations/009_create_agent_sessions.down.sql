@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS agent_user_states;
+DROP TABLE IF EXISTS agent_app_states;
+DROP TABLE IF EXISTS agent_session_events;
+DROP TABLE IF EXISTS agent_sessions;
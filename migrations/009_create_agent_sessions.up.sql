@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS agent_sessions (
+    app_name    TEXT        NOT NULL,
+    user_id     TEXT        NOT NULL,
+    id          TEXT        NOT NULL,
+    state       JSONB       NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (app_name, user_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_sessions_user ON agent_sessions(user_id, updated_at DESC);
+
+CREATE TABLE IF NOT EXISTS agent_session_events (
+    seq            BIGSERIAL   PRIMARY KEY,
+    id             TEXT        NOT NULL,
+    app_name       TEXT        NOT NULL,
+    user_id        TEXT        NOT NULL,
+    session_id     TEXT        NOT NULL,
+    invocation_id  TEXT        NOT NULL DEFAULT '',
+    author         TEXT        NOT NULL DEFAULT '',
+    timestamp      TIMESTAMPTZ NOT NULL,
+    event          JSONB       NOT NULL,
+    FOREIGN KEY (app_name, user_id, session_id) REFERENCES agent_sessions(app_name, user_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_session_events_session ON agent_session_events(app_name, user_id, session_id, seq);
+
+CREATE TABLE IF NOT EXISTS agent_app_states (
+    app_name  TEXT  PRIMARY KEY,
+    state     JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS agent_user_states (
+    app_name  TEXT  NOT NULL,
+    user_id   TEXT  NOT NULL,
+    state     JSONB NOT NULL DEFAULT '{}',
+    PRIMARY KEY (app_name, user_id)
+);
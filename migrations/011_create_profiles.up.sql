@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS profiles (
+    id                TEXT        PRIMARY KEY,
+    user_id           TEXT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name              TEXT        NOT NULL,
+    allergies         JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    diet_goals        JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    avoid_ingredients JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Profiles are picked by name in the app, so names are unique per account.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profiles_user_name ON profiles(user_id, LOWER(name));
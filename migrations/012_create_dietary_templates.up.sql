@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS dietary_templates (
+    key               TEXT        PRIMARY KEY,
+    -- NULL for the built-in templates, which the server writes at startup.
+    owner_id          TEXT        REFERENCES users(id) ON DELETE CASCADE,
+    name              TEXT        NOT NULL,
+    description       TEXT        NOT NULL DEFAULT '',
+    allergies         JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    diet_goals        JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    avoid_ingredients JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    share_code        TEXT        UNIQUE,
+    forked_from       TEXT        REFERENCES dietary_templates(key) ON DELETE SET NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dietary_templates_owner ON dietary_templates(owner_id, created_at);
@@ -0,0 +1,10 @@
+-- The allergies, diet_goals, and avoid_ingredients columns of users hold the
+-- user's own entries; each row here layers one template on top of them.
+CREATE TABLE IF NOT EXISTS user_template_layers (
+    user_id      TEXT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    template_key TEXT        NOT NULL REFERENCES dietary_templates(key) ON DELETE CASCADE,
+    applied_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, template_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_template_layers_user ON user_template_layers(user_id, applied_at);
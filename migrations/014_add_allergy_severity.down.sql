@@ -0,0 +1,21 @@
+-- Severities and "may contain" settings are lost; only the names are kept.
+UPDATE users SET allergies = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN jsonb_typeof(a.value) = 'object' THEN a.value -> 'name' ELSE a.value END
+        ORDER BY a.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements(users.allergies) WITH ORDINALITY AS a(value, ordinality)
+);
+
+UPDATE profiles SET allergies = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN jsonb_typeof(a.value) = 'object' THEN a.value -> 'name' ELSE a.value END
+        ORDER BY a.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements(profiles.allergies) WITH ORDINALITY AS a(value, ordinality)
+);
+
+UPDATE dietary_templates SET allergies = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN jsonb_typeof(a.value) = 'object' THEN a.value -> 'name' ELSE a.value END
+        ORDER BY a.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements(dietary_templates.allergies) WITH ORDINALITY AS a(value, ordinality)
+);
@@ -0,0 +1,29 @@
+-- Allergy lists change from names to objects carrying a severity and whether
+-- "may contain" warnings count. Existing names become plain allergies; the
+-- application still reads either form.
+UPDATE users SET allergies = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN jsonb_typeof(a.value) = 'string'
+             THEN jsonb_build_object('name', a.value #>> '{}', 'severity', 'allergy', 'mayContain', false)
+             ELSE a.value END
+        ORDER BY a.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements(users.allergies) WITH ORDINALITY AS a(value, ordinality)
+);
+
+UPDATE profiles SET allergies = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN jsonb_typeof(a.value) = 'string'
+             THEN jsonb_build_object('name', a.value #>> '{}', 'severity', 'allergy', 'mayContain', false)
+             ELSE a.value END
+        ORDER BY a.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements(profiles.allergies) WITH ORDINALITY AS a(value, ordinality)
+);
+
+UPDATE dietary_templates SET allergies = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN jsonb_typeof(a.value) = 'string'
+             THEN jsonb_build_object('name', a.value #>> '{}', 'severity', 'allergy', 'mayContain', false)
+             ELSE a.value END
+        ORDER BY a.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements(dietary_templates.allergies) WITH ORDINALITY AS a(value, ordinality)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE scans DROP CONSTRAINT IF EXISTS scans_preference_version_fkey;
+ALTER TABLE scans DROP COLUMN IF EXISTS preference_version;
+DROP TABLE IF EXISTS preference_history;
+ALTER TABLE users DROP COLUMN IF EXISTS preference_version;
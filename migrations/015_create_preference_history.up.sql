@@ -0,0 +1,25 @@
+-- preference_version counts the recorded changes to a user's effective
+-- preferences; 0 means none has been recorded yet.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preference_version INT NOT NULL DEFAULT 0;
+
+-- Each row is a user's effective preferences after one change: the user's
+-- own entries merged with the templates applied at the time.
+CREATE TABLE IF NOT EXISTS preference_history (
+    user_id           TEXT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    version           INT         NOT NULL,
+    source            TEXT        NOT NULL,
+    action            TEXT        NOT NULL,
+    allergies         JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    diet_goals        JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    avoid_ingredients JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    home_region       TEXT        NOT NULL DEFAULT '',
+    templates         JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, version)
+);
+
+-- A scan records the preference version it was scored against, or NULL when
+-- it predates the history.
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS preference_version INT;
+ALTER TABLE scans ADD CONSTRAINT scans_preference_version_fkey
+    FOREIGN KEY (user_id, preference_version) REFERENCES preference_history(user_id, version);
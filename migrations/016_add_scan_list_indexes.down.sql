@@ -0,0 +1,6 @@
+-- pg_trgm stays installed; other objects may have come to use it.
+DROP INDEX IF EXISTS idx_scans_brand_trgm;
+DROP INDEX IF EXISTS idx_scans_product_name_trgm;
+CREATE INDEX IF NOT EXISTS idx_scans_user_id ON scans(user_id);
+DROP INDEX IF EXISTS idx_scans_user_score;
+DROP INDEX IF EXISTS idx_scans_user_timestamp;
@@ -0,0 +1,12 @@
+-- Scan lists are paged by keyset on (timestamp, id) or (safety_score,
+-- timestamp, id) within a user; both indexes serve either direction.
+CREATE INDEX IF NOT EXISTS idx_scans_user_timestamp ON scans(user_id, timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_scans_user_score     ON scans(user_id, safety_score DESC, timestamp DESC, id DESC);
+
+-- idx_scans_user_timestamp covers every lookup by user_id.
+DROP INDEX IF EXISTS idx_scans_user_id;
+
+-- Brand and product filters match substrings with ILIKE.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_scans_product_name_trgm ON scans USING GIN (product_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_scans_brand_trgm        ON scans USING GIN (brand gin_trgm_ops);
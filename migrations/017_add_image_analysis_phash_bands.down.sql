@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_image_analyses_phash_band3;
+DROP INDEX IF EXISTS idx_image_analyses_phash_band2;
+DROP INDEX IF EXISTS idx_image_analyses_phash_band1;
+DROP INDEX IF EXISTS idx_image_analyses_phash_band0;
+
+ALTER TABLE image_analyses
+    DROP COLUMN IF EXISTS phash_band3,
+    DROP COLUMN IF EXISTS phash_band2,
+    DROP COLUMN IF EXISTS phash_band1,
+    DROP COLUMN IF EXISTS phash_band0;
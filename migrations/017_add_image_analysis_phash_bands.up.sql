@@ -0,0 +1,13 @@
+-- Near-duplicate lookups probe the hash as four 16-bit bands. Two hashes
+-- within d bits share a band that differs in at most d/4 bits, so each band
+-- is looked up through its own index instead of XOR-ing every cached row.
+ALTER TABLE image_analyses
+    ADD COLUMN IF NOT EXISTS phash_band0 INTEGER GENERATED ALWAYS AS (((phash >> 48) & 65535)::int) STORED,
+    ADD COLUMN IF NOT EXISTS phash_band1 INTEGER GENERATED ALWAYS AS (((phash >> 32) & 65535)::int) STORED,
+    ADD COLUMN IF NOT EXISTS phash_band2 INTEGER GENERATED ALWAYS AS (((phash >> 16) & 65535)::int) STORED,
+    ADD COLUMN IF NOT EXISTS phash_band3 INTEGER GENERATED ALWAYS AS ((phash & 65535)::int) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_image_analyses_phash_band0 ON image_analyses(phash_band0, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_image_analyses_phash_band1 ON image_analyses(phash_band1, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_image_analyses_phash_band2 ON image_analyses(phash_band2, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_image_analyses_phash_band3 ON image_analyses(phash_band3, created_at DESC);
@@ -0,0 +1,14 @@
+DELETE FROM user_template_layers l
+WHERE NOT EXISTS (SELECT 1 FROM dietary_templates t WHERE t.key = l.template_key);
+
+ALTER TABLE user_template_layers
+    ADD CONSTRAINT user_template_layers_template_key_fkey
+    FOREIGN KEY (template_key) REFERENCES dietary_templates(key) ON DELETE CASCADE;
+
+ALTER TABLE user_template_layers DROP CONSTRAINT IF EXISTS user_template_layers_snapshot_check;
+
+ALTER TABLE user_template_layers
+    DROP COLUMN IF EXISTS avoid_ingredients,
+    DROP COLUMN IF EXISTS diet_goals,
+    DROP COLUMN IF EXISTS allergies,
+    DROP COLUMN IF EXISTS name;
@@ -0,0 +1,30 @@
+-- A layer applied from a template another user shared keeps its own copy of
+-- the template's name and lists, so the owner editing or deleting the
+-- template never changes the subscriber's preferences. Layers of built-in
+-- and own templates leave the columns NULL and follow the template.
+ALTER TABLE user_template_layers
+    ADD COLUMN IF NOT EXISTS name              TEXT,
+    ADD COLUMN IF NOT EXISTS allergies         JSONB,
+    ADD COLUMN IF NOT EXISTS diet_goals        JSONB,
+    ADD COLUMN IF NOT EXISTS avoid_ingredients JSONB;
+
+ALTER TABLE user_template_layers
+    ADD CONSTRAINT user_template_layers_snapshot_check CHECK (
+        (name IS NULL) = (allergies IS NULL)
+        AND (allergies IS NULL) = (diet_goals IS NULL)
+        AND (diet_goals IS NULL) = (avoid_ingredients IS NULL)
+    );
+
+UPDATE user_template_layers l
+SET name = t.name,
+    allergies = t.allergies,
+    diet_goals = t.diet_goals,
+    avoid_ingredients = t.avoid_ingredients
+FROM dietary_templates t
+WHERE t.key = l.template_key
+  AND t.owner_id IS NOT NULL
+  AND t.owner_id <> l.user_id;
+
+-- Snapshots outlive their template, so deleting a template can no longer
+-- cascade into the layers; the repository removes the following layers.
+ALTER TABLE user_template_layers DROP CONSTRAINT IF EXISTS user_template_layers_template_key_fkey;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_agent_sessions_updated;
+DROP INDEX IF EXISTS idx_agent_user_states_user;
+
+ALTER TABLE agent_user_states DROP CONSTRAINT IF EXISTS agent_user_states_user_id_fkey;
+ALTER TABLE agent_sessions DROP CONSTRAINT IF EXISTS agent_sessions_user_id_fkey;
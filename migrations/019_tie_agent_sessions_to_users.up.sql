@@ -0,0 +1,15 @@
+-- Agent sessions are only kept for signed-in users now. Sessions of
+-- anonymous runs and of users that no longer exist are dropped, their events
+-- with them, so the user foreign keys can hold.
+DELETE FROM agent_sessions s WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = s.user_id);
+DELETE FROM agent_user_states s WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = s.user_id);
+
+ALTER TABLE agent_sessions ADD CONSTRAINT agent_sessions_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE agent_user_states ADD CONSTRAINT agent_user_states_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_agent_user_states_user ON agent_user_states(user_id);
+
+-- The retention job deletes sessions by their last update.
+CREATE INDEX IF NOT EXISTS idx_agent_sessions_updated ON agent_sessions(updated_at);
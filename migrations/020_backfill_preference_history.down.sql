@@ -0,0 +1,12 @@
+-- Scans recorded against a backfilled version go back to having none.
+UPDATE scans s SET preference_version = NULL
+FROM preference_history h
+WHERE h.action = 'backfilled'
+  AND s.user_id = h.user_id
+  AND s.preference_version = h.version;
+
+DELETE FROM preference_history WHERE action = 'backfilled';
+
+UPDATE users SET preference_version = 0
+WHERE preference_version = 1
+  AND NOT EXISTS (SELECT 1 FROM preference_history h WHERE h.user_id = users.id);
@@ -0,0 +1,97 @@
+-- Users who have not changed their preferences since migration 015 have no
+-- version yet, so nothing can point at what they had. Record their current
+-- effective preferences as version 1, merged like model.MergeLayers: the
+-- user's own entries first, then each applied template oldest first, entries
+-- differing only in case or surrounding space once as first spelled, and an
+-- allergy at the most severe reaction and trace sensitivity any layer gives.
+WITH pending AS (
+    SELECT id, home_region FROM users WHERE preference_version = 0
+),
+layers AS (
+    SELECT u.id AS user_id, 0::bigint AS pos, NULL::text AS template_key,
+           users.allergies, users.diet_goals, users.avoid_ingredients
+    FROM pending u
+    JOIN users ON users.id = u.id
+    UNION ALL
+    SELECT l.user_id,
+           ROW_NUMBER() OVER (PARTITION BY l.user_id ORDER BY l.applied_at, l.template_key),
+           l.template_key,
+           COALESCE(l.allergies, t.allergies),
+           COALESCE(l.diet_goals, t.diet_goals),
+           COALESCE(l.avoid_ingredients, t.avoid_ingredients)
+    FROM user_template_layers l
+    JOIN pending u ON u.id = l.user_id
+    LEFT JOIN dietary_templates t ON t.key = l.template_key
+    WHERE l.allergies IS NOT NULL OR t.key IS NOT NULL
+),
+allergy_entries AS (
+    SELECT layers.user_id, layers.pos, a.ordinality,
+           BTRIM(CASE WHEN jsonb_typeof(a.value) = 'object' THEN a.value ->> 'name' ELSE a.value #>> '{}' END) AS name,
+           COALESCE(a.value ->> 'severity', 'allergy') AS severity,
+           COALESCE((a.value ->> 'mayContain')::boolean, false) AS may_contain
+    FROM layers, jsonb_array_elements(COALESCE(layers.allergies, '[]'::jsonb)) WITH ORDINALITY AS a(value, ordinality)
+),
+merged_allergies AS (
+    SELECT user_id,
+           MIN(pos) AS pos,
+           (ARRAY_AGG(ordinality ORDER BY pos, ordinality))[1] AS ordinality,
+           (ARRAY_AGG(name ORDER BY pos, ordinality))[1] AS name,
+           (ARRAY_AGG(severity ORDER BY CASE severity
+               WHEN 'anaphylactic' THEN 3
+               WHEN 'allergy' THEN 2
+               WHEN 'intolerance' THEN 1
+               WHEN 'preference' THEN 0
+               ELSE -1 END DESC))[1] AS severity,
+           BOOL_OR(may_contain) AS may_contain
+    FROM allergy_entries
+    WHERE name <> ''
+    GROUP BY user_id, LOWER(name)
+),
+allergies AS (
+    SELECT user_id,
+           jsonb_agg(jsonb_build_object('name', name, 'severity', severity, 'mayContain', may_contain)
+               ORDER BY pos, ordinality) AS list
+    FROM merged_allergies
+    GROUP BY user_id
+),
+list_entries AS (
+    SELECT layers.user_id, 'diet_goals' AS list, layers.pos, e.ordinality, BTRIM(e.value) AS entry
+    FROM layers, jsonb_array_elements_text(COALESCE(layers.diet_goals, '[]'::jsonb)) WITH ORDINALITY AS e(value, ordinality)
+    UNION ALL
+    SELECT layers.user_id, 'avoid_ingredients', layers.pos, e.ordinality, BTRIM(e.value)
+    FROM layers, jsonb_array_elements_text(COALESCE(layers.avoid_ingredients, '[]'::jsonb)) WITH ORDINALITY AS e(value, ordinality)
+),
+merged_lists AS (
+    SELECT user_id, list,
+           MIN(pos) AS pos,
+           (ARRAY_AGG(ordinality ORDER BY pos, ordinality))[1] AS ordinality,
+           (ARRAY_AGG(entry ORDER BY pos, ordinality))[1] AS entry
+    FROM list_entries
+    WHERE entry <> ''
+    GROUP BY user_id, list, LOWER(entry)
+),
+lists AS (
+    SELECT user_id, list, jsonb_agg(entry ORDER BY pos, ordinality) AS entries
+    FROM merged_lists
+    GROUP BY user_id, list
+),
+templates AS (
+    SELECT user_id, jsonb_agg(template_key ORDER BY pos) AS keys
+    FROM layers
+    WHERE template_key IS NOT NULL
+    GROUP BY user_id
+)
+INSERT INTO preference_history (user_id, version, source, action, allergies, diet_goals, avoid_ingredients, home_region, templates)
+SELECT u.id, 1, 'manual', 'backfilled',
+       COALESCE(a.list, '[]'::jsonb),
+       COALESCE(g.entries, '[]'::jsonb),
+       COALESCE(i.entries, '[]'::jsonb),
+       u.home_region,
+       COALESCE(t.keys, '[]'::jsonb)
+FROM pending u
+LEFT JOIN allergies a ON a.user_id = u.id
+LEFT JOIN lists g ON g.user_id = u.id AND g.list = 'diet_goals'
+LEFT JOIN lists i ON i.user_id = u.id AND i.list = 'avoid_ingredients'
+LEFT JOIN templates t ON t.user_id = u.id;
+
+UPDATE users SET preference_version = 1 WHERE preference_version = 0;